@@ -29,6 +29,8 @@ import (
 	"ocrserver/internal/opensearch"
 	"ocrserver/internal/rotas"
 	"ocrserver/internal/services"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/services/tribunais"
 	"ocrserver/internal/utils/logger"
 	"ocrserver/internal/utils/middleware"
 )
@@ -81,6 +83,17 @@ func main() {
 	services.InitCnjGlobal(cfg)
 	services.InitOpenaiService(cfg.OpenApiKey, cfg) // idempotente caso sem chave
 	ialib.InitOpenai(cfg.OpenApiKey, cfg)           // idempotente caso sem chave
+	if cfg.LLMCompatBaseURL != "" {
+		ialib.InitOpenaiCompat(cfg) // servidor compatível (vLLM/Ollama), se configurado
+	}
+	if cfg.LLMProviderGeracao == ialib.PROVIDER_FAKE || cfg.LLMProviderEmbedding == ialib.PROVIDER_FAKE {
+		ialib.InitOpenaiFake(cfg) // respostas simuladas, sem rede
+	}
+	tribunais.InitPerfis(cfg) // perfis de tribunal da importação do PJe
+	// Arquivos dos uploads: diretório local ou S3
+	if err := armazenamento.InitArmazenamento(cfg); err != nil {
+		log.Fatalf("erro ao iniciar o armazenamento dos uploads: %v", err)
+	}
 
 	// 4) Router e middlewares
 	router := gin.New()
@@ -102,9 +115,9 @@ func main() {
 	// CORS configurável
 	corsCfg := cors.Config{
 		AllowOrigins:     cfg.AllowedOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization", "X-Request-ID"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "X-Request-ID", "Upload-Offset"},
+		ExposeHeaders:    []string{"Content-Length", "Upload-Offset", "Content-Disposition", "X-Pagina-Inicial"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -123,6 +136,12 @@ func main() {
 	//Iniciar o serviço
 	cleaner.Start(appCtx)
 
+	// Limpeza do armazenamento dos uploads (retomáveis expirados e retenção)
+	services.NewUploadsCleaner(services.UploadServiceGlobal, cfg).Start(appCtx)
+
+	// Fila de jobs assíncronos: retoma os interrompidos e inicia os workers
+	services.JobsServiceGlobal.Start(appCtx)
+
 	/**************************************/
 
 	// 6) Servidor HTTP com shutdown gracioso
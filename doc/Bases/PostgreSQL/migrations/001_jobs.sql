@@ -0,0 +1,28 @@
+-- -----------------------------------------------------------------------------
+-- Migração 001: fila persistente de jobs assíncronos (services/jobsService.go)
+-- Aplicar em ordem, com: psql -v ON_ERROR_STOP=1 -f 001_jobs.sql
+-- Os comandos são idempotentes: reaplicar a migração não altera a base.
+-- -----------------------------------------------------------------------------
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.jobs
+(
+    id_job character(36) PRIMARY KEY,
+    tipo character varying(30) NOT NULL,
+    status character varying(15) NOT NULL DEFAULT 'pendente',
+    progresso integer NOT NULL DEFAULT 0,
+    mensagem text NOT NULL DEFAULT '',
+    params jsonb NOT NULL,
+    resultado jsonb,
+    erro text NOT NULL DEFAULT '',
+    username character varying(20) NOT NULL DEFAULT '',
+    tentativas integer NOT NULL DEFAULT 0,
+    cancelar boolean NOT NULL DEFAULT false,
+    dt_inc timestamp without time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dt_inicio timestamp without time zone,
+    dt_fim timestamp without time zone,
+    dt_heartbeat timestamp without time zone
+);
+CREATE INDEX IF NOT EXISTS jobs_status_dt_inc_idx ON jobs (status, dt_inc);
+
+COMMIT;
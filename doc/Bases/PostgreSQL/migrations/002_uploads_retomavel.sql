@@ -0,0 +1,15 @@
+-- -----------------------------------------------------------------------------
+-- Migração 002: upload retomável em blocos (services/uploadRetomavelService.go)
+-- Status 'R' enquanto recebe e 'S' quando concluído; nm_file_new passa a ser a
+-- chave do arquivo no armazenamento (BLOB_BACKEND), ex.: sha256/ab/<hash>.pdf
+-- Aplicar em ordem, com: psql -v ON_ERROR_STOP=1 -f 002_uploads_retomavel.sql
+-- -----------------------------------------------------------------------------
+BEGIN;
+
+ALTER TABLE uploads
+    ADD COLUMN IF NOT EXISTS bytes_total bigint,
+    ADD COLUMN IF NOT EXISTS bytes_recebidos bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sha256 character(64),
+    ADD COLUMN IF NOT EXISTS dt_expira timestamp without time zone;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- -----------------------------------------------------------------------------
+-- Migração 003: arquivos já extraídos no contexto (hash SHA-256 do arquivo), para
+-- detectar o reenvio e comparar os documentos com a importação anterior
+-- Aplicar em ordem, com: psql -v ON_ERROR_STOP=1 -f 003_uploads_importados.sql
+-- -----------------------------------------------------------------------------
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.uploads_importados
+(
+    id_import SERIAL PRIMARY KEY,
+    id_ctxt character(36) NOT NULL,
+    sha256 character(64) NOT NULL,
+    nm_file_ori character varying(255) NOT NULL,
+    dt_inc timestamp without time zone NOT NULL,
+    novos integer NOT NULL DEFAULT 0,
+    inalterados integer NOT NULL DEFAULT 0,
+    alterados integer NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS uploads_importados_ctxt_sha256 ON uploads_importados (id_ctxt, sha256);
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- -----------------------------------------------------------------------------
+-- Migração 004: registro das classificações de natureza dos documentos (regra,
+-- palavras-chave ou modelo), com a classificação local que a precedeu
+-- Aplicar em ordem, com: psql -v ON_ERROR_STOP=1 -f 004_naturezas_classificadas.sql
+-- -----------------------------------------------------------------------------
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.naturezas_classificadas
+(
+    id_classif serial PRIMARY KEY,
+    id_ctxt character(36) NOT NULL,
+    id_doc character varying(100) NOT NULL DEFAULT '',
+    id_natu integer NOT NULL,
+    fonte character varying(15) NOT NULL,
+    confianca real NOT NULL DEFAULT 0,
+    motivo text NOT NULL DEFAULT '',
+    id_natu_local integer NOT NULL DEFAULT 0,
+    confianca_local real NOT NULL DEFAULT 0,
+    dt_inc timestamp without time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS naturezas_classificadas_ctxt ON naturezas_classificadas (id_ctxt, dt_inc);
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- -----------------------------------------------------------------------------
+-- Migração 005: autuações cujo JSON não passou na validação do schema da natureza
+-- Aplicar em ordem, com: psql -v ON_ERROR_STOP=1 -f 005_autuacoes_quarentena.sql
+-- -----------------------------------------------------------------------------
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.autuacoes_quarentena
+(
+    id_quar serial PRIMARY KEY,
+    id_ctxt character(36) NOT NULL,
+    id_doc character varying(100) NOT NULL,
+    id_natu integer NOT NULL,
+    id_pje character varying(50) NOT NULL DEFAULT '',
+    formato character varying(80) NOT NULL DEFAULT '',
+    resposta text NOT NULL DEFAULT '',
+    erros text NOT NULL DEFAULT '',
+    dt_inc timestamp without time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS autuacoes_quarentena_ctxt ON autuacoes_quarentena (id_ctxt, dt_inc);
+
+COMMIT;
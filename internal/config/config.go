@@ -31,6 +31,18 @@ type Config struct {
 	PgPass     string
 	DBPoolSize int
 
+	// Fila de jobs assíncronos (tabela "jobs")
+	JobsWorkers       int
+	JobsMaxTentativas int
+	JobsPollInterval  time.Duration
+
+	// Autuação dos documentos de autos_temp: documentos processados em paralelo e
+	// tentativas por documento nas falhas transitórias do modelo (429/5xx/timeout);
+	// pedidos de correção do JSON fora do schema, antes da quarentena
+	AutuacaoWorkers       int
+	AutuacaoMaxTentativas int
+	AutuacaoMaxReparos    int
+
 	// JWT
 	JWTSecretKey       string
 	AccessTokenExpire  time.Duration
@@ -48,6 +60,77 @@ type Config struct {
 	OpenOptionModelSecundary      string //Modelo secundário 'gpt-5-nano'
 	OpenOptionTimeoutSeconds      int
 
+	// Provedores de IA por tarefa: "openai", "compat" ou "fake"
+	LLMProviderGeracao   string
+	LLMProviderEmbedding string
+
+	// Servidor compatível com a API OpenAI (vLLM, Ollama etc.)
+	LLMCompatBaseURL        string // ex: http://192.168.0.40:8000
+	LLMCompatApiKey         string
+	LLMCompatModel          string
+	LLMCompatModelTop       string
+	LLMCompatModelEmbedding string
+	LLMCompatFormatoJSON    bool // servidor aceita response_format "json_schema"
+
+	// Backend fake (offline): diretório opcional com respostas <natureza>.json
+	LLMFakeDir string
+
+	// Orçamento de contexto (tokens) das chamadas ao modelo e divisão dos autos em blocos
+	LLMContextBudget        int            // padrão para modelos não listados
+	LLMContextBudgetModelos map[string]int // orçamento por modelo: "gpt-5=272000,gpt-5-mini=272000"
+	AutosChunkTokens        int            // tamanho máximo de cada bloco de documento a resumir
+	AutosResumoTokens       int            // tamanho aproximado do resumo de cada bloco
+
+	// Recuperação na base de conhecimentos (RAG)
+	RagBuscaModo           string  // "hibrida" (BM25 + kNN) ou "semantica" (somente kNN)
+	RagCandidatos          int     // candidatos trazidos por cada busca antes da fusão
+	RagRRFK                int     // constante k da fusão por posição (reciprocal rank fusion)
+	RagFiltroClasseAssunto bool    // restringe a busca à classe/assunto do contexto
+	RagReranker            string  // "llm" ou "nenhum"
+	RagRerankLimiar        float64 // nota mínima (0 a 1) para o documento ser usado
+
+	// Exportação de minutas e análises
+	ExportTimbreArquivo string // modelo (text/template) do timbre; vazio usa o timbre padrão
+
+	// Extração do texto dos PDFs: "nativo" (Go puro) ou "pdftotext" (poppler)
+	PdfExtrator string
+	PdfMaxMB    int // maior PDF lido pelo extrator nativo (carregado inteiro em memória)
+
+	// OCR das páginas digitalizadas: "tesseract" ou "nenhum"
+	OcrMotor  string
+	OcrIdioma string // idiomas do Tesseract (ex.: "por", "por+eng")
+
+	// Classificação da natureza dos documentos extraídos: abaixo da confiança mínima
+	// (0 a 1) do classificador local, a natureza é pedida ao modelo
+	NaturezaConfiancaMinima float64
+
+	// Perfis de tribunal da importação do PJe
+	PerfisTribunaisArquivo string // JSON com perfis adicionais; vazio usa só os perfis padrão
+	PerfilTribunal         string // perfil padrão dos uploads: "auto" (detecção) ou o id do perfil
+
+	// Upload em lote: limites da descompactação dos ZIPs
+	ZipMaxEntradas        int // entradas por ZIP
+	ZipMaxDescompactadoMB int // soma dos arquivos descompactados de um ZIP
+
+	// Upload retomável (em blocos)
+	UploadRetomavelMaxMB       int // tamanho máximo do arquivo
+	UploadRetomavelBlocoMB     int // tamanho máximo de cada bloco
+	UploadRetomavelExpiraHoras int // validade do upload sem receber blocos
+
+	// Armazenamento dos arquivos enviados: "local" (diretório) ou "s3" (S3/MinIO)
+	BlobBackend      string
+	BlobLocalDir     string
+	BlobS3Endpoint   string // ex: http://minio:9000
+	BlobS3Bucket     string
+	BlobS3Regiao     string
+	BlobS3AccessKey  string
+	BlobS3SecretKey  string
+	BlobS3Prefixo    string // prefixo das chaves no bucket (ex: "ocrserver/")
+	BlobS3PathStyle  bool   // endereçamento endpoint/bucket/chave (MinIO)
+	BlobRetencaoDias int    // 0: o arquivo é apagado logo após a extração
+	// Mantém os arquivos extraídos, para abrir a página original citada nos autos
+	BlobManterOriginais bool
+
 	// Elastic (se usado)
 	ElasticHost     string
 	ElasticPort     string
@@ -93,6 +176,14 @@ func LoadConfig() (*Config, error) {
 	return GlobalConfig, loadErr
 }
 
+// ContextBudget devolve o orçamento total de tokens (entrada + saída) do modelo.
+func (cfg *Config) ContextBudget(modelo string) int {
+	if n, ok := cfg.LLMContextBudgetModelos[modelo]; ok {
+		return n
+	}
+	return cfg.LLMContextBudget
+}
+
 func loadDotEnvIfPresent() {
 	// Procura .env no cwd
 	if _, err := os.Stat(".env"); err == nil {
@@ -152,6 +243,36 @@ func parseInt(key, val string, def, min, max int) int {
 	return n
 }
 
+func parseFloat(key, val string, def, min, max float64) float64 {
+	if strings.TrimSpace(val) == "" {
+		return def
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil {
+		log.Printf("⚠️  %s inválido (%q), usando default=%g: %v", key, val, def, err)
+		return def
+	}
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+func parseBool(key, val string, def bool) bool {
+	if strings.TrimSpace(val) == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(val))
+	if err != nil {
+		log.Printf("⚠️  %s inválido (%q), usando default=%t: %v", key, val, def, err)
+		return def
+	}
+	return b
+}
+
 // Aceita "15" (minutos) ou durações do Go: "15m", "2h"
 func parseDurationFlexible(key, val string, def time.Duration) time.Duration {
 	val = strings.TrimSpace(val)
@@ -209,6 +330,21 @@ func splitAndTrimCSV(s string) []string {
 	return out
 }
 
+// Aceita "modelo=tokens" separados por vírgula: "gpt-5=272000,gpt-5-mini=272000"
+func parseBudgetModelos(key, val string) map[string]int {
+	out := map[string]int{}
+	for _, item := range splitAndTrimCSV(val) {
+		modelo, tokens, ok := strings.Cut(item, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(tokens))
+		if !ok || strings.TrimSpace(modelo) == "" || err != nil || n <= 0 {
+			log.Printf("⚠️  %s: item inválido (%q) ignorado", key, item)
+			continue
+		}
+		out[strings.TrimSpace(modelo)] = n
+	}
+	return out
+}
+
 func mask(s string) string {
 	if s == "" {
 		return "(vazio)"
@@ -285,9 +421,114 @@ func initEnv(cfg *Config) error {
 		return err
 	}
 
+	// Provedores de IA por tarefa
+	cfg.LLMProviderGeracao = strings.ToLower(getEnv("LLM_PROVIDER_GERACAO", "openai"))
+	cfg.LLMProviderEmbedding = strings.ToLower(getEnv("LLM_PROVIDER_EMBEDDING", "openai"))
+	for _, p := range []string{cfg.LLMProviderGeracao, cfg.LLMProviderEmbedding} {
+		if p != "openai" && p != "compat" && p != "fake" {
+			return fmt.Errorf("provedor de IA inválido: %q (use openai, compat ou fake)", p)
+		}
+	}
+	usaOpenai := cfg.LLMProviderGeracao == "openai" || cfg.LLMProviderEmbedding == "openai"
+	usaCompat := cfg.LLMProviderGeracao == "compat" || cfg.LLMProviderEmbedding == "compat"
+
+	if usaCompat {
+		rawCompat := getEnv("LLM_COMPAT_BASE_URL", "")
+		if cfg.LLMCompatBaseURL, err = normalizeURLHost(rawCompat); err != nil {
+			return fmt.Errorf("LLM_COMPAT_BASE_URL inválido: %w", err)
+		}
+	}
+	cfg.LLMCompatApiKey = getEnv("LLM_COMPAT_API_KEY", "")
+	cfg.LLMCompatModel = getEnv("LLM_COMPAT_MODEL", "")
+	cfg.LLMCompatModelTop = getEnv("LLM_COMPAT_MODEL_TOP", cfg.LLMCompatModel)
+	cfg.LLMCompatModelEmbedding = getEnv("LLM_COMPAT_MODEL_EMBEDDING", "")
+	cfg.LLMCompatFormatoJSON = parseBool("LLM_COMPAT_JSON_SCHEMA", getEnv("LLM_COMPAT_JSON_SCHEMA", "true"), true)
+	if cfg.LLMProviderGeracao == "compat" && cfg.LLMCompatModel == "" {
+		return fmt.Errorf("variável de ambiente obrigatória LLM_COMPAT_MODEL não definida")
+	}
+	if cfg.LLMProviderEmbedding == "compat" && cfg.LLMCompatModelEmbedding == "" {
+		return fmt.Errorf("variável de ambiente obrigatória LLM_COMPAT_MODEL_EMBEDDING não definida")
+	}
+	cfg.LLMFakeDir = getEnv("LLM_FAKE_DIR", "")
+
+	// Orçamento de contexto e blocos dos autos
+	cfg.LLMContextBudget = parseInt("LLM_CONTEXT_BUDGET", getEnv("LLM_CONTEXT_BUDGET", "120000"), 120000, 8000, 2000000)
+	cfg.LLMContextBudgetModelos = parseBudgetModelos("LLM_CONTEXT_BUDGET_MODELOS", getEnv("LLM_CONTEXT_BUDGET_MODELOS", ""))
+	cfg.AutosChunkTokens = parseInt("AUTOS_CHUNK_TOKENS", getEnv("AUTOS_CHUNK_TOKENS", "3000"), 3000, 500, 32000)
+	cfg.AutosResumoTokens = parseInt("AUTOS_RESUMO_TOKENS", getEnv("AUTOS_RESUMO_TOKENS", "600"), 600, 100, 4000)
+
+	// Recuperação na base de conhecimentos
+	cfg.RagBuscaModo = strings.ToLower(getEnv("RAG_BUSCA_MODO", "hibrida"))
+	if cfg.RagBuscaModo != "hibrida" && cfg.RagBuscaModo != "semantica" {
+		return fmt.Errorf("RAG_BUSCA_MODO inválido: %q (use hibrida ou semantica)", cfg.RagBuscaModo)
+	}
+	cfg.RagCandidatos = parseInt("RAG_CANDIDATOS", getEnv("RAG_CANDIDATOS", "20"), 20, 5, 100)
+	cfg.RagRRFK = parseInt("RAG_RRF_K", getEnv("RAG_RRF_K", "60"), 60, 1, 1000)
+	cfg.RagFiltroClasseAssunto = parseBool("RAG_FILTRO_CLASSE_ASSUNTO", getEnv("RAG_FILTRO_CLASSE_ASSUNTO", "true"), true)
+	cfg.RagReranker = strings.ToLower(getEnv("RAG_RERANKER", "llm"))
+	if cfg.RagReranker != "llm" && cfg.RagReranker != "nenhum" {
+		return fmt.Errorf("RAG_RERANKER inválido: %q (use llm ou nenhum)", cfg.RagReranker)
+	}
+	cfg.RagRerankLimiar = parseFloat("RAG_RERANK_LIMIAR", getEnv("RAG_RERANK_LIMIAR", "0.5"), 0.5, 0, 1)
+
+	cfg.ExportTimbreArquivo = getEnv("EXPORT_TIMBRE_ARQUIVO", "")
+
+	cfg.NaturezaConfiancaMinima = parseFloat("NATUREZA_CONFIANCA_MINIMA", getEnv("NATUREZA_CONFIANCA_MINIMA", "0.7"), 0.7, 0, 1)
+
+	cfg.PdfExtrator = strings.ToLower(getEnv("PDF_EXTRATOR", "nativo"))
+	if cfg.PdfExtrator != "nativo" && cfg.PdfExtrator != "pdftotext" {
+		return fmt.Errorf("PDF_EXTRATOR inválido: %q (use nativo ou pdftotext)", cfg.PdfExtrator)
+	}
+	cfg.PdfMaxMB = parseInt("PDF_MAX_MB", getEnv("PDF_MAX_MB", "512"), 512, 1, 4096)
+
+	cfg.OcrMotor = strings.ToLower(getEnv("OCR_MOTOR", "tesseract"))
+	if cfg.OcrMotor != "tesseract" && cfg.OcrMotor != "nenhum" {
+		return fmt.Errorf("OCR_MOTOR inválido: %q (use tesseract ou nenhum)", cfg.OcrMotor)
+	}
+	cfg.OcrIdioma = getEnv("OCR_IDIOMA", "por")
+
+	cfg.PerfisTribunaisArquivo = getEnv("PERFIS_TRIBUNAIS_ARQUIVO", "")
+	cfg.PerfilTribunal = strings.ToLower(getEnv("PERFIL_TRIBUNAL", "auto"))
+
+	cfg.ZipMaxEntradas = parseInt("ZIP_MAX_ENTRADAS", getEnv("ZIP_MAX_ENTRADAS", "500"), 500, 1, 10000)
+	cfg.ZipMaxDescompactadoMB = parseInt("ZIP_MAX_DESCOMPACTADO_MB", getEnv("ZIP_MAX_DESCOMPACTADO_MB", "1024"), 1024, 1, 16384)
+	cfg.UploadRetomavelMaxMB = parseInt("UPLOAD_RETOMAVEL_MAX_MB", getEnv("UPLOAD_RETOMAVEL_MAX_MB", "2048"), 2048, 1, 65536)
+	cfg.UploadRetomavelBlocoMB = parseInt("UPLOAD_RETOMAVEL_BLOCO_MB", getEnv("UPLOAD_RETOMAVEL_BLOCO_MB", "16"), 16, 1, 256)
+	cfg.BlobBackend = strings.ToLower(getEnv("BLOB_BACKEND", "local"))
+	switch cfg.BlobBackend {
+	case "local":
+	case "s3":
+		if cfg.BlobS3Endpoint, err = getEnvRequired("BLOB_S3_ENDPOINT"); err != nil {
+			return err
+		}
+		if cfg.BlobS3Bucket, err = getEnvRequired("BLOB_S3_BUCKET"); err != nil {
+			return err
+		}
+		if cfg.BlobS3AccessKey, err = getEnvRequired("BLOB_S3_ACCESS_KEY"); err != nil {
+			return err
+		}
+		if cfg.BlobS3SecretKey, err = getEnvRequired("BLOB_S3_SECRET_KEY"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("BLOB_BACKEND inválido: %q (use local ou s3)", cfg.BlobBackend)
+	}
+	cfg.BlobLocalDir = getEnv("BLOB_LOCAL_DIR", "uploads")
+	cfg.BlobS3Regiao = getEnv("BLOB_S3_REGIAO", "us-east-1")
+	cfg.BlobS3Prefixo = getEnv("BLOB_S3_PREFIXO", "")
+	cfg.BlobS3PathStyle = parseBool("BLOB_S3_PATH_STYLE", getEnv("BLOB_S3_PATH_STYLE", "true"), true)
+	cfg.BlobRetencaoDias = parseInt("BLOB_RETENCAO_DIAS", getEnv("BLOB_RETENCAO_DIAS", "0"), 0, 0, 3650)
+	cfg.BlobManterOriginais = parseBool("BLOB_MANTER_ORIGINAIS", getEnv("BLOB_MANTER_ORIGINAIS", "true"), true)
+	cfg.UploadRetomavelExpiraHoras = parseInt("UPLOAD_RETOMAVEL_EXPIRA_HORAS", getEnv("UPLOAD_RETOMAVEL_EXPIRA_HORAS", "24"), 24, 1, 720)
+
 	// OpenAI (usa strings — evita acoplamento com SDK)
-	if cfg.OpenApiKey, err = getEnvRequired("OPENAI_API_KEY"); err != nil {
-		return err
+	// A chave só é obrigatória se alguma tarefa usar a OpenAI.
+	if usaOpenai {
+		if cfg.OpenApiKey, err = getEnvRequired("OPENAI_API_KEY"); err != nil {
+			return err
+		}
+	} else {
+		cfg.OpenApiKey = getEnv("OPENAI_API_KEY", "")
 	}
 	cfg.OpenOptionMaxCompletionTokens = parseInt("OPENAI_OPTION_MAX_COMPLETION_TOKENS",
 		getEnv("OPENAI_OPTION_MAX_COMPLETION_TOKENS", "16384"),
@@ -301,6 +542,16 @@ func initEnv(cfg *Config) error {
 	// Pool do DB
 	cfg.DBPoolSize = parseInt("DB_POOLSIZE", getEnv("DB_POOLSIZE", "25"), 25, 5, 200)
 
+	// Fila de jobs
+	cfg.JobsWorkers = parseInt("JOBS_WORKERS", getEnv("JOBS_WORKERS", "2"), 2, 1, 32)
+	cfg.JobsMaxTentativas = parseInt("JOBS_MAX_TENTATIVAS", getEnv("JOBS_MAX_TENTATIVAS", "3"), 3, 1, 10)
+	cfg.JobsPollInterval = parseDurationFlexible("JOBS_POLL_INTERVAL", getEnv("JOBS_POLL_INTERVAL", "2s"), 2*time.Second)
+
+	// Autuação
+	cfg.AutuacaoWorkers = parseInt("AUTUACAO_WORKERS", getEnv("AUTUACAO_WORKERS", "4"), 4, 1, 32)
+	cfg.AutuacaoMaxTentativas = parseInt("AUTUACAO_MAX_TENTATIVAS", getEnv("AUTUACAO_MAX_TENTATIVAS", "4"), 4, 1, 10)
+	cfg.AutuacaoMaxReparos = parseInt("AUTUACAO_MAX_REPAROS", getEnv("AUTUACAO_MAX_REPAROS", "1"), 1, 0, 3)
+
 	// Expiração de tokens (minutos numéricos OU duration Go)
 	cfg.AccessTokenExpire = parseDurationFlexible("ACCESSTOKEN_EXPIRE", getEnv("ACCESSTOKEN_EXPIRE", "10m"), 10*time.Minute)
 	cfg.RefreshTokenExpire = parseDurationFlexible("REFRESHTOKEN_EXPIRE", getEnv("REFRESHTOKEN_EXPIRE", "60m"), 60*time.Minute)
@@ -337,7 +588,57 @@ func showEnv(cfg *Config) {
 	fmt.Println("OPENAI_OPTION_MODEL_SECUNDARY:", cfg.OpenOptionModelSecundary)
 	fmt.Println("OPENAI_OPTION_MAX_COMPLETION_TOKENS:", cfg.OpenOptionMaxCompletionTokens)
 
+	fmt.Println("LLM_PROVIDER_GERACAO:", cfg.LLMProviderGeracao)
+	fmt.Println("LLM_PROVIDER_EMBEDDING:", cfg.LLMProviderEmbedding)
+	fmt.Println("LLM_COMPAT_BASE_URL:", cfg.LLMCompatBaseURL)
+	fmt.Println("LLM_COMPAT_API_KEY:", mask(cfg.LLMCompatApiKey))
+	fmt.Println("LLM_COMPAT_MODEL:", cfg.LLMCompatModel)
+	fmt.Println("LLM_COMPAT_MODEL_TOP:", cfg.LLMCompatModelTop)
+	fmt.Println("LLM_COMPAT_MODEL_EMBEDDING:", cfg.LLMCompatModelEmbedding)
+	fmt.Println("LLM_COMPAT_JSON_SCHEMA:", cfg.LLMCompatFormatoJSON)
+	fmt.Println("LLM_FAKE_DIR:", cfg.LLMFakeDir)
+	fmt.Println("LLM_CONTEXT_BUDGET:", cfg.LLMContextBudget)
+	fmt.Println("LLM_CONTEXT_BUDGET_MODELOS:", cfg.LLMContextBudgetModelos)
+	fmt.Println("AUTOS_CHUNK_TOKENS:", cfg.AutosChunkTokens)
+	fmt.Println("AUTOS_RESUMO_TOKENS:", cfg.AutosResumoTokens)
+	fmt.Println("RAG_BUSCA_MODO:", cfg.RagBuscaModo)
+	fmt.Println("RAG_CANDIDATOS:", cfg.RagCandidatos)
+	fmt.Println("RAG_RRF_K:", cfg.RagRRFK)
+	fmt.Println("RAG_FILTRO_CLASSE_ASSUNTO:", cfg.RagFiltroClasseAssunto)
+	fmt.Println("RAG_RERANKER:", cfg.RagReranker)
+	fmt.Println("RAG_RERANK_LIMIAR:", cfg.RagRerankLimiar)
+	fmt.Println("EXPORT_TIMBRE_ARQUIVO:", cfg.ExportTimbreArquivo)
+	fmt.Println("NATUREZA_CONFIANCA_MINIMA:", cfg.NaturezaConfiancaMinima)
+	fmt.Println("PDF_EXTRATOR:", cfg.PdfExtrator)
+	fmt.Println("PDF_MAX_MB:", cfg.PdfMaxMB)
+	fmt.Println("OCR_MOTOR:", cfg.OcrMotor)
+	fmt.Println("OCR_IDIOMA:", cfg.OcrIdioma)
+	fmt.Println("PERFIS_TRIBUNAIS_ARQUIVO:", cfg.PerfisTribunaisArquivo)
+	fmt.Println("PERFIL_TRIBUNAL:", cfg.PerfilTribunal)
+	fmt.Println("ZIP_MAX_ENTRADAS:", cfg.ZipMaxEntradas)
+	fmt.Println("ZIP_MAX_DESCOMPACTADO_MB:", cfg.ZipMaxDescompactadoMB)
+	fmt.Println("UPLOAD_RETOMAVEL_MAX_MB:", cfg.UploadRetomavelMaxMB)
+	fmt.Println("UPLOAD_RETOMAVEL_BLOCO_MB:", cfg.UploadRetomavelBlocoMB)
+	fmt.Println("UPLOAD_RETOMAVEL_EXPIRA_HORAS:", cfg.UploadRetomavelExpiraHoras)
+	fmt.Println("BLOB_BACKEND:", cfg.BlobBackend)
+	fmt.Println("BLOB_LOCAL_DIR:", cfg.BlobLocalDir)
+	fmt.Println("BLOB_S3_ENDPOINT:", cfg.BlobS3Endpoint)
+	fmt.Println("BLOB_S3_BUCKET:", cfg.BlobS3Bucket)
+	fmt.Println("BLOB_S3_REGIAO:", cfg.BlobS3Regiao)
+	fmt.Println("BLOB_S3_ACCESS_KEY:", mask(cfg.BlobS3AccessKey))
+	fmt.Println("BLOB_S3_SECRET_KEY:", mask(cfg.BlobS3SecretKey))
+	fmt.Println("BLOB_S3_PREFIXO:", cfg.BlobS3Prefixo)
+	fmt.Println("BLOB_S3_PATH_STYLE:", cfg.BlobS3PathStyle)
+	fmt.Println("BLOB_RETENCAO_DIAS:", cfg.BlobRetencaoDias)
+	fmt.Println("BLOB_MANTER_ORIGINAIS:", cfg.BlobManterOriginais)
+
 	fmt.Println("DB_POOLSIZE:", cfg.DBPoolSize)
+	fmt.Println("JOBS_WORKERS:", cfg.JobsWorkers)
+	fmt.Println("JOBS_MAX_TENTATIVAS:", cfg.JobsMaxTentativas)
+	fmt.Println("JOBS_POLL_INTERVAL:", cfg.JobsPollInterval)
+	fmt.Println("AUTUACAO_WORKERS:", cfg.AutuacaoWorkers)
+	fmt.Println("AUTUACAO_MAX_TENTATIVAS:", cfg.AutuacaoMaxTentativas)
+	fmt.Println("AUTUACAO_MAX_REPAROS:", cfg.AutuacaoMaxReparos)
 	fmt.Println("ACCESS_TOKEN_EXPIRE:", cfg.AccessTokenExpire)
 	fmt.Println("REFRESH_TOKEN_EXPIRE:", cfg.RefreshTokenExpire)
 
@@ -32,10 +32,13 @@ const (
 	NATU_DOC_OUTROS       = 1001
 	NATU_DOC_MOVIMENTACAO = 1003
 
-	NATU_DOC_IA_PROMPT     = 100
-	NATU_DOC_IA_PREANALISE = 101
-	NATU_DOC_IA_ANALISE    = 102
-	NATU_DOC_IA_SENTENCA   = 103
+	NATU_DOC_IA_PROMPT      = 100
+	NATU_DOC_IA_PREANALISE  = 101
+	NATU_DOC_IA_ANALISE     = 102
+	NATU_DOC_IA_SENTENCA    = 103
+	NATU_DOC_IA_DECISAO     = 104
+	NATU_DOC_IA_DESPACHO    = 105
+	NATU_DOC_IA_COMPLEMENTO = 106 // respostas às questões controvertidas
 )
 
 // ============================================================================
@@ -145,6 +148,9 @@ var itemsDocumento = []Item{
 	{Key: NATU_DOC_IA_PREANALISE, Descriptions: []string{"Pré-análise jurídica"}},
 	{Key: NATU_DOC_IA_ANALISE, Descriptions: []string{"Análise Jurídica"}},
 	{Key: NATU_DOC_IA_SENTENCA, Descriptions: []string{"Minuta de Sentença"}},
+	{Key: NATU_DOC_IA_DECISAO, Descriptions: []string{"Minuta de Decisão"}},
+	{Key: NATU_DOC_IA_DESPACHO, Descriptions: []string{"Minuta de Despacho"}},
+	{Key: NATU_DOC_IA_COMPLEMENTO, Descriptions: []string{"Respostas às Questões Controvertidas"}},
 }
 
 // ============================================================================
@@ -208,6 +214,27 @@ func removeComplemento(texto string) string {
 	return regexComplementos.ReplaceAllString(texto, "")
 }
 
+// NormalizaTipo devolve a forma do tipo documental usada nas comparações
+// (sem o complemento entre parênteses, sem acentos e em minúsculas)
+func NormalizaTipo(nmTipo string) string {
+	return normalizeText(removeComplemento(nmTipo))
+}
+
+// NormalizaTexto converte o texto para minúsculas, sem acentos, para a busca de expressões
+func NormalizaTexto(texto string) string {
+	return normalizeText(texto)
+}
+
+// DescricoesNatureza devolve as denominações conhecidas da natureza (a principal primeiro)
+func DescricoesNatureza(key int) []string {
+	for _, item := range itemsDocumento {
+		if item.Key == key {
+			return item.Descriptions
+		}
+	}
+	return nil
+}
+
 // GetNaturezaDocumento retorna a descrição principal da natureza pelo código
 func GetNaturezaDocumento(key int) string {
 	if desc, ok := keyParaDescricao[key]; ok {
@@ -0,0 +1,18 @@
+package consts
+
+// Tipos de job executados pela fila assíncrona (tabela "jobs")
+const (
+	JOB_TIPO_EXTRACAO_PDF     = "extracao_pdf"     // UploadServiceType.ProcessaPDF
+	JOB_TIPO_AUTUACAO         = "autuacao"         // autuação dos documentos de autos_temp
+	JOB_TIPO_PIPELINE_RAG     = "pipeline_rag"     // OrquestradorType.StartPipelineResult
+	JOB_TIPO_EMBEDDINGS_AUTOS = "embeddings_autos" // embeddings dos documentos já autuados (autos_json_embedding)
+)
+
+// Situação do job
+const (
+	JOB_STATUS_PENDENTE   = "pendente"
+	JOB_STATUS_EXECUTANDO = "executando"
+	JOB_STATUS_CONCLUIDO  = "concluido"
+	JOB_STATUS_FALHA      = "falha"
+	JOB_STATUS_CANCELADO  = "cancelado"
+)
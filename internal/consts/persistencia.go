@@ -1,6 +1,10 @@
 package consts
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type AutosRow struct {
 	IdCtxt       string    `json:"id_ctxt"`
@@ -9,33 +13,111 @@ type AutosRow struct {
 	Doc          string    `json:"doc"`
 	DocJsonRaw   string    `json:"doc_json_raw"`
 	DocEmbedding []float32 `json:"doc_embedding"`
+	// Cache dos resumos por bloco (documentos maiores que o orçamento de contexto)
+	DocResumoHash string   `json:"doc_resumo_hash,omitempty"`
+	DocResumos    []string `json:"doc_resumos,omitempty"`
+	// Páginas, índice e assinatura do documento nos autos enviados
+	Procedencia *ProcedenciaAutos `json:"procedencia,omitempty"`
 }
 
 type ResponseAutosRow struct {
-	Id           string    `json:"id"`
-	IdCtxt       string    `json:"id_ctxt"`
-	IdNatu       int       `json:"id_natu"`
-	IdPje        string    `json:"id_pje"`
-	Doc          string    `json:"doc"`
-	DocJsonRaw   string    `json:"doc_json_raw"`
-	DocEmbedding []float32 `json:"doc_embedding"`
+	Id            string            `json:"id"`
+	IdCtxt        string            `json:"id_ctxt"`
+	IdNatu        int               `json:"id_natu"`
+	IdPje         string            `json:"id_pje"`
+	Doc           string            `json:"doc"`
+	DocJsonRaw    string            `json:"doc_json_raw"`
+	DocEmbedding  []float32         `json:"doc_embedding"`
+	DocResumoHash string            `json:"doc_resumo_hash,omitempty"`
+	DocResumos    []string          `json:"doc_resumos,omitempty"`
+	Procedencia   *ProcedenciaAutos `json:"procedencia,omitempty"`
+}
+
+// Página do PDF que compõe o documento e a origem do seu texto ("texto" ou "ocr")
+type PaginaAutos struct {
+	Numero int    `json:"numero"`
+	Origem string `json:"origem"`
+}
+
+/*
+Procedência do documento nos autos enviados: o arquivo (e, nos ZIPs, o PDF contido), as
+páginas no PDF, a data/hora do índice e a assinatura eletrônica. As datas seguem o
+formato do carimbo: "dd/mm/aaaa hh:mm[:ss]".
+*/
+type ProcedenciaAutos struct {
+	Arquivo      string `json:"arquivo,omitempty"`       // nome do arquivo enviado
+	ChaveArquivo string `json:"chave_arquivo,omitempty"` // chave no armazenamento dos uploads
+	Fonte        string `json:"fonte,omitempty"`         // PDF contido no ZIP (eproc, Projudi)
+	IdDocumento  string `json:"id_documento,omitempty"`  // id completo no sistema de origem ("Num." do PJe)
+	PagIni       int    `json:"pag_ini,omitempty"`       // primeira página no PDF
+	PagFim       int    `json:"pag_fim,omitempty"`       // última página no PDF
+	DtIndice     string `json:"dt_indice,omitempty"`     // data/hora do documento no índice dos autos
+	Signatario   string `json:"signatario,omitempty"`    // "Assinado eletronicamente por"
+	DtAssinatura string `json:"dt_assinatura,omitempty"` // data/hora da assinatura
+}
+
+/*
+Referencia descreve a localização do documento para a citação nas análises e minutas:
+"Num. 123456789 - fls. 10-12" (PJe) ou "Doc. <id> - fls. 3-4 de <PDF do ZIP>" (demais
+sistemas), seguida da data do índice e da assinatura, quando conhecidas.
+*/
+func (p *ProcedenciaAutos) Referencia(idPje string) string {
+	if p == nil {
+		return ""
+	}
+	var partes []string
+	switch {
+	case p.IdDocumento != "":
+		partes = append(partes, "Num. "+p.IdDocumento)
+	case idPje != "":
+		partes = append(partes, "Doc. "+idPje)
+	}
+	fls := ""
+	switch {
+	case p.PagIni > 0 && p.PagFim > p.PagIni:
+		fls = fmt.Sprintf("fls. %d-%d", p.PagIni, p.PagFim)
+	case p.PagIni > 0:
+		fls = fmt.Sprintf("fls. %d", p.PagIni)
+	}
+	if fls != "" && p.Fonte != "" {
+		fls += " de " + p.Fonte
+	}
+	if fls != "" {
+		partes = append(partes, fls)
+	}
+	if p.DtIndice != "" {
+		partes = append(partes, "juntado em "+p.DtIndice)
+	}
+	if p.Signatario != "" {
+		assinatura := "assinado por " + p.Signatario
+		if p.DtAssinatura != "" {
+			assinatura += " em " + p.DtAssinatura
+		}
+		partes = append(partes, assinatura)
+	}
+	return strings.Join(partes, " - ")
 }
 
 type AutosTempRow struct {
-	IdCtxt string    `json:"id_ctxt"`
-	IdNatu int       `json:"id_natu"`
-	IdPje  string    `json:"id_pje"`
-	DtInc  time.Time `json:"dt_inc"` // data/hora da inclusão
-	Doc    string    `json:"doc"`
+	IdCtxt  string        `json:"id_ctxt"`
+	IdNatu  int           `json:"id_natu"`
+	IdPje   string        `json:"id_pje"`
+	DtInc   time.Time     `json:"dt_inc"` // data/hora da inclusão
+	Doc     string        `json:"doc"`
+	Paginas []PaginaAutos `json:"paginas,omitempty"`
+	// Páginas, índice e assinatura do documento nos autos enviados
+	Procedencia *ProcedenciaAutos `json:"procedencia,omitempty"`
 }
 
 type ResponseAutosTempRow struct {
-	Id     string    `json:"id"`
-	IdCtxt string    `json:"id_ctxt"`
-	IdNatu int       `json:"id_natu"`
-	IdPje  string    `json:"id_pje"`
-	DtInc  time.Time `json:"dt_inc"` // data/hora da inclusão
-	Doc    string    `json:"doc"`
+	Id          string            `json:"id"`
+	IdCtxt      string            `json:"id_ctxt"`
+	IdNatu      int               `json:"id_natu"`
+	IdPje       string            `json:"id_pje"`
+	DtInc       time.Time         `json:"dt_inc"` // data/hora da inclusão
+	Doc         string            `json:"doc"`
+	Paginas     []PaginaAutos     `json:"paginas,omitempty"`
+	Procedencia *ProcedenciaAutos `json:"procedencia,omitempty"`
 }
 
 type AutosJsonEmbeddingRow struct {
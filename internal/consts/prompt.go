@@ -17,6 +17,10 @@ const (
 	PROMPT_AUTUACAO_SENTENCA          = 300
 	PROMPT_RAG_COMPLEMENTA_JULGAMENTO = 301
 	PROMPT_AUTUACAO_CERTIDAO          = 302
+	PROMPT_AUTUACAO_NATUREZA          = 303 // prompt interno (não cadastrado): natureza do documento
+	PROMPT_RAG_RESPOSTAS_QUESTOES     = 304 // prompt interno (não cadastrado): respostas às questões controvertidas
+	PROMPT_RAG_RESUMO_AUTOS           = 305 // prompt interno (não cadastrado): resumo de bloco dos autos
+	PROMPT_RAG_RERANK                 = 306 // prompt interno (não cadastrado): pertinência dos trechos da base
 	PROMPT_RAG_OUTROS                 = 999
 )
 
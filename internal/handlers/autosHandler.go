@@ -2,6 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
 
 	"net/http"
 
@@ -85,7 +90,7 @@ func (obj *AutosHandlerType) InsertHandler(c *gin.Context) {
 	//var docJsonRaw string
 	docJsonRaw := string(data.DocJsonRaw)
 
-	row, err := obj.service.InserirAutos(data.IdCtxt, data.IdNatu, data.IdPje, data.Doc, docJsonRaw)
+	row, err := obj.service.InserirAutos(data.IdCtxt, data.IdNatu, data.IdPje, data.Doc, docJsonRaw, nil, "")
 
 	if err != nil {
 		logger.Log.Errorf("Erro na inclusão do registro %v", err)
@@ -93,6 +98,11 @@ func (obj *AutosHandlerType) InsertHandler(c *gin.Context) {
 		return
 	}
 
+	// O embedding não impede a inclusão: a falha é refeita por POST /admin/embeddings/autos
+	if err := services.AutosJsonServiceGlobal.IndexarAutos(c.Request.Context(), row); err != nil {
+		logger.Log.Warningf("Documento %s incluído sem embedding: %v", row.Id, err)
+	}
+
 	rsp := gin.H{
 		"row":     row,
 		"message": "Registro inserido com sucesso!",
@@ -184,6 +194,61 @@ func (obj *AutosHandlerType) SelectByIdHandler(c *gin.Context) {
 	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
 }
 
+/*
+ * Devolve o arquivo original (PDF) de um documento dos autos, para a consulta da página
+ * citada. O documento é procurado em "autos" e, se ainda não autuado, em "autos_temp".
+ * O cabeçalho "X-Pagina-Inicial" traz a página do documento no PDF ("#page=<n>").
+ *
+ * - **Rota**: "/contexto/autos/:id/original"
+ * - **Params**: ID do documento
+ * - **Método**: GET
+ * - **Resposta**: o PDF (inline); 404 se o documento não tem procedência ou o arquivo
+ *   não está mais no armazenamento
+ */
+func (obj *AutosHandlerType) OriginalHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	paramID := c.Param("id")
+	if paramID == "" {
+		logger.Log.Error("ID ausente na requisição")
+		response.HandleError(c, http.StatusBadRequest, "ID ausente", "", requestID)
+		return
+	}
+
+	var procedencia *consts.ProcedenciaAutos
+	if row, err := obj.service.SelectById(paramID); err == nil && row != nil {
+		procedencia = row.Procedencia
+	} else if temp, err := services.AutosTempServiceGlobal.SelectById(paramID); err == nil && temp != nil {
+		procedencia = temp.Procedencia
+	} else {
+		logger.Log.Errorf("Documento não localizado pelo ID: %s", paramID)
+		response.HandleError(c, http.StatusNotFound, "Documento não localizado", "", requestID)
+		return
+	}
+
+	arq, err := services.AbreArquivoOriginal(c.Request.Context(), procedencia)
+	if errors.Is(err, services.ErrOriginalIndisponivel) {
+		response.HandleError(c, http.StatusNotFound, "Arquivo original indisponível para o documento", "", requestID)
+		return
+	}
+	if err != nil {
+		logger.Log.Errorf("Erro ao abrir o arquivo original do documento %s: %v", paramID, err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao abrir o arquivo original", "", requestID)
+		return
+	}
+	defer arq.Close()
+
+	contentType := "application/octet-stream"
+	if strings.EqualFold(path.Ext(arq.Nome), ".pdf") {
+		contentType = "application/pdf"
+	}
+	cabecalhos := map[string]string{
+		"Content-Disposition": fmt.Sprintf(`inline; filename="%s"`, strings.ReplaceAll(arq.Nome, `"`, "")),
+		"X-Pagina-Inicial":    strconv.Itoa(arq.Pagina),
+	}
+	c.DataFromReader(http.StatusOK, arq.Tamanho, contentType, arq, cabecalhos)
+}
+
 /**
  * Devolve os registros da tabela 'autos' para um determinado contexto'
  * Rota: "/contexto/documentos/:id"
@@ -222,6 +287,63 @@ func (obj *AutosHandlerType) SelectAllHandler(c *gin.Context) {
 	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
 }
 
+/**
+ * Busca nos documentos dos autos de um contexto: lexical, semântica ou híbrida.
+ * Devolve os documentos em ordem de relevância, com os trechos destacados (<em>) e a
+ * procedência (arquivo e páginas nos autos enviados).
+ * Rota: "/contexto/autos/search"
+ * Body: {"id_ctxt": "...", "texto": "...", "naturezas": [..], "modo": "hibrida", "limite": 10}
+ * Método: POST
+ */
+func (obj *AutosHandlerType) SearchHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	var params services.ParamsBuscaAutos
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Log.Errorf("Body inválido: %v", err)
+		response.HandleError(c, http.StatusBadRequest, "Body inválido", "", requestID)
+		return
+	}
+
+	params.IdCtxt = strings.TrimSpace(params.IdCtxt)
+	params.Texto = strings.TrimSpace(params.Texto)
+	if params.IdCtxt == "" || params.Texto == "" {
+		response.HandleError(c, http.StatusBadRequest, "id_ctxt e texto são obrigatórios", "", requestID)
+		return
+	}
+	if len(params.Texto) > 8000 {
+		response.HandleError(c, http.StatusBadRequest, "texto muito grande", "", requestID)
+		return
+	}
+	if _, err := services.ModoBuscaAutos(params.Modo); err != nil {
+		response.HandleError(c, http.StatusBadRequest, err.Error(), "", requestID)
+		return
+	}
+
+	res, err := obj.service.BuscaAutos(c.Request.Context(), params)
+	if err != nil {
+		if c.Request.Context().Err() != nil {
+			c.Status(499)
+			return
+		}
+		logger.Log.Errorf("Erro na busca nos autos: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro na busca nos autos", "", requestID)
+		return
+	}
+
+	msg := "Consulta realizada com sucesso"
+	if len(res.Docs) == 0 {
+		msg += ": nenhum documento retornado"
+	}
+
+	rsp := gin.H{
+		"modo":    res.Modo,
+		"docs":    res.Docs,
+		"message": msg,
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
 // /*
 // *
 //   - Executa uma análise do texto constante no registro de 'temp_autos',
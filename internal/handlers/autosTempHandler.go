@@ -1,9 +1,7 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
-	"runtime/debug"
 	"strings"
 	"sync"
 
@@ -12,6 +10,8 @@ import (
 
 	"ocrserver/internal/opensearch"
 	"ocrserver/internal/services"
+	"ocrserver/internal/services/importadores"
+	"ocrserver/internal/services/tribunais"
 
 	"ocrserver/internal/utils/logger"
 	"ocrserver/internal/utils/middleware"
@@ -87,17 +87,135 @@ func (obj *AutosTempHandlerType) PDFHandler(c *gin.Context) {
 		return
 	}
 
-	extractedFiles, extractedErros := services.UploadServiceGlobal.ProcessaPDF(c.Request.Context(), bodyParams)
+	// Perfil do tribunal e formato dos autos informados no upload: validados antes de iniciar a extração
+	for _, p := range bodyParams {
+		if _, err := tribunais.Global().Perfil(p.Perfil); err != nil {
+			response.HandleError(c, http.StatusBadRequest, "Perfil de tribunal inválido", err.Error(), requestID)
+			return
+		}
+		if err := importadores.ValidaFormato(p.Formato); err != nil {
+			response.HandleError(c, http.StatusBadRequest, "Formato dos autos inválido", err.Error(), requestID)
+			return
+		}
+	}
+
+	// Execução assíncrona: devolve o job e o cliente acompanha em /jobs/:id
+	if c.Query("async") == "true" {
+		job, err := services.JobsServiceGlobal.Submeter(consts.JOB_TIPO_EXTRACAO_PDF, bodyParams, c.GetString("userName"))
+		if err != nil {
+			logger.Log.Errorf("Erro ao submeter o job de extração: %v", err)
+			response.HandleError(c, http.StatusInternalServerError, "Erro ao submeter o job de extração", err.Error(), requestID)
+			return
+		}
+		response.HandleSucesso(c, http.StatusAccepted, job, requestID)
+		return
+	}
+
+	extractedFiles, extractedErros, relatorios := services.UploadServiceGlobal.ProcessaPDF(c.Request.Context(), bodyParams)
 
 	rsp := gin.H{
 		"extractedErros": extractedErros,
 		"extractedFiles": extractedFiles,
+		"relatorios":     relatorios,
 		"message":        "Registros selecionados com sucesso!",
 	}
 
 	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
 }
 
+// Método: GET
+// URL: "/contexto/documentos/perfis"
+// Lista os perfis de tribunal disponíveis para a extração ("auto" detecta pelo texto do PDF)
+func (obj *AutosTempHandlerType) PerfisHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	type perfilItem struct {
+		Id   string `json:"id"`
+		Nome string `json:"nome"`
+	}
+	lista := []perfilItem{{Id: tribunais.PERFIL_AUTO, Nome: "Detecção automática"}}
+	for _, p := range tribunais.Global().Lista() {
+		lista = append(lista, perfilItem{Id: p.Id, Nome: p.Nome})
+	}
+
+	rsp := gin.H{
+		"perfis":  lista,
+		"message": "Perfis de tribunal disponíveis",
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
+// Método: GET
+// URL: "/contexto/documentos/classificacoes/:id"
+// Lista as classificações de natureza dos documentos extraídos no contexto: a natureza
+// atribuída, a fonte (regra, palavras-chave ou modelo) e a confiança do classificador local
+func (obj *AutosTempHandlerType) ClassificacoesHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+	idCtxt := c.Param("id")
+	if idCtxt == "" {
+		response.HandleError(c, http.StatusBadRequest, "Parâmetro id é obrigatório", "", requestID)
+		return
+	}
+
+	rows, err := services.NaturezaServiceGlobal.SelectByContexto(idCtxt, MAX_CLASSIFICACOES_LISTADAS)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar as classificações do contexto %s: %v", idCtxt, err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao consultar as classificações", "", requestID)
+		return
+	}
+
+	rsp := gin.H{
+		"classificacoes": rows,
+		"message":        "Classificações de natureza do contexto",
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
+// MAX_CLASSIFICACOES_LISTADAS limita a lista de classificações devolvida por ClassificacoesHandler
+const MAX_CLASSIFICACOES_LISTADAS = 1000
+
+// Método: GET
+// URL: "/contexto/documentos/quarentena/:id"
+// Lista os documentos do contexto em quarentena: o JSON extraído pelo modelo que, mesmo
+// após o reparo, não segue o schema da natureza, com os erros de validação
+func (obj *AutosTempHandlerType) QuarentenaHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+	idCtxt := c.Param("id")
+	if idCtxt == "" {
+		response.HandleError(c, http.StatusBadRequest, "Parâmetro id é obrigatório", "", requestID)
+		return
+	}
+
+	rows, err := services.QuarentenaServiceGlobal.SelectByContexto(idCtxt, MAX_QUARENTENA_LISTADOS)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar a quarentena do contexto %s: %v", idCtxt, err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao consultar a quarentena", "", requestID)
+		return
+	}
+
+	rsp := gin.H{
+		"quarentena": rows,
+		"message":    "Documentos do contexto em quarentena",
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
+// MAX_QUARENTENA_LISTADOS limita a lista de documentos devolvida por QuarentenaHandler
+const MAX_QUARENTENA_LISTADOS = 200
+
+// Método: GET
+// URL: "/contexto/documentos/formatos"
+// Lista os formatos de autos aceitos na extração: PDF do PJe, pasta digital do e-SAJ e ZIP do eproc e do Projudi
+func (obj *AutosTempHandlerType) FormatosHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	rsp := gin.H{
+		"formatos": importadores.Lista(),
+		"message":  "Formatos de autos disponíveis",
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
 /*
 *
   - Executa uma análise do texto constante no registro de 'temp_autos',
@@ -137,90 +255,27 @@ func (obj *AutosTempHandlerType) AutuarDocumentosHandler(c *gin.Context) {
 
 	msgs.CreateLogTimeMessage("Iniciando processamento")
 
-	type resultadoProcessamento struct {
-		IdDoc string
-		Erro  error
+	itens := make([]services.ItemAutuacao, 0, len(autuaFiles))
+	for _, reg := range autuaFiles {
+		itens = append(itens, services.ItemAutuacao{IdContexto: reg.IdContexto, IdDoc: reg.IdDoc})
 	}
 
-	// Se quiser limitar concorrência (RECOMENDADO p/ OCR/OpenSearch):
-	// const maxWorkers = 8
-	// sem := make(chan struct{}, maxWorkers)
-
-	resultChan := make(chan resultadoProcessamento, len(autuaFiles))
-
-	var wg sync.WaitGroup
-
-	// Consumidor: agrega resultados enquanto as goroutines rodam
-	var (
-		mu             sync.Mutex
-		extractedFiles []string
-		extractedErros []string
-	)
-
-	doneAgg := make(chan struct{})
-	go func() {
-		defer close(doneAgg)
-		for res := range resultChan {
-			mu.Lock()
-			if res.Erro != nil {
-				msg := fmt.Sprintf("Erro ao processar documento IdDoc=%s: %v", res.IdDoc, res.Erro)
-				logger.Log.Error(msg)
-				//extractedErros = append(extractedErros, res.IdDoc)
-				extractedErros = append(extractedErros, res.Erro.Error())
-			} else {
-				extractedFiles = append(extractedFiles, res.IdDoc)
-			}
-			mu.Unlock()
+	// Execução assíncrona: devolve o job e o cliente acompanha em /jobs/:id
+	if c.Query("async") == "true" {
+		job, err := services.JobsServiceGlobal.Submeter(consts.JOB_TIPO_AUTUACAO, itens, c.GetString("userName"))
+		if err != nil {
+			logger.Log.Errorf("Erro ao submeter o job de autuação: %v", err)
+			response.HandleError(c, http.StatusInternalServerError, "Erro ao submeter o job de autuação", err.Error(), requestID)
+			return
 		}
-	}()
-
-	for _, reg := range autuaFiles {
-		idCtxt := reg.IdContexto
-		idDoc := reg.IdDoc
-
-		wg.Add(1)
-		go func(idCtxt, idDoc string) {
-			defer wg.Done()
-
-			// sem <- struct{}{}        // se habilitar o limitador
-			// defer func() { <-sem }() // libera o slot
-
-			// RECOVER para evitar derrubar o processo inteiro
-			defer func() {
-				if r := recover(); r != nil {
-					// stacktrace completo para diagnosticar o nil
-					stack := debug.Stack()
-					err := fmt.Errorf("panic em ProcessarDocumento idCtxt=%s idDoc=%s: %v", idCtxt, idDoc, r)
-					logger.Log.Errorf("%v\n%s", err, stack)
-
-					resultChan <- resultadoProcessamento{
-						IdDoc: idDoc,
-						Erro:  err,
-					}
-				}
-			}()
-
-			// validação mínima
-			if idCtxt == "" || idDoc == "" {
-				resultChan <- resultadoProcessamento{
-					IdDoc: idDoc,
-					Erro:  fmt.Errorf("idCtxt ou idDoc vazio (idCtxt=%q idDoc=%q)", idCtxt, idDoc),
-				}
-				return
-			}
-
-			err := services.ProcessarDocumento(idCtxt, idDoc)
-
-			resultChan <- resultadoProcessamento{
-				IdDoc: idDoc,
-				Erro:  err,
-			}
-		}(idCtxt, idDoc)
+		response.HandleSucesso(c, http.StatusAccepted, job, requestID)
+		return
 	}
 
-	wg.Wait()
-	close(resultChan)
-	<-doneAgg
+	// O cliente que desconecta cancela os documentos ainda não autuados; os já gravados em
+	// "autos" são reconhecidos na nova autuação (ver services.ChaveAutuacao)
+	res := services.AutuarDocumentos(c.Request.Context(), itens)
+	extractedFiles, extractedErros := res.ExtractedFiles, res.ExtractedErros
 
 	msgs.CreateLogTimeMessage("Processamento concluído")
 
@@ -387,6 +442,9 @@ func (obj *AutosTempHandlerType) SelectAllHandler(c *gin.Context) {
 	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
 }
 
+// MAX_VERIFICACOES_NATUREZA limita as verificações de natureza simultâneas no saneamento
+const MAX_VERIFICACOES_NATUREZA = 4
+
 /*
 Analisa todos os documentos inseridos na tabela "autos_temp", excluindo os registros que não
 correspondam a documentos válidos para a juntada.
@@ -407,7 +465,7 @@ func (service *AutosTempHandlerType) SanearByContextHandler(c *gin.Context) {
 
 	rows, err := services.AutosTempServiceGlobal.SelectByContexto(idContexto)
 	if err != nil {
-		logger.Log.Errorf("Erro ao buscar arquivos pelo contexto %d: %v", idContexto, err)
+		logger.Log.Errorf("Erro ao buscar arquivos pelo contexto %s: %v", idContexto, err)
 		c.JSON(http.StatusInternalServerError, msgs.CreateResponseMessage("Erro ao buscar arquivos"))
 		return
 	}
@@ -423,6 +481,9 @@ func (service *AutosTempHandlerType) SanearByContextHandler(c *gin.Context) {
 	// Usar canal para capturar erros na verificação (opcional)
 	errCh := make(chan error, len(rows))
 
+	// Limita as verificações simultâneas: as de baixa confiança chamam o modelo
+	sem := make(chan struct{}, MAX_VERIFICACOES_NATUREZA)
+
 	for _, row := range rows {
 		wg.Add(1)
 		deletar := false
@@ -432,9 +493,11 @@ func (service *AutosTempHandlerType) SanearByContextHandler(c *gin.Context) {
 
 		go func() {
 			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
 			//Rotina que faz o trabalho pesado de verificação de cada registro
-			natuDoc, err := service.Service.VerificarNaturezaDocumento(c.Request.Context(), idContexto, rowCopy.Doc)
+			natuDoc, err := service.Service.VerificarNaturezaDocumento(c.Request.Context(), idContexto, rowCopy.Id, rowCopy.Doc)
 			if err != nil {
 				logger.Log.Errorf("Erro ao verificar a natureza do documento: %s", rowCopy.IdPje)
 				return
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
+	"ocrserver/internal/consts"
 	"ocrserver/internal/handlers/response"
 	"ocrserver/internal/models"
+	"ocrserver/internal/services"
 	"ocrserver/internal/services/ialib"
 	"ocrserver/internal/services/rag/pipeline"
 
@@ -56,6 +60,19 @@ func (service *ContextoQueryHandlerType) QueryHandlerPipeline(c *gin.Context) {
 		messages.AddMessage(msg)
 	}
 
+	// Execução assíncrona: devolve o job e o cliente acompanha em /jobs/:id
+	if c.Query("async") == "true" {
+		params := pipeline.JobPipelineParams{IdCtxt: body.IdCtxt, Messages: body.Messages, PrevID: body.PrevID}
+		job, err := services.JobsServiceGlobal.Submeter(consts.JOB_TIPO_PIPELINE_RAG, params, userName)
+		if err != nil {
+			logger.Log.Errorf("Erro ao submeter o job do pipeline: %v", err)
+			response.HandleError(c, http.StatusInternalServerError, "Erro ao submeter o job do pipeline", err.Error(), requestID)
+			return
+		}
+		response.HandleSucesso(c, http.StatusAccepted, job, requestID)
+		return
+	}
+
 	orch := pipeline.NewOrquestradorType()
 
 	// ✅ novo método
@@ -67,17 +84,7 @@ func (service *ContextoQueryHandlerType) QueryHandlerPipeline(c *gin.Context) {
 	}
 
 	// Data rica, sempre igual (front não sofre)
-	data := gin.H{
-		"message":   res.Message,
-		"status":    res.Status.String(),
-		"ok":        res.Status == pipeline.StatusOK,
-		"blocked":   res.Status == pipeline.StatusBlocked,
-		"invalid":   res.Status == pipeline.StatusInvalid,
-		"id":        res.ID,
-		"output":    res.Output,
-		"eventCode": res.EventCode,
-		"eventDesc": res.EventDesc,
-	}
+	data := dadosPipelineResult(res)
 
 	// Map status -> HTTP + Ok + ErrorDetail (quando não OK)
 	switch res.Status {
@@ -124,3 +131,112 @@ func (service *ContextoQueryHandlerType) QueryHandlerPipeline(c *gin.Context) {
 		return
 	}
 }
+
+// dadosPipelineResult monta o objeto devolvido ao cliente (igual nas versões JSON e SSE).
+func dadosPipelineResult(res pipeline.PipelineResult) gin.H {
+	return gin.H(res.Resumo())
+}
+
+/*
+QueryHandlerPipelineStream
+Versão em streaming (Server-Sent Events) do QueryHandlerPipeline. Emite um evento
+"progresso" por etapa do pipeline (evento identificado, autos recuperados, RAG
+concluído, geração iniciada, evento salvo), eventos "token" com os trechos do texto
+gerado pelo modelo e, ao final, um evento "resultado" com o mesmo objeto da versão
+JSON. Em caso de falha, emite "erro". Comentários ": ping" a cada 15s mantêm a
+conexão viva atrás de proxies reversos.
+*/
+func (service *ContextoQueryHandlerType) QueryHandlerPipelineStream(c *gin.Context) {
+	userName := c.GetString("userName")
+	requestID := middleware.GetRequestID(c)
+
+	var body BodyParamsQuery
+	if err := c.ShouldBindJSON(&body); err != nil {
+		logger.Log.Errorf("Parâmetros inválidos: %v", err)
+		response.HandleError(c, http.StatusBadRequest, "Parâmetros do body inválidos", "", requestID)
+		return
+	}
+
+	if body.IdCtxt == "" {
+		logger.Log.Error("O ID do contexto é obrigatório")
+		response.HandleError(c, http.StatusBadRequest, "O ID do contexto é obrigatório", "", requestID)
+		return
+	}
+
+	if len(body.Messages) == 0 {
+		logger.Log.Error("A lista de mensagens está vazia")
+		response.HandleError(c, http.StatusBadRequest, "A lista de mensagens está vazia", "", requestID)
+		return
+	}
+
+	var messages ialib.MsgGpt
+	for _, msg := range body.Messages {
+		messages.AddMessage(msg)
+	}
+
+	// A minuta pode demorar mais que o WriteTimeout do servidor: remove o prazo desta resposta
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.Log.Warningf("Não foi possível remover o prazo de escrita do SSE: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: não bufferizar
+	c.Status(http.StatusOK)
+
+	// Escritas podem vir do pipeline e do heartbeat: serializa
+	var mu sync.Mutex
+	enviar := func(evento string, dados any) {
+		mu.Lock()
+		defer mu.Unlock()
+		c.SSEvent(evento, dados)
+		c.Writer.Flush()
+	}
+
+	ctx := c.Request.Context()
+	// O handler só retorna após o fim do heartbeat: o gin reaproveita o Context (e o Writer)
+	fimPing := make(chan struct{})
+	var wgPing sync.WaitGroup
+	defer func() {
+		close(fimPing)
+		wgPing.Wait()
+	}()
+	wgPing.Add(1)
+	go func() {
+		defer wgPing.Done()
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mu.Lock()
+				_, _ = c.Writer.WriteString(": ping\n\n")
+				c.Writer.Flush()
+				mu.Unlock()
+			case <-fimPing:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ctxPipeline := pipeline.WithProgresso(ctx, func(ev pipeline.ProgressoEvento) {
+		if ev.Etapa == pipeline.ETAPA_TOKEN {
+			enviar("token", ev.Dados)
+			return
+		}
+		enviar("progresso", ev)
+	})
+
+	orch := pipeline.NewOrquestradorType()
+	res, err := orch.StartPipelineResult(ctxPipeline, body.IdCtxt, messages, body.PrevID, userName)
+	if err != nil {
+		logger.Log.Errorf("Erro durante o pipeline RAG (stream): %v", err)
+		enviar("erro", response.NewError(http.StatusInternalServerError, "Erro durante o pipeline RAG", err.Error(), requestID))
+		return
+	}
+
+	enviar("resultado", response.NewSuccess(dadosPipelineResult(res), requestID))
+}
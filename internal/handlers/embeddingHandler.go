@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 
+	"ocrserver/internal/consts"
+
 	"ocrserver/internal/handlers/response"
 	"ocrserver/internal/services"
 
@@ -327,3 +329,34 @@ func (handler *EmbeddingHandlerType) SearchEmbeddingHandler(c *gin.Context) {
 
 	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
 }
+
+/*
+Gera, em job assíncrono, os embeddings dos documentos de "autos" ainda sem registro em
+"autos_json_embedding" (documentos autuados antes da indexação). Devolve o job (202); o
+andamento é acompanhado em /jobs/:id.
+
+  - Rota: "/admin/embeddings/autos"
+  - Método: POST
+  - Body: {"contextos": [...], "reindexar": false}, ambos opcionais: sem contextos, todos
+    os contextos; com "reindexar", refaz também os embeddings existentes.
+*/
+func (service *EmbeddingHandlerType) BackfillAutosHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	var params services.ParamsEmbeddingsAutos
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&params); err != nil {
+			logger.Log.Errorf("Parâmetros inválidos: %v", err)
+			response.HandleError(c, http.StatusBadRequest, "Parâmetros inválidos", err.Error(), requestID)
+			return
+		}
+	}
+
+	job, err := services.JobsServiceGlobal.Submeter(consts.JOB_TIPO_EMBEDDINGS_AUTOS, params, c.GetString("userName"))
+	if err != nil {
+		logger.Log.Errorf("Erro ao submeter o job de embeddings: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao submeter o job de embeddings", err.Error(), requestID)
+		return
+	}
+	response.HandleSucesso(c, http.StatusAccepted, job, requestID)
+}
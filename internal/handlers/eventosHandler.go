@@ -10,11 +10,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"ocrserver/internal/consts"
 	"ocrserver/internal/handlers/response"
 	"ocrserver/internal/opensearch"
 	"ocrserver/internal/services"
+	"ocrserver/internal/services/exportacao"
 	"ocrserver/internal/utils/logger"
 	"ocrserver/internal/utils/middleware"
 
@@ -209,3 +213,96 @@ func (obj *EventosHandlerType) SelectAllHandler(c *gin.Context) {
 	}
 	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
 }
+
+/*
+Exportar evento (GET /contexto/eventos/:id/export?format=docx|odt|pdf|html|md)
+
+Gera o arquivo da minuta/análise com o timbre do juízo. O formato padrão é docx.
+*/
+func (obj *EventosHandlerType) ExportHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	paramID := c.Param("id")
+	if paramID == "" {
+		logger.Log.Error("ID ausente")
+		response.HandleError(c, http.StatusBadRequest, "ID ausente", "", requestID)
+		return
+	}
+
+	formato := strings.ToLower(c.DefaultQuery("format", exportacao.FORMATO_DOCX))
+	if !exportacao.FormatoValido(formato) {
+		logger.Log.Errorf("Formato de exportação inválido: %s", formato)
+		response.HandleError(c, http.StatusBadRequest, "Formato inválido (use docx, odt, pdf, html ou md)", "", requestID)
+		return
+	}
+
+	row, statusCode, err := obj.service.SelectById(paramID)
+	if statusCode == http.StatusNotFound {
+		logger.Log.Errorf("Evento não encontrado ID: %s", paramID)
+		response.HandleError(c, http.StatusNotFound, "Evento não encontrado", "", requestID)
+		return
+	}
+	if err != nil || row == nil {
+		logger.Log.Errorf("Erro ao consultar evento pelo ID: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao consultar evento", "", requestID)
+		return
+	}
+
+	// Dados do processo para o cabeçalho e o timbre
+	proc := exportacao.DadosProcesso{}
+	ctxts, err := services.ContextoServiceGlobal.SelectContextoByIdCtxt(row.IdCtxt)
+	if err != nil {
+		logger.Log.Warningf("Contexto %s não localizado para exportação: %v", row.IdCtxt, err)
+	} else if len(ctxts) > 0 {
+		proc = exportacao.DadosProcesso{
+			Numero:  ctxts[0].NrProc,
+			Juizo:   ctxts[0].Juizo,
+			Classe:  ctxts[0].Classe,
+			Assunto: ctxts[0].Assunto,
+		}
+	}
+
+	timbre, err := exportacao.Timbre(proc)
+	if err != nil {
+		logger.Log.Errorf("Erro ao montar o timbre: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao montar o timbre", "", requestID)
+		return
+	}
+
+	doc, err := exportacao.MontaDocumento(row, proc, timbre)
+	if err != nil {
+		logger.Log.Errorf("Erro ao montar documento do evento %s: %v", paramID, err)
+		response.HandleError(c, http.StatusUnprocessableEntity, "Conteúdo do evento inválido para exportação", "", requestID)
+		return
+	}
+
+	arquivo, contentType, err := exportacao.Exporta(doc, formato)
+	if err != nil {
+		logger.Log.Errorf("Erro ao exportar evento %s: %v", paramID, err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao gerar o arquivo", "", requestID)
+		return
+	}
+
+	nome := fmt.Sprintf("%s_%s.%s", nomeArquivoExport(consts.GetNaturezaDocumento(row.IdNatu)), nomeArquivoExport(proc.Numero), formato)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, nome))
+	c.Data(http.StatusOK, contentType, arquivo)
+}
+
+// nomeArquivoExport reduz o texto a letras, dígitos e "_" para uso no nome do arquivo
+func nomeArquivoExport(s string) string {
+	s = strings.NewReplacer("á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+		"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c").Replace(strings.ToLower(s))
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			sb.WriteByte('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "evento"
+	}
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+/*
+---------------------------------------------------------------------------------------
+File: jobsHandler.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Consulta e cancelamento dos jobs assíncronos (tabela "jobs"). Os jobs
+são criados pelas rotas de extração, autuação e análise com "?async=true".
+---------------------------------------------------------------------------------------
+*/
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ocrserver/internal/handlers/response"
+	"ocrserver/internal/models"
+	"ocrserver/internal/services"
+	"ocrserver/internal/utils/logger"
+	"ocrserver/internal/utils/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobsHandlerType struct {
+	service *services.JobsServiceType
+}
+
+func NewJobsHandlers(service *services.JobsServiceType) *JobsHandlerType {
+	return &JobsHandlerType{service: service}
+}
+
+// Somente o dono do job (ou o admin) pode consultá-lo ou cancelá-lo
+func podeAcessarJob(c *gin.Context, job *models.JobRow) bool {
+	return job.UserName == c.GetString("userName") || c.GetString("userRole") == "admin"
+}
+
+/*
+ * Devolve status, progresso, resultado e erro do job.
+ *
+ * - **Rota**: "/jobs/:id"
+ * - **Método**: GET
+ */
+func (obj *JobsHandlerType) SelectByIdHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	idJob := c.Param("id")
+	if idJob == "" {
+		response.HandleError(c, http.StatusBadRequest, "ID do job não informado", "", requestID)
+		return
+	}
+
+	job, err := obj.service.SelectById(idJob)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar o job %s: %v", idJob, err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao consultar o job", err.Error(), requestID)
+		return
+	}
+	if job == nil || !podeAcessarJob(c, job) {
+		response.HandleError(c, http.StatusNotFound, "Job não encontrado", "", requestID)
+		return
+	}
+
+	response.HandleSucesso(c, http.StatusOK, job, requestID)
+}
+
+/*
+ * Lista os jobs mais recentes do usuário logado.
+ *
+ * - **Rota**: "/jobs?limite=50"
+ * - **Método**: GET
+ */
+func (obj *JobsHandlerType) SelectAllHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", "50"))
+	if err != nil || limite <= 0 || limite > 500 {
+		limite = 50
+	}
+
+	rows, err := obj.service.SelectByUser(c.GetString("userName"), limite)
+	if err != nil {
+		logger.Log.Errorf("Erro ao listar os jobs: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao listar os jobs", err.Error(), requestID)
+		return
+	}
+
+	response.HandleSucesso(c, http.StatusOK, gin.H{"jobs": rows}, requestID)
+}
+
+/*
+ * Solicita o cancelamento do job (pendente ou em execução).
+ *
+ * - **Rota**: "/jobs/:id/cancel"
+ * - **Método**: POST
+ */
+func (obj *JobsHandlerType) CancelHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	idJob := c.Param("id")
+	job, err := obj.service.SelectById(idJob)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar o job %s: %v", idJob, err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao consultar o job", err.Error(), requestID)
+		return
+	}
+	if job == nil || !podeAcessarJob(c, job) {
+		response.HandleError(c, http.StatusNotFound, "Job não encontrado", "", requestID)
+		return
+	}
+
+	ok, err := obj.service.Cancelar(idJob)
+	if err != nil {
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao cancelar o job", err.Error(), requestID)
+		return
+	}
+	if !ok {
+		response.HandleError(c, http.StatusConflict, "Job já finalizado", job.Status, requestID)
+		return
+	}
+
+	response.HandleSucesso(c, http.StatusOK, gin.H{"id_job": idJob, "message": "Cancelamento solicitado"}, requestID)
+}
@@ -6,15 +6,19 @@ import (
 
 	"net/http"
 
+	"ocrserver/internal/consts"
 	"ocrserver/internal/handlers/response"
 	"ocrserver/internal/models"
 	"ocrserver/internal/services"
+	"ocrserver/internal/services/importadores"
+	"ocrserver/internal/services/tribunais"
 	"ocrserver/internal/utils/logger"
 	"ocrserver/internal/utils/middleware"
 
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"ocrserver/internal/database/pgdb"
@@ -31,6 +35,9 @@ type UploadHandlerType struct {
 // Tamanho máximo do arquivo aceito no upload(80MB)
 const MAX_SIZE_UPLOAD = 10 << 23
 
+// Tamanho máximo da requisição do upload em lote(1GB)
+const MAX_SIZE_UPLOAD_LOTE = 1 << 30
+
 func NewUploadHandlers(service *services.UploadServiceType) *UploadHandlerType {
 
 	return &UploadHandlerType{Service: service}
@@ -78,37 +85,155 @@ func (service *UploadHandlerType) UploadFileHandler(c *gin.Context) {
 		return
 	}
 
-	//uniqueFileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(handler.Filename))
-
-	uniqueFileName := generateUniqueFileName() + filepath.Ext(handler.Filename)
-
-	savePath := filepath.Join("uploads", uniqueFileName)
-
-	if err := os.MkdirAll("uploads", os.ModePerm); err != nil {
-		logger.Log.Errorf("Erro ao criar diretório uploads: %v", err)
-		response.HandleError(c, http.StatusInternalServerError, "Erro ao criar diretório uploads", err.Error(), requestID)
+	// O arquivo passa por um diretório temporário e segue para o armazenamento (BLOB_BACKEND)
+	tmpDir, err := os.MkdirTemp("", "upload-*")
+	if err != nil {
+		logger.Log.Errorf("Erro ao criar diretório temporário: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao criar diretório temporário", err.Error(), requestID)
 		return
 	}
+	defer os.RemoveAll(tmpDir)
 
+	savePath := filepath.Join(tmpDir, generateUniqueFileName()+filepath.Ext(handler.Filename))
 	if err := c.SaveUploadedFile(handler, savePath); err != nil {
 		logger.Log.Errorf("Erro ao salvar arquivo: %v", err)
 		response.HandleError(c, http.StatusInternalServerError, "Erro ao salvar arquivo", err.Error(), requestID)
 		return
 	}
 
-	if err := service.InsertUploadedFile(idContexto, uniqueFileName, filenameOri); err != nil {
+	// Arquivo idêntico (SHA-256) aguardando a extração no contexto: não é registrado de novo
+	reg, err := service.Service.RegistraUpload(c.Request.Context(), idContexto, savePath, filenameOri)
+	if err != nil {
 		logger.Log.Errorf("Erro ao registrar arquivo no banco: %v", err)
 		response.HandleError(c, http.StatusInternalServerError, "Erro ao registrar arquivo no banco", err.Error(), requestID)
 		return
 	}
 
+	if reg.Pendente {
+		rsp := gin.H{
+			"upload":  reg,
+			"message": "Arquivo idêntico já enviado e aguardando a extração",
+		}
+		response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+		return
+	}
+
+	message := "Arquivo transferido com sucesso"
+	if reg.DtImportacaoAnterior != nil {
+		message = "Arquivo transferido com sucesso; o mesmo arquivo já foi extraído neste contexto em " + reg.DtImportacaoAnterior.Format("02/01/2006 15:04")
+	}
 	rsp := gin.H{
-		"message": "Arquivo transferido com sucesso",
+		"upload":  reg,
+		"message": message,
 	}
 
 	response.HandleSucesso(c, http.StatusCreated, rsp, requestID)
 }
 
+/*
+*
+  - Upload em lote: vários arquivos (PDF, TXT ou ZIP) numa só requisição, com a extração
+    de cada um e o relatório por arquivo
+  - Rota: "/contexto/documentos/upload/lote"
+  - Params: ?async=true devolve o job de extração (acompanhado em /jobs/:id)
+  - Content-Type: multipart/form-data.
+  - Body: {
+  - files: File[],
+  - idContexto: string,
+    perfil: string,   // perfil de tribunal (opcional)
+    formato: string,  // formato dos autos (opcional)
+    }
+  - Método: POST
+  - Teste: curl -X POST http://localhost:4001/contexto/documentos/upload/lote -F "idContexto=1" -F "files=@pecas.zip" -F "files=@inicial.pdf"
+*/
+func (service *UploadHandlerType) UploadLoteHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MAX_SIZE_UPLOAD_LOTE)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		logger.Log.Errorf("Erro ao obter os arquivos do lote: %v", err)
+		response.HandleError(c, http.StatusBadRequest, "Erro ao obter os arquivos: requisição com mais de 1GB ou inválida", err.Error(), requestID)
+		return
+	}
+	handlers := append(form.File["files"], form.File["file"]...)
+
+	idContexto := c.PostForm("idContexto")
+	if idContexto == "" || len(handlers) == 0 {
+		logger.Log.Error("Campos idContexto e files obrigatórios")
+		response.HandleError(c, http.StatusBadRequest, "Campos idContexto e files obrigatórios", "", requestID)
+		return
+	}
+
+	perfil := c.PostForm("perfil")
+	if _, err := tribunais.Global().Perfil(perfil); err != nil {
+		response.HandleError(c, http.StatusBadRequest, "Perfil de tribunal inválido", err.Error(), requestID)
+		return
+	}
+	formato := c.PostForm("formato")
+	if err := importadores.ValidaFormato(formato); err != nil {
+		response.HandleError(c, http.StatusBadRequest, "Formato dos autos inválido", err.Error(), requestID)
+		return
+	}
+
+	tmpDir, err := os.MkdirTemp("", "lote-*")
+	if err != nil {
+		logger.Log.Errorf("Erro ao criar diretório temporário: %v", err)
+		response.HandleError(c, http.StatusInternalServerError, "Erro ao criar diretório temporário", err.Error(), requestID)
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Grava os arquivos recebidos; a falha de um deles entra no relatório sem interromper os demais
+	var falhas []services.ArquivoLote
+	recebidos := make([]services.ArquivoRecebido, 0, len(handlers))
+	for i, handler := range handlers {
+		savePath := filepath.Join(tmpDir, fmt.Sprintf("%d%s", i, strings.ToLower(filepath.Ext(handler.Filename))))
+		if err := c.SaveUploadedFile(handler, savePath); err != nil {
+			logger.Log.Errorf("Erro ao salvar arquivo %s: %v", handler.Filename, err)
+			falhas = append(falhas, services.ArquivoLote{Arquivo: handler.Filename, Status: services.LOTE_FALHA, Motivo: err.Error()})
+			continue
+		}
+		recebidos = append(recebidos, services.ArquivoRecebido{Nome: filepath.Base(handler.Filename), Caminho: savePath})
+	}
+
+	lote := append(falhas, service.Service.RegistraLote(c.Request.Context(), idContexto, recebidos, formato)...)
+
+	// Execução assíncrona: o relatório traz os arquivos registrados e o job da extração
+	if c.PostForm("async") == "true" || c.Query("async") == "true" {
+		var job any
+		if params := services.ParamsLote(idContexto, lote, perfil, formato); len(params) > 0 {
+			job, err = services.JobsServiceGlobal.Submeter(consts.JOB_TIPO_EXTRACAO_PDF, params, c.GetString("userName"))
+			if err != nil {
+				logger.Log.Errorf("Erro ao submeter o job de extração: %v", err)
+				response.HandleError(c, http.StatusInternalServerError, "Erro ao submeter o job de extração", err.Error(), requestID)
+				return
+			}
+		}
+		rsp := gin.H{
+			"arquivos": lote,
+			"job":      job,
+			"message":  "Arquivos registrados; extração em andamento",
+		}
+		response.HandleSucesso(c, http.StatusAccepted, rsp, requestID)
+		return
+	}
+
+	lote = service.Service.ExtraiLote(c.Request.Context(), idContexto, lote, perfil, formato)
+
+	resumo := map[string]int{}
+	for _, item := range lote {
+		resumo[item.Status]++
+	}
+	rsp := gin.H{
+		"arquivos": lote,
+		"resumo":   resumo,
+		"message":  "Processamento do lote concluído",
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
 /*
  * Devolve os registros da tabela 'uploads' para um determinado contexto.
  *
@@ -272,8 +397,8 @@ func (service *UploadHandlerType) DeleteHandler(c *gin.Context) {
 			continue
 		}
 
-		// Deleta o registro do banco
-		err = service.Service.DeleteRegistro(reg.IdFile)
+		// Deleta o registro do banco e o arquivo armazenado
+		err = service.Service.ApagaUpload(c.Request.Context(), row)
 		if err != nil {
 
 			logger.Log.Error("Erro ao deletar registro:", err.Error())
@@ -281,18 +406,6 @@ func (service *UploadHandlerType) DeleteHandler(c *gin.Context) {
 			continue
 		}
 
-		// Deleta o arquivo do sistema de arquivos
-		fullFileName := filepath.Join("uploads", row.NmFileNew)
-		if service.FileExist(fullFileName) {
-			err = service.DeletarFile(fullFileName)
-			if err != nil {
-
-				logger.Log.Error("Erro ao deletar arquivo físico:", err.Error())
-				failedFiles = append(failedFiles, reg.IdFile)
-				continue
-			}
-		}
-
 		// Adiciona ao rastreamento de sucessos
 		deletedFiles = append(deletedFiles, reg.IdFile)
 	}
@@ -333,26 +446,14 @@ func (service *UploadHandlerType) DeleteHandlerById(c *gin.Context) {
 		return
 	}
 
-	// Deleta o registro do banco
-	err = service.Service.DeleteRegistro(idFile)
+	// Deleta o registro do banco e o arquivo armazenado
+	err = service.Service.ApagaUpload(c.Request.Context(), row)
 	if err != nil {
 		logger.Log.Error("Erro ao deletar registro:", err.Error())
 		response.HandleError(c, http.StatusBadRequest, "Erro ao deletar o registro!: ", err.Error(), requestID)
 		return
 	}
 
-	// Deleta o arquivo do sistema de arquivos
-	fullFileName := filepath.Join("uploads", row.NmFileNew)
-	if service.FileExist(fullFileName) {
-		err = service.DeletarFile(fullFileName)
-		if err != nil {
-
-			logger.Log.Error("Erro ao deletar arquivo físico:"+fullFileName, err.Error())
-			response.HandleError(c, http.StatusBadRequest, "Erro ao deletar o arquivo: ", err.Error(), requestID)
-			return
-		}
-	}
-
 	rsp := gin.H{
 		"rows":    nil,
 		"message": "Documento(s) deletado(s) com sucesso!",
@@ -388,7 +489,7 @@ por upload.
 func (service *UploadHandlerType) InsertUploadedFile(idCtxt string, fileName string, fileNameOri string) error {
 	// Validações de entrada
 	if idCtxt == "" {
-		return fmt.Errorf("ID de contexto inválido: %d", idCtxt)
+		return fmt.Errorf("ID de contexto inválido: %q", idCtxt)
 	}
 	if fileName == "" {
 		return fmt.Errorf("Nome do arquivo não pode ser vazio")
@@ -0,0 +1,212 @@
+/*
+---------------------------------------------------------------------------------------
+File: uploadRetomavelHandler.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Rotas do upload retomável (em blocos) dos autos grandes, que não cabem no
+limite de MAX_SIZE_UPLOAD ou cujo envio cai no meio pela VPN do tribunal:
+  - POST   /contexto/documentos/upload/retomavel              inicia (tamanho e sha256)
+  - GET    /contexto/documentos/upload/retomavel/:id          bytes já recebidos
+  - PATCH  /contexto/documentos/upload/retomavel/:id          envia um bloco
+  - POST   /contexto/documentos/upload/retomavel/:id/concluir confere o hash e extrai
+
+O offset do próximo bloco vai no cabeçalho "Upload-Offset", na requisição e na
+resposta. O cancelamento usa a rota DELETE /contexto/documentos/upload/:id.
+---------------------------------------------------------------------------------------
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/handlers/response"
+	"ocrserver/internal/models"
+	"ocrserver/internal/services"
+	"ocrserver/internal/services/importadores"
+	"ocrserver/internal/services/tribunais"
+	"ocrserver/internal/utils/logger"
+	"ocrserver/internal/utils/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bodyIniciaRetomavel struct {
+	IdContexto  string `json:"idContexto"`
+	FilenameOri string `json:"filename_ori"`
+	Tamanho     int64  `json:"tamanho"` // bytes
+	Sha256      string `json:"sha256"`  // hash do arquivo completo, em hexadecimal
+}
+
+type bodyConcluiRetomavel struct {
+	Perfil  string `json:"perfil"`
+	Formato string `json:"formato"`
+}
+
+// Método: POST
+// URL: "/contexto/documentos/upload/retomavel"
+// Inicia o upload retomável e devolve o id_file e o offset do primeiro bloco (0)
+func (service *UploadHandlerType) IniciaRetomavelHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	var body bodyIniciaRetomavel
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.HandleError(c, http.StatusBadRequest, "Body params inválidos", err.Error(), requestID)
+		return
+	}
+
+	row, err := service.Service.IniciaRetomavel(c.Request.Context(), body.IdContexto, body.FilenameOri, body.Tamanho, body.Sha256)
+	if err != nil {
+		logger.Log.Errorf("Erro ao iniciar o upload retomável: %v", err)
+		responderErroRetomavel(c, nil, err, requestID)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(row.BytesRecebidos, 10))
+	rsp := gin.H{
+		"upload":  row,
+		"message": "Upload iniciado",
+	}
+	response.HandleSucesso(c, http.StatusCreated, rsp, requestID)
+}
+
+// Método: GET
+// URL: "/contexto/documentos/upload/retomavel/:id"
+// Devolve o andamento do upload: o cliente retoma o envio a partir de bytes_recebidos
+func (service *UploadHandlerType) SituacaoRetomavelHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	idFile, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.HandleError(c, http.StatusBadRequest, "id_file inválido", "", requestID)
+		return
+	}
+
+	row, err := service.Service.SituacaoRetomavel(idFile)
+	if err != nil {
+		responderErroRetomavel(c, nil, err, requestID)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(row.BytesRecebidos, 10))
+	response.HandleSucesso(c, http.StatusOK, gin.H{"upload": row}, requestID)
+}
+
+// Método: PATCH
+// URL: "/contexto/documentos/upload/retomavel/:id"
+// Header: Upload-Offset: <bytes já recebidos>; Body: bytes do bloco (application/octet-stream)
+func (service *UploadHandlerType) AnexaBlocoHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	idFile, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.HandleError(c, http.StatusBadRequest, "id_file inválido", "", requestID)
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		response.HandleError(c, http.StatusBadRequest, "Cabeçalho Upload-Offset obrigatório e válido", "", requestID)
+		return
+	}
+
+	row, err := service.Service.AnexaBloco(c.Request.Context(), idFile, offset, c.Request.Body)
+	if err != nil {
+		logger.Log.Errorf("Erro no bloco do upload id_file=%d offset=%d: %v", idFile, offset, err)
+		responderErroRetomavel(c, row, err, requestID)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(row.BytesRecebidos, 10))
+	response.HandleSucesso(c, http.StatusOK, gin.H{"upload": row}, requestID)
+}
+
+// Método: POST
+// URL: "/contexto/documentos/upload/retomavel/:id/concluir"
+// Params: ?async=true devolve o job de extração (acompanhado em /jobs/:id)
+// Body: {perfil, formato} opcionais, como em POST /contexto/documentos
+func (service *UploadHandlerType) ConcluiRetomavelHandler(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	idFile, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.HandleError(c, http.StatusBadRequest, "id_file inválido", "", requestID)
+		return
+	}
+
+	var body bodyConcluiRetomavel
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			response.HandleError(c, http.StatusBadRequest, "Body params inválidos", err.Error(), requestID)
+			return
+		}
+	}
+	if _, err := tribunais.Global().Perfil(body.Perfil); err != nil {
+		response.HandleError(c, http.StatusBadRequest, "Perfil de tribunal inválido", err.Error(), requestID)
+		return
+	}
+	if err := importadores.ValidaFormato(body.Formato); err != nil {
+		response.HandleError(c, http.StatusBadRequest, "Formato dos autos inválido", err.Error(), requestID)
+		return
+	}
+
+	row, err := service.Service.ConcluiRetomavel(c.Request.Context(), idFile)
+	if err != nil {
+		logger.Log.Errorf("Erro ao concluir o upload id_file=%d: %v", idFile, err)
+		responderErroRetomavel(c, row, err, requestID)
+		return
+	}
+
+	// Extração do arquivo concluído, como em POST /contexto/documentos
+	params := []services.BodyParamsPDF{{IdContexto: row.IdCtxt, IdFile: row.IdFile, Perfil: body.Perfil, Formato: body.Formato}}
+	if c.Query("async") == "true" {
+		job, err := services.JobsServiceGlobal.Submeter(consts.JOB_TIPO_EXTRACAO_PDF, params, c.GetString("userName"))
+		if err != nil {
+			logger.Log.Errorf("Erro ao submeter o job de extração: %v", err)
+			response.HandleError(c, http.StatusInternalServerError, "Erro ao submeter o job de extração", err.Error(), requestID)
+			return
+		}
+		rsp := gin.H{
+			"upload":  row,
+			"job":     job,
+			"message": "Upload concluído; extração em andamento",
+		}
+		response.HandleSucesso(c, http.StatusAccepted, rsp, requestID)
+		return
+	}
+
+	extractedFiles, extractedErros, relatorios := service.Service.ProcessaPDF(c.Request.Context(), params)
+
+	rsp := gin.H{
+		"upload":         row,
+		"extractedErros": extractedErros,
+		"extractedFiles": extractedFiles,
+		"relatorios":     relatorios,
+		"message":        "Upload concluído e arquivo extraído",
+	}
+	response.HandleSucesso(c, http.StatusOK, rsp, requestID)
+}
+
+// responderErroRetomavel converte os erros do upload retomável no status HTTP, com o offset atual quando conhecido
+func responderErroRetomavel(c *gin.Context, row *models.UploadParcialRow, err error, requestID string) {
+	if row != nil {
+		c.Header("Upload-Offset", strconv.FormatInt(row.BytesRecebidos, 10))
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrUploadInvalido):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrUploadNaoEncontrado):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrUploadExpirado):
+		status = http.StatusGone
+	case errors.Is(err, services.ErrUploadOffset), errors.Is(err, services.ErrUploadConcluido), errors.Is(err, services.ErrUploadIncompleto):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrUploadTamanho):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrUploadHash):
+		status = http.StatusUnprocessableEntity
+	}
+	response.HandleError(c, status, "Erro no upload retomável", err.Error(), requestID)
+}
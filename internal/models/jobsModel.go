@@ -0,0 +1,243 @@
+/*
+---------------------------------------------------------------------------------------
+File: jobsModel.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Persistência da fila de jobs assíncronos (tabela "jobs"). A reserva do
+próximo job usa "FOR UPDATE SKIP LOCKED", permitindo vários workers (e várias
+instâncias do servidor) consumindo a mesma fila sem disputa.
+---------------------------------------------------------------------------------------
+*/
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"ocrserver/internal/consts"
+
+	"github.com/lib/pq"
+)
+
+type JobRow struct {
+	IdJob       string          `json:"id_job"`
+	Tipo        string          `json:"tipo"`
+	Status      string          `json:"status"`
+	Progresso   int             `json:"progresso"` // 0 a 100
+	Mensagem    string          `json:"mensagem"`
+	Params      json.RawMessage `json:"params"`
+	Resultado   json.RawMessage `json:"resultado,omitempty"`
+	Erro        string          `json:"erro,omitempty"`
+	UserName    string          `json:"username"`
+	Tentativas  int             `json:"tentativas"`
+	Cancelar    bool            `json:"cancelar"`
+	DtInc       time.Time       `json:"dt_inc"`
+	DtInicio    *time.Time      `json:"dt_inicio,omitempty"`
+	DtFim       *time.Time      `json:"dt_fim,omitempty"`
+	DtHeartbeat *time.Time      `json:"dt_heartbeat,omitempty"`
+}
+
+type JobsModelType struct {
+	Db *sql.DB
+}
+
+func NewJobsModel(db *sql.DB) *JobsModelType {
+	return &JobsModelType{Db: db}
+}
+
+const jobsColunas = `id_job, tipo, status, progresso, mensagem, params, resultado, erro, username,
+	tentativas, cancelar, dt_inc, dt_inicio, dt_fim, dt_heartbeat`
+
+func scanJob(scanner interface{ Scan(dest ...any) error }) (*JobRow, error) {
+	var (
+		row       JobRow
+		resultado []byte
+	)
+	err := scanner.Scan(&row.IdJob, &row.Tipo, &row.Status, &row.Progresso, &row.Mensagem, &row.Params,
+		&resultado, &row.Erro, &row.UserName, &row.Tentativas, &row.Cancelar, &row.DtInc,
+		&row.DtInicio, &row.DtFim, &row.DtHeartbeat)
+	if err != nil {
+		return nil, err
+	}
+	if len(resultado) > 0 {
+		row.Resultado = json.RawMessage(resultado)
+	}
+	return &row, nil
+}
+
+func (model *JobsModelType) InsertRow(idJob string, tipo string, params json.RawMessage, userName string) (*JobRow, error) {
+	query := `INSERT INTO jobs (id_job, tipo, status, params, username, dt_inc)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + jobsColunas
+
+	row, err := scanJob(model.Db.QueryRow(query, idJob, tipo, consts.JOB_STATUS_PENDENTE, string(params), userName, time.Now()))
+	if err != nil {
+		log.Printf("Erro ao inserir o registro na tabela jobs: %v", err)
+		return nil, fmt.Errorf("erro ao inserir o registro na tabela jobs: %w", err)
+	}
+	return row, nil
+}
+
+func (model *JobsModelType) SelectById(idJob string) (*JobRow, error) {
+	query := `SELECT ` + jobsColunas + ` FROM jobs WHERE id_job = $1`
+
+	row, err := scanJob(model.Db.QueryRow(query, idJob))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("Erro ao buscar o registro na tabela jobs: %v", err)
+		return nil, fmt.Errorf("erro ao buscar o registro na tabela jobs: %w", err)
+	}
+	return row, nil
+}
+
+// SelectByUser lista os jobs mais recentes do usuário.
+func (model *JobsModelType) SelectByUser(userName string, limite int) ([]JobRow, error) {
+	query := `SELECT ` + jobsColunas + ` FROM jobs WHERE username = $1 ORDER BY dt_inc DESC LIMIT $2`
+
+	rows, err := model.Db.Query(query, userName, limite)
+	if err != nil {
+		log.Printf("Erro ao executar a consulta na tabela jobs: %v", err)
+		return nil, fmt.Errorf("erro ao executar a consulta na tabela jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var results []JobRow
+	for rows.Next() {
+		row, err := scanJob(rows)
+		if err != nil {
+			log.Printf("Erro ao escanear os resultados: %v", err)
+			return nil, fmt.Errorf("erro ao escanear os resultados: %w", err)
+		}
+		results = append(results, *row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração das linhas: %w", err)
+	}
+	return results, nil
+}
+
+/*
+ReservarProximo reserva, de forma atômica, o job pendente mais antigo dos tipos
+informados, marcando-o como "executando". Retorna nil quando a fila está vazia.
+*/
+func (model *JobsModelType) ReservarProximo(tipos []string) (*JobRow, error) {
+	query := `
+		UPDATE jobs SET status = $1, tentativas = tentativas + 1, dt_inicio = $2, dt_heartbeat = $2, erro = ''
+		WHERE id_job = (
+			SELECT id_job FROM jobs
+			WHERE status = $3 AND NOT cancelar AND tipo = ANY($4)
+			ORDER BY dt_inc
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobsColunas
+
+	row, err := scanJob(model.Db.QueryRow(query, consts.JOB_STATUS_EXECUTANDO, time.Now(),
+		consts.JOB_STATUS_PENDENTE, pq.Array(tipos)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao reservar job: %w", err)
+	}
+	return row, nil
+}
+
+// UpdateProgresso registra o avanço do job e renova o heartbeat. Devolve o flag de cancelamento.
+func (model *JobsModelType) UpdateProgresso(idJob string, progresso int, mensagem string) (bool, error) {
+	query := `UPDATE jobs SET progresso = $1, mensagem = $2, dt_heartbeat = $3
+		WHERE id_job = $4 RETURNING cancelar`
+
+	var cancelar bool
+	if err := model.Db.QueryRow(query, progresso, mensagem, time.Now(), idJob).Scan(&cancelar); err != nil {
+		return false, fmt.Errorf("erro ao atualizar o progresso do job: %w", err)
+	}
+	return cancelar, nil
+}
+
+// Heartbeat renova o sinal de vida do job em execução. Devolve o flag de cancelamento.
+func (model *JobsModelType) Heartbeat(idJob string) (bool, error) {
+	query := `UPDATE jobs SET dt_heartbeat = $1 WHERE id_job = $2 RETURNING cancelar`
+
+	var cancelar bool
+	if err := model.Db.QueryRow(query, time.Now(), idJob).Scan(&cancelar); err != nil {
+		return false, fmt.Errorf("erro ao renovar o heartbeat do job: %w", err)
+	}
+	return cancelar, nil
+}
+
+// Finalizar grava a situação final do job (concluido, falha ou cancelado).
+func (model *JobsModelType) Finalizar(idJob string, status string, resultado json.RawMessage, erro string) error {
+	query := `UPDATE jobs SET status = $1, resultado = $2, erro = $3, dt_fim = $4,
+		progresso = CASE WHEN $1::text = 'concluido' THEN 100 ELSE progresso END
+		WHERE id_job = $5`
+
+	// jsonb: envia como texto (o lib/pq codifica []byte como bytea)
+	var res any
+	if len(resultado) > 0 {
+		res = string(resultado)
+	}
+	if _, err := model.Db.Exec(query, status, res, erro, time.Now(), idJob); err != nil {
+		log.Printf("Erro ao finalizar o job %s: %v", idJob, err)
+		return fmt.Errorf("erro ao finalizar o job: %w", err)
+	}
+	return nil
+}
+
+// Reenfileirar devolve o job à fila (nova tentativa).
+func (model *JobsModelType) Reenfileirar(idJob string, erro string) error {
+	query := `UPDATE jobs SET status = $1, erro = $2, dt_heartbeat = NULL WHERE id_job = $3`
+
+	if _, err := model.Db.Exec(query, consts.JOB_STATUS_PENDENTE, erro, idJob); err != nil {
+		return fmt.Errorf("erro ao reenfileirar o job: %w", err)
+	}
+	return nil
+}
+
+/*
+SolicitarCancelamento marca o job para cancelamento. Se ainda estiver pendente, é
+cancelado imediatamente; se estiver em execução, o worker interrompe ao perceber o flag.
+Devolve false quando o job já está finalizado.
+*/
+func (model *JobsModelType) SolicitarCancelamento(idJob string) (bool, error) {
+	query := `UPDATE jobs SET cancelar = true,
+			status = CASE WHEN status = $1 THEN $2 ELSE status END,
+			dt_fim = CASE WHEN status = $1 THEN $3 ELSE dt_fim END
+		WHERE id_job = $4 AND status IN ($1, $5)`
+
+	result, err := model.Db.Exec(query, consts.JOB_STATUS_PENDENTE, consts.JOB_STATUS_CANCELADO,
+		time.Now(), idJob, consts.JOB_STATUS_EXECUTANDO)
+	if err != nil {
+		log.Printf("Erro ao cancelar o job %s: %v", idJob, err)
+		return false, fmt.Errorf("erro ao cancelar o job: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// SelectInterrompidos devolve os jobs "executando" cujo heartbeat parou antes de 'limite'.
+func (model *JobsModelType) SelectInterrompidos(limite time.Time) ([]JobRow, error) {
+	query := `SELECT ` + jobsColunas + ` FROM jobs
+		WHERE status = $1 AND (dt_heartbeat IS NULL OR dt_heartbeat < $2)`
+
+	rows, err := model.Db.Query(query, consts.JOB_STATUS_EXECUTANDO, limite)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar jobs interrompidos: %w", err)
+	}
+	defer rows.Close()
+
+	var results []JobRow
+	for rows.Next() {
+		row, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear os resultados: %w", err)
+		}
+		results = append(results, *row)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,84 @@
+/*
+---------------------------------------------------------------------------------------
+File: naturezaModel.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Registro das classificações da natureza dos documentos extraídos (tabela
+"naturezas_classificadas"): a natureza atribuída, a fonte da decisão (regra, palavras-
+chave ou modelo) e a confiança do classificador local, para a auditoria e o ajuste da
+confiança mínima (NATUREZA_CONFIANCA_MINIMA).
+---------------------------------------------------------------------------------------
+*/
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+type NaturezaClassificadaRow struct {
+	IdClassif      int       `json:"id_classif"`
+	IdCtxt         string    `json:"id_ctxt"`
+	IdDoc          string    `json:"id_doc"`  // id do documento em autos_temp (ou a chave do arquivo)
+	IdNatu         int       `json:"id_natu"` // natureza atribuída
+	Fonte          string    `json:"fonte"`   // regra, palavras-chave ou modelo
+	Confianca      float64   `json:"confianca"`
+	Motivo         string    `json:"motivo"`
+	IdNatuLocal    int       `json:"id_natu_local"`   // natureza sugerida pelo classificador local
+	ConfiancaLocal float64   `json:"confianca_local"` // confiança do classificador local
+	DtInc          time.Time `json:"dt_inc"`
+}
+
+type NaturezaModelType struct {
+	Db *sql.DB
+}
+
+func NewNaturezaModel(db *sql.DB) *NaturezaModelType {
+	return &NaturezaModelType{Db: db}
+}
+
+func (model *NaturezaModelType) InsertRow(row NaturezaClassificadaRow) (int64, error) {
+	query := `
+		INSERT INTO naturezas_classificadas (id_ctxt, id_doc, id_natu, fonte, confianca, motivo,
+			id_natu_local, confianca_local, dt_inc)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id_classif;
+	`
+	var id int64
+	err := model.Db.QueryRow(query, row.IdCtxt, row.IdDoc, row.IdNatu, row.Fonte, row.Confianca, row.Motivo,
+		row.IdNatuLocal, row.ConfiancaLocal, row.DtInc).Scan(&id)
+	if err != nil {
+		log.Printf("Erro ao inserir o registro na tabela naturezas_classificadas: %v", err)
+		return 0, fmt.Errorf("erro ao inserir o registro na tabela naturezas_classificadas: %w", err)
+	}
+	return id, nil
+}
+
+// SelectByContexto lista as classificações do contexto, das mais recentes para as mais antigas
+func (model *NaturezaModelType) SelectByContexto(idCtxt string, limite int) ([]NaturezaClassificadaRow, error) {
+	query := `SELECT id_classif, id_ctxt, id_doc, id_natu, fonte, confianca, motivo, id_natu_local,
+	                 confianca_local, dt_inc
+	          FROM naturezas_classificadas
+	          WHERE id_ctxt = $1
+	          ORDER BY dt_inc DESC LIMIT $2`
+
+	rows, err := model.Db.Query(query, idCtxt, limite)
+	if err != nil {
+		log.Printf("Erro ao executar a consulta na tabela naturezas_classificadas: %v", err)
+		return nil, fmt.Errorf("erro ao executar a consulta na tabela naturezas_classificadas: %w", err)
+	}
+	defer rows.Close()
+
+	results := []NaturezaClassificadaRow{}
+	for rows.Next() {
+		var row NaturezaClassificadaRow
+		if err := rows.Scan(&row.IdClassif, &row.IdCtxt, &row.IdDoc, &row.IdNatu, &row.Fonte, &row.Confianca,
+			&row.Motivo, &row.IdNatuLocal, &row.ConfiancaLocal, &row.DtInc); err != nil {
+			log.Printf("Erro ao ler o registro da tabela naturezas_classificadas: %v", err)
+			return nil, fmt.Errorf("erro ao ler o registro da tabela naturezas_classificadas: %w", err)
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,81 @@
+/*
+---------------------------------------------------------------------------------------
+File: quarentenaModel.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Quarentena da autuação (tabela "autuacoes_quarentena"): o JSON extraído
+pelo modelo que, mesmo após o reparo, não segue o schema da natureza do documento. O
+documento permanece em "autos_temp"; o registro guarda a resposta e os erros de
+validação, para a revisão e a nova autuação.
+---------------------------------------------------------------------------------------
+*/
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+type QuarentenaRow struct {
+	IdQuar   int       `json:"id_quar"`
+	IdCtxt   string    `json:"id_ctxt"`
+	IdDoc    string    `json:"id_doc"` // id do documento em autos_temp
+	IdNatu   int       `json:"id_natu"`
+	IdPje    string    `json:"id_pje"`
+	Formato  string    `json:"formato"`  // nome do schema exigido
+	Resposta string    `json:"resposta"` // última resposta do modelo
+	Erros    string    `json:"erros"`    // erros de validação, um por linha
+	DtInc    time.Time `json:"dt_inc"`
+}
+
+type QuarentenaModelType struct {
+	Db *sql.DB
+}
+
+func NewQuarentenaModel(db *sql.DB) *QuarentenaModelType {
+	return &QuarentenaModelType{Db: db}
+}
+
+func (model *QuarentenaModelType) InsertRow(row QuarentenaRow) (int64, error) {
+	query := `
+		INSERT INTO autuacoes_quarentena (id_ctxt, id_doc, id_natu, id_pje, formato, resposta, erros, dt_inc)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id_quar;
+	`
+	var id int64
+	err := model.Db.QueryRow(query, row.IdCtxt, row.IdDoc, row.IdNatu, row.IdPje, row.Formato, row.Resposta,
+		row.Erros, row.DtInc).Scan(&id)
+	if err != nil {
+		log.Printf("Erro ao inserir o registro na tabela autuacoes_quarentena: %v", err)
+		return 0, fmt.Errorf("erro ao inserir o registro na tabela autuacoes_quarentena: %w", err)
+	}
+	return id, nil
+}
+
+// SelectByContexto lista os documentos em quarentena no contexto, dos mais recentes para os mais antigos
+func (model *QuarentenaModelType) SelectByContexto(idCtxt string, limite int) ([]QuarentenaRow, error) {
+	query := `SELECT id_quar, id_ctxt, id_doc, id_natu, id_pje, formato, resposta, erros, dt_inc
+	          FROM autuacoes_quarentena
+	          WHERE id_ctxt = $1
+	          ORDER BY dt_inc DESC LIMIT $2`
+
+	rows, err := model.Db.Query(query, idCtxt, limite)
+	if err != nil {
+		log.Printf("Erro ao executar a consulta na tabela autuacoes_quarentena: %v", err)
+		return nil, fmt.Errorf("erro ao executar a consulta na tabela autuacoes_quarentena: %w", err)
+	}
+	defer rows.Close()
+
+	results := []QuarentenaRow{}
+	for rows.Next() {
+		var row QuarentenaRow
+		if err := rows.Scan(&row.IdQuar, &row.IdCtxt, &row.IdDoc, &row.IdNatu, &row.IdPje, &row.Formato,
+			&row.Resposta, &row.Erros, &row.DtInc); err != nil {
+			log.Printf("Erro ao ler o registro da tabela autuacoes_quarentena: %v", err)
+			return nil, fmt.Errorf("erro ao ler o registro da tabela autuacoes_quarentena: %w", err)
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
@@ -20,6 +21,24 @@ type UploadRow struct {
 	Status    string    `json:"status"`
 }
 
+// Situação do registro em "uploads"
+const (
+	UPLOAD_STATUS_DISPONIVEL = "S" // arquivo completo, pronto para a extração
+	UPLOAD_STATUS_RECEBENDO  = "R" // upload retomável em andamento
+)
+
+/*
+UploadParcialRow traz, além do registro, o andamento do upload retomável: tamanho
+declarado, bytes já gravados, hash SHA-256 esperado e validade.
+*/
+type UploadParcialRow struct {
+	UploadRow
+	BytesTotal     int64      `json:"bytes_total"`
+	BytesRecebidos int64      `json:"bytes_recebidos"`
+	Sha256         string     `json:"sha256"`
+	DtExpira       *time.Time `json:"dt_expira,omitempty"`
+}
+
 type UploadModelType struct {
 	Db *sql.DB
 }
@@ -30,7 +49,7 @@ func NewUploadModel(db *sql.DB) *UploadModelType {
 }
 
 func (model *UploadModelType) SelectRows() ([]UploadRow, error) {
-	querySql := "SELECT * FROM uploads"
+	querySql := "SELECT id_file, id_ctxt, nm_file_new, nm_file_ori, sn_autos, dt_inc, status FROM uploads"
 	rows, err := model.Db.Query(querySql)
 	if err != nil {
 		log.Printf("Erro ao realizar o SELECT na tabela uploads %v:", err)
@@ -175,3 +194,189 @@ func (model *UploadModelType) SelectRowsByContextoId(idCtxt string) ([]UploadRow
 
 	return results, nil
 }
+
+// InsertRowParcial registra um upload retomável, ainda sem bytes recebidos
+func (model *UploadModelType) InsertRowParcial(idCtxt string, nmFileNew string, nmFileOri string, bytesTotal int64, sha256 string, dtExpira time.Time) (int64, error) {
+	query := `
+		INSERT INTO uploads (id_ctxt, nm_file_new, nm_file_ori, sn_autos, dt_inc, status, bytes_total, bytes_recebidos, sha256, dt_expira)
+		VALUES ($1, $2, $3, 'N', $4, $5, $6, 0, $7, $8) RETURNING id_file;
+	`
+	var id int64
+	err := model.Db.QueryRow(query, idCtxt, nmFileNew, nmFileOri, time.Now(), UPLOAD_STATUS_RECEBENDO, bytesTotal, sha256, dtExpira).Scan(&id)
+	if err != nil {
+		log.Printf("Erro ao inserir o upload retomável na tabela uploads: %v", err)
+		return 0, fmt.Errorf("erro ao inserir o upload retomável na tabela uploads: %w", err)
+	}
+	return id, nil
+}
+
+const uploadsParcialColunas = `id_file, id_ctxt, nm_file_new, nm_file_ori, sn_autos, dt_inc, status,
+	COALESCE(bytes_total, 0), bytes_recebidos, COALESCE(sha256, ''), dt_expira`
+
+func scanUploadParcial(scanner interface{ Scan(...any) error }) (*UploadParcialRow, error) {
+	var row UploadParcialRow
+	var dtExpira sql.NullTime
+	err := scanner.Scan(&row.IdFile, &row.IdCtxt, &row.NmFileNew, &row.NmFileOri, &row.SnAutos, &row.DtInc, &row.Status,
+		&row.BytesTotal, &row.BytesRecebidos, &row.Sha256, &dtExpira)
+	if err != nil {
+		return nil, err
+	}
+	if dtExpira.Valid {
+		row.DtExpira = &dtExpira.Time
+	}
+	return &row, nil
+}
+
+// SelectRowParcial devolve o registro com o andamento do upload; nil se não existir
+func (model *UploadModelType) SelectRowParcial(idFile int) (*UploadParcialRow, error) {
+	query := `SELECT ` + uploadsParcialColunas + ` FROM uploads WHERE id_file = $1`
+	row, err := scanUploadParcial(model.Db.QueryRow(query, idFile))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar o upload retomável id_file=%d: %v", idFile, err)
+		return nil, fmt.Errorf("erro ao buscar o upload retomável: %w", err)
+	}
+	return row, nil
+}
+
+/*
+UpdateRecebidos avança os bytes recebidos e renova a validade. A condição sobre o valor
+anterior impede que dois blocos enviados ao mesmo tempo avancem o mesmo trecho: devolve
+false se o registro já não estava em "de".
+*/
+func (model *UploadModelType) UpdateRecebidos(idFile int, de int64, para int64, dtExpira time.Time) (bool, error) {
+	query := `UPDATE uploads SET bytes_recebidos = $1, dt_expira = $2
+		WHERE id_file = $3 AND status = $4 AND bytes_recebidos = $5`
+	result, err := model.Db.Exec(query, para, dtExpira, idFile, UPLOAD_STATUS_RECEBENDO, de)
+	if err != nil {
+		log.Printf("Erro ao atualizar os bytes recebidos id_file=%d: %v", idFile, err)
+		return false, fmt.Errorf("erro ao atualizar os bytes recebidos: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// ConcluiRow torna o arquivo disponível para a extração, gravado na chave nmFileNew
+func (model *UploadModelType) ConcluiRow(idFile int, nmFileNew string) (bool, error) {
+	query := `UPDATE uploads SET status = $1, nm_file_new = $2, dt_expira = NULL WHERE id_file = $3 AND status = $4`
+	result, err := model.Db.Exec(query, UPLOAD_STATUS_DISPONIVEL, nmFileNew, idFile, UPLOAD_STATUS_RECEBENDO)
+	if err != nil {
+		log.Printf("Erro ao concluir o upload id_file=%d: %v", idFile, err)
+		return false, fmt.Errorf("erro ao concluir o upload: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// SelectRowsExpirados devolve os uploads retomáveis não concluídos cuja validade venceu
+func (model *UploadModelType) SelectRowsExpirados(agora time.Time) ([]UploadParcialRow, error) {
+	query := `SELECT ` + uploadsParcialColunas + ` FROM uploads WHERE status = $1 AND dt_expira < $2`
+	rows, err := model.Db.Query(query, UPLOAD_STATUS_RECEBENDO, agora)
+	if err != nil {
+		log.Printf("Erro ao buscar os uploads expirados: %v", err)
+		return nil, fmt.Errorf("erro ao buscar os uploads expirados: %w", err)
+	}
+	defer rows.Close()
+
+	var results []UploadParcialRow
+	for rows.Next() {
+		row, err := scanUploadParcial(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear os uploads expirados: %w", err)
+		}
+		results = append(results, *row)
+	}
+	return results, rows.Err()
+}
+
+// ExisteArquivo indica se algum registro usa o arquivo (chave do armazenamento)
+func (model *UploadModelType) ExisteArquivo(nmFileNew string) (bool, error) {
+	var existe bool
+	err := model.Db.QueryRow(`SELECT EXISTS (SELECT 1 FROM uploads WHERE nm_file_new = $1)`, nmFileNew).Scan(&existe)
+	if err != nil {
+		log.Printf("Erro ao consultar o arquivo %s na tabela uploads: %v", nmFileNew, err)
+		return false, fmt.Errorf("erro ao consultar o arquivo na tabela uploads: %w", err)
+	}
+	return existe, nil
+}
+
+// SelectRowPendente devolve o upload do contexto, ainda não extraído, gravado na chave nmFileNew; nil se não existir
+func (model *UploadModelType) SelectRowPendente(idCtxt string, nmFileNew string) (*UploadRow, error) {
+	query := `SELECT id_file, id_ctxt, nm_file_new, nm_file_ori, sn_autos, dt_inc, status
+	          FROM uploads
+	          WHERE id_ctxt = $1 AND nm_file_new = $2 AND status = $3
+	          ORDER BY id_file LIMIT 1`
+	var row UploadRow
+	err := model.Db.QueryRow(query, idCtxt, nmFileNew, UPLOAD_STATUS_DISPONIVEL).
+		Scan(&row.IdFile, &row.IdCtxt, &row.NmFileNew, &row.NmFileOri, &row.SnAutos, &row.DtInc, &row.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar o upload pendente %s na tabela uploads: %v", nmFileNew, err)
+		return nil, fmt.Errorf("erro ao buscar o upload pendente: %w", err)
+	}
+	return &row, nil
+}
+
+/*
+UploadImportadoRow registra a extração de um arquivo no contexto: o hash SHA-256 do
+arquivo e quantos documentos eram novos, inalterados ou alterados em relação à
+importação anterior. O registro em "uploads" é apagado após a extração; este fica.
+*/
+type UploadImportadoRow struct {
+	IdImport    int       `json:"id_import"`
+	IdCtxt      string    `json:"id_ctxt"`
+	Sha256      string    `json:"sha256"`
+	NmFileOri   string    `json:"nm_file_ori"`
+	DtInc       time.Time `json:"dt_inc"`
+	Novos       int       `json:"novos"`
+	Inalterados int       `json:"inalterados"`
+	Alterados   int       `json:"alterados"`
+}
+
+func (model *UploadModelType) InsertImportado(row UploadImportadoRow) (int64, error) {
+	query := `
+		INSERT INTO uploads_importados (id_ctxt, sha256, nm_file_ori, dt_inc, novos, inalterados, alterados)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id_import;
+	`
+	var id int64
+	err := model.Db.QueryRow(query, row.IdCtxt, row.Sha256, row.NmFileOri, row.DtInc, row.Novos, row.Inalterados, row.Alterados).Scan(&id)
+	if err != nil {
+		log.Printf("Erro ao inserir o registro na tabela uploads_importados: %v", err)
+		return 0, fmt.Errorf("erro ao inserir o registro na tabela uploads_importados: %w", err)
+	}
+	return id, nil
+}
+
+// ExisteImportado indica se o arquivo (hash) já foi extraído em algum contexto
+func (model *UploadModelType) ExisteImportado(sha256 string) (bool, error) {
+	var existe bool
+	err := model.Db.QueryRow(`SELECT EXISTS (SELECT 1 FROM uploads_importados WHERE sha256 = $1)`, sha256).Scan(&existe)
+	if err != nil {
+		log.Printf("Erro ao consultar o arquivo %s na tabela uploads_importados: %v", sha256, err)
+		return false, fmt.Errorf("erro ao consultar o arquivo na tabela uploads_importados: %w", err)
+	}
+	return existe, nil
+}
+
+// SelectUltimoImportado devolve a última extração do arquivo (hash) no contexto; nil se nunca foi importado
+func (model *UploadModelType) SelectUltimoImportado(idCtxt string, sha256 string) (*UploadImportadoRow, error) {
+	query := `SELECT id_import, id_ctxt, sha256, nm_file_ori, dt_inc, novos, inalterados, alterados
+	          FROM uploads_importados
+	          WHERE id_ctxt = $1 AND sha256 = $2
+	          ORDER BY dt_inc DESC LIMIT 1`
+	var row UploadImportadoRow
+	err := model.Db.QueryRow(query, idCtxt, sha256).
+		Scan(&row.IdImport, &row.IdCtxt, &row.Sha256, &row.NmFileOri, &row.DtInc, &row.Novos, &row.Inalterados, &row.Alterados)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar o arquivo %s na tabela uploads_importados: %v", sha256, err)
+		return nil, fmt.Errorf("erro ao buscar o arquivo na tabela uploads_importados: %w", err)
+	}
+	return &row, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"strings"
 	"time"
@@ -47,6 +48,7 @@ func (idx *AutosIndexType) Indexa(
 	Doc string,
 	DocJsonRaw string,
 	DocEmbedding []float32,
+	procedencia *consts.ProcedenciaAutos,
 	idOptional string,
 ) (*consts.ResponseAutosRow, error) {
 	if idx == nil || idx.osCli == nil {
@@ -62,6 +64,7 @@ func (idx *AutosIndexType) Indexa(
 		Doc:          Doc,
 		DocJsonRaw:   DocJsonRaw,
 		DocEmbedding: DocEmbedding,
+		Procedencia:  procedencia,
 	}
 
 	res, err := idx.osCli.Index(
@@ -93,6 +96,7 @@ func (idx *AutosIndexType) Indexa(
 		Doc:          Doc,
 		DocJsonRaw:   DocJsonRaw,
 		DocEmbedding: DocEmbedding,
+		Procedencia:  procedencia,
 	}
 
 	return row, nil
@@ -184,6 +188,44 @@ func (idx *AutosIndexType) Update(
 	return row, nil
 }
 
+/*
+AtualizaResumos grava, em atualização parcial, o cache dos resumos por bloco do
+documento. O hash identifica o texto (e os parâmetros) que originaram os resumos.
+*/
+func (idx *AutosIndexType) AtualizaResumos(id string, hash string, resumos []string) error {
+	if idx == nil || idx.osCli == nil {
+		return fmt.Errorf("OpenSearch não conectado")
+	}
+
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	body := types.JsonMap{
+		"doc": types.JsonMap{
+			"doc_resumo_hash": hash,
+			"doc_resumos":     resumos,
+		},
+	}
+
+	res, err := idx.osCli.Update(
+		ctx,
+		opensearchapi.UpdateReq{
+			Index:      idx.indexName,
+			DocumentID: id,
+			Body:       opensearchutil.NewJSONReader(&body),
+		})
+	if err != nil {
+		logger.Log.Errorf("Erro ao gravar os resumos do documento %s: %v", id, err)
+		return err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	return nil
+}
+
 // Deletar documento pelo ID no índice autos
 func (idx *AutosIndexType) Delete(id string) error {
 	if idx == nil || idx.osCli == nil {
@@ -232,13 +274,13 @@ func (idx *AutosIndexType) ConsultaById(id string) (*consts.ResponseAutosRow, er
 	ctx, cancel := NewCtx(idx.timeout)
 	defer cancel()
 
-	//Cria a requisição
-	req := opensearchapi.DocumentSourceReq{
+	//Cria a requisição (Document.Get devolve o envelope com "found" e "_source")
+	req := opensearchapi.DocumentGetReq{
 		Index:      idx.indexName,
 		DocumentID: id,
 	}
 	//Executa a requisição, passando a requisição
-	res, err := idx.osCli.Document.Source(
+	res, err := idx.osCli.Document.Get(
 		ctx,
 		req,
 	)
@@ -266,13 +308,16 @@ func (idx *AutosIndexType) ConsultaById(id string) (*consts.ResponseAutosRow, er
 	src := result.Source
 
 	doc := consts.ResponseAutosRow{
-		Id:           result.ID,
-		IdCtxt:       src.IdCtxt,
-		IdNatu:       src.IdNatu,
-		IdPje:        src.IdPje,
-		Doc:          src.Doc,
-		DocJsonRaw:   src.DocJsonRaw,
-		DocEmbedding: src.DocEmbedding,
+		Id:            result.ID,
+		IdCtxt:        src.IdCtxt,
+		IdNatu:        src.IdNatu,
+		IdPje:         src.IdPje,
+		Doc:           src.Doc,
+		DocJsonRaw:    src.DocJsonRaw,
+		DocEmbedding:  src.DocEmbedding,
+		DocResumoHash: src.DocResumoHash,
+		DocResumos:    src.DocResumos,
+		Procedencia:   src.Procedencia,
 	}
 
 	return &doc, nil
@@ -343,13 +388,16 @@ func (idx *AutosIndexType) ConsultaByIdCtxt(idCtxt string) ([]consts.ResponseAut
 		doc := hit.Source
 
 		docAdd := consts.ResponseAutosRow{
-			Id:           hit.ID,
-			IdCtxt:       doc.IdCtxt,
-			IdNatu:       doc.IdNatu,
-			IdPje:        doc.IdPje,
-			Doc:          doc.Doc,
-			DocJsonRaw:   doc.DocJsonRaw,
-			DocEmbedding: doc.DocEmbedding,
+			Id:            hit.ID,
+			IdCtxt:        doc.IdCtxt,
+			IdNatu:        doc.IdNatu,
+			IdPje:         doc.IdPje,
+			Doc:           doc.Doc,
+			DocJsonRaw:    doc.DocJsonRaw,
+			DocEmbedding:  doc.DocEmbedding,
+			DocResumoHash: doc.DocResumoHash,
+			DocResumos:    doc.DocResumos,
+			Procedencia:   doc.Procedencia,
 		}
 
 		docs = append(docs, docAdd)
@@ -607,3 +655,250 @@ func (idx *AutosIndexType) IsExiste(idCtxt string, idPje string) (bool, error) {
 
 	return false, nil
 }
+
+// ConsultaByIdPje devolve o documento do contexto com o id_pje informado; nil se não existir
+func (idx *AutosIndexType) ConsultaByIdPje(idCtxt string, idPje string) (*consts.ResponseAutosRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	if idCtxt == "" || idPje == "" {
+		return nil, fmt.Errorf("parâmetros inválidos: idCtxt=%q, idPje=%q", idCtxt, idPje)
+	}
+
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	query := types.JsonMap{
+		"size": 1,
+		"_source": types.JsonMap{
+			"excludes": []string{"doc_embedding", "doc_resumos"},
+		},
+		"query": types.JsonMap{
+			"bool": types.JsonMap{
+				"filter": []interface{}{
+					types.JsonMap{"term": types.JsonMap{"id_ctxt": idCtxt}},
+					types.JsonMap{"term": types.JsonMap{"id_pje": idPje}},
+				},
+			},
+		},
+	}
+
+	res, err := idx.osCli.Search(
+		ctx,
+		&opensearchapi.SearchReq{
+			Indices: []string{idx.indexName},
+			Body:    opensearchutil.NewJSONReader(query),
+		},
+	)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar o id_pje=%s: %v", idPje, err)
+		return nil, err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return nil, err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	var result SearchResponseGeneric[consts.AutosRow]
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&result); err != nil {
+		logger.Log.Errorf("Erro ao decodificar resposta JSON: %v", err)
+		return nil, err
+	}
+	if len(result.Hits.Hits) == 0 {
+		return nil, nil
+	}
+
+	hit := result.Hits.Hits[0]
+	return &consts.ResponseAutosRow{
+		Id:            hit.ID,
+		IdCtxt:        hit.Source.IdCtxt,
+		IdNatu:        hit.Source.IdNatu,
+		IdPje:         hit.Source.IdPje,
+		Doc:           hit.Source.Doc,
+		DocJsonRaw:    hit.Source.DocJsonRaw,
+		DocResumoHash: hit.Source.DocResumoHash,
+		Procedencia:   hit.Source.Procedencia,
+	}, nil
+}
+
+// Filtro da busca nos autos de um contexto (naturezas vazias: todas)
+type FiltroAutos struct {
+	IdCtxt    string
+	Naturezas []int
+}
+
+// Documento de "autos" encontrado na busca, com os trechos destacados e a procedência
+type ResponseBuscaAutosRow struct {
+	Id          string                   `json:"id"`
+	IdCtxt      string                   `json:"id_ctxt"`
+	IdNatu      int                      `json:"id_natu"`
+	IdPje       string                   `json:"id_pje"`
+	Score       float64                  `json:"score"`
+	Trechos     []string                 `json:"trechos"`
+	Referencia  string                   `json:"referencia,omitempty"`
+	Procedencia *consts.ProcedenciaAutos `json:"procedencia,omitempty"`
+}
+
+// Trechos destacados por documento na busca nos autos (até TRECHOS_POR_DOCUMENTO, de TAMANHO_TRECHO caracteres)
+const (
+	TRECHOS_POR_DOCUMENTO = 3
+	TAMANHO_TRECHO        = 300
+)
+
+// filtrosAutos restringe a busca ao contexto e, se informadas, às naturezas
+func filtrosAutos(filtro FiltroAutos) []types.JsonMap {
+	filters := []types.JsonMap{
+		{"term": types.JsonMap{"id_ctxt": filtro.IdCtxt}},
+	}
+	if len(filtro.Naturezas) > 0 {
+		filters = append(filters, types.JsonMap{
+			"terms": types.JsonMap{"id_natu": filtro.Naturezas},
+		})
+	}
+	return filters
+}
+
+/*
+highlightAutos destaca os termos da busca no texto do documento. Sem termos em comum
+(documento trazido pela busca vetorial), devolve o início do texto (no_match_size).
+*/
+func highlightAutos() types.JsonMap {
+	return types.JsonMap{
+		"fields": types.JsonMap{
+			"doc": types.JsonMap{
+				"fragment_size":       TAMANHO_TRECHO,
+				"number_of_fragments": TRECHOS_POR_DOCUMENTO,
+				"no_match_size":       TAMANHO_TRECHO,
+			},
+		},
+	}
+}
+
+// buscaTrechosAutos executa a consulta e converte os hits, com o _score e os trechos destacados.
+func (idx *AutosIndexType) buscaTrechosAutos(query types.JsonMap) ([]ResponseBuscaAutosRow, error) {
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	res, err := idx.osCli.Search(ctx,
+		&opensearchapi.SearchReq{
+			Indices: []string{idx.indexName},
+			Body:    opensearchutil.NewJSONReader(query),
+		})
+	if err != nil {
+		return nil, err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return nil, err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	var result SearchResponseGeneric[consts.AutosRow]
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&result); err != nil {
+		logger.Log.Errorf("Erro ao decodificar resposta JSON: %v", err)
+		return nil, err
+	}
+
+	docs := make([]ResponseBuscaAutosRow, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		src := hit.Source
+		doc := ResponseBuscaAutosRow{
+			Id:          hit.ID,
+			IdCtxt:      src.IdCtxt,
+			IdNatu:      src.IdNatu,
+			IdPje:       src.IdPje,
+			Trechos:     hit.Highlight["doc"],
+			Referencia:  src.Procedencia.Referencia(src.IdPje),
+			Procedencia: src.Procedencia,
+		}
+		if hit.Score != nil {
+			doc.Score = *hit.Score
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// ConsultaLexicaAutos executa a busca BM25 (analyzer brazilian) no texto dos documentos do contexto.
+func (idx *AutosIndexType) ConsultaLexicaAutos(texto string, filtro FiltroAutos, size int) ([]ResponseBuscaAutosRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return nil, nil
+	}
+
+	query := types.JsonMap{
+		"size":    size,
+		"_source": types.JsonMap{"includes": []string{"id_ctxt", "id_natu", "id_pje", "procedencia"}},
+		"query": types.JsonMap{
+			"bool": types.JsonMap{
+				"must": types.JsonMap{
+					"match": types.JsonMap{"doc": texto},
+				},
+				"filter": filtrosAutos(filtro),
+			},
+		},
+		"highlight": highlightAutos(),
+	}
+	return idx.buscaTrechosAutos(query)
+}
+
+/*
+TrechosAutos traz os documentos informados (ids) do contexto, com os trechos em que o
+texto da busca aparece, para os resultados da busca vetorial. A ordem não é preservada
+e o Score é o da busca lexical.
+*/
+func (idx *AutosIndexType) TrechosAutos(ids []string, texto string, filtro FiltroAutos) ([]ResponseBuscaAutosRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := types.JsonMap{
+		"size":    len(ids),
+		"_source": types.JsonMap{"includes": []string{"id_ctxt", "id_natu", "id_pje", "procedencia"}},
+		"query": types.JsonMap{
+			"bool": types.JsonMap{
+				"filter": append(filtrosAutos(filtro), types.JsonMap{
+					"ids": types.JsonMap{"values": ids},
+				}),
+				"should": []types.JsonMap{
+					{"match": types.JsonMap{"doc": texto}},
+				},
+			},
+		},
+		"highlight": highlightAutos(),
+	}
+	return idx.buscaTrechosAutos(query)
+}
+
+// FusaoRRFAutos funde listas ordenadas pela soma de 1/(k + posição), como FusaoRRF.
+func FusaoRRFAutos(k int, listas ...[]ResponseBuscaAutosRow) []ResponseBuscaAutosRow {
+	scores := map[string]float64{}
+	docs := map[string]ResponseBuscaAutosRow{}
+	ordem := []string{}
+	for _, lista := range listas {
+		for pos, doc := range lista {
+			// Os trechos da busca lexical prevalecem (a vetorial não os traz)
+			if atual, ok := docs[doc.Id]; !ok {
+				docs[doc.Id] = doc
+				ordem = append(ordem, doc.Id)
+			} else if len(atual.Trechos) == 0 && len(doc.Trechos) > 0 {
+				docs[doc.Id] = doc
+			}
+			scores[doc.Id] += 1.0 / float64(k+pos+1)
+		}
+	}
+
+	out := make([]ResponseBuscaAutosRow, 0, len(ordem))
+	for _, id := range ordem {
+		doc := docs[id]
+		doc.Score = scores[id]
+		out = append(out, doc)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
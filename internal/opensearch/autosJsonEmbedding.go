@@ -71,10 +71,11 @@ func (idx *AutosJsonEmbeddingType) Indexa(
 		DocEmbedding: docEmbedding,
 	}
 
+	// Um embedding por documento: o id é o do documento em "autos", e a nova indexação o substitui
 	res, err := idx.osCli.Index(context.Background(),
 		opensearchapi.IndexReq{
 			Index:      idx.indexName,
-			DocumentID: "", // pode ser "" para id automático
+			DocumentID: idDoc,
 			Body:       opensearchutil.NewJSONReader(&doc),
 			Params: opensearchapi.IndexParams{
 				Refresh: "true",
@@ -156,6 +157,10 @@ func (idx *AutosJsonEmbeddingType) Delete(id string) error {
 				Refresh: "true", //"wait_for", ou "true"
 			},
 		})
+	if err != nil && res == nil {
+		logger.Log.Errorf("Erro ao deletar documento: %v", err)
+		return err
+	}
 
 	err = ReadOSErr(res.Inspect().Response)
 	if err != nil {
@@ -198,7 +203,7 @@ func (idx *AutosJsonEmbeddingType) ConsultaById(id string) (*consts.ResponseAuto
 
 	body := res.Inspect().Response.Body
 	var docResp struct {
-		Source consts.AutosRow `json:"_source"`
+		Source consts.AutosJsonEmbeddingRow `json:"_source"`
 	}
 
 	if err := json.NewDecoder(body).Decode(&docResp); err != nil {
@@ -209,7 +214,7 @@ func (idx *AutosJsonEmbeddingType) ConsultaById(id string) (*consts.ResponseAuto
 
 	return &consts.ResponseAutosJsonEmbeddingRow{
 		Id:           id,
-		IdDoc:        docResp.Source.Doc,
+		IdDoc:        docResp.Source.IdDoc,
 		IdCtxt:       docResp.Source.IdCtxt,
 		IdNatu:       docResp.Source.IdNatu,
 		DocEmbedding: docResp.Source.DocEmbedding,
@@ -586,3 +591,70 @@ func (idx *AutosJsonEmbeddingType) IsExiste(idCtxt int, idPje string) (bool, err
 
 	return false, nil
 }
+
+/*
+ConsultaVetorialAutos executa a busca kNN nos embeddings dos documentos do contexto, com
+os filtros aplicados dentro do próprio kNN. O Id devolvido é o do documento em "autos",
+sem os trechos (ver AutosIndexType.TrechosAutos).
+*/
+func (idx *AutosJsonEmbeddingType) ConsultaVetorialAutos(vector []float32, filtro FiltroAutos, size int) ([]ResponseBuscaAutosRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	if len(vector) != ExpectedRagVectorSize {
+		return nil, erros.CreateError(fmt.Sprintf("vetor tem %d dimensões, esperado %d", len(vector), ExpectedRagVectorSize))
+	}
+
+	query := types.JsonMap{
+		"size":    size,
+		"_source": types.JsonMap{"excludes": []string{"doc_embedding"}},
+		"query": types.JsonMap{
+			"knn": types.JsonMap{
+				"doc_embedding": types.JsonMap{
+					"vector": vector,
+					"k":      size,
+					"filter": types.JsonMap{"bool": types.JsonMap{"filter": filtrosAutos(filtro)}},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	res, err := idx.osCli.Search(ctx,
+		&opensearchapi.SearchReq{
+			Indices: []string{idx.indexName},
+			Body:    opensearchutil.NewJSONReader(query),
+		})
+	if err != nil {
+		return nil, err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return nil, err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	var result SearchResponseGeneric[consts.AutosJsonEmbeddingRow]
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&result); err != nil {
+		logger.Log.Errorf("Erro ao decodificar resposta JSON: %v", err)
+		return nil, err
+	}
+
+	docs := make([]ResponseBuscaAutosRow, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		doc := ResponseBuscaAutosRow{
+			Id:     hit.Source.IdDoc,
+			IdCtxt: hit.Source.IdCtxt,
+			IdNatu: hit.Source.IdNatu,
+		}
+		if doc.Id == "" {
+			doc.Id = hit.ID // o _id do embedding é o id do documento em "autos"
+		}
+		if hit.Score != nil {
+			doc.Score = *hit.Score
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
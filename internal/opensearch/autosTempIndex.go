@@ -43,11 +43,14 @@ func NewAutos_tempIndex() *AutosTempIndexType {
 
 // Documento do índice autos
 type BodyAutosTempIndex struct {
-	IdCtxt string    `json:"id_ctxt"`
-	IdNatu int       `json:"id_natu"`
-	IdPje  string    `json:"id_pje"`
-	DtInc  time.Time `json:"dt_inc"` // data/hora da inclusão
-	Doc    string    `json:"doc"`    // texto analisado com analyzer brazilian
+	IdCtxt  string               `json:"id_ctxt"`
+	IdNatu  int                  `json:"id_natu"`
+	IdPje   string               `json:"id_pje"`
+	DtInc   time.Time            `json:"dt_inc"`            // data/hora da inclusão
+	Doc     string               `json:"doc"`               // texto analisado com analyzer brazilian
+	Paginas []consts.PaginaAutos `json:"paginas,omitempty"` // páginas do PDF e origem do texto (camada de texto ou OCR)
+	// arquivo, páginas, índice e assinatura do documento nos autos enviados
+	Procedencia *consts.ProcedenciaAutos `json:"procedencia,omitempty"`
 }
 
 // Estrutura para update parcial (usa o mesmo IndexAutosDoc para atualizar qualquer campo)
@@ -66,6 +69,8 @@ func (idx *AutosTempIndexType) Indexa(
 	IdNatu int,
 	IdPje string,
 	Doc string,
+	paginas []consts.PaginaAutos,
+	procedencia *consts.ProcedenciaAutos,
 	idOptional string,
 ) (*consts.ResponseAutosTempRow, error) {
 	if idx == nil || idx.osCli == nil {
@@ -78,11 +83,13 @@ func (idx *AutosTempIndexType) Indexa(
 	dt_inc := time.Now()
 	// Monta o documento para indexar
 	body := BodyAutosTempIndex{
-		IdCtxt: IdCtxt,
-		IdNatu: IdNatu,
-		IdPje:  IdPje,
-		DtInc:  dt_inc,
-		Doc:    Doc,
+		IdCtxt:      IdCtxt,
+		IdNatu:      IdNatu,
+		IdPje:       IdPje,
+		DtInc:       dt_inc,
+		Doc:         Doc,
+		Paginas:     paginas,
+		Procedencia: procedencia,
 	}
 
 	res, err := idx.osCli.Index(
@@ -107,11 +114,13 @@ func (idx *AutosTempIndexType) Indexa(
 
 	// Monta o objeto AutosRow para retorno
 	row := &consts.ResponseAutosTempRow{
-		Id:     res.ID, // Você não tem esse campo ainda, pode deixar zero ou tratar fora
-		IdCtxt: IdCtxt,
-		IdNatu: IdNatu,
-		IdPje:  IdPje,
-		DtInc:  dt_inc,
+		Id:          res.ID, // Você não tem esse campo ainda, pode deixar zero ou tratar fora
+		IdCtxt:      IdCtxt,
+		IdNatu:      IdNatu,
+		IdPje:       IdPje,
+		DtInc:       dt_inc,
+		Paginas:     paginas,
+		Procedencia: procedencia,
 	}
 
 	return row, nil
@@ -293,12 +302,14 @@ func (idx *AutosTempIndexType) ConsultaById(id string) (*consts.ResponseAutosTem
 	src := result.Source
 
 	return &consts.ResponseAutosTempRow{
-		Id:     id,
-		IdCtxt: src.IdCtxt,
-		IdNatu: src.IdNatu,
-		IdPje:  src.IdPje,
-		DtInc:  src.DtInc,
-		Doc:    src.Doc,
+		Id:          id,
+		IdCtxt:      src.IdCtxt,
+		IdNatu:      src.IdNatu,
+		IdPje:       src.IdPje,
+		DtInc:       src.DtInc,
+		Doc:         src.Doc,
+		Paginas:     src.Paginas,
+		Procedencia: src.Procedencia,
 	}, nil
 }
 
@@ -364,11 +375,13 @@ func (idx *AutosTempIndexType) ConsultaByIdCtxt(idCtxt string) ([]consts.Respons
 		doc := hit.Source
 
 		docAdd := consts.ResponseAutosTempRow{
-			Id:     hit.ID,
-			IdCtxt: doc.IdCtxt,
-			IdNatu: doc.IdNatu,
-			IdPje:  doc.IdPje,
-			Doc:    doc.Doc,
+			Id:          hit.ID,
+			IdCtxt:      doc.IdCtxt,
+			IdNatu:      doc.IdNatu,
+			IdPje:       doc.IdPje,
+			Doc:         doc.Doc,
+			Paginas:     doc.Paginas,
+			Procedencia: doc.Procedencia,
 		}
 
 		docs = append(docs, docAdd)
@@ -442,12 +455,14 @@ func (idx *AutosTempIndexType) ConsultaByIdNatu(idNatu int) ([]consts.ResponseAu
 	for _, hit := range result.Hits.Hits {
 		doc := hit.Source
 		docAdd := consts.ResponseAutosTempRow{
-			Id:     hit.ID,
-			IdCtxt: doc.IdCtxt,
-			IdNatu: doc.IdNatu,
-			IdPje:  doc.IdPje,
-			DtInc:  doc.DtInc,
-			Doc:    doc.Doc,
+			Id:          hit.ID,
+			IdCtxt:      doc.IdCtxt,
+			IdNatu:      doc.IdNatu,
+			IdPje:       doc.IdPje,
+			DtInc:       doc.DtInc,
+			Doc:         doc.Doc,
+			Paginas:     doc.Paginas,
+			Procedencia: doc.Procedencia,
 		}
 		docs = append(docs, docAdd)
 	}
@@ -544,12 +559,14 @@ func (idx *AutosTempIndexType) ConsultaSemantica(vector []float32, idNatuFilter
 			break
 		}
 		docAdd := consts.ResponseAutosTempRow{
-			Id:     hit.ID,
-			IdCtxt: doc.IdCtxt,
-			IdNatu: doc.IdNatu,
-			IdPje:  doc.IdPje,
-			DtInc:  doc.DtInc,
-			Doc:    doc.Doc,
+			Id:          hit.ID,
+			IdCtxt:      doc.IdCtxt,
+			IdNatu:      doc.IdNatu,
+			IdPje:       doc.IdPje,
+			DtInc:       doc.DtInc,
+			Doc:         doc.Doc,
+			Paginas:     doc.Paginas,
+			Procedencia: doc.Procedencia,
 		}
 		docs = append(docs, docAdd)
 	}
@@ -757,3 +774,113 @@ func (idx *AutosTempIndexType) DeleteOlderThan(ctx context.Context, olderThan ti
 
 	return resp.Deleted, nil
 }
+
+// ConsultaByIdPje devolve o documento do contexto com o id_pje informado; nil se não existir
+func (idx *AutosTempIndexType) ConsultaByIdPje(idCtxt string, idPje string) (*consts.ResponseAutosTempRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	if idCtxt == "" || idPje == "" {
+		return nil, fmt.Errorf("parâmetros inválidos: idCtxt=%q, idPje=%q", idCtxt, idPje)
+	}
+
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	query := types.JsonMap{
+		"size": 1,
+		"sort": []interface{}{
+			types.JsonMap{"dt_inc": types.JsonMap{"order": "desc"}},
+		},
+		"query": types.JsonMap{
+			"bool": types.JsonMap{
+				"filter": []interface{}{
+					types.JsonMap{"term": types.JsonMap{"id_ctxt": idCtxt}},
+					types.JsonMap{"term": types.JsonMap{"id_pje": idPje}},
+				},
+			},
+		},
+	}
+
+	res, err := idx.osCli.Search(
+		ctx,
+		&opensearchapi.SearchReq{
+			Indices: []string{idx.indexName},
+			Body:    opensearchutil.NewJSONReader(query),
+		},
+	)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar o id_pje=%s: %v", idPje, err)
+		return nil, err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return nil, err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	var result SearchResponseGeneric[consts.AutosTempRow]
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&result); err != nil {
+		logger.Log.Errorf("Erro ao decodificar resposta JSON: %v", err)
+		return nil, err
+	}
+	if len(result.Hits.Hits) == 0 {
+		return nil, nil
+	}
+
+	hit := result.Hits.Hits[0]
+	return &consts.ResponseAutosTempRow{
+		Id:          hit.ID,
+		IdCtxt:      hit.Source.IdCtxt,
+		IdNatu:      hit.Source.IdNatu,
+		IdPje:       hit.Source.IdPje,
+		DtInc:       hit.Source.DtInc,
+		Doc:         hit.Source.Doc,
+		Paginas:     hit.Source.Paginas,
+		Procedencia: hit.Source.Procedencia,
+	}, nil
+}
+
+/*
+AtualizaTexto substitui, em atualização parcial, o texto, a natureza, as páginas e a
+procedência do documento (nova versão do mesmo documento numa reimportação). A data de inclusão passa
+a ser a da atualização.
+*/
+func (idx *AutosTempIndexType) AtualizaTexto(id string, idNatu int, doc string, paginas []consts.PaginaAutos, procedencia *consts.ProcedenciaAutos) error {
+	if idx == nil || idx.osCli == nil {
+		return fmt.Errorf("OpenSearch não conectado")
+	}
+
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	body := types.JsonMap{
+		"doc": types.JsonMap{
+			"id_natu":     idNatu,
+			"doc":         doc,
+			"paginas":     paginas,
+			"procedencia": procedencia,
+			"dt_inc":      time.Now(),
+		},
+	}
+
+	res, err := idx.osCli.Update(
+		ctx,
+		opensearchapi.UpdateReq{
+			Index:      idx.indexName,
+			DocumentID: id,
+			Body:       opensearchutil.NewJSONReader(body),
+			Params: opensearchapi.UpdateParams{
+				Refresh: "true",
+			},
+		})
+	if err != nil {
+		logger.Log.Errorf("Erro ao atualizar o texto do documento %s: %v", id, err)
+		return err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	return nil
+}
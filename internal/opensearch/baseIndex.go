@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"ocrserver/internal/config"
@@ -94,6 +95,14 @@ type ResponseBaseRow struct {
 
 	//TextoEmbedding []float32 `json:"texto_embedding,omitempty"` // knn_vector dimension=3072
 
+	Score float64 `json:"score,omitempty"` // pontuação da busca (fusão ou reordenação)
+}
+
+// FiltroBase restringe as buscas na base de conhecimentos. Campos vazios não filtram.
+type FiltroBase struct {
+	Natureza string
+	Classe   string
+	Assunto  string
 }
 
 // Indexar documento
@@ -559,3 +568,202 @@ func (idx *BaseIndexType) IsExiste(idCtxt string, idPje string, hashTexto string
 
 	return existe, nil
 }
+
+/*
+filtrosBase monta os filtros da busca. Classe e assunto são comparados pelo texto
+analisado (ao menos metade dos termos em comum), pois a base é alimentada manualmente
+e raramente repete a nomenclatura exata das tabelas do CNJ. Registros sem classe ou
+sem assunto são considerados genéricos e continuam elegíveis.
+*/
+func filtrosBase(filtro FiltroBase) []types.JsonMap {
+	filters := []types.JsonMap{}
+	if filtro.Natureza != "" {
+		filters = append(filters, types.JsonMap{
+			"term": types.JsonMap{"natureza": filtro.Natureza},
+		})
+	}
+	for campo, valor := range map[string]string{"classe": filtro.Classe, "assunto": filtro.Assunto} {
+		valor = strings.TrimSpace(valor)
+		if valor == "" {
+			continue
+		}
+		filters = append(filters, types.JsonMap{
+			"bool": types.JsonMap{
+				"should": []types.JsonMap{
+					{"match": types.JsonMap{campo: types.JsonMap{"query": valor, "minimum_should_match": "50%"}}},
+					{"bool": types.JsonMap{"must_not": types.JsonMap{"exists": types.JsonMap{"field": campo}}}},
+				},
+				"minimum_should_match": 1,
+			},
+		})
+	}
+	return filters
+}
+
+// buscaBase executa a consulta e converte os hits, preservando o _score.
+func (idx *BaseIndexType) buscaBase(query types.JsonMap) ([]ResponseBaseRow, error) {
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	res, err := idx.osCli.Search(ctx,
+		&opensearchapi.SearchReq{
+			Indices: []string{idx.indexName},
+			Body:    opensearchutil.NewJSONReader(query),
+		})
+	if err != nil {
+		return nil, err
+	}
+	if err := ReadOSErr(res.Inspect().Response); err != nil {
+		return nil, err
+	}
+	defer res.Inspect().Response.Body.Close()
+
+	var result SearchResponseGeneric[BaseRow]
+	if err := json.NewDecoder(res.Inspect().Response.Body).Decode(&result); err != nil {
+		logger.Log.Errorf("Erro ao decodificar resposta JSON: %v", err)
+		return nil, err
+	}
+
+	docs := make([]ResponseBaseRow, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		src := hit.Source
+		doc := ResponseBaseRow{
+			Id:          hit.ID,
+			IdCtxt:      src.IdCtxt,
+			IdPje:       src.IdPje,
+			HashTexto:   src.HashTexto,
+			UsernameInc: src.UsernameInc,
+			DtInc:       src.DtInc,
+			Status:      src.Status,
+
+			Classe:   src.Classe,
+			Assunto:  src.Assunto,
+			Natureza: src.Natureza,
+			Tipo:     src.Tipo,
+			Tema:     src.Tema,
+			Fonte:    src.Fonte,
+			Texto:    src.Texto,
+		}
+		if hit.Score != nil {
+			doc.Score = *hit.Score
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// ConsultaVetorial executa a busca kNN com os filtros aplicados dentro do próprio kNN.
+func (idx *BaseIndexType) ConsultaVetorial(vector []float32, filtro FiltroBase, size int) ([]ResponseBaseRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	if len(vector) != ExpectedRagVectorSize {
+		return nil, erros.CreateError(fmt.Sprintf("vetor tem %d dimensões, esperado %d", len(vector), ExpectedRagVectorSize))
+	}
+
+	knn := types.JsonMap{
+		"vector": vector,
+		"k":      size,
+	}
+	if filters := filtrosBase(filtro); len(filters) > 0 {
+		knn["filter"] = types.JsonMap{"bool": types.JsonMap{"filter": filters}}
+	}
+
+	query := types.JsonMap{
+		"size":    size,
+		"_source": types.JsonMap{"excludes": []string{"texto_embedding"}},
+		"query": types.JsonMap{
+			"knn": types.JsonMap{"texto_embedding": knn},
+		},
+	}
+	return idx.buscaBase(query)
+}
+
+// ConsultaLexica executa a busca BM25 (analyzer brazilian) sobre tema, texto, assunto e classe.
+func (idx *BaseIndexType) ConsultaLexica(texto string, filtro FiltroBase, size int) ([]ResponseBaseRow, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return nil, nil
+	}
+
+	query := types.JsonMap{
+		"size":    size,
+		"_source": types.JsonMap{"excludes": []string{"texto_embedding"}},
+		"query": types.JsonMap{
+			"bool": types.JsonMap{
+				"must": types.JsonMap{
+					"multi_match": types.JsonMap{
+						"query":  texto,
+						"fields": []string{"tema^3", "texto", "assunto^2", "classe"},
+					},
+				},
+				"filter": filtrosBase(filtro),
+			},
+		},
+	}
+	return idx.buscaBase(query)
+}
+
+/*
+ConsultaHibrida combina a busca lexical (BM25) e a vetorial (kNN) pela fusão de posições
+(reciprocal rank fusion): cada documento soma 1/(rrfK + posição) em cada lista em que
+aparece. O Score devolvido é o da fusão, e a ordem é decrescente.
+*/
+func (idx *BaseIndexType) ConsultaHibrida(texto string, vector []float32, filtro FiltroBase, candidatos int, rrfK int) ([]ResponseBaseRow, error) {
+	var (
+		wg                     sync.WaitGroup
+		lexica, vetorial       []ResponseBaseRow
+		errLexica, errVetorial error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		lexica, errLexica = idx.ConsultaLexica(texto, filtro, candidatos)
+	}()
+	go func() {
+		defer wg.Done()
+		vetorial, errVetorial = idx.ConsultaVetorial(vector, filtro, candidatos)
+	}()
+	wg.Wait()
+
+	// Uma das buscas pode falhar sem impedir o uso da outra
+	if errLexica != nil && errVetorial != nil {
+		return nil, fmt.Errorf("busca lexical: %v; busca vetorial: %w", errLexica, errVetorial)
+	}
+	if errLexica != nil {
+		logger.Log.Warningf("Busca lexical falhou, usando somente a vetorial: %v", errLexica)
+	}
+	if errVetorial != nil {
+		logger.Log.Warningf("Busca vetorial falhou, usando somente a lexical: %v", errVetorial)
+	}
+
+	return FusaoRRF(rrfK, lexica, vetorial), nil
+}
+
+// FusaoRRF funde listas ordenadas pela soma de 1/(k + posição), posição iniciada em 1.
+func FusaoRRF(k int, listas ...[]ResponseBaseRow) []ResponseBaseRow {
+	scores := map[string]float64{}
+	docs := map[string]ResponseBaseRow{}
+	ordem := []string{}
+	for _, lista := range listas {
+		for pos, doc := range lista {
+			if _, ok := docs[doc.Id]; !ok {
+				docs[doc.Id] = doc
+				ordem = append(ordem, doc.Id)
+			}
+			scores[doc.Id] += 1.0 / float64(k+pos+1)
+		}
+	}
+
+	out := make([]ResponseBaseRow, 0, len(ordem))
+	for _, id := range ordem {
+		doc := docs[id]
+		doc.Score = scores[id]
+		out = append(out, doc)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
@@ -25,6 +25,8 @@ type SearchResponseGeneric[T any] struct {
 			Score  *float64 `json:"_score,omitempty"`
 			Source T        `json:"_source"`
 			Sort   []any    `json:"sort,omitempty"`
+			// Trechos destacados por campo (consultas com "highlight")
+			Highlight map[string][]string `json:"highlight,omitempty"`
 		} `json:"hits"`
 	} `json:"hits"`
 }
@@ -454,3 +454,72 @@ func (idx *ModelosIndexType) ConsultaSemantica(vector []float32, natureza string
 
 	return out, nil
 }
+
+/*
+ConsultaCitacao localiza modelos (acórdãos, súmulas, doutrina) que contenham alguma das
+variantes informadas (ex.: "1.234.567" e "1234567") como frase na ementa ou no inteiro
+teor. Usada para conferir as citações das minutas.
+*/
+func (idx *ModelosIndexType) ConsultaCitacao(variantes []string, size int) ([]ResponseModelos, error) {
+	if idx == nil || idx.osCli == nil {
+		return nil, fmt.Errorf("OpenSearch não conectado")
+	}
+
+	should := []map[string]any{}
+	for _, v := range variantes {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		// slop tolera "Súmula nº 385" para a variante "Súmula 385"
+		frase := map[string]any{"query": v, "slop": 2}
+		should = append(should,
+			map[string]any{"match_phrase": map[string]any{"ementa": frase}},
+			map[string]any{"match_phrase": map[string]any{"inteiro_teor": frase}},
+		)
+	}
+	if len(should) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := NewCtx(idx.timeout)
+	defer cancel()
+
+	queryBody := map[string]any{
+		"size": size,
+		"_source": map[string]any{
+			"excludes": []string{"ementa_embedding", "inteiro_teor_embedding"},
+		},
+		"query": map[string]any{
+			"bool": map[string]any{"should": should, "minimum_should_match": 1},
+		},
+	}
+
+	res, err := idx.osCli.Search(ctx, &opensearchapi.SearchReq{
+		Indices: []string{idx.indexName},
+		Body:    opensearchutil.NewJSONReader(queryBody),
+	})
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar o OpenSearch: %v", err)
+		return nil, err
+	}
+	httpRes := res.Inspect().Response
+	defer httpRes.Body.Close()
+
+	var result SearchResponseGeneric[ModelosText]
+	if err := DecodeJSONHTTP(httpRes, &result); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	docs := make([]ResponseModelos, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		docs = append(docs, ResponseModelos{
+			Id:           hit.ID,
+			Natureza:     hit.Source.Natureza,
+			Ementa:       hit.Source.Ementa,
+			Inteiro_teor: hit.Source.Inteiro_teor,
+		})
+	}
+	return docs, nil
+}
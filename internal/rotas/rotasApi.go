@@ -12,6 +12,7 @@ import (
 	"ocrserver/internal/models"
 	"ocrserver/internal/opensearch"
 	"ocrserver/internal/services"
+	"ocrserver/internal/services/rag/pipeline"
 )
 
 // SetRotasSistema registra todas as rotas e injeta dependências
@@ -25,6 +26,9 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 	sessionsModel := models.NewSessionsModel(db.Pool)
 	//contextoModel := models.NewContextoModel(db.Pool)
 	uploadModel := models.NewUploadModel(db.Pool)
+	jobsModel := models.NewJobsModel(db.Pool)
+	naturezaModel := models.NewNaturezaModel(db.Pool)
+	quarentenaModel := models.NewQuarentenaModel(db.Pool)
 
 	// --- OpenSearch Indexes ---
 	indexModelos := opensearch.NewIndexModelos()
@@ -77,10 +81,16 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 	//services.InitContextoService(contextoModel)
 	services.InitContextoService(contextoIndex)
 	services.InitUploadService(uploadModel)
+	services.InitNaturezaService(naturezaModel)
+	services.InitQuarentenaService(quarentenaModel)
 	services.InitAutosJsonService(autosJSONEmbedding)
 	opensearch.InitModelosService()
 	opensearch.InitBaseIndex()
 	services.InitBaseService(baseIndex)
+	services.InitJobsService(jobsModel, cfg)
+	pipeline.RegistrarJobs(services.JobsServiceGlobal)
+	jobsHandlers := handlers.NewJobsHandlers(services.JobsServiceGlobal)
+	embeddingHandlers := handlers.NewEmbeddingHandlers(services.AutosJsonServiceGlobal)
 
 	// --- ROTAS PÚBLICAS ---
 	router.GET("/sys/version", handlers.VersionHandler)
@@ -159,6 +169,11 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 	uploadGroup := router.Group("/contexto/documentos/upload", jwt.AuthMiddleware())
 	{
 		uploadGroup.POST("", uploadHandlers.UploadFileHandler)
+		uploadGroup.POST("/lote", uploadHandlers.UploadLoteHandler)
+		uploadGroup.POST("/retomavel", uploadHandlers.IniciaRetomavelHandler)
+		uploadGroup.GET("/retomavel/:id", uploadHandlers.SituacaoRetomavelHandler)
+		uploadGroup.PATCH("/retomavel/:id", uploadHandlers.AnexaBlocoHandler)
+		uploadGroup.POST("/retomavel/:id/concluir", uploadHandlers.ConcluiRetomavelHandler)
 		uploadGroup.GET("/:id", uploadHandlers.SelectHandler)
 		uploadGroup.DELETE("/:id", uploadHandlers.DeleteHandlerById)
 	}
@@ -169,6 +184,10 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 	{
 		documentosGroup.POST("", autosTempHandlers.PDFHandler)
 		documentosGroup.GET("/all/:id", autosTempHandlers.SelectAllHandler)
+		documentosGroup.GET("/perfis", autosTempHandlers.PerfisHandler)
+		documentosGroup.GET("/formatos", autosTempHandlers.FormatosHandler)
+		documentosGroup.GET("/classificacoes/:id", autosTempHandlers.ClassificacoesHandler)
+		documentosGroup.GET("/quarentena/:id", autosTempHandlers.QuarentenaHandler)
 		documentosGroup.DELETE("/:id", autosTempHandlers.DeleteHandler)
 		documentosGroup.POST("/autua", autosTempHandlers.AutuarDocumentosHandler)
 	}
@@ -177,8 +196,11 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 	autosGroup := router.Group("/contexto/autos", jwt.AuthMiddleware())
 	{
 		autosGroup.POST("", autosHandlers.InsertHandler)
+		autosGroup.PUT("", autosHandlers.UpdateHandler)
+		autosGroup.POST("/search", autosHandlers.SearchHandler)
 		autosGroup.GET("/all/:id", autosHandlers.SelectAllHandler)
 		autosGroup.GET("/:id", autosHandlers.SelectByIdHandler)
+		autosGroup.GET("/:id/original", autosHandlers.OriginalHandler)
 		autosGroup.DELETE("/:id", autosHandlers.DeleteHandler)
 	}
 
@@ -188,6 +210,7 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 		eventosGroup.POST("", eventosHandlers.InsertHandler)
 		eventosGroup.GET("/all/:id", eventosHandlers.SelectAllHandler)
 		eventosGroup.GET("/:id", eventosHandlers.SelectByIdHandler)
+		eventosGroup.GET("/:id/export", eventosHandlers.ExportHandler)
 		eventosGroup.DELETE("/:id", eventosHandlers.DeleteHandler)
 	}
 
@@ -195,6 +218,21 @@ func SetRotasSistema(router *gin.Engine, cfg *config.Config, db *pgdb.DBPool) {
 	contextoQueryGroup := router.Group("/contexto/query", jwt.AuthMiddleware())
 	{
 		contextoQueryGroup.POST("/analise", contextoQueryHandlers.QueryHandlerPipeline)
+		contextoQueryGroup.POST("/analise/stream", contextoQueryHandlers.QueryHandlerPipelineStream)
+	}
+
+	// Jobs assíncronos: extração, autuação e análise submetidas com "?async=true"
+	jobsGroup := router.Group("/jobs", jwt.AuthMiddleware())
+	{
+		jobsGroup.GET("", jobsHandlers.SelectAllHandler)
+		jobsGroup.GET("/:id", jobsHandlers.SelectByIdHandler)
+		jobsGroup.POST("/:id/cancel", jobsHandlers.CancelHandler)
+	}
+
+	// Administração: reindexação dos embeddings dos autos (POST /admin/embeddings/autos)
+	adminGroup := router.Group("/admin", jwt.AuthMiddleware(), jwt.AuthorizeMiddleware("admin"))
+	{
+		adminGroup.POST("/embeddings/autos", embeddingHandlers.BackfillAutosHandler)
 	}
 
 	// Chat - bate-papo
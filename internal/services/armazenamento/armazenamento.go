@@ -0,0 +1,256 @@
+/*
+---------------------------------------------------------------------------------------
+File: armazenamento.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Armazenamento dos arquivos enviados no upload (BlobStore). Antes gravados
+no diretório relativo "uploads" de cada instância, os arquivos passam a ser objetos
+identificados por uma chave, num diretório local (BLOB_BACKEND=local) ou num bucket
+S3/MinIO (BLOB_BACKEND=s3), compartilhado entre as réplicas e preservado nos
+reinícios do contêiner. As chaves são endereçadas pelo conteúdo (sha256/ab/<hash>.pdf):
+o mesmo arquivo enviado duas vezes ocupa um só objeto. A extração trabalha sobre uma
+cópia local temporária (CopiaLocal), dispensada no backend local.
+---------------------------------------------------------------------------------------
+*/
+package armazenamento
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/utils/logger"
+)
+
+// Backends disponíveis (BLOB_BACKEND)
+const (
+	BACKEND_LOCAL = "local"
+	BACKEND_S3    = "s3"
+)
+
+// PREFIXO_CONTEUDO é o prefixo das chaves endereçadas pelo conteúdo
+const PREFIXO_CONTEUDO = "sha256/"
+
+var ErrNaoEncontrado = errors.New("objeto não encontrado")
+
+// Objeto descreve um arquivo armazenado
+type Objeto struct {
+	Chave         string
+	Tamanho       int64
+	DtModificacao time.Time
+}
+
+// BlobStore grava, lê e apaga os arquivos enviados, identificados por chave ("a/b/c.pdf")
+type BlobStore interface {
+	Backend() string
+	// Grava o conteúdo na chave; tamanho < 0 indica tamanho desconhecido
+	Grava(ctx context.Context, chave string, r io.Reader, tamanho int64) error
+	// Le devolve o conteúdo da chave ou ErrNaoEncontrado
+	Le(ctx context.Context, chave string) (io.ReadCloser, error)
+	// Apaga remove a chave; chave inexistente não é erro
+	Apaga(ctx context.Context, chave string) error
+	Existe(ctx context.Context, chave string) (bool, error)
+	// Lista devolve os objetos cujas chaves começam pelo prefixo
+	Lista(ctx context.Context, prefixo string) ([]Objeto, error)
+}
+
+// Local é implementado pelos backends com os objetos em disco, lidos sem cópia
+type Local interface {
+	CaminhoLocal(chave string) string
+}
+
+var (
+	globalStore BlobStore
+	onceInit    sync.Once
+)
+
+/*
+InitArmazenamento cria o BlobStore configurado em BLOB_BACKEND. A configuração inválida
+ou o bucket inacessível impedem a subida do servidor: gravar os uploads em outro lugar
+(ex.: no disco de uma só réplica) os tornaria invisíveis às demais.
+*/
+func InitArmazenamento(cfg *config.Config) error {
+	var errOut error
+	onceInit.Do(func() {
+		store, err := Novo(cfg)
+		if err != nil {
+			errOut = fmt.Errorf("armazenamento %q: %w", cfg.BlobBackend, err)
+			return
+		}
+		if _, ok := store.(Local); !ok {
+			// smoke test: o HEAD de uma chave inexistente confere endpoint, bucket e credenciais
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if _, err := store.Existe(ctx, ".verificacao"); err != nil {
+				errOut = fmt.Errorf("armazenamento %s inacessível: %w", store.Backend(), err)
+				return
+			}
+		}
+		globalStore = store
+		logger.Log.Infof("Armazenamento dos uploads: %s", store.Backend())
+	})
+	return errOut
+}
+
+// Global devolve o BlobStore da aplicação; InitArmazenamento deve ter sido chamado antes
+func Global() BlobStore {
+	if globalStore == nil {
+		panic("armazenamento não iniciado: chame InitArmazenamento na subida do servidor")
+	}
+	return globalStore
+}
+
+// Novo cria o BlobStore a partir da configuração
+func Novo(cfg *config.Config) (BlobStore, error) {
+	switch strings.ToLower(cfg.BlobBackend) {
+	case "", BACKEND_LOCAL:
+		return NovoLocal(cfg.BlobLocalDir), nil
+	case BACKEND_S3:
+		return NovoS3(S3Config{
+			Endpoint:  cfg.BlobS3Endpoint,
+			Bucket:    cfg.BlobS3Bucket,
+			Regiao:    cfg.BlobS3Regiao,
+			AccessKey: cfg.BlobS3AccessKey,
+			SecretKey: cfg.BlobS3SecretKey,
+			Prefixo:   cfg.BlobS3Prefixo,
+			PathStyle: cfg.BlobS3PathStyle,
+		})
+	}
+	return nil, fmt.Errorf("backend de armazenamento desconhecido: %q", cfg.BlobBackend)
+}
+
+// ChaveConteudo monta a chave endereçada pelo conteúdo: sha256/<2 primeiros dígitos>/<hash><ext>
+func ChaveConteudo(hash string, ext string) string {
+	hash = strings.ToLower(hash)
+	return PREFIXO_CONTEUDO + hash[:2] + "/" + hash + strings.ToLower(ext)
+}
+
+// EhChaveConteudo indica se a chave foi gerada por ChaveConteudo
+func EhChaveConteudo(chave string) bool {
+	return strings.HasPrefix(chave, PREFIXO_CONTEUDO)
+}
+
+// HashDaChave devolve o hash SHA-256 contido na chave endereçada pelo conteúdo ("" nas demais)
+func HashDaChave(chave string) string {
+	if !EhChaveConteudo(chave) {
+		return ""
+	}
+	nome := path.Base(chave)
+	hash := strings.TrimSuffix(nome, path.Ext(nome))
+	if len(hash) != sha256.Size*2 {
+		return ""
+	}
+	return hash
+}
+
+// HashArquivo calcula o hash SHA-256 do arquivo local, em hexadecimal
+func HashArquivo(caminho string) (string, error) {
+	f, err := os.Open(caminho)
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir o arquivo: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("erro ao calcular o hash do arquivo: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+/*
+GravaArquivo grava o arquivo local na chave endereçada pelo conteúdo e devolve a chave
+e o hash. Se o objeto já existe (mesmo conteúdo), nada é enviado.
+*/
+func GravaArquivo(ctx context.Context, store BlobStore, caminho string, ext string) (chave string, hash string, err error) {
+	f, err := os.Open(caminho)
+	if err != nil {
+		return "", "", fmt.Errorf("erro ao abrir o arquivo: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	tamanho, err := io.Copy(h, f)
+	if err != nil {
+		return "", "", fmt.Errorf("erro ao calcular o hash do arquivo: %w", err)
+	}
+	hash = hex.EncodeToString(h.Sum(nil))
+	chave = ChaveConteudo(hash, ext)
+
+	existe, err := store.Existe(ctx, chave)
+	if err != nil {
+		return "", "", err
+	}
+	if existe {
+		return chave, hash, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", "", fmt.Errorf("erro ao ler o arquivo: %w", err)
+	}
+	if err := store.Grava(ctx, chave, f, tamanho); err != nil {
+		return "", "", err
+	}
+	return chave, hash, nil
+}
+
+/*
+CopiaLocal devolve um caminho em disco com o conteúdo da chave, para as leituras que
+exigem arquivo (extração do PDF, OCR, ZIP). No backend local é o próprio arquivo; nos
+demais, uma cópia temporária apagada por limpa().
+*/
+func CopiaLocal(ctx context.Context, store BlobStore, chave string) (caminho string, limpa func(), err error) {
+	if l, ok := store.(Local); ok {
+		caminho = l.CaminhoLocal(chave)
+		if _, err := os.Stat(caminho); err != nil {
+			if os.IsNotExist(err) {
+				return "", nil, ErrNaoEncontrado
+			}
+			return "", nil, err
+		}
+		return caminho, func() {}, nil
+	}
+
+	r, err := store.Le(ctx, chave)
+	if err != nil {
+		return "", nil, err
+	}
+	defer r.Close()
+
+	tmp, err := os.CreateTemp("", "blob-*"+path.Ext(chave))
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao criar o arquivo temporário: %w", err)
+	}
+	limpa = func() { os.Remove(tmp.Name()) }
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		limpa()
+		return "", nil, fmt.Errorf("erro ao copiar %s: %w", chave, err)
+	}
+	if err := tmp.Close(); err != nil {
+		limpa()
+		return "", nil, err
+	}
+	return tmp.Name(), limpa, nil
+}
+
+// chaveValida recusa chaves vazias, absolutas ou com ".."
+func chaveValida(chave string) error {
+	if chave == "" || strings.HasPrefix(chave, "/") || strings.Contains(chave, "\\") {
+		return fmt.Errorf("chave inválida: %q", chave)
+	}
+	for _, parte := range strings.Split(chave, "/") {
+		if parte == ".." || parte == "." {
+			return fmt.Errorf("chave inválida: %q", chave)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+---------------------------------------------------------------------------------------
+File: local.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: BlobStore em diretório local (BLOB_LOCAL_DIR, padrão "uploads"). A chave é
+o caminho relativo ao diretório, de modo que os arquivos gravados antes do BlobStore
+("uploads/<nome>") continuam acessíveis pela chave "<nome>". A gravação usa um arquivo
+temporário renomeado ao final: a chave nunca aponta para um arquivo pela metade.
+---------------------------------------------------------------------------------------
+*/
+package armazenamento
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type LocalStore struct {
+	raiz string
+}
+
+func NovoLocal(raiz string) *LocalStore {
+	if raiz == "" {
+		raiz = "uploads"
+	}
+	return &LocalStore{raiz: raiz}
+}
+
+func (s *LocalStore) Backend() string {
+	return BACKEND_LOCAL + " (" + s.raiz + ")"
+}
+
+func (s *LocalStore) CaminhoLocal(chave string) string {
+	return filepath.Join(s.raiz, filepath.FromSlash(chave))
+}
+
+func (s *LocalStore) Grava(ctx context.Context, chave string, r io.Reader, tamanho int64) error {
+	if err := chaveValida(chave); err != nil {
+		return err
+	}
+	destino := s.CaminhoLocal(chave)
+	if err := os.MkdirAll(filepath.Dir(destino), os.ModePerm); err != nil {
+		return fmt.Errorf("erro ao criar o diretório de %s: %w", chave, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(destino), ".grava-*")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", chave, err)
+	}
+	_, err = io.Copy(tmp, leitorCtx{ctx: ctx, r: r})
+	if errC := tmp.Close(); err == nil {
+		err = errC
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), destino)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar %s: %w", chave, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Le(ctx context.Context, chave string) (io.ReadCloser, error) {
+	if err := chaveValida(chave); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.CaminhoLocal(chave))
+	if os.IsNotExist(err) {
+		return nil, ErrNaoEncontrado
+	}
+	return f, err
+}
+
+func (s *LocalStore) Apaga(ctx context.Context, chave string) error {
+	if err := chaveValida(chave); err != nil {
+		return err
+	}
+	err := os.Remove(s.CaminhoLocal(chave))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao apagar %s: %w", chave, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Existe(ctx context.Context, chave string) (bool, error) {
+	if err := chaveValida(chave); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(s.CaminhoLocal(chave))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStore) Lista(ctx context.Context, prefixo string) ([]Objeto, error) {
+	var objetos []Objeto
+	err := filepath.WalkDir(s.raiz, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == s.raiz {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".grava-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.raiz, p)
+		if err != nil {
+			return err
+		}
+		chave := filepath.ToSlash(rel)
+		if !strings.HasPrefix(chave, prefixo) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // apagado durante a listagem
+		}
+		objetos = append(objetos, Objeto{Chave: chave, Tamanho: info.Size(), DtModificacao: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar %s: %w", s.raiz, err)
+	}
+	return objetos, nil
+}
+
+// leitorCtx interrompe a cópia quando o contexto é cancelado
+type leitorCtx struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (l leitorCtx) Read(p []byte) (int, error) {
+	if err := l.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return l.r.Read(p)
+}
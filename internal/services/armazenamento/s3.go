@@ -0,0 +1,263 @@
+/*
+---------------------------------------------------------------------------------------
+File: s3.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: BlobStore em bucket compatível com S3 (AWS S3, MinIO, Ceph RGW). Cliente
+HTTP próprio, sem SDK: PUT, GET, HEAD e DELETE de objeto e ListObjectsV2, com a
+assinatura AWS Signature Version 4 (sigv4.go). Com BLOB_S3_PATH_STYLE=true (padrão,
+exigido pelo MinIO) a URL é endpoint/bucket/chave; senão, bucket.endpoint/chave.
+Para testar localmente:
+
+	docker run -p 9000:9000 minio/minio server /data
+	BLOB_BACKEND=s3 BLOB_S3_ENDPOINT=http://localhost:9000 BLOB_S3_BUCKET=uploads
+	BLOB_S3_ACCESS_KEY=minioadmin BLOB_S3_SECRET_KEY=minioadmin
+
+---------------------------------------------------------------------------------------
+*/
+package armazenamento
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	Regiao    string
+	AccessKey string
+	SecretKey string
+	Prefixo   string
+	PathStyle bool
+}
+
+type S3Store struct {
+	cfg    S3Config
+	base   *url.URL
+	client *http.Client
+}
+
+func NovoS3(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("endpoint e bucket do S3 obrigatórios")
+	}
+	if !strings.Contains(cfg.Endpoint, "://") {
+		cfg.Endpoint = "https://" + cfg.Endpoint
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("endpoint do S3 inválido: %w", err)
+	}
+	if cfg.Regiao == "" {
+		cfg.Regiao = "us-east-1"
+	}
+	if cfg.Prefixo != "" && !strings.HasSuffix(cfg.Prefixo, "/") {
+		cfg.Prefixo += "/"
+	}
+	return &S3Store{
+		cfg:  cfg,
+		base: base,
+		// sem timeout total: os arquivos dos autos podem levar minutos; o ctx controla o cancelamento
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 60 * time.Second,
+			IdleConnTimeout:       90 * time.Second,
+		}},
+	}, nil
+}
+
+func (s *S3Store) Backend() string {
+	return BACKEND_S3 + " (" + s.base.String() + "/" + s.cfg.Bucket + ")"
+}
+
+// url monta a URL do objeto (chave vazia: o bucket)
+func (s *S3Store) url(chave string, query url.Values) *url.URL {
+	u := *s.base
+	caminho := "/"
+	if s.cfg.PathStyle {
+		caminho = "/" + s.cfg.Bucket
+		if chave != "" {
+			caminho += "/"
+		}
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	if chave != "" {
+		caminho += s.cfg.Prefixo + chave
+	}
+	u.Path = caminho
+	u.RawPath = codificaCaminho(caminho)
+	if query != nil {
+		u.RawQuery = codificaQuery(query)
+	}
+	return &u
+}
+
+func (s *S3Store) requisicao(ctx context.Context, metodo string, u *url.URL, corpo io.Reader, tamanho int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, metodo, u.String(), corpo)
+	if err != nil {
+		return nil, err
+	}
+	payload := hashVazio
+	if corpo != nil {
+		req.ContentLength = tamanho
+		if tamanho == 0 {
+			req.Body = http.NoBody
+		}
+		payload = "UNSIGNED-PAYLOAD"
+	}
+	assinaV4(req, payload, s.cfg.Regiao, s.cfg.AccessKey, s.cfg.SecretKey, time.Now())
+	return s.client.Do(req)
+}
+
+func (s *S3Store) Grava(ctx context.Context, chave string, r io.Reader, tamanho int64) error {
+	if err := chaveValida(chave); err != nil {
+		return err
+	}
+	if tamanho < 0 {
+		// o PUT exige o tamanho: o conteúdo passa antes por um arquivo temporário
+		tmp, err := os.CreateTemp("", "blob-*")
+		if err != nil {
+			return fmt.Errorf("erro ao criar o arquivo temporário: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if tamanho, err = io.Copy(tmp, r); err != nil {
+			return fmt.Errorf("erro ao gravar %s: %w", chave, err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r = tmp
+	}
+	resp, err := s.requisicao(ctx, http.MethodPut, s.url(chave, nil), r, tamanho)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar %s no S3: %w", chave, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return erroS3("gravar "+chave, resp)
+	}
+	return nil
+}
+
+func (s *S3Store) Le(ctx context.Context, chave string) (io.ReadCloser, error) {
+	if err := chaveValida(chave); err != nil {
+		return nil, err
+	}
+	resp, err := s.requisicao(ctx, http.MethodGet, s.url(chave, nil), nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s do S3: %w", chave, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNaoEncontrado
+	}
+	defer resp.Body.Close()
+	return nil, erroS3("ler "+chave, resp)
+}
+
+func (s *S3Store) Apaga(ctx context.Context, chave string) error {
+	if err := chaveValida(chave); err != nil {
+		return err
+	}
+	resp, err := s.requisicao(ctx, http.MethodDelete, s.url(chave, nil), nil, 0)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar %s do S3: %w", chave, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return erroS3("apagar "+chave, resp)
+	}
+	return nil
+}
+
+func (s *S3Store) Existe(ctx context.Context, chave string) (bool, error) {
+	if err := chaveValida(chave); err != nil {
+		return false, err
+	}
+	resp, err := s.requisicao(ctx, http.MethodHead, s.url(chave, nil), nil, 0)
+	if err != nil {
+		return false, fmt.Errorf("erro ao consultar %s no S3: %w", chave, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, erroS3("consultar "+chave, resp)
+}
+
+// resposta do ListObjectsV2
+type listaS3 struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+func (s *S3Store) Lista(ctx context.Context, prefixo string) ([]Objeto, error) {
+	var objetos []Objeto
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.cfg.Prefixo + prefixo}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.requisicao(ctx, http.MethodGet, s.url("", query), nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar o bucket: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = erroS3("listar "+prefixo, resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var lista listaS3
+		err = xml.NewDecoder(resp.Body).Decode(&lista)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("resposta inválida do ListObjectsV2: %w", err)
+		}
+		for _, c := range lista.Contents {
+			objetos = append(objetos, Objeto{
+				Chave:         strings.TrimPrefix(c.Key, s.cfg.Prefixo),
+				Tamanho:       c.Size,
+				DtModificacao: c.LastModified,
+			})
+		}
+		if !lista.IsTruncated || lista.NextContinuationToken == "" {
+			return objetos, nil
+		}
+		token = lista.NextContinuationToken
+	}
+}
+
+// erroS3 monta o erro com o código e a mensagem do XML de erro do S3
+func erroS3(operacao string, resp *http.Response) error {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	corpo, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(corpo, &e) == nil && e.Code != "" {
+		return fmt.Errorf("S3 - erro ao %s: %s (%s - %s)", operacao, resp.Status, e.Code, e.Message)
+	}
+	return fmt.Errorf("S3 - erro ao %s: %s", operacao, resp.Status)
+}
@@ -0,0 +1,114 @@
+/*
+---------------------------------------------------------------------------------------
+File: sigv4.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Assinatura AWS Signature Version 4 das requisições ao S3, no cabeçalho
+Authorization. Assina host, x-amz-content-sha256 e x-amz-date; o corpo do PUT vai como
+UNSIGNED-PAYLOAD, o que evita ler o arquivo duas vezes (hash e envio).
+Referência: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+---------------------------------------------------------------------------------------
+*/
+package armazenamento
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// hash SHA-256 do corpo vazio
+const hashVazio = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func assinaV4(req *http.Request, payload string, regiao string, accessKey string, secretKey string, agora time.Time) {
+	agora = agora.UTC()
+	amzData := agora.Format("20060102T150405Z")
+	dia := agora.Format("20060102")
+
+	host := req.URL.Host
+	req.Header.Set("x-amz-date", amzData)
+	req.Header.Set("x-amz-content-sha256", payload)
+
+	cabecalhos := "host:" + host + "\n" +
+		"x-amz-content-sha256:" + payload + "\n" +
+		"x-amz-date:" + amzData + "\n"
+	assinados := "host;x-amz-content-sha256;x-amz-date"
+
+	caminho := req.URL.EscapedPath()
+	if caminho == "" {
+		caminho = "/"
+	}
+	canonica := strings.Join([]string{
+		req.Method,
+		caminho,
+		codificaQuery(req.URL.Query()),
+		cabecalhos,
+		assinados,
+		payload,
+	}, "\n")
+
+	escopo := dia + "/" + regiao + "/s3/aws4_request"
+	hCanonica := sha256.Sum256([]byte(canonica))
+	texto := "AWS4-HMAC-SHA256\n" + amzData + "\n" + escopo + "\n" + hex.EncodeToString(hCanonica[:])
+
+	chave := hmacSHA256([]byte("AWS4"+secretKey), dia)
+	chave = hmacSHA256(chave, regiao)
+	chave = hmacSHA256(chave, "s3")
+	chave = hmacSHA256(chave, "aws4_request")
+	assinatura := hex.EncodeToString(hmacSHA256(chave, texto))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+escopo+
+		", SignedHeaders="+assinados+", Signature="+assinatura)
+}
+
+func hmacSHA256(chave []byte, texto string) []byte {
+	h := hmac.New(sha256.New, chave)
+	h.Write([]byte(texto))
+	return h.Sum(nil)
+}
+
+// codificaQuery ordena e codifica os parâmetros como exige a assinatura (espaço vira %20)
+func codificaQuery(query url.Values) string {
+	chaves := make([]string, 0, len(query))
+	for k := range query {
+		chaves = append(chaves, k)
+	}
+	sort.Strings(chaves)
+	var partes []string
+	for _, k := range chaves {
+		valores := append([]string(nil), query[k]...)
+		sort.Strings(valores)
+		for _, v := range valores {
+			partes = append(partes, codificaURI(k, true)+"="+codificaURI(v, true))
+		}
+	}
+	return strings.Join(partes, "&")
+}
+
+// codificaCaminho codifica o caminho da chave, preservando as barras
+func codificaCaminho(caminho string) string {
+	return codificaURI(caminho, false)
+}
+
+// codificaURI aplica a codificação do SigV4: só A-Z, a-z, 0-9, "-", ".", "_" e "~" ficam literais
+func codificaURI(s string, codificaBarra bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !codificaBarra:
+			b.WriteByte(c)
+		default:
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,211 @@
+/*
+---------------------------------------------------------------------------------------
+File: autosBuscaService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Busca nos autos de um contexto ("onde o réu fala da prescrição?"): lexical
+(BM25 no texto do documento, índice "autos"), semântica (kNN nos embeddings de
+"autos_json_embedding") ou híbrida, pela fusão de posições (RRF). Devolve os
+documentos com os trechos destacados e a procedência (arquivo e páginas), para a
+interface e para o pipeline de análise.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/utils/logger"
+)
+
+// Modos da busca nos autos
+const (
+	BUSCA_AUTOS_HIBRIDA   = "hibrida"   // BM25 + kNN, fundidos por RRF
+	BUSCA_AUTOS_LEXICA    = "lexica"    // somente BM25
+	BUSCA_AUTOS_SEMANTICA = "semantica" // somente kNN
+)
+
+// Documentos devolvidos na busca nos autos: padrão e máximo
+const (
+	LIMITE_BUSCA_AUTOS     = 10
+	MAX_LIMITE_BUSCA_AUTOS = 50
+)
+
+// Parâmetros da busca nos autos
+type ParamsBuscaAutos struct {
+	IdCtxt    string `json:"id_ctxt"`
+	Texto     string `json:"texto"`
+	Naturezas []int  `json:"naturezas"` // vazio: todas
+	Modo      string `json:"modo"`      // hibrida, lexica ou semantica (padrão: RAG_BUSCA_MODO)
+	Limite    int    `json:"limite"`    // padrão LIMITE_BUSCA_AUTOS
+}
+
+// Resultado da busca nos autos, em ordem decrescente de relevância
+type ResultadoBuscaAutos struct {
+	Modo string                             `json:"modo"`
+	Docs []opensearch.ResponseBuscaAutosRow `json:"docs"`
+}
+
+// ModoBuscaAutos normaliza o modo informado (aceita também hybrid, lexical e semantic)
+func ModoBuscaAutos(modo string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(modo)) {
+	case "":
+		if config.GlobalConfig != nil && config.GlobalConfig.RagBuscaModo == BUSCA_AUTOS_SEMANTICA {
+			return BUSCA_AUTOS_SEMANTICA, nil
+		}
+		return BUSCA_AUTOS_HIBRIDA, nil
+	case BUSCA_AUTOS_HIBRIDA, "hybrid":
+		return BUSCA_AUTOS_HIBRIDA, nil
+	case BUSCA_AUTOS_LEXICA, "lexical":
+		return BUSCA_AUTOS_LEXICA, nil
+	case BUSCA_AUTOS_SEMANTICA, "semantic":
+		return BUSCA_AUTOS_SEMANTICA, nil
+	}
+	return "", fmt.Errorf("modo de busca inválido: %q (use hibrida, lexica ou semantica)", modo)
+}
+
+/*
+BuscaAutos procura o texto nos documentos do contexto. Na busca híbrida, a falha de uma
+das buscas não impede o uso da outra. Os documentos trazidos só pela busca vetorial
+recebem os trechos em uma segunda consulta (AutosIndexType.TrechosAutos).
+*/
+func (obj *AutosServiceType) BuscaAutos(ctx context.Context, params ParamsBuscaAutos) (*ResultadoBuscaAutos, error) {
+	if obj == nil || AutosJsonServiceGlobal == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return nil, fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+
+	params.IdCtxt = strings.TrimSpace(params.IdCtxt)
+	params.Texto = strings.TrimSpace(params.Texto)
+	if params.IdCtxt == "" || params.Texto == "" {
+		return nil, fmt.Errorf("id_ctxt e texto são obrigatórios")
+	}
+	modo, err := ModoBuscaAutos(params.Modo)
+	if err != nil {
+		return nil, err
+	}
+	limite := params.Limite
+	if limite <= 0 {
+		limite = LIMITE_BUSCA_AUTOS
+	}
+	if limite > MAX_LIMITE_BUSCA_AUTOS {
+		limite = MAX_LIMITE_BUSCA_AUTOS
+	}
+
+	cfg := config.GlobalConfig
+	candidatos := max(cfg.RagCandidatos, limite)
+	filtro := opensearch.FiltroAutos{IdCtxt: params.IdCtxt, Naturezas: params.Naturezas}
+
+	var docs []opensearch.ResponseBuscaAutosRow
+	switch modo {
+	case BUSCA_AUTOS_LEXICA:
+		docs, err = obj.idx.ConsultaLexicaAutos(params.Texto, filtro, candidatos)
+	case BUSCA_AUTOS_SEMANTICA:
+		docs, err = obj.buscaVetorialAutos(ctx, params.Texto, filtro, candidatos)
+	default:
+		docs, err = obj.buscaHibridaAutos(ctx, params.Texto, filtro, candidatos, cfg.RagRRFK)
+	}
+	if err != nil {
+		logger.Log.Errorf("Erro na busca nos autos do contexto %s (%s): %v", params.IdCtxt, modo, err)
+		return nil, err
+	}
+
+	if len(docs) > limite {
+		docs = docs[:limite]
+	}
+	if docs, err = obj.completaTrechos(docs, params.Texto, filtro); err != nil {
+		logger.Log.Warningf("Busca nos autos do contexto %s sem os trechos da busca vetorial: %v", params.IdCtxt, err)
+	}
+	if docs == nil {
+		docs = []opensearch.ResponseBuscaAutosRow{}
+	}
+	return &ResultadoBuscaAutos{Modo: modo, Docs: docs}, nil
+}
+
+// buscaVetorialAutos gera o embedding do texto da busca e consulta "autos_json_embedding"
+func (obj *AutosServiceType) buscaVetorialAutos(ctx context.Context, texto string, filtro opensearch.FiltroAutos, candidatos int) ([]opensearch.ResponseBuscaAutosRow, error) {
+	vec32, usage, err := OpenaiServiceGlobal.GetEmbeddingFromText(ctx, texto)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar o embedding da busca: %w", err)
+	}
+	ContextoServiceGlobal.UpdateTokenUso(filtro.IdCtxt, int(usage.PromptTokens), 0)
+
+	return AutosJsonServiceGlobal.idx.ConsultaVetorialAutos(vec32, filtro, candidatos)
+}
+
+// buscaHibridaAutos executa as buscas lexical e vetorial em paralelo e as funde por RRF
+func (obj *AutosServiceType) buscaHibridaAutos(ctx context.Context, texto string, filtro opensearch.FiltroAutos, candidatos int, rrfK int) ([]opensearch.ResponseBuscaAutosRow, error) {
+	var (
+		wg                     sync.WaitGroup
+		lexica, vetorial       []opensearch.ResponseBuscaAutosRow
+		errLexica, errVetorial error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		lexica, errLexica = obj.idx.ConsultaLexicaAutos(texto, filtro, candidatos)
+	}()
+	go func() {
+		defer wg.Done()
+		vetorial, errVetorial = obj.buscaVetorialAutos(ctx, texto, filtro, candidatos)
+	}()
+	wg.Wait()
+
+	if errLexica != nil && errVetorial != nil {
+		return nil, fmt.Errorf("busca lexical: %v; busca vetorial: %w", errLexica, errVetorial)
+	}
+	if errLexica != nil {
+		logger.Log.Warningf("Busca lexical nos autos falhou, usando somente a vetorial: %v", errLexica)
+	}
+	if errVetorial != nil {
+		logger.Log.Warningf("Busca vetorial nos autos falhou, usando somente a lexical: %v", errVetorial)
+	}
+
+	return opensearch.FusaoRRFAutos(rrfK, lexica, vetorial), nil
+}
+
+/*
+completaTrechos traz os trechos, o id_pje e a procedência dos documentos da busca
+vetorial. O embedding de documento que não está mais em "autos" é descartado.
+*/
+func (obj *AutosServiceType) completaTrechos(docs []opensearch.ResponseBuscaAutosRow, texto string, filtro opensearch.FiltroAutos) ([]opensearch.ResponseBuscaAutosRow, error) {
+	var ids []string
+	for _, doc := range docs {
+		if len(doc.Trechos) == 0 {
+			ids = append(ids, doc.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return docs, nil
+	}
+
+	trechos, err := obj.idx.TrechosAutos(ids, texto, filtro)
+	if err != nil {
+		return docs, err
+	}
+	porId := make(map[string]opensearch.ResponseBuscaAutosRow, len(trechos))
+	for _, t := range trechos {
+		porId[t.Id] = t
+	}
+
+	out := make([]opensearch.ResponseBuscaAutosRow, 0, len(docs))
+	for _, doc := range docs {
+		if len(doc.Trechos) == 0 {
+			t, ok := porId[doc.Id]
+			if !ok {
+				continue
+			}
+			doc.IdPje = t.IdPje
+			doc.Trechos = t.Trechos
+			doc.Referencia = t.Referencia
+			doc.Procedencia = t.Procedencia
+		}
+		out = append(out, doc)
+	}
+	return out, nil
+}
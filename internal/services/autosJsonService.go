@@ -11,10 +11,14 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"ocrserver/internal/consts"
 	"ocrserver/internal/opensearch"
+	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/services/rag/parsers"
 
 	"ocrserver/internal/utils/logger"
 	"sync"
@@ -154,3 +158,75 @@ func (obj *AutosJsonServiceType) IncluirDocumento(idDoc string, idCtxt string, i
 
 	return resp.Id, nil
 }
+
+// MAX_TOKENS_EMBEDDING_AUTOS limita o texto enviado ao modelo de embedding (entrada máxima de 8191 tokens)
+const MAX_TOKENS_EMBEDDING_AUTOS = 8000
+
+/*
+IndexarAutos gera o embedding do documento autuado e o grava em "autos_json_embedding",
+com o id do documento em "autos": a nova indexação (documento alterado) substitui a
+anterior. O texto é o produzido pelo parser da natureza (parsers.ParserDocumentosJson);
+sem JSON legível, vale o texto extraído do documento.
+*/
+func (obj *AutosJsonServiceType) IndexarAutos(ctx context.Context, row *consts.ResponseAutosRow) error {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+
+	texto, err := parsers.ParserDocumentosJson(row.IdNatu, json.RawMessage(row.DocJsonRaw))
+	if err != nil || strings.TrimSpace(texto) == "" {
+		logger.Log.Warningf("Documento %s sem texto do parser (natureza %d), embedding pelo texto extraído: %v", row.Id, row.IdNatu, err)
+		texto = row.Doc
+	}
+	if strings.TrimSpace(texto) == "" {
+		return fmt.Errorf("documento %s sem texto para o embedding", row.Id)
+	}
+	if parte, err := ialib.TruncaTokens(texto, MAX_TOKENS_EMBEDDING_AUTOS); err == nil {
+		texto = parte
+	} else {
+		logger.Log.Warningf("Erro ao truncar o texto do documento %s: %v", row.Id, err)
+	}
+
+	vec32, usage, err := OpenaiServiceGlobal.GetEmbeddingFromText(ctx, texto)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar embedding do documento %s: %w", row.Id, err)
+	}
+	ContextoServiceGlobal.UpdateTokenUso(row.IdCtxt, int(usage.PromptTokens), 0)
+
+	// Embeddings gravados com id automático (versões anteriores) dão lugar ao novo
+	if err := obj.removeEmbeddings(row.Id, row.Id); err != nil {
+		return err
+	}
+
+	if _, err := obj.InserirEmbedding(row.Id, row.IdCtxt, row.IdNatu, vec32); err != nil {
+		return fmt.Errorf("erro ao gravar o embedding do documento %s: %w", row.Id, err)
+	}
+	return nil
+}
+
+// RemoverAutos apaga os embeddings do documento de "autos"
+func (obj *AutosJsonServiceType) RemoverAutos(idDoc string) error {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+	return obj.removeEmbeddings(idDoc, "")
+}
+
+// removeEmbeddings apaga os embeddings do documento, exceto o de id 'manter'
+func (obj *AutosJsonServiceType) removeEmbeddings(idDoc string, manter string) error {
+	regs, err := obj.SelectByIdDoc(idDoc)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar os embeddings do documento %s: %w", idDoc, err)
+	}
+	for _, reg := range regs {
+		if reg.Id == manter {
+			continue
+		}
+		if err := obj.DeletaEmbedding(reg.Id); err != nil {
+			return fmt.Errorf("erro ao apagar o embedding %s do documento %s: %w", reg.Id, idDoc, err)
+		}
+	}
+	return nil
+}
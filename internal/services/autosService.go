@@ -9,6 +9,7 @@ Data: 03-05-2025
 package services
 
 import (
+	"context"
 	"fmt"
 
 	"ocrserver/internal/consts"
@@ -51,6 +52,8 @@ func (obj *AutosServiceType) InserirAutos(
 	IdPje string,
 	doc string,
 	docJsonRaw string, // agora recebe string
+	procedencia *consts.ProcedenciaAutos, // origem do documento nos autos enviados (nil se inserido à parte)
+	idOptional string, // id do registro ("" para id automático; a autuação usa ChaveAutuacao)
 ) (*consts.ResponseAutosRow, error) {
 	if obj == nil {
 		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
@@ -58,7 +61,7 @@ func (obj *AutosServiceType) InserirAutos(
 	}
 
 	// Indexa diretamente a string JSON
-	row, err := obj.idx.Indexa(IdCtxt, IdNatu, IdPje, doc, docJsonRaw, nil, "")
+	row, err := obj.idx.Indexa(IdCtxt, IdNatu, IdPje, doc, docJsonRaw, nil, procedencia, idOptional)
 	if err != nil {
 		logger.Log.Errorf("Erro na inclusão do registro: %s - %v", IdPje, err)
 		return nil, err
@@ -76,9 +79,27 @@ func (obj *AutosServiceType) UpdateAutos(data consts.ResponseAutosRow) (*consts.
 		logger.Log.Error("Erro na inclusão do registro", err.Error())
 		return nil, err
 	}
+
+	// O embedding acompanha o documento alterado; falhando, a reindexação (POST /admin/embeddings/autos) o refaz
+	if err := AutosJsonServiceGlobal.IndexarAutos(context.Background(), &data); err != nil {
+		logger.Log.Errorf("Erro ao atualizar o embedding do documento %s: %v", data.Id, err)
+	}
 	return row, nil
 }
 
+// AtualizaResumos grava o cache dos resumos por bloco do documento.
+func (obj *AutosServiceType) AtualizaResumos(id string, hash string, resumos []string) error {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+	if err := obj.idx.AtualizaResumos(id, hash, resumos); err != nil {
+		logger.Log.Errorf("Erro ao gravar os resumos do documento %s: %v", id, err)
+		return err
+	}
+	return nil
+}
+
 func (obj *AutosServiceType) DeletaAutos(id string) error {
 	if obj == nil {
 		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
@@ -92,21 +113,9 @@ func (obj *AutosServiceType) DeletaAutos(id string) error {
 	}
 
 	//*******************************************************************
-	emb, err := AutosJsonServiceGlobal.SelectByIdDoc(id)
-	if err != nil {
-		logger.Log.Error("Erro ao deletar documento no índice 'autos'.")
-		return fmt.Errorf("Erro ao deletar documento no índice 'autos'.")
-	}
-	//logger.Log.Infof("Registro: %d.", len(emb))
-	for _, reg := range emb {
-
-		//logger.Log.Infof("Registro: %s.", reg.Id)
-
-		err := AutosJsonServiceGlobal.DeletaEmbedding(reg.Id)
-		if err != nil {
-			logger.Log.Error("Erro ao deletar documento no índice 'autos'.")
-			return fmt.Errorf("Erro ao deletar documento no índice 'autos'.")
-		}
+	if err := AutosJsonServiceGlobal.RemoverAutos(id); err != nil {
+		logger.Log.Errorf("Erro ao deletar o embedding do documento %s: %v", id, err)
+		return fmt.Errorf("Erro ao deletar documento no índice 'autos_json_embedding'.")
 	}
 
 	return nil
@@ -146,17 +155,17 @@ func (obj *AutosServiceType) GetAutosByContexto(id string) ([]consts.ResponseAut
 
 	rows, err := obj.SelectByContexto(id)
 	if err != nil {
-		logger.Log.Errorf("[id_ctxt=%d] Erro ao buscar autos do contexto: %v", id, err)
-		return nil, fmt.Errorf("erro ao buscar autos do contexto %d: %w", id, err)
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao buscar autos do contexto: %v", id, err)
+		return nil, fmt.Errorf("erro ao buscar autos do contexto %s: %w", id, err)
 	}
 
 	if len(rows) == 0 {
-		logger.Log.Warningf("[id_ctxt=%d] Nenhum registro de autos encontrado no contexto.", id)
+		logger.Log.Warningf("[id_ctxt=%s] Nenhum registro de autos encontrado no contexto.", id)
 		// retornar erro semântico ou não, dependendo do uso
 		// return nil, fmt.Errorf("nenhum registro de autos encontrado para o contexto %d", id)
 	}
 
-	logger.Log.Infof("[id_ctxt=%d] Recuperados %d registros de autos.", id, len(rows))
+	logger.Log.Infof("[id_ctxt=%s] Recuperados %d registros de autos.", id, len(rows))
 	return rows, nil
 }
 
@@ -173,3 +182,18 @@ func (obj *AutosServiceType) IsDocAutuado(idCtxt string, idPje string) (bool, er
 	}
 	return exist, nil
 }
+
+// SelectByIdPje devolve o documento autuado no contexto com o id_pje informado; nil se não existir
+func (obj *AutosServiceType) SelectByIdPje(idCtxt string, idPje string) (*consts.ResponseAutosRow, error) {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return nil, fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+
+	row, err := obj.idx.ConsultaByIdPje(idCtxt, idPje)
+	if err != nil {
+		logger.Log.Errorf("Erro ao consultar o documento %s no índice 'autos': %v", idPje, err)
+		return nil, err
+	}
+	return row, nil
+}
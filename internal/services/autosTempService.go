@@ -69,7 +69,7 @@ func (obj *AutosTempServiceType) InserirAutos(IdCtxt string, IdNatu int, IdPje s
 		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
 		return nil, fmt.Errorf("Tentativa de uso de serviço não iniciado.")
 	}
-	row, err := obj.idx.Indexa(IdCtxt, IdNatu, IdPje, doc, "")
+	row, err := obj.idx.Indexa(IdCtxt, IdNatu, IdPje, doc, nil, nil, "")
 	if err != nil {
 		logger.Log.Error("Erro na inclusão do registro", err.Error())
 		return nil, err
@@ -143,54 +143,79 @@ func (obj *AutosTempServiceType) GetAutosByContexto(id string) ([]consts.Respons
 	return rows, nil
 }
 
-func (obj *AutosTempServiceType) VerificarNaturezaDocumento(ctx context.Context, idCtxt string, texto string) (*NaturezaDoc, error) {
+/*
+VerificarNaturezaDocumento identifica a natureza do documento pelo classificador local
+(ClassificaNaturezaLocal) e só consulta o modelo quando a confiança fica abaixo de
+NATUREZA_CONFIANCA_MINIMA. A decisão é registrada com a fonte e a confiança.
+*/
+func (obj *AutosTempServiceType) VerificarNaturezaDocumento(ctx context.Context, idCtxt string, idDoc string, texto string) (*NaturezaDoc, error) {
+	local := ClassificaNaturezaLocal(texto)
+	if local.Confianca >= config.GlobalConfig.NaturezaConfiancaMinima {
+		logger.Log.Infof("Natureza documento identificada (%s): key=%d, description=%s, confiança=%.2f",
+			local.Fonte, local.Key, local.Description, local.Confianca)
+		NaturezaServiceGlobal.Registra(idCtxt, idDoc, local, local)
+		return &local, nil
+	}
+
+	natureza, err := verificarNaturezaModelo(ctx, idCtxt, texto)
+	if err != nil {
+		return nil, err
+	}
+	natureza.Fonte = NATUREZA_FONTE_MODELO
+	natureza.Confianca = 0
+	natureza.Motivo = fmt.Sprintf("confiança local %.2f (%s: %s)", local.Confianca, local.Description, local.Motivo)
+
+	logger.Log.Infof("Natureza documento identificada (%s): key=%d, description=%s", natureza.Fonte, natureza.Key, natureza.Description)
+	NaturezaServiceGlobal.Registra(idCtxt, idDoc, *natureza, local)
+	return natureza, nil
+}
+
+// MAX_CARACTERES_NATUREZA_MODELO limita o texto enviado ao modelo: o início e o fim do documento bastam
+const MAX_CARACTERES_NATUREZA_MODELO = 12000
+
+// Naturezas oferecidas ao modelo, com os códigos de consts
+var opcoesNaturezaModelo = append(append([]int{}, naturezasClassificaveis...), consts.NATU_DOC_OUTROS)
+
+func verificarNaturezaModelo(ctx context.Context, idCtxt string, texto string) (*NaturezaDoc, error) {
+	var opcoes strings.Builder
+	for _, key := range opcoesNaturezaModelo {
+		fmt.Fprintf(&opcoes, "{ \"key\": %d, \"description\": %q }\n", key, consts.GetNaturezaDocumento(key))
+	}
 
 	var msgs ialib.MsgGpt
-	assistente := `O seguinte texto pertence aos autos de um processo judicial. 
+	assistente := fmt.Sprintf(`O seguinte texto pertence aos autos de um processo judicial. 
 
 Primeiramente, verifique se o texto é uma movimentação, registro ou anotação processual, contendo expressões como:
 "Mov.", "Movimentação", "Observações dos Movimentos", "Registro", "Publicação", "Entrada", "Intimação", "Anotação".
 Se essas expressões estiverem presentes, e o texto não contiver o corpo formal completo da decisão (com fundamentação e conclusão explícita do juiz),
 classifique o documento como:
-- { "key": 1003, "description": "movimentação/processo" }.
+- { "key": %d, "description": "%s" }.
 
 Em seguida, verifique se o texto contém alguma das expressões indicativas de certidões ou outros documentos, tais como:
 "certidão", "certifico que", "Por ordem do MM. Juiz", "teor do ato", "o referido é verdade, dou fé",
 "encaminhado edital/relação para publicação", "ato ordinatório".
 
 Se qualquer dessas expressões estiver presente em qualquer parte do texto, incluindo cabeçalhos, movimentações ou descrições, classifique o documento imediatamente como:
-- { "key": 1002, "description": "certidões" } se for claramente certidão,
-- caso contrário, classifique como { "key": 1001, "description": "outros documentos" }.
+- { "key": %d, "description": "%s" } se for claramente certidão,
+- caso contrário, classifique como { "key": %d, "description": "%s" }.
 
 Somente se nenhuma dessas expressões estiver presente, analise o conteúdo para identificar a natureza do documento conforme as opções a seguir:
 
-{ "key": 1, "description": "Petição inicial" }
-{ "key": 2, "description": "Contestação" }
-{ "key": 3, "description": "Réplica" }
-{ "key": 4, "description": "Despacho inicial" }
-{ "key": 5, "description": "Despacho" }
-{ "key": 6, "description": "Petição" }
-{ "key": 7, "description": "Decisão" }
-{ "key": 8, "description": "Sentença" }
-{ "key": 9, "description": "Embargos de declaração" }
-{ "key": 10, "description": "Contra-razões" }
-{ "key": 11, "description": "Recurso" }
-{ "key": 12, "description": "Procuração" }
-{ "key": 13, "description": "Rol de Testemunhas" }
-{ "key": 14, "description": "Contrato" }
-{ "key": 15, "description": "Laudo Pericial" }
-{ "key": 16, "description": "Ata de audiência" }
-{ "key": 17, "description": "Parecer do Ministério Público" }
-
-Se não puder identificar claramente a natureza do texto, classifique como { "key": 1001, "description": "outros documentos" }.
-
-Responda apenas com um JSON no formato: {"key": int, "description": string }.`
+%s
+Se não puder identificar claramente a natureza do texto, classifique como { "key": %d, "description": "%s" }.
+
+Responda apenas com um JSON no formato: {"key": int, "description": string }.`,
+		consts.NATU_DOC_MOVIMENTACAO, consts.GetNaturezaDocumento(consts.NATU_DOC_MOVIMENTACAO),
+		consts.NATU_DOC_CERTIDAO, consts.GetNaturezaDocumento(consts.NATU_DOC_CERTIDAO),
+		consts.NATU_DOC_OUTROS, consts.GetNaturezaDocumento(consts.NATU_DOC_OUTROS),
+		opcoes.String(),
+		consts.NATU_DOC_OUTROS, consts.GetNaturezaDocumento(consts.NATU_DOC_OUTROS))
 
 	msgs.CreateMessage("", ialib.ROLE_USER, assistente)
-	msgs.CreateMessage("", ialib.ROLE_USER, texto)
+	msgs.CreateMessage("", ialib.ROLE_USER, trechoNaturezaModelo(texto))
 
 	retSubmit, err := OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
+		ialib.WithNaturezaPrompt(ctx, consts.PROMPT_AUTUACAO_NATUREZA),
 		msgs,
 		"",
 		config.GlobalConfig.OpenOptionModel,
@@ -215,12 +240,19 @@ Responda apenas com um JSON no formato: {"key": int, "description": string }.`
 		logger.Log.Warningf("Resposta recebida: %s", resp)
 		return nil, erros.CreateError("Resposta inesperada ou formato inválido do modelo")
 	}
-
-	logger.Log.Infof("Natureza documento identificada: key=%d, description=%s", natureza.Key, natureza.Description)
-
 	return &natureza, nil
 }
 
+// trechoNaturezaModelo devolve o texto inteiro ou, nos documentos longos, o início e o fim
+func trechoNaturezaModelo(texto string) string {
+	if len(texto) <= MAX_CARACTERES_NATUREZA_MODELO {
+		return texto
+	}
+	inicio := MAX_CARACTERES_NATUREZA_MODELO * 2 / 3
+	fim := MAX_CARACTERES_NATUREZA_MODELO - inicio
+	return strings.ToValidUTF8(texto[:inicio], "") + "\n[...]\n" + strings.ToValidUTF8(texto[len(texto)-fim:], "")
+}
+
 func (obj *AutosTempServiceType) Exists(id string) (bool, error) {
 	if obj == nil {
 		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
@@ -0,0 +1,388 @@
+/*
+---------------------------------------------------------------------------------------
+File: documento.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Modelo neutro do documento exportado e sua montagem a partir dos eventos
+(análise jurídica, minutas de sentença, decisão e despacho). Os renderizadores (docx,
+odt, pdf, html e md) recebem apenas esse modelo.
+---------------------------------------------------------------------------------------
+*/
+package exportacao
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/services/rag/pipeline"
+)
+
+// Tipos de bloco
+const (
+	BLOCO_TITULO    = "titulo"    // título do documento (centralizado)
+	BLOCO_SECAO     = "secao"     // RELATÓRIO, FUNDAMENTAÇÃO, DISPOSITIVO...
+	BLOCO_SUBSECAO  = "subsecao"  // Preliminares, Mérito...
+	BLOCO_PARAGRAFO = "paragrafo" // texto justificado
+	BLOCO_ITEM      = "item"      // item de lista
+	BLOCO_CAMPO     = "campo"     // "Rótulo: valor" do cabeçalho do processo
+)
+
+type Bloco struct {
+	Tipo   string
+	Rotulo string // somente BLOCO_CAMPO
+	Texto  string
+}
+
+type Documento struct {
+	Timbre []string // linhas do timbre do juízo
+	Titulo string
+	Blocos []Bloco
+}
+
+// DadosProcesso identifica o processo no cabeçalho e no timbre.
+type DadosProcesso struct {
+	Numero  string
+	Juizo   string
+	Classe  string
+	Assunto string
+}
+
+func (d *Documento) add(tipo, texto string) {
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return
+	}
+	d.Blocos = append(d.Blocos, Bloco{Tipo: tipo, Texto: texto})
+}
+
+func (d *Documento) campo(rotulo, valor string) {
+	valor = strings.TrimSpace(valor)
+	if valor == "" {
+		return
+	}
+	d.Blocos = append(d.Blocos, Bloco{Tipo: BLOCO_CAMPO, Rotulo: rotulo, Texto: valor})
+}
+
+func (d *Documento) paragrafos(lista []string) {
+	for _, p := range lista {
+		d.add(BLOCO_PARAGRAFO, p)
+	}
+}
+
+func (d *Documento) itens(lista []string) {
+	for _, p := range lista {
+		d.add(BLOCO_ITEM, p)
+	}
+}
+
+// secao só é incluída quando há conteúdo
+func (d *Documento) secao(titulo string, conteudo ...[]string) {
+	for _, c := range conteudo {
+		if len(c) > 0 {
+			d.add(BLOCO_SECAO, titulo)
+			return
+		}
+	}
+}
+
+func valor(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// cabecalho inclui os dados do processo e as partes
+func (d *Documento) cabecalho(proc DadosProcesso, p *pipeline.Processo, partes *pipeline.TPartes) {
+	if p != nil {
+		proc.Numero = primeiroNaoVazio(valor(p.Numero), proc.Numero)
+		proc.Classe = primeiroNaoVazio(valor(p.Classe), proc.Classe)
+		proc.Assunto = primeiroNaoVazio(valor(p.Assunto), proc.Assunto)
+	}
+	d.campo("Processo nº", proc.Numero)
+	d.campo("Classe", proc.Classe)
+	d.campo("Assunto", proc.Assunto)
+	if partes != nil {
+		d.campo(rotuloPartes("Autor", partes.Autor), strings.Join(partes.Autor, "; "))
+		d.campo(rotuloPartes("Réu", partes.Reu), strings.Join(partes.Reu, "; "))
+	}
+}
+
+func rotuloPartes(rotulo string, lista []string) string {
+	if len(lista) > 1 {
+		if rotulo == "Réu" {
+			return "Réus"
+		}
+		return rotulo + "es"
+	}
+	return rotulo
+}
+
+func primeiroNaoVazio(s ...string) string {
+	for _, v := range s {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func (d *Documento) fundamentacao(f *pipeline.Fundamentacao) {
+	if f == nil {
+		return
+	}
+	var sumulas []string
+	var acordaos []string
+	if f.Jurisprudencia != nil {
+		sumulas = f.Jurisprudencia.Sumulas
+		for _, a := range f.Jurisprudencia.Acordaos {
+			acordaos = append(acordaos, textoAcordao(a))
+		}
+	}
+
+	d.secao("FUNDAMENTAÇÃO", f.Preliminares, f.Merito, f.Doutrina, sumulas, acordaos)
+	if len(f.Preliminares) > 0 {
+		d.add(BLOCO_SUBSECAO, "Preliminares")
+		d.paragrafos(f.Preliminares)
+	}
+	if len(f.Merito) > 0 {
+		d.add(BLOCO_SUBSECAO, "Mérito")
+		d.paragrafos(f.Merito)
+	}
+	if len(f.Doutrina) > 0 {
+		d.add(BLOCO_SUBSECAO, "Doutrina")
+		d.paragrafos(f.Doutrina)
+	}
+	if len(sumulas) > 0 || len(acordaos) > 0 {
+		d.add(BLOCO_SUBSECAO, "Jurisprudência")
+		d.itens(sumulas)
+		d.itens(acordaos)
+	}
+}
+
+// textoAcordao: "STJ, REsp 1.234.567, Rel. Min. Fulano, j. 01/02/2024. Ementa..."
+func textoAcordao(a pipeline.Acordao) string {
+	partes := []string{}
+	for _, s := range []string{valor(a.Tribunal), valor(a.Processo)} {
+		if strings.TrimSpace(s) != "" {
+			partes = append(partes, strings.TrimSpace(s))
+		}
+	}
+	if r := strings.TrimSpace(valor(a.Relator)); r != "" {
+		partes = append(partes, "Rel. "+r)
+	}
+	if dt := strings.TrimSpace(valor(a.Data)); dt != "" {
+		partes = append(partes, "j. "+dt)
+	}
+	texto := strings.Join(partes, ", ")
+	if e := strings.TrimSpace(valor(a.Ementa)); e != "" {
+		texto += ". " + e
+	}
+	return texto
+}
+
+func textoDeterminacao(det pipeline.Determinacao) string {
+	texto := strings.TrimSpace(det.Providencia)
+	if dest := strings.TrimSpace(valor(det.Destinatario)); dest != "" {
+		texto += " (" + dest + ")"
+	}
+	if prazo := strings.TrimSpace(valor(det.Prazo)); prazo != "" {
+		texto += " Prazo: " + prazo + "."
+	}
+	return texto
+}
+
+func montaSentenca(doc *Documento, proc DadosProcesso, raw string) error {
+	var m pipeline.MinutaSentenca
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return fmt.Errorf("minuta de sentença inválida: %w", err)
+	}
+	doc.Titulo = "SENTENÇA"
+	doc.cabecalho(proc, m.Processo, m.Partes)
+
+	doc.secao("RELATÓRIO", m.Relatorio)
+	doc.paragrafos(m.Relatorio)
+
+	doc.fundamentacao(m.Fundamentacao)
+
+	if m.Dispositivo != nil {
+		disp := m.Dispositivo
+		doc.add(BLOCO_SECAO, "DISPOSITIVO")
+		doc.add(BLOCO_PARAGRAFO, valor(disp.Decisao))
+		doc.itens(disp.Condenacoes)
+		doc.add(BLOCO_PARAGRAFO, valor(disp.Honorarios))
+		doc.add(BLOCO_PARAGRAFO, valor(disp.Custas))
+	}
+	return nil
+}
+
+func montaDecisao(doc *Documento, proc DadosProcesso, raw string) error {
+	var m pipeline.MinutaDecisao
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return fmt.Errorf("minuta de decisão inválida: %w", err)
+	}
+	doc.Titulo = "DECISÃO"
+	doc.cabecalho(proc, m.Processo, m.Partes)
+
+	doc.secao("RELATÓRIO", m.Relatorio)
+	doc.paragrafos(m.Relatorio)
+
+	doc.fundamentacao(m.Fundamentacao)
+
+	questoes := make([]string, 0, len(m.Questoes))
+	for _, q := range m.Questoes {
+		questoes = append(questoes, fmt.Sprintf("%s: %s.", strings.TrimSpace(q.Pedido), strings.TrimSpace(q.Resultado)))
+	}
+	var determinacoes []string
+	decisao := ""
+	if m.Dispositivo != nil {
+		decisao = valor(m.Dispositivo.Decisao)
+		for _, det := range m.Dispositivo.Determinacoes {
+			determinacoes = append(determinacoes, textoDeterminacao(det))
+		}
+	}
+	doc.secao("DISPOSITIVO", questoes, []string{decisao}, determinacoes)
+	doc.add(BLOCO_PARAGRAFO, decisao)
+	doc.itens(questoes)
+	doc.itens(determinacoes)
+	return nil
+}
+
+func montaDespacho(doc *Documento, proc DadosProcesso, raw string) error {
+	var m pipeline.MinutaDespacho
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return fmt.Errorf("minuta de despacho inválida: %w", err)
+	}
+	doc.Titulo = "DESPACHO"
+	doc.cabecalho(proc, m.Processo, nil)
+
+	for _, det := range m.Determinacoes {
+		doc.add(BLOCO_PARAGRAFO, textoDeterminacao(det))
+	}
+	return nil
+}
+
+func montaAnalise(doc *Documento, proc DadosProcesso, raw string, titulo string) error {
+	var a pipeline.AnaliseJuridicaIA
+	if err := json.Unmarshal([]byte(raw), &a); err != nil {
+		return fmt.Errorf("análise jurídica inválida: %w", err)
+	}
+	doc.Titulo = titulo
+	proc.Numero = primeiroNaoVazio(a.Identificacao.NumeroProcesso, proc.Numero)
+	doc.cabecalho(proc, nil, &a.Partes)
+	doc.campo("Natureza", a.Identificacao.Natureza)
+	doc.campo("Valor da causa", a.ValorDaCausa)
+
+	doc.secao("SÍNTESE DOS FATOS", []string{a.SinteseFatos.Autor, a.SinteseFatos.Reu})
+	if strings.TrimSpace(a.SinteseFatos.Autor) != "" {
+		doc.add(BLOCO_SUBSECAO, "Versão do autor")
+		doc.add(BLOCO_PARAGRAFO, a.SinteseFatos.Autor)
+	}
+	if strings.TrimSpace(a.SinteseFatos.Reu) != "" {
+		doc.add(BLOCO_SUBSECAO, "Versão do réu")
+		doc.add(BLOCO_PARAGRAFO, a.SinteseFatos.Reu)
+	}
+
+	doc.secao("PEDIDOS DO AUTOR", a.PedidosAutor)
+	doc.itens(a.PedidosAutor)
+
+	def := a.DefesasReu
+	doc.secao("DEFESAS DO RÉU", def.Preliminares, def.PrejudiciaisMerito, def.DefesaMerito, def.PedidosReu)
+	for _, g := range []struct {
+		titulo string
+		lista  []string
+	}{
+		{"Preliminares", def.Preliminares},
+		{"Prejudiciais de mérito", def.PrejudiciaisMerito},
+		{"Defesa de mérito", def.DefesaMerito},
+		{"Pedidos do réu", def.PedidosReu},
+	} {
+		if len(g.lista) > 0 {
+			doc.add(BLOCO_SUBSECAO, g.titulo)
+			doc.itens(g.lista)
+		}
+	}
+
+	questoes := make([]string, 0, len(a.QuestoesControvertidas))
+	for _, q := range a.QuestoesControvertidas {
+		questoes = append(questoes, q.Descricao)
+	}
+	doc.secao("QUESTÕES CONTROVERTIDAS", questoes)
+	doc.itens(questoes)
+
+	doc.secao("PROVAS", a.Provas.Autor, a.Provas.Reu)
+	if len(a.Provas.Autor) > 0 {
+		doc.add(BLOCO_SUBSECAO, "Do autor")
+		doc.itens(a.Provas.Autor)
+	}
+	if len(a.Provas.Reu) > 0 {
+		doc.add(BLOCO_SUBSECAO, "Do réu")
+		doc.itens(a.Provas.Reu)
+	}
+
+	fj := a.FundamentacaoJuridica
+	jurisp := make([]string, 0, len(fj.Jurisprudencia))
+	for _, j := range fj.Jurisprudencia {
+		jurisp = append(jurisp, textoAcordao(pipeline.Acordao{Tribunal: &j.Tribunal, Processo: &j.Processo, Ementa: &j.Ementa}))
+	}
+	doc.secao("FUNDAMENTAÇÃO JURÍDICA", fj.Autor, fj.Reu, jurisp)
+	if len(fj.Autor) > 0 {
+		doc.add(BLOCO_SUBSECAO, "Do autor")
+		doc.paragrafos(fj.Autor)
+	}
+	if len(fj.Reu) > 0 {
+		doc.add(BLOCO_SUBSECAO, "Do réu")
+		doc.paragrafos(fj.Reu)
+	}
+	if len(jurisp) > 0 {
+		doc.add(BLOCO_SUBSECAO, "Jurisprudência")
+		doc.itens(jurisp)
+	}
+
+	decisoes := make([]string, 0, len(a.DecisoesInterlocutorias))
+	for _, di := range a.DecisoesInterlocutorias {
+		decisoes = append(decisoes, di.Conteudo)
+	}
+	doc.secao("DECISÕES INTERLOCUTÓRIAS", decisoes)
+	doc.itens(decisoes)
+
+	doc.secao("ANDAMENTO PROCESSUAL", a.AndamentoProcessual)
+	doc.itens(a.AndamentoProcessual)
+
+	doc.secao("OBSERVAÇÕES", a.Observacoes)
+	doc.itens(a.Observacoes)
+	return nil
+}
+
+/*
+MontaDocumento converte o evento no modelo de documento. Eventos sem estrutura conhecida
+são exportados a partir do texto (campo "doc"), um parágrafo por linha.
+*/
+func MontaDocumento(row *opensearch.ResponseEventosRow, proc DadosProcesso, timbre []string) (*Documento, error) {
+	doc := &Documento{Timbre: timbre}
+
+	var err error
+	switch row.IdNatu {
+	case consts.NATU_DOC_IA_SENTENCA:
+		err = montaSentenca(doc, proc, row.DocJsonRaw)
+	case consts.NATU_DOC_IA_DECISAO:
+		err = montaDecisao(doc, proc, row.DocJsonRaw)
+	case consts.NATU_DOC_IA_DESPACHO:
+		err = montaDespacho(doc, proc, row.DocJsonRaw)
+	case consts.NATU_DOC_IA_ANALISE:
+		err = montaAnalise(doc, proc, row.DocJsonRaw, "ANÁLISE JURÍDICA")
+	case consts.NATU_DOC_IA_PREANALISE:
+		err = montaAnalise(doc, proc, row.DocJsonRaw, "PRÉ-ANÁLISE JURÍDICA")
+	default:
+		doc.Titulo = strings.ToUpper(consts.GetNaturezaDocumento(row.IdNatu))
+		doc.cabecalho(proc, nil, nil)
+		doc.paragrafos(strings.Split(row.Doc, "\n"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
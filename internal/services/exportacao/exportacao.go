@@ -0,0 +1,57 @@
+/*
+---------------------------------------------------------------------------------------
+File: exportacao.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Exportação das minutas e análises geradas pela IA para arquivos editáveis
+(DOCX e ODT), PDF, HTML e Markdown. Toda a geração é feita em Go puro, sem depender
+de LibreOffice ou de outros programas externos.
+---------------------------------------------------------------------------------------
+*/
+package exportacao
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formatos aceitos na exportação
+const (
+	FORMATO_DOCX     = "docx"
+	FORMATO_ODT      = "odt"
+	FORMATO_PDF      = "pdf"
+	FORMATO_HTML     = "html"
+	FORMATO_MARKDOWN = "md"
+)
+
+type formatoExportacao struct {
+	contentType string
+	render      func(*Documento) ([]byte, error)
+}
+
+var formatos = map[string]formatoExportacao{
+	FORMATO_DOCX:     {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", RenderDOCX},
+	FORMATO_ODT:      {"application/vnd.oasis.opendocument.text", RenderODT},
+	FORMATO_PDF:      {"application/pdf", RenderPDF},
+	FORMATO_HTML:     {"text/html; charset=utf-8", RenderHTML},
+	FORMATO_MARKDOWN: {"text/markdown; charset=utf-8", RenderMarkdown},
+}
+
+// FormatoValido indica se o formato (docx, odt, pdf, html ou md) é suportado
+func FormatoValido(formato string) bool {
+	_, ok := formatos[strings.ToLower(formato)]
+	return ok
+}
+
+// Exporta renderiza o documento no formato pedido e devolve o conteúdo e o content-type
+func Exporta(doc *Documento, formato string) ([]byte, string, error) {
+	f, ok := formatos[strings.ToLower(formato)]
+	if !ok {
+		return nil, "", fmt.Errorf("formato de exportação não suportado: %q", formato)
+	}
+	b, err := f.render(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao gerar %s: %w", formato, err)
+	}
+	return b, f.contentType, nil
+}
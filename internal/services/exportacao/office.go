@@ -0,0 +1,249 @@
+/*
+---------------------------------------------------------------------------------------
+File: office.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Renderização do documento exportado em DOCX (Office Open XML) e ODT
+(OpenDocument), gerados diretamente em Go (zip + XML), sem LibreOffice. O timbre vai
+no cabeçalho de página e a numeração no rodapé.
+---------------------------------------------------------------------------------------
+*/
+package exportacao
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// esc escapa o texto para XML, normalizando os espaços
+func esc(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(strings.Join(strings.Fields(s), " ")))
+	return buf.String()
+}
+
+type arquivoZip struct {
+	nome     string
+	conteudo string
+	store    bool // sem compressão (exigido para o "mimetype" do ODT)
+}
+
+func compacta(arquivos []arquivoZip) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, a := range arquivos {
+		metodo := zip.Deflate
+		if a.store {
+			metodo = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: a.nome, Method: metodo})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao criar %s: %w", a.nome, err)
+		}
+		if _, err := w.Write([]byte(a.conteudo)); err != nil {
+			return nil, fmt.Errorf("erro ao gravar %s: %w", a.nome, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ============================================================
+// DOCX
+// ============================================================
+
+const docxNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>
+<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>
+</Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles ` + docxNS + `>
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="Times New Roman" w:hAnsi="Times New Roman" w:cs="Times New Roman"/><w:sz w:val="24"/><w:lang w:val="pt-BR"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="360" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+</w:styles>`
+
+// Propriedades de parágrafo por tipo de bloco (medidas em twips: 1 cm = 567)
+var docxPPr = map[string]string{
+	BLOCO_TITULO:    `<w:jc w:val="center"/><w:spacing w:before="240" w:after="240"/>`,
+	BLOCO_SECAO:     `<w:keepNext/><w:spacing w:before="240" w:after="120"/>`,
+	BLOCO_SUBSECAO:  `<w:keepNext/><w:spacing w:before="120" w:after="120"/>`,
+	BLOCO_PARAGRAFO: `<w:jc w:val="both"/><w:ind w:firstLine="1418"/>`,
+	BLOCO_ITEM:      `<w:jc w:val="both"/><w:ind w:left="709" w:hanging="284"/>`,
+	BLOCO_CAMPO:     `<w:spacing w:after="0"/>`,
+}
+
+func docxRun(texto string, negrito bool, italico bool) string {
+	rpr := ""
+	if negrito {
+		rpr += `<w:b/>`
+	}
+	if italico {
+		rpr += `<w:i/>`
+	}
+	if rpr != "" {
+		rpr = `<w:rPr>` + rpr + `</w:rPr>`
+	}
+	return `<w:r>` + rpr + `<w:t xml:space="preserve">` + esc(texto) + `</w:t></w:r>`
+}
+
+func docxParagrafo(ppr string, runs ...string) string {
+	return `<w:p><w:pPr>` + ppr + `</w:pPr>` + strings.Join(runs, "") + `</w:p>`
+}
+
+func RenderDOCX(doc *Documento) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(docxParagrafo(docxPPr[BLOCO_TITULO], docxRun(doc.Titulo, true, false)))
+	for _, b := range doc.Blocos {
+		ppr := docxPPr[b.Tipo]
+		switch b.Tipo {
+		case BLOCO_TITULO, BLOCO_SECAO:
+			body.WriteString(docxParagrafo(ppr, docxRun(b.Texto, true, false)))
+		case BLOCO_SUBSECAO:
+			body.WriteString(docxParagrafo(ppr, docxRun(b.Texto, true, true)))
+		case BLOCO_CAMPO:
+			body.WriteString(docxParagrafo(ppr, docxRun(b.Rotulo+": ", true, false), docxRun(b.Texto, false, false)))
+		case BLOCO_ITEM:
+			body.WriteString(docxParagrafo(ppr, docxRun("• ", false, false), docxRun(b.Texto, false, false)))
+		default:
+			body.WriteString(docxParagrafo(ppr, docxRun(b.Texto, false, false)))
+		}
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document ` + docxNS + `><w:body>` + body.String() +
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId2"/><w:footerReference w:type="default" r:id="rId3"/>` +
+		`<w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1701" w:right="1134" w:bottom="1134" w:left="1701" w:header="709" w:footer="709" w:gutter="0"/></w:sectPr>` +
+		`</w:body></w:document>`
+
+	var header strings.Builder
+	for _, l := range doc.Timbre {
+		header.WriteString(docxParagrafo(`<w:jc w:val="center"/><w:spacing w:after="0"/>`, docxRun(l, true, false)))
+	}
+	if header.Len() == 0 {
+		header.WriteString(`<w:p/>`)
+	}
+
+	return compacta([]arquivoZip{
+		{nome: "[Content_Types].xml", conteudo: docxContentTypes},
+		{nome: "_rels/.rels", conteudo: docxRels},
+		{nome: "word/_rels/document.xml.rels", conteudo: docxDocumentRels},
+		{nome: "word/styles.xml", conteudo: docxStyles},
+		{nome: "word/document.xml", conteudo: document},
+		{nome: "word/header1.xml", conteudo: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr ` + docxNS + `>` + header.String() + `</w:hdr>`},
+		{nome: "word/footer1.xml", conteudo: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:ftr ` + docxNS + `><w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:fldSimple w:instr="PAGE"><w:r><w:t>1</w:t></w:r></w:fldSimple></w:p></w:ftr>`},
+	})
+}
+
+// ============================================================
+// ODT
+// ============================================================
+
+const odtNS = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.2"`
+
+const odtManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:media-type="application/vnd.oasis.opendocument.text" manifest:version="1.2"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+
+const odtFonte = `fo:font-family="'Times New Roman'" fo:font-size="12pt"`
+
+const odtEstilos = `<office:automatic-styles>
+<style:style style:name="Titulo" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:margin-top="0.4cm" fo:margin-bottom="0.4cm"/><style:text-properties ` + odtFonte + ` fo:font-weight="bold"/></style:style>
+<style:style style:name="Secao" style:family="paragraph"><style:paragraph-properties fo:margin-top="0.4cm" fo:margin-bottom="0.2cm" fo:keep-with-next="always"/><style:text-properties ` + odtFonte + ` fo:font-weight="bold"/></style:style>
+<style:style style:name="Subsecao" style:family="paragraph"><style:paragraph-properties fo:margin-top="0.2cm" fo:margin-bottom="0.2cm" fo:keep-with-next="always"/><style:text-properties ` + odtFonte + ` fo:font-weight="bold" fo:font-style="italic"/></style:style>
+<style:style style:name="Paragrafo" style:family="paragraph"><style:paragraph-properties fo:text-align="justify" fo:text-indent="2.5cm" fo:line-height="150%" fo:margin-bottom="0.2cm"/><style:text-properties ` + odtFonte + `/></style:style>
+<style:style style:name="Item" style:family="paragraph"><style:paragraph-properties fo:text-align="justify" fo:margin-left="1.25cm" fo:text-indent="-0.5cm" fo:line-height="150%" fo:margin-bottom="0.2cm"/><style:text-properties ` + odtFonte + `/></style:style>
+<style:style style:name="Campo" style:family="paragraph"><style:paragraph-properties fo:margin-bottom="0cm"/><style:text-properties ` + odtFonte + `/></style:style>
+<style:style style:name="Negrito" style:family="text"><style:text-properties fo:font-weight="bold"/></style:style>
+</office:automatic-styles>`
+
+var odtEstiloBloco = map[string]string{
+	BLOCO_TITULO:    "Titulo",
+	BLOCO_SECAO:     "Secao",
+	BLOCO_SUBSECAO:  "Subsecao",
+	BLOCO_PARAGRAFO: "Paragrafo",
+	BLOCO_ITEM:      "Item",
+	BLOCO_CAMPO:     "Campo",
+}
+
+func odtParagrafo(estilo, conteudo string) string {
+	return `<text:p text:style-name="` + estilo + `">` + conteudo + `</text:p>`
+}
+
+func RenderODT(doc *Documento) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(odtParagrafo("Titulo", esc(doc.Titulo)))
+	for _, b := range doc.Blocos {
+		estilo := odtEstiloBloco[b.Tipo]
+		switch b.Tipo {
+		case BLOCO_CAMPO:
+			body.WriteString(odtParagrafo(estilo, `<text:span text:style-name="Negrito">`+esc(b.Rotulo+":")+`</text:span> `+esc(b.Texto)))
+		case BLOCO_ITEM:
+			body.WriteString(odtParagrafo(estilo, "•<text:tab/>"+esc(b.Texto)))
+		default:
+			body.WriteString(odtParagrafo(estilo, esc(b.Texto)))
+		}
+	}
+
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content ` + odtNS + `>` + odtEstilos +
+		`<office:body><office:text>` + body.String() + `</office:text></office:body></office:document-content>`
+
+	var header strings.Builder
+	for _, l := range doc.Timbre {
+		header.WriteString(odtParagrafo("MP1", esc(l)))
+	}
+
+	styles := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles ` + odtNS + `>
+<office:automatic-styles>
+<style:page-layout style:name="pm1"><style:page-layout-properties fo:page-width="21cm" fo:page-height="29.7cm" fo:margin-top="2cm" fo:margin-bottom="2cm" fo:margin-left="3cm" fo:margin-right="2cm"/></style:page-layout>
+<style:style style:name="MP1" style:family="paragraph"><style:paragraph-properties fo:text-align="center"/><style:text-properties fo:font-family="'Times New Roman'" fo:font-size="11pt" fo:font-weight="bold"/></style:style>
+<style:style style:name="MP2" style:family="paragraph"><style:paragraph-properties fo:text-align="end"/><style:text-properties fo:font-family="'Times New Roman'" fo:font-size="10pt"/></style:style>
+</office:automatic-styles>
+<office:master-styles>
+<style:master-page style:name="Standard" style:page-layout-name="pm1">
+<style:header>` + header.String() + `</style:header>
+<style:footer><text:p text:style-name="MP2"><text:page-number text:select-page="current">1</text:page-number></text:p></style:footer>
+</style:master-page>
+</office:master-styles>
+</office:document-styles>`
+
+	return compacta([]arquivoZip{
+		{nome: "mimetype", conteudo: "application/vnd.oasis.opendocument.text", store: true},
+		{nome: "META-INF/manifest.xml", conteudo: odtManifest},
+		{nome: "styles.xml", conteudo: styles},
+		{nome: "content.xml", conteudo: content},
+	})
+}
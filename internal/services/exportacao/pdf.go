@@ -0,0 +1,431 @@
+/*
+---------------------------------------------------------------------------------------
+File: pdf.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Renderização do documento exportado em PDF, gerado diretamente em Go. Usa
+as fontes padrão do PDF (Helvetica, sem incorporação) com codificação WinAnsi, que
+cobre os caracteres do português. O texto é quebrado e justificado aqui mesmo, com as
+larguras das fontes (métricas AFM); o timbre se repete no topo de cada página e o
+rodapé traz "Página X de N".
+---------------------------------------------------------------------------------------
+*/
+package exportacao
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// Página A4 e margens (pontos: 1 cm = 28,35)
+const (
+	pdfLargura     = 595.28
+	pdfAltura      = 841.89
+	pdfMargemEsq   = 85.04 // 3 cm
+	pdfMargemDir   = 56.69 // 2 cm
+	pdfMargemSup   = 56.69
+	pdfMargemInf   = 56.69
+	pdfRecuo       = 56.69 // recuo da primeira linha do parágrafo
+	pdfRecuoItem   = 28.35
+	pdfCorpo       = 11.0
+	pdfEntrelinha  = 16.5
+	pdfTimbreCorpo = 10.0
+	pdfRodapeCorpo = 9.0
+)
+
+// Fontes (recursos /F1, /F2 e /F3 da página)
+const (
+	fonteNormal = iota
+	fonteNegrito
+	fonteNegritoItalico
+)
+
+var pdfNomesFontes = []string{"Helvetica", "Helvetica-Bold", "Helvetica-BoldOblique"}
+
+// Larguras (1/1000 do corpo) dos caracteres 32 a 126 da Helvetica e da Helvetica-Bold.
+var larguraHelvetica = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var larguraHelveticaBold = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// Letra-base dos caracteres acentuados 0xC0 a 0xFF (mesma largura na Helvetica)
+const baseLatin1 = "AAAAAA\x00CEEEEIIIIDNOOOOO\x00OUUUUY\x00\x00aaaaaa\x00ceeeeiiiidnooooo\x00ouuuuy\x00y"
+
+// Caracteres fora do Latin-1 presentes na WinAnsi
+var winAnsiEspeciais = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+var larguraEspeciais = map[byte]int{
+	0x85: 1000, 0x89: 1000, 0x8C: 1000, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333,
+	0x95: 350, 0x96: 556, 0x97: 1000, 0x99: 1000, 0x9C: 944,
+	0xA0: 278, 0xAA: 370, 0xBA: 365, 0xB0: 400, 0xC6: 1000, 0xD7: 584, 0xE6: 889, 0xF7: 584,
+}
+
+// paraWinAnsi converte o texto para a codificação WinAnsi (caracteres sem equivalente viram "?")
+func paraWinAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x20:
+			out = append(out, ' ')
+		case r < 0x7F || (r >= 0xA0 && r <= 0xFF):
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsiEspeciais[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+func larguraCaractere(c byte, fonte int) int {
+	tabela := &larguraHelvetica
+	if fonte != fonteNormal {
+		tabela = &larguraHelveticaBold
+	}
+	if c >= 0xC0 && baseLatin1[c-0xC0] != 0 {
+		c = baseLatin1[c-0xC0]
+	}
+	if c >= 32 && c <= 126 {
+		return tabela[c-32]
+	}
+	if w, ok := larguraEspeciais[c]; ok {
+		return w
+	}
+	return 556
+}
+
+func larguraTexto(texto []byte, fonte int, corpo float64) float64 {
+	total := 0
+	for _, c := range texto {
+		total += larguraCaractere(c, fonte)
+	}
+	return float64(total) * corpo / 1000
+}
+
+// ============================================================
+// Composição das linhas
+// ============================================================
+
+type palavra struct {
+	fonte int
+	texto []byte
+}
+
+type linhaPDF struct {
+	x, largura float64 // início e largura disponível
+	corpo      float64
+	palavras   []palavra
+	justifica  bool
+	centraliza bool
+}
+
+// palavrasDe separa o texto em palavras de uma mesma fonte
+func palavrasDe(texto string, fonte int) []palavra {
+	var out []palavra
+	for _, p := range strings.Fields(texto) {
+		out = append(out, palavra{fonte: fonte, texto: paraWinAnsi(p)})
+	}
+	return out
+}
+
+/*
+quebraLinhas distribui as palavras em linhas. A primeira linha começa em x0 (recuo) e
+as demais em x; palavras maiores que a linha são partidas.
+*/
+func quebraLinhas(palavras []palavra, x0, x, corpo float64, justifica bool) []linhaPDF {
+	limite := pdfLargura - pdfMargemDir
+	espaco := larguraTexto([]byte(" "), fonteNormal, corpo)
+
+	var linhas []linhaPDF
+	atual := linhaPDF{x: x0, largura: limite - x0, corpo: corpo, justifica: justifica}
+	ocupado := 0.0
+	for i := 0; i < len(palavras); i++ {
+		p := palavras[i]
+		w := larguraTexto(p.texto, p.fonte, corpo)
+		extra := w
+		if len(atual.palavras) > 0 {
+			extra += espaco
+		}
+		if ocupado+extra > atual.largura && len(atual.palavras) > 0 {
+			linhas = append(linhas, atual)
+			atual = linhaPDF{x: x, largura: limite - x, corpo: corpo, justifica: justifica}
+			ocupado = 0
+			i--
+			continue
+		}
+		if w > atual.largura && len(atual.palavras) == 0 {
+			// palavra maior que a linha: parte no último caractere que cabe
+			n := len(p.texto) - 1
+			for n > 1 && larguraTexto(p.texto[:n], p.fonte, corpo) > atual.largura {
+				n--
+			}
+			atual.palavras = append(atual.palavras, palavra{fonte: p.fonte, texto: p.texto[:n]})
+			linhas = append(linhas, atual)
+			atual = linhaPDF{x: x, largura: limite - x, corpo: corpo, justifica: justifica}
+			ocupado = 0
+			palavras[i] = palavra{fonte: p.fonte, texto: p.texto[n:]}
+			i--
+			continue
+		}
+		atual.palavras = append(atual.palavras, p)
+		ocupado += extra
+	}
+	if len(atual.palavras) > 0 {
+		atual.justifica = false // a última linha do parágrafo não é justificada
+		linhas = append(linhas, atual)
+	}
+	return linhas
+}
+
+// ============================================================
+// Paginação
+// ============================================================
+
+type paginaPDF struct {
+	conteudo bytes.Buffer
+}
+
+type compositorPDF struct {
+	doc     *Documento
+	paginas []*paginaPDF
+	y       float64
+}
+
+func (c *compositorPDF) topoConteudo() float64 {
+	y := pdfAltura - pdfMargemSup - float64(len(c.doc.Timbre))*(pdfTimbreCorpo+3)
+	if len(c.doc.Timbre) > 0 {
+		y -= 12
+	}
+	return y
+}
+
+func (c *compositorPDF) novaPagina() {
+	c.paginas = append(c.paginas, &paginaPDF{})
+	c.y = c.topoConteudo()
+}
+
+func (c *compositorPDF) pagina() *paginaPDF {
+	return c.paginas[len(c.paginas)-1]
+}
+
+// escreveLinha posiciona a linha (ou muda de página) e grava os operadores de texto
+func (c *compositorPDF) escreveLinha(l linhaPDF, entrelinha float64) {
+	if c.y-entrelinha < pdfMargemInf {
+		c.novaPagina()
+	}
+	c.y -= entrelinha
+
+	espaco := larguraTexto([]byte(" "), fonteNormal, l.corpo)
+	total := 0.0
+	for i, p := range l.palavras {
+		total += larguraTexto(p.texto, p.fonte, l.corpo)
+		if i > 0 {
+			total += espaco
+		}
+	}
+	x := l.x
+	tw := 0.0
+	if l.centraliza {
+		x = l.x + (l.largura-total)/2
+	} else if l.justifica && len(l.palavras) > 1 {
+		tw = (l.largura - total) / float64(len(l.palavras)-1)
+	}
+
+	buf := &c.pagina().conteudo
+	fmt.Fprintf(buf, "BT %.2f Tw 1 0 0 1 %.2f %.2f Tm\n", tw, x, c.y)
+	// palavras consecutivas da mesma fonte vão num único Tj
+	var trecho []byte
+	for i, p := range l.palavras {
+		trecho = append(trecho, p.texto...)
+		if i < len(l.palavras)-1 {
+			trecho = append(trecho, ' ')
+		}
+		if i == len(l.palavras)-1 || l.palavras[i+1].fonte != p.fonte {
+			fmt.Fprintf(buf, "/F%d %.1f Tf (%s) Tj\n", p.fonte+1, l.corpo, escapaPDF(trecho))
+			trecho = trecho[:0]
+		}
+	}
+	buf.WriteString("ET\n")
+}
+
+// cabe reserva espaço para n linhas (ex.: título de seção junto com o texto seguinte)
+func (c *compositorPDF) cabe(n int) {
+	if c.y-float64(n)*pdfEntrelinha < pdfMargemInf {
+		c.novaPagina()
+	}
+}
+
+func escapaPDF(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '\\', '(', ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func (c *compositorPDF) compoe() {
+	c.novaPagina()
+	x := pdfMargemEsq
+	largura := pdfLargura - pdfMargemDir - pdfMargemEsq
+
+	centro := func(texto string, fonte int, corpo float64) {
+		for _, l := range quebraLinhas(palavrasDe(texto, fonte), x, x, corpo, false) {
+			l.centraliza = true
+			l.largura = largura
+			c.escreveLinha(l, corpo*1.6)
+		}
+	}
+
+	centro(c.doc.Titulo, fonteNegrito, pdfCorpo+2)
+	c.y -= pdfEntrelinha / 2
+
+	for _, b := range c.doc.Blocos {
+		switch b.Tipo {
+		case BLOCO_TITULO:
+			c.y -= pdfEntrelinha / 2
+			centro(b.Texto, fonteNegrito, pdfCorpo+2)
+		case BLOCO_SECAO:
+			c.cabe(3)
+			c.y -= pdfEntrelinha / 2
+			for _, l := range quebraLinhas(palavrasDe(b.Texto, fonteNegrito), x, x, pdfCorpo, false) {
+				c.escreveLinha(l, pdfEntrelinha)
+			}
+		case BLOCO_SUBSECAO:
+			c.cabe(3)
+			c.y -= pdfEntrelinha / 4
+			for _, l := range quebraLinhas(palavrasDe(b.Texto, fonteNegritoItalico), x, x, pdfCorpo, false) {
+				c.escreveLinha(l, pdfEntrelinha)
+			}
+		case BLOCO_CAMPO:
+			palavras := append(palavrasDe(b.Rotulo+":", fonteNegrito), palavrasDe(b.Texto, fonteNormal)...)
+			for _, l := range quebraLinhas(palavras, x, x, pdfCorpo, false) {
+				c.escreveLinha(l, pdfEntrelinha)
+			}
+		case BLOCO_ITEM:
+			marcador := linhaPDF{x: x + pdfRecuoItem/2, corpo: pdfCorpo, palavras: palavrasDe("•", fonteNormal)}
+			for i, l := range quebraLinhas(palavrasDe(b.Texto, fonteNormal), x+pdfRecuoItem, x+pdfRecuoItem, pdfCorpo, true) {
+				c.escreveLinha(l, pdfEntrelinha)
+				if i == 0 {
+					// o marcador fica na mesma linha do início do item
+					c.y += pdfEntrelinha
+					c.escreveLinha(marcador, pdfEntrelinha)
+				}
+			}
+			c.y -= pdfEntrelinha / 4
+		default:
+			for _, l := range quebraLinhas(palavrasDe(b.Texto, fonteNormal), x+pdfRecuo, x, pdfCorpo, true) {
+				c.escreveLinha(l, pdfEntrelinha)
+			}
+			c.y -= pdfEntrelinha / 3
+		}
+	}
+}
+
+// molduraPagina desenha timbre e rodapé; chamada depois da composição (total de páginas conhecido)
+func (c *compositorPDF) molduraPagina(n int) []byte {
+	var buf bytes.Buffer
+	largura := pdfLargura - pdfMargemDir - pdfMargemEsq
+
+	y := pdfAltura - pdfMargemSup
+	for _, linha := range c.doc.Timbre {
+		texto := paraWinAnsi(linha)
+		w := larguraTexto(texto, fonteNegrito, pdfTimbreCorpo)
+		y -= pdfTimbreCorpo + 3
+		fmt.Fprintf(&buf, "BT /F2 %.1f Tf 1 0 0 1 %.2f %.2f Tm (%s) Tj ET\n",
+			pdfTimbreCorpo, pdfMargemEsq+(largura-w)/2, y, escapaPDF(texto))
+	}
+	if len(c.doc.Timbre) > 0 {
+		y -= 6
+		fmt.Fprintf(&buf, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargemEsq, y, pdfLargura-pdfMargemDir, y)
+	}
+
+	rodape := paraWinAnsi(fmt.Sprintf("Página %d de %d", n, len(c.paginas)))
+	w := larguraTexto(rodape, fonteNormal, pdfRodapeCorpo)
+	fmt.Fprintf(&buf, "BT /F1 %.1f Tf 1 0 0 1 %.2f %.2f Tm (%s) Tj ET\n",
+		pdfRodapeCorpo, pdfLargura-pdfMargemDir-w, pdfMargemInf/2, escapaPDF(rodape))
+	return buf.Bytes()
+}
+
+func RenderPDF(doc *Documento) ([]byte, error) {
+	c := &compositorPDF{doc: doc}
+	c.compoe()
+
+	var out bytes.Buffer
+	var offsets []int
+	objeto := func(corpo string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), corpo)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// 1 catálogo, 2 árvore de páginas, 3-5 fontes, 6 info; depois, página e conteúdo de cada página
+	const primeiraPagina = 7
+	kids := make([]string, len(c.paginas))
+	for i := range c.paginas {
+		kids[i] = fmt.Sprintf("%d 0 R", primeiraPagina+2*i)
+	}
+	objeto("<< /Type /Catalog /Pages 2 0 R >>")
+	objeto(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(c.paginas)))
+	for _, nome := range pdfNomesFontes {
+		objeto(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", nome))
+	}
+	objeto(fmt.Sprintf("<< /Title (%s) /Producer (ocrserver) >>", escapaPDF(paraWinAnsi(doc.Titulo))))
+
+	for i, p := range c.paginas {
+		var raw bytes.Buffer
+		raw.Write(c.molduraPagina(i + 1))
+		raw.Write(p.conteudo.Bytes())
+
+		var comp bytes.Buffer
+		zw := zlib.NewWriter(&comp)
+		if _, err := zw.Write(raw.Bytes()); err != nil {
+			return nil, fmt.Errorf("erro ao compactar página: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("erro ao compactar página: %w", err)
+		}
+
+		objeto(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			pdfLargura, pdfAltura, primeiraPagina+2*i+1))
+		objeto(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", comp.Len(), comp.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
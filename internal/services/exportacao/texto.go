@@ -0,0 +1,133 @@
+/*
+---------------------------------------------------------------------------------------
+File: texto.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Renderização do documento exportado em HTML e Markdown.
+---------------------------------------------------------------------------------------
+*/
+package exportacao
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+)
+
+const modeloHTML = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>{{.Titulo}}</title>
+<style>
+  body { font-family: "Times New Roman", serif; font-size: 12pt; line-height: 1.5; max-width: 17cm; margin: 2cm auto; }
+  .timbre { text-align: center; font-weight: bold; margin: 0; }
+  .timbre-fim { border-bottom: 1px solid #000; margin-bottom: 1.5em; padding-bottom: .5em; }
+  h1 { text-align: center; font-size: 14pt; margin: 1em 0; }
+  h2 { font-size: 12pt; margin: 1.2em 0 .5em; }
+  h3 { font-size: 12pt; font-style: italic; margin: 1em 0 .4em; }
+  p { text-align: justify; text-indent: 2.5cm; margin: 0 0 .6em; }
+  p.campo { text-indent: 0; margin: 0; }
+  li { text-align: justify; margin-bottom: .4em; }
+</style>
+</head>
+<body>
+<div class="timbre-fim">
+{{- range .Timbre}}
+<p class="timbre">{{.}}</p>
+{{- end}}
+</div>
+<h1>{{.Titulo}}</h1>
+{{- range .Grupos}}
+{{- if eq .Tipo "titulo"}}
+<h1>{{.Texto}}</h1>
+{{- else if eq .Tipo "secao"}}
+<h2>{{.Texto}}</h2>
+{{- else if eq .Tipo "subsecao"}}
+<h3>{{.Texto}}</h3>
+{{- else if eq .Tipo "campo"}}
+<p class="campo"><b>{{.Rotulo}}:</b> {{.Texto}}</p>
+{{- else if eq .Tipo "lista"}}
+<ul>
+{{- range .Itens}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>{{.Texto}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`
+
+var tplHTML = template.Must(template.New("html").Parse(modeloHTML))
+
+// grupoHTML agrupa os itens consecutivos numa única lista
+type grupoHTML struct {
+	Bloco
+	Itens []string
+}
+
+func RenderHTML(doc *Documento) ([]byte, error) {
+	var grupos []grupoHTML
+	for _, b := range doc.Blocos {
+		if b.Tipo == BLOCO_ITEM {
+			if n := len(grupos); n > 0 && grupos[n-1].Tipo == "lista" {
+				grupos[n-1].Itens = append(grupos[n-1].Itens, b.Texto)
+				continue
+			}
+			grupos = append(grupos, grupoHTML{Bloco: Bloco{Tipo: "lista"}, Itens: []string{b.Texto}})
+			continue
+		}
+		grupos = append(grupos, grupoHTML{Bloco: b})
+	}
+
+	var buf bytes.Buffer
+	err := tplHTML.Execute(&buf, map[string]any{
+		"Titulo": doc.Titulo,
+		"Timbre": doc.Timbre,
+		"Grupos": grupos,
+	})
+	return buf.Bytes(), err
+}
+
+// escapaMD evita que o texto gerado pelo modelo seja interpretado como marcação
+func escapaMD(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`).Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func RenderMarkdown(doc *Documento) ([]byte, error) {
+	var sb strings.Builder
+	for _, l := range doc.Timbre {
+		sb.WriteString("**" + escapaMD(l) + "**  \n")
+	}
+	if len(doc.Timbre) > 0 {
+		sb.WriteString("\n---\n\n")
+	}
+	sb.WriteString("# " + escapaMD(doc.Titulo) + "\n\n")
+
+	anterior := ""
+	for _, b := range doc.Blocos {
+		if anterior == BLOCO_ITEM && b.Tipo != BLOCO_ITEM || anterior == BLOCO_CAMPO && b.Tipo != BLOCO_CAMPO {
+			sb.WriteString("\n")
+		}
+		switch b.Tipo {
+		case BLOCO_TITULO:
+			sb.WriteString("# " + escapaMD(b.Texto) + "\n\n")
+		case BLOCO_SECAO:
+			sb.WriteString("## " + escapaMD(b.Texto) + "\n\n")
+		case BLOCO_SUBSECAO:
+			sb.WriteString("### " + escapaMD(b.Texto) + "\n\n")
+		case BLOCO_CAMPO:
+			sb.WriteString("**" + escapaMD(b.Rotulo) + ":** " + escapaMD(b.Texto) + "  \n")
+		case BLOCO_ITEM:
+			sb.WriteString("- " + escapaMD(b.Texto) + "\n")
+		default:
+			sb.WriteString(escapaMD(b.Texto) + "\n\n")
+		}
+		anterior = b.Tipo
+	}
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,53 @@
+/*
+---------------------------------------------------------------------------------------
+File: timbre.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Timbre do juízo nos documentos exportados. O modelo é um text/template
+(uma linha do timbre por linha do arquivo) indicado em EXPORT_TIMBRE_ARQUIVO; sem o
+arquivo, usa-se TIMBRE_PADRAO. Campos disponíveis: {{.Numero}}, {{.Juizo}},
+{{.Classe}} e {{.Assunto}}.
+---------------------------------------------------------------------------------------
+*/
+package exportacao
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"ocrserver/internal/config"
+)
+
+const TIMBRE_PADRAO = `PODER JUDICIÁRIO
+{{.Juizo}}`
+
+// Timbre monta as linhas do timbre para o processo. Linhas vazias são descartadas.
+func Timbre(proc DadosProcesso) ([]string, error) {
+	modelo := TIMBRE_PADRAO
+	if arq := strings.TrimSpace(config.GlobalConfig.ExportTimbreArquivo); arq != "" {
+		b, err := os.ReadFile(arq)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler o modelo de timbre %q: %w", arq, err)
+		}
+		modelo = string(b)
+	}
+
+	tpl, err := template.New("timbre").Parse(modelo)
+	if err != nil {
+		return nil, fmt.Errorf("modelo de timbre inválido: %w", err)
+	}
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, proc); err != nil {
+		return nil, fmt.Errorf("erro ao aplicar o modelo de timbre: %w", err)
+	}
+
+	var linhas []string
+	for _, l := range strings.Split(sb.String(), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			linhas = append(linhas, l)
+		}
+	}
+	return linhas, nil
+}
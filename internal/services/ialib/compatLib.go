@@ -0,0 +1,493 @@
+/*
+---------------------------------------------------------------------------------------
+File: compatLib.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Backend LLMProvider para servidores compatíveis com a API da OpenAI
+(vLLM, Ollama, LM Studio etc.), usando os endpoints /v1/chat/completions e
+/v1/embeddings. Permite executar os fluxos do pipeline com modelos on-premise.
+---------------------------------------------------------------------------------------
+*/
+
+package ialib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/utils/erros"
+	"ocrserver/internal/utils/logger"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared/constant"
+)
+
+/*
+Quantidade máxima de conversas mantidas em memória para emular previous_response_id. O
+histórico é local ao processo: perde-se ao reiniciar o servidor e não é compartilhado
+entre réplicas. Nesses casos (ou quando a conversa já foi descartada), o prevID não é
+encontrado e a chamada segue sem o contexto anterior, com aviso no log; com o backend
+compat, use uma única réplica ou afinidade de sessão.
+*/
+const COMPAT_MAX_HISTORICO = 512
+
+type OpenaiCompatType struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	cfg        *config.Config
+
+	// Os servidores compatíveis não guardam estado entre chamadas. Para manter a
+	// semântica de prevID usada pelo pipeline, o histórico é guardado localmente.
+	muHist    sync.Mutex
+	historico map[string][]MessageResponseItem
+	ordemHist []string
+}
+
+var (
+	OpenaiCompatGlobal   *OpenaiCompatType
+	onceInitOpenaiCompat sync.Once
+)
+
+// InitOpenaiCompat inicializa o cliente global uma única vez.
+func InitOpenaiCompat(cfg *config.Config) {
+	onceInitOpenaiCompat.Do(func() {
+		OpenaiCompatGlobal = NewOpenaiCompatClient(cfg)
+		logger.Log.Infof("Global OpenaiCompat configurado com sucesso: %s", OpenaiCompatGlobal.baseURL)
+	})
+}
+
+func NewOpenaiCompatClient(cfg *config.Config) *OpenaiCompatType {
+	base := strings.TrimRight(strings.TrimSpace(cfg.LLMCompatBaseURL), "/")
+	base = strings.TrimSuffix(base, "/v1")
+
+	return &OpenaiCompatType{
+		baseURL:    base,
+		apiKey:     cfg.LLMCompatApiKey,
+		httpClient: &http.Client{},
+		cfg:        cfg,
+		historico:  make(map[string][]MessageResponseItem),
+	}
+}
+
+// ------------------------- Estruturas HTTP -------------------------------
+
+type compatChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type compatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type compatChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []compatChatMessage   `json:"messages"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	Stream         bool                  `json:"stream"`
+	StreamOptions  *compatStreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *compatResponseFormat `json:"response_format,omitempty"`
+}
+
+// compatResponseFormat pede a saída estruturada ("json_schema"), aceita pelo vLLM e pelo Ollama
+type compatResponseFormat struct {
+	Type       string           `json:"type"`
+	JSONSchema compatJSONSchema `json:"json_schema"`
+}
+
+type compatJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"`
+}
+
+// formatoCompat converte o formato do contexto (WithFormatoJSON) para o chat/completions; nil se ausente
+func formatoCompat(ctx context.Context) *compatResponseFormat {
+	formato := FormatoJSONFromContext(ctx)
+	if formato == nil {
+		return nil
+	}
+	return &compatResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: compatJSONSchema{Name: formato.Nome, Schema: formato.Schema, Strict: formato.Estrito},
+	}
+}
+
+type compatUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+type compatChatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      compatChatMessage `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+	} `json:"choices"`
+	Usage compatUsage `json:"usage"`
+}
+
+type compatEmbeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type compatEmbeddingResponse struct {
+	Model string `json:"model"`
+	Data  []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		PromptTokens int64 `json:"prompt_tokens"`
+		TotalTokens  int64 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// compatHTTPError preserva o status HTTP para a decisão de retry.
+type compatHTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *compatHTTPError) Error() string {
+	return fmt.Sprintf("servidor compatível retornou status %d: %s", e.StatusCode, e.Body)
+}
+
+// ------------------------- LLMProvider -----------------------------------
+
+func (obj *OpenaiCompatType) Nome() string { return PROVIDER_COMPAT }
+
+// SuportaFormatoJSON: depende do servidor (LLM_COMPAT_JSON_SCHEMA)
+func (obj *OpenaiCompatType) SuportaFormatoJSON() bool {
+	return obj != nil && obj.cfg != nil && obj.cfg.LLMCompatFormatoJSON
+}
+
+/*
+SubmitPrompt
+Converte as mensagens para o formato chat/completions e devolve a resposta no
+formato *responses.Response, igual ao backend OpenAI. Os parâmetros effort e
+verbosity não existem nesse protocolo e são ignorados.
+*/
+func (obj *OpenaiCompatType) SubmitPrompt(
+	ctx context.Context,
+	inputMsgs MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+) (*responses.Response, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("serviço OpenaiCompat não iniciado")
+	}
+
+	msgs := inputMsgs.GetMessages()
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("lista de mensagens vazia")
+	}
+
+	// Timeout defensivo: se o contexto não tiver deadline, aplica o da config
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		timeout := 180
+		if obj.cfg.OpenOptionTimeoutSeconds > 0 {
+			timeout = obj.cfg.OpenOptionTimeoutSeconds
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	conversa, body := obj.montaRequisicao(msgs, prevID, modelo)
+	if obj.SuportaFormatoJSON() {
+		body.ResponseFormat = formatoCompat(ctx)
+	}
+
+	var out compatChatResponse
+	if err := obj.postJSON(ctx, "/v1/chat/completions", body, &out); err != nil {
+		logger.Log.Errorf("Falha final na chamada ao servidor compatível: %v", err)
+		return nil, err
+	}
+	if len(out.Choices) == 0 {
+		return nil, fmt.Errorf("resposta sem choices do servidor compatível")
+	}
+	choice := out.Choices[0]
+
+	return obj.montaResposta(conversa, out.ID, out.Model, choice.Message.Content, choice.FinishReason, out.Usage)
+}
+
+// montaRequisicao junta o histórico de prevID às mensagens e monta o corpo do chat/completions.
+func (obj *OpenaiCompatType) montaRequisicao(
+	msgs []MessageResponseItem,
+	prevID string,
+	modelo string,
+) ([]MessageResponseItem, compatChatRequest) {
+	// Recupera a conversa anterior, se houver
+	conversa := make([]MessageResponseItem, 0, len(msgs))
+	if prevID != "" {
+		anteriores, ok := obj.getHistorico(prevID)
+		if !ok {
+			logger.Log.Warningf("prevID %s não encontrado no histórico local; seguindo sem contexto anterior", prevID)
+		}
+		conversa = append(conversa, anteriores...)
+	}
+	conversa = append(conversa, msgs...)
+
+	model := obj.resolveModelo(modelo)
+	logger.Log.Infof("Modelo de IA (compat): %s", model)
+
+	body := compatChatRequest{
+		Model:     model,
+		Messages:  make([]compatChatMessage, 0, len(conversa)),
+		MaxTokens: obj.cfg.OpenOptionMaxCompletionTokens,
+		Stream:    false,
+	}
+	for _, it := range conversa {
+		body.Messages = append(body.Messages, compatChatMessage{Role: compatRole(it.Role), Content: it.Text})
+	}
+	return conversa, body
+}
+
+// montaResposta converte o retorno do chat/completions em *responses.Response e guarda o histórico.
+func (obj *OpenaiCompatType) montaResposta(
+	conversa []MessageResponseItem,
+	idResp string,
+	model string,
+	texto string,
+	finishReason string,
+	usage compatUsage,
+) (*responses.Response, error) {
+	if finishReason == "content_filter" {
+		logger.Log.Errorf("Resposta bloqueada por política de conteúdo")
+		return nil, erros.CreateError("Resposta truncada pela política do modelo!")
+	}
+
+	if idResp == "" {
+		id_v7, _ := uuid.NewV7()
+		idResp = id_v7.String()
+	}
+	idResp = "compat_" + idResp
+
+	conversa = append(conversa, MessageResponseItem{Id: idResp, Role: ROLE_ASSISTANT, Text: texto})
+	obj.setHistorico(idResp, conversa)
+
+	resp := &responses.Response{
+		ID:     idResp,
+		Model:  model,
+		Status: responses.ResponseStatusCompleted,
+		Output: []responses.ResponseOutputItemUnion{
+			{
+				ID:     idResp,
+				Type:   "message",
+				Role:   constant.Assistant(openai.MessageRoleAssistant),
+				Status: "completed",
+				Content: []responses.ResponseOutputMessageContentUnion{
+					{Type: "output_text", Text: texto},
+				},
+			},
+		},
+		Usage: responses.ResponseUsage{
+			InputTokens:  usage.PromptTokens,
+			OutputTokens: usage.CompletionTokens,
+			TotalTokens:  usage.TotalTokens,
+		},
+	}
+	if finishReason == "length" {
+		resp.Status = responses.ResponseStatusIncomplete
+		resp.IncompleteDetails.Reason = "max_output_tokens"
+	}
+
+	logger.Log.Infof("Modelo: %s - TOKENS - Input: %d - Output: %d - Total: %d",
+		resp.Model, resp.Usage.InputTokens, resp.Usage.OutputTokens, resp.Usage.TotalTokens)
+
+	return resp, nil
+}
+
+func (obj *OpenaiCompatType) GetEmbedding(
+	ctx context.Context,
+	inputTxt string,
+) ([]float32, *openai.CreateEmbeddingResponseUsage, error) {
+	if obj == nil {
+		return nil, nil, fmt.Errorf("serviço OpenaiCompat não iniciado")
+	}
+	if strings.TrimSpace(obj.cfg.LLMCompatModelEmbedding) == "" {
+		return nil, nil, fmt.Errorf("LLM_COMPAT_MODEL_EMBEDDING não configurado")
+	}
+
+	//Timeout defensivo se caller não definiu
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 90*time.Second)
+		defer cancel()
+	}
+
+	body := compatEmbeddingRequest{
+		Model: obj.cfg.LLMCompatModelEmbedding,
+		Input: inputTxt,
+	}
+
+	var out compatEmbeddingResponse
+	if err := obj.postJSON(ctx, "/v1/embeddings", body, &out); err != nil {
+		return nil, nil, fmt.Errorf("falha ao obter embedding: %w", err)
+	}
+	if len(out.Data) == 0 {
+		return nil, nil, fmt.Errorf("nenhum embedding retornado")
+	}
+
+	embedding := out.Data[0].Embedding
+
+	// Os índices vetoriais foram criados com ExpectedRagVectorSize dimensões: um vetor de
+	// outro tamanho seria recusado (ou, pior, comparado) pelo OpenSearch
+	if l := len(embedding); l != opensearch.ExpectedRagVectorSize {
+		return nil, nil, fmt.Errorf("dimensão do embedding de %s é %d, mas os índices esperam %d",
+			obj.cfg.LLMCompatModelEmbedding, l, opensearch.ExpectedRagVectorSize)
+	}
+	vec32 := (&OpenaiType{}).Float64ToFloat32Slice(embedding)
+
+	// Alguns servidores não informam usage para embeddings: estima localmente
+	usage := openai.CreateEmbeddingResponseUsage{
+		PromptTokens: out.Usage.PromptTokens,
+		TotalTokens:  out.Usage.TotalTokens,
+	}
+	if usage.TotalTokens == 0 {
+		msg := MsgGpt{}
+		msg.CreateMessage("", ROLE_USER, inputTxt)
+		if n, err := tokensCounterO200k(msg); err == nil {
+			usage.PromptTokens = int64(n)
+			usage.TotalTokens = int64(n)
+		}
+	}
+	logger.Log.Infof("Modelo: %s - TOKENS Embeddings - Prompt: %d - Total: %d",
+		out.Model, usage.PromptTokens, usage.TotalTokens)
+
+	return vec32, &usage, nil
+}
+
+// TokensCounter usa o encoding o200k_base como estimativa; o tokenizer real do
+// modelo local pode divergir ligeiramente.
+func (obj *OpenaiCompatType) TokensCounter(inputMsgs MsgGpt) (int, error) {
+	return tokensCounterO200k(inputMsgs)
+}
+
+// ------------------------- Helpers ---------------------------------------
+
+/*
+resolveModelo
+O pipeline informa nomes de modelos da OpenAI (OPENAI_OPTION_MODEL/_TOP). Aqui eles
+são traduzidos para os modelos configurados no servidor local.
+*/
+func (obj *OpenaiCompatType) resolveModelo(modelo string) string {
+	modelo = strings.TrimSpace(modelo)
+	if modelo != "" && modelo == obj.cfg.OpenOptionModelTop && obj.cfg.LLMCompatModelTop != "" {
+		return obj.cfg.LLMCompatModelTop
+	}
+	return obj.cfg.LLMCompatModel
+}
+
+// compatRole: a role "developer" não é reconhecida pela maioria dos servidores.
+func compatRole(role string) string {
+	switch role {
+	case ROLE_ASSISTANT:
+		return ROLE_ASSISTANT
+	case ROLE_SYSTEM, ROLE_DEVELOPER:
+		return ROLE_SYSTEM
+	default:
+		return ROLE_USER
+	}
+}
+
+// postJSON envia a requisição com retry 3x em 429/5xx com backoff.
+func (obj *OpenaiCompatType) postJSON(ctx context.Context, path string, in any, out any) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar requisição: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= 3; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		lastErr = obj.doPost(ctx, path, payload, out)
+		if lastErr == nil {
+			return nil
+		}
+
+		var httpErr *compatHTTPError
+		if errors.As(lastErr, &httpErr) &&
+			(httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500) && attempt < 3 {
+			backoff := erros.RetryBackoff(attempt)
+			logger.Log.Warningf("Erro HTTP %d no servidor compatível. Retentando em %v...", httpErr.StatusCode, backoff)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			continue
+		}
+		break
+	}
+	return lastErr
+}
+
+func (obj *OpenaiCompatType) doPost(ctx context.Context, path string, payload []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, obj.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("erro ao montar requisição: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if obj.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+obj.apiKey)
+	}
+
+	res, err := obj.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro na requisição a %s: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		raw, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return &compatHTTPError{StatusCode: res.StatusCode, Body: strings.TrimSpace(string(raw))}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta de %s: %w", path, err)
+	}
+	return nil
+}
+
+func (obj *OpenaiCompatType) getHistorico(id string) ([]MessageResponseItem, bool) {
+	obj.muHist.Lock()
+	defer obj.muHist.Unlock()
+	h, ok := obj.historico[id]
+	return h, ok
+}
+
+func (obj *OpenaiCompatType) setHistorico(id string, conversa []MessageResponseItem) {
+	obj.muHist.Lock()
+	defer obj.muHist.Unlock()
+
+	obj.historico[id] = conversa
+	obj.ordemHist = append(obj.ordemHist, id)
+
+	// Descarta as conversas mais antigas
+	for len(obj.ordemHist) > COMPAT_MAX_HISTORICO {
+		delete(obj.historico, obj.ordemHist[0])
+		obj.ordemHist = obj.ordemHist[1:]
+	}
+}
@@ -0,0 +1,86 @@
+package ialib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/utils/logger"
+)
+
+func novoCompatTeste(t *testing.T, h http.HandlerFunc) *OpenaiCompatType {
+	t.Helper()
+	logger.InitLoggerGlobal("", false)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return NewOpenaiCompatClient(&config.Config{LLMCompatBaseURL: srv.URL, LLMCompatModelEmbedding: "embed-teste"})
+}
+
+func TestCompatEmbeddingDimensao(t *testing.T) {
+	casos := []struct {
+		nome string
+		dim  int
+		erro string // trecho esperado; vazio: sem erro
+	}{
+		{nome: "dimensão dos índices", dim: opensearch.ExpectedRagVectorSize},
+		{nome: "dimensão menor", dim: 768, erro: "768"},
+		{nome: "dimensão maior", dim: opensearch.ExpectedRagVectorSize + 1, erro: "os índices esperam"},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			compat := novoCompatTeste(t, func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]any{
+					"model": "embed-teste",
+					"data":  []map[string]any{{"embedding": make([]float64, c.dim)}},
+					"usage": map[string]any{"prompt_tokens": 1, "total_tokens": 1},
+				})
+			})
+
+			vec, _, err := compat.GetEmbedding(context.Background(), "texto")
+			if c.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), c.erro) {
+					t.Fatalf("erro = %v, esperado conter %q", err, c.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if len(vec) != c.dim {
+				t.Errorf("vetor com %d dimensões, esperado %d", len(vec), c.dim)
+			}
+		})
+	}
+}
+
+func TestCompatPostJSONCancelaDuranteEspera(t *testing.T) {
+	var chamadas atomic.Int32
+	compat := novoCompatTeste(t, func(w http.ResponseWriter, r *http.Request) {
+		chamadas.Add(1)
+		http.Error(w, "ocupado", http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	inicio := time.Now()
+	err := compat.postJSON(ctx, "/v1/embeddings", map[string]any{}, &map[string]any{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("erro = %v, esperado context.DeadlineExceeded", err)
+	}
+	if d := time.Since(inicio); d > time.Second {
+		t.Errorf("postJSON demorou %v após o cancelamento do contexto", d)
+	}
+	if n := chamadas.Load(); n != 1 {
+		t.Errorf("%d chamadas ao servidor, esperada 1 (sem retentar após o cancelamento)", n)
+	}
+}
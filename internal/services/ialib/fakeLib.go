@@ -0,0 +1,502 @@
+/*
+---------------------------------------------------------------------------------------
+File: fakeLib.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Backend LLMProvider determinístico e offline ("fake"), destinado a
+desenvolvimento e testes automatizados. Devolve respostas JSON pré-definidas,
+escolhidas pela natureza do prompt, e embeddings calculados por hash do texto, sem
+qualquer acesso à rede.
+---------------------------------------------------------------------------------------
+*/
+
+package ialib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/consts"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/utils/logger"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared/constant"
+)
+
+type OpenaiFakeType struct {
+	cfg *config.Config
+
+	// Respostas carregadas de LLM_FAKE_DIR/<natureza>.json, se houver
+	muResp    sync.RWMutex
+	respostas map[int]string
+}
+
+var (
+	OpenaiFakeGlobal   *OpenaiFakeType
+	onceInitOpenaiFake sync.Once
+)
+
+// InitOpenaiFake inicializa o backend fake global uma única vez.
+func InitOpenaiFake(cfg *config.Config) {
+	onceInitOpenaiFake.Do(func() {
+		OpenaiFakeGlobal = NewOpenaiFakeClient(cfg)
+		logger.Log.Warning("Global OpenaiFake configurado: respostas de IA SIMULADAS (modo offline).")
+	})
+}
+
+func NewOpenaiFakeClient(cfg *config.Config) *OpenaiFakeType {
+	obj := &OpenaiFakeType{
+		cfg:       cfg,
+		respostas: make(map[int]string),
+	}
+	if cfg != nil && cfg.LLMFakeDir != "" {
+		obj.carregaRespostas(cfg.LLMFakeDir)
+	}
+	return obj
+}
+
+// ------------------------- Natureza do prompt no contexto ----------------
+
+type ctxKeyFake int
+
+const (
+	ctxKeyNaturezaPrompt ctxKeyFake = iota
+	ctxKeyDocumento
+)
+
+// DocumentoInfo identifica o documento submetido ao modelo (autuação/classificação).
+type DocumentoInfo struct {
+	IdNatu int
+	IdPje  string
+}
+
+/*
+WithNaturezaPrompt
+Anexa ao contexto a natureza do prompt (consts.PROMPT_*) que está sendo submetido.
+Os backends reais ignoram a informação; o backend fake a utiliza para escolher a
+resposta pré-definida.
+*/
+func WithNaturezaPrompt(ctx context.Context, natuPrompt int) context.Context {
+	return context.WithValue(ctx, ctxKeyNaturezaPrompt, natuPrompt)
+}
+
+// WithDocumento anexa ao contexto a natureza (consts.NATU_DOC_*) e o id_pje do documento em análise.
+func WithDocumento(ctx context.Context, idNatu int, idPje string) context.Context {
+	return context.WithValue(ctx, ctxKeyDocumento, DocumentoInfo{IdNatu: idNatu, IdPje: idPje})
+}
+
+func NaturezaPromptFromContext(ctx context.Context) (int, bool) {
+	v, ok := ctx.Value(ctxKeyNaturezaPrompt).(int)
+	return v, ok
+}
+
+func DocumentoFromContext(ctx context.Context) (DocumentoInfo, bool) {
+	v, ok := ctx.Value(ctxKeyDocumento).(DocumentoInfo)
+	return v, ok
+}
+
+// ------------------------- LLMProvider -----------------------------------
+
+func (obj *OpenaiFakeType) Nome() string { return PROVIDER_FAKE }
+
+// SuportaFormatoJSON: as respostas simuladas são ajustadas ao schema (ajustaAoSchema)
+func (obj *OpenaiFakeType) SuportaFormatoJSON() bool { return true }
+
+func (obj *OpenaiFakeType) SubmitPrompt(
+	ctx context.Context,
+	inputMsgs MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+) (*responses.Response, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("serviço OpenaiFake não iniciado")
+	}
+
+	msgs := inputMsgs.GetMessages()
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("lista de mensagens vazia")
+	}
+
+	natuPrompt, _ := NaturezaPromptFromContext(ctx)
+	texto := obj.montaResposta(ctx, natuPrompt, msgs)
+	if formato := FormatoJSONFromContext(ctx); formato != nil {
+		texto = ajustaAoSchema(texto, formato.Schema)
+	}
+
+	// ID determinístico: mesma conversa => mesmo ID
+	h := sha256.New()
+	h.Write([]byte(prevID))
+	for _, m := range msgs {
+		h.Write([]byte(m.Role))
+		h.Write([]byte(m.Text))
+	}
+	idResp := "fake_" + hex.EncodeToString(h.Sum(nil))[:32]
+
+	inTokens, _ := tokensCounterO200k(inputMsgs)
+	outMsg := MsgGpt{}
+	outMsg.CreateMessage("", ROLE_ASSISTANT, texto)
+	outTokens, _ := tokensCounterO200k(outMsg)
+
+	logger.Log.Infof("Modelo: %s - natureza prompt=%d - TOKENS (estimados) - Input: %d - Output: %d",
+		PROVIDER_FAKE, natuPrompt, inTokens, outTokens)
+
+	return &responses.Response{
+		ID:     idResp,
+		Model:  PROVIDER_FAKE,
+		Status: responses.ResponseStatusCompleted,
+		Output: []responses.ResponseOutputItemUnion{
+			{
+				ID:     idResp,
+				Type:   "message",
+				Role:   constant.Assistant(openai.MessageRoleAssistant),
+				Status: "completed",
+				Content: []responses.ResponseOutputMessageContentUnion{
+					{Type: "output_text", Text: texto},
+				},
+			},
+		},
+		Usage: responses.ResponseUsage{
+			InputTokens:  int64(inTokens),
+			OutputTokens: int64(outTokens),
+			TotalTokens:  int64(inTokens + outTokens),
+		},
+	}, nil
+}
+
+/*
+GetEmbedding
+Embedding determinístico por "feature hashing": cada palavra normalizada do texto
+incrementa (ou decrementa) uma posição do vetor escolhida pelo seu hash. Textos com
+vocabulário semelhante produzem vetores próximos, o que mantém a busca semântica
+minimamente coerente sem rede.
+*/
+func (obj *OpenaiFakeType) GetEmbedding(
+	ctx context.Context,
+	inputTxt string,
+) ([]float32, *openai.CreateEmbeddingResponseUsage, error) {
+	if obj == nil {
+		return nil, nil, fmt.Errorf("serviço OpenaiFake não iniciado")
+	}
+
+	dim := opensearch.ExpectedRagVectorSize
+	vec := make([]float64, dim)
+
+	for _, palavra := range palavrasNormalizadas(inputTxt) {
+		hs := fnv.New64a()
+		hs.Write([]byte(palavra))
+		v := hs.Sum64()
+		idx := int(v % uint64(dim))
+		if v>>63 == 1 {
+			vec[idx] -= 1
+		} else {
+			vec[idx] += 1
+		}
+	}
+
+	// Normaliza (L2); vetor nulo não é aceito pela similaridade de cosseno
+	var norma float64
+	for _, x := range vec {
+		norma += x * x
+	}
+	if norma == 0 {
+		vec[0] = 1
+		norma = 1
+	}
+	norma = math.Sqrt(norma)
+
+	vec32 := make([]float32, dim)
+	for i, x := range vec {
+		vec32[i] = float32(x / norma)
+	}
+
+	msg := MsgGpt{}
+	msg.CreateMessage("", ROLE_USER, inputTxt)
+	n, _ := tokensCounterO200k(msg)
+	usage := openai.CreateEmbeddingResponseUsage{PromptTokens: int64(n), TotalTokens: int64(n)}
+
+	return vec32, &usage, nil
+}
+
+func (obj *OpenaiFakeType) TokensCounter(inputMsgs MsgGpt) (int, error) {
+	return tokensCounterO200k(inputMsgs)
+}
+
+// ------------------------- Respostas -------------------------------------
+
+func (obj *OpenaiFakeType) carregaRespostas(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		logger.Log.Warningf("LLM_FAKE_DIR inacessível (%s): %v", dir, err)
+		return
+	}
+	obj.muResp.Lock()
+	defer obj.muResp.Unlock()
+
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		natu, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			logger.Log.Warningf("Erro ao ler resposta fake %s: %v", e.Name(), err)
+			continue
+		}
+		obj.respostas[natu] = string(raw)
+		logger.Log.Infof("Resposta fake carregada: natureza=%d (%s)", natu, e.Name())
+	}
+}
+
+func (obj *OpenaiFakeType) getRespostaArquivo(natu int) (string, bool) {
+	obj.muResp.RLock()
+	defer obj.muResp.RUnlock()
+	r, ok := obj.respostas[natu]
+	return r, ok
+}
+
+/*
+montaResposta
+Escolhe a resposta pela natureza do prompt. As respostas de LLM_FAKE_DIR têm
+precedência sobre as embutidas. Em ambas, os marcadores {{ID_PJE}}, {{KEY}},
+{{DESCRIPTION}} e {{TEXTO_USUARIO}} são substituídos pelos valores da chamada.
+*/
+func (obj *OpenaiFakeType) montaResposta(ctx context.Context, natuPrompt int, msgs []MessageResponseItem) string {
+	ultimo := ultimaMensagemUsuario(msgs)
+
+	doc, _ := DocumentoFromContext(ctx)
+	key := doc.IdNatu
+	switch {
+	case natuPrompt == consts.PROMPT_AUTUACAO_SENTENCA:
+		key = consts.NATU_DOC_SENTENCA
+	case natuPrompt == consts.PROMPT_AUTUACAO_CERTIDAO:
+		key = consts.NATU_DOC_CERTIDAO
+	case key <= 0:
+		key = classificaTextoFake(ultimo)
+	}
+
+	modelo, ok := obj.getRespostaArquivo(natuPrompt)
+	if !ok {
+		switch natuPrompt {
+		case consts.PROMPT_RAG_IDENTIFICA:
+			modelo = respostaIdentificaFake(ultimo)
+		default:
+			modelo, ok = fakeRespostas[natuPrompt]
+			if !ok {
+				modelo = fakeRespostaTexto
+			}
+		}
+	}
+
+	idPje := doc.IdPje
+	if idPje == "" {
+		h := sha256.Sum256([]byte(ultimo))
+		idPje = strconv.FormatUint(uint64(h[0])<<24|uint64(h[1])<<16|uint64(h[2])<<8|uint64(h[3]), 10)
+	}
+
+	r := strings.NewReplacer(
+		"{{ID_PJE}}", idPje,
+		"{{KEY}}", strconv.Itoa(key),
+		"{{DESCRIPTION}}", jsonEscape(consts.GetNaturezaDocumento(key)),
+		"{{TEXTO_USUARIO}}", jsonEscape(resumo(ultimo, 200)),
+	)
+	return r.Replace(modelo)
+}
+
+// respostaIdentificaFake decide o evento pelas palavras da última mensagem do usuário.
+func respostaIdentificaFake(texto string) string {
+	t := strings.Join(palavrasNormalizadas(texto), " ")
+
+	evento, descricao := 999, "outros"
+	switch {
+	case strings.Contains(t, "base") && (strings.Contains(t, "adicion") || strings.Contains(t, "inclu") || strings.Contains(t, "salv")):
+		evento, descricao = 302, "adicionar à base de conhecimento"
+	case strings.Contains(t, "respost") || strings.Contains(t, "complement"):
+		evento, descricao = 301, "complementação de informações"
+	case strings.Contains(t, "decisao") || strings.Contains(t, "tutela") || strings.Contains(t, "liminar"):
+		evento, descricao = 203, "minuta de decisão"
+	case strings.Contains(t, "despacho"):
+		evento, descricao = 204, "minuta de despacho"
+	case strings.Contains(t, "sentenc") || strings.Contains(t, "minuta") || strings.Contains(t, "julg"):
+		evento, descricao = 202, "minuta de sentença"
+	case strings.Contains(t, "anali"):
+		evento, descricao = 201, "análise jurídica"
+	case strings.Contains(t, "conceit"):
+		evento, descricao = 205, "conceitos"
+	}
+	return fmt.Sprintf(`{"tipo":{"evento":%d,"descricao":%q},"confirmacao":""}`, evento, descricao)
+}
+
+// classificaTextoFake faz uma classificação simples por palavras-chave no início do texto.
+func classificaTextoFake(texto string) int {
+	t := strings.Join(palavrasNormalizadas(resumo(texto, 2000)), " ")
+	regras := []struct {
+		termo string
+		key   int
+	}{
+		{"peticao inicial", consts.NATU_DOC_INICIAL},
+		{"contestacao", consts.NATU_DOC_CONTESTACAO},
+		{"replica", consts.NATU_DOC_REPLICA},
+		{"sentenca", consts.NATU_DOC_SENTENCA},
+		{"embargos", consts.NATU_DOC_EMBARGOS},
+		{"apelacao", consts.NATU_DOC_APELACAO},
+		{"procuracao", consts.NATU_DOC_PROCURACAO},
+		{"laudo", consts.NATU_DOC_LAUDO_PERICIAL},
+		{"audiencia", consts.NATU_DOC_TERMO_AUDIENCIA},
+		{"certifico", consts.NATU_DOC_CERTIDAO},
+		{"decisao", consts.NATU_DOC_DECISAO},
+		{"despacho", consts.NATU_DOC_DESPACHO},
+	}
+	for _, r := range regras {
+		if strings.Contains(t, r.termo) {
+			return r.key
+		}
+	}
+	return consts.NATU_DOC_PETICAO
+}
+
+// ------------------------- Helpers ---------------------------------------
+
+func ultimaMensagemUsuario(msgs []MessageResponseItem) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == ROLE_USER && strings.TrimSpace(msgs[i].Text) != "" {
+			return msgs[i].Text
+		}
+	}
+	return msgs[len(msgs)-1].Text
+}
+
+// palavrasNormalizadas: minúsculas, sem acentos, apenas letras e dígitos.
+func palavrasNormalizadas(s string) []string {
+	s = strings.ToLower(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case 'á', 'à', 'ã', 'â', 'ä':
+			return 'a'
+		case 'é', 'è', 'ê', 'ë':
+			return 'e'
+		case 'í', 'ì', 'î', 'ï':
+			return 'i'
+		case 'ó', 'ò', 'õ', 'ô', 'ö':
+			return 'o'
+		case 'ú', 'ù', 'û', 'ü':
+			return 'u'
+		case 'ç':
+			return 'c'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Fields(s)
+}
+
+func resumo(s string, max int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= max {
+		return string(r)
+	}
+	return string(r[:max])
+}
+
+// jsonEscape escapa o texto para inclusão dentro de uma string JSON já delimitada.
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+/*
+ajustaAoSchema adapta a resposta pré-definida à saída estruturada pedida, como faria o
+modelo no modo estrito: mantém os campos previstos no schema, preenche os ausentes com
+valores vazios e descarta os demais. Resposta que não é JSON segue inalterada.
+*/
+func ajustaAoSchema(texto string, schema map[string]any) string {
+	var valor any
+	if err := json.Unmarshal([]byte(texto), &valor); err != nil {
+		return texto
+	}
+	b, err := json.Marshal(conformaAoSchema(schema, valor))
+	if err != nil {
+		return texto
+	}
+	return string(b)
+}
+
+func conformaAoSchema(schema map[string]any, valor any) any {
+	tipos := tiposDoSchema(schema["type"])
+	if len(tipos) == 0 {
+		return valor
+	}
+	switch tipos[0] {
+	case "object":
+		origem, _ := valor.(map[string]any)
+		out := map[string]any{}
+		props, _ := schema["properties"].(map[string]any)
+		for nome, sub := range props {
+			subSchema, _ := sub.(map[string]any)
+			out[nome] = conformaAoSchema(subSchema, origem[nome])
+		}
+		if adicional, ok := schema["additionalProperties"].(bool); !ok || adicional {
+			for nome, v := range origem {
+				if _, previsto := props[nome]; !previsto {
+					out[nome] = v
+				}
+			}
+		}
+		return out
+	case "array":
+		itens, _ := schema["items"].(map[string]any)
+		out := []any{}
+		if lista, ok := valor.([]any); ok {
+			for _, item := range lista {
+				out = append(out, conformaAoSchema(itens, item))
+			}
+		} else if valor != nil {
+			out = append(out, conformaAoSchema(itens, valor))
+		}
+		return out
+	case "string":
+		switch v := valor.(type) {
+		case string:
+			return v
+		case nil:
+			return ""
+		case []any:
+			partes := make([]string, 0, len(v))
+			for _, item := range v {
+				partes = append(partes, fmt.Sprint(item))
+			}
+			return strings.Join(partes, "\n")
+		default:
+			return fmt.Sprint(v)
+		}
+	case "integer", "number":
+		if n, ok := valor.(float64); ok {
+			return n
+		}
+		return 0
+	case "boolean":
+		b, _ := valor.(bool)
+		return b
+	}
+	return valor
+}
@@ -0,0 +1,171 @@
+/*
+---------------------------------------------------------------------------------------
+File: fakeRespostas.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Respostas pré-definidas do backend fake, indexadas pela natureza do
+prompt. Seguem os formatos JSON esperados pelo pipeline (ConfirmaEvento,
+AnaliseJuridicaIA, ComplementoEvento, RespostaExtraida, MinutaSentenca, MinutaDecisao,
+MinutaDespacho, SentencaAutos, DocumentoBase).
+---------------------------------------------------------------------------------------
+*/
+
+package ialib
+
+import "ocrserver/internal/consts"
+
+// Resposta textual padrão para prompts sem JSON (diálogo livre, chat).
+const fakeRespostaTexto = `[modo offline] Resposta simulada para: "{{TEXTO_USUARIO}}"`
+
+var fakeRespostas = map[int]string{
+
+	// Classificação da natureza do documento (saneamento)
+	consts.PROMPT_AUTUACAO_NATUREZA: `{"key": {{KEY}}, "description": "{{DESCRIPTION}}"}`,
+
+	// Autuação genérica (DocumentoBase)
+	consts.PROMPT_ANALISE_AUTUACAO: `{
+  "tipo": {"key": {{KEY}}, "description": "{{DESCRIPTION}}"},
+  "processo": "0000000-00.2026.8.06.0001",
+  "id_pje": "{{ID_PJE}}",
+  "assinatura_data": "01/01/2026",
+  "assinatura_por": "Assinante Simulado"
+}`,
+
+	// Autuação de certidão
+	consts.PROMPT_AUTUACAO_CERTIDAO: `{
+  "tipo": {"key": {{KEY}}, "description": "{{DESCRIPTION}}"},
+  "processo": "0000000-00.2026.8.06.0001",
+  "id_pje": "{{ID_PJE}}",
+  "assinatura_data": "01/01/2026",
+  "assinatura_por": "Servidor Simulado",
+  "conteudo": "Certifico, para os devidos fins, o decurso do prazo."
+}`,
+
+	// Autuação de sentença (SentencaAutos) - alimenta o fluxo de inclusão na base
+	consts.PROMPT_AUTUACAO_SENTENCA: `{
+  "tipo": {"key": {{KEY}}, "description": "{{DESCRIPTION}}"},
+  "processo": "0000000-00.2026.8.06.0001",
+  "id_pje": "{{ID_PJE}}",
+  "assinatura_data": "01/01/2026",
+  "assinatura_por": "Juiz Simulado",
+  "metadados": {
+    "classe": "Procedimento Comum Cível",
+    "assunto": "Indenização por Dano Moral",
+    "juizo": "1ª Vara Cível",
+    "partes": {"autor": ["Autor Simulado"], "reu": ["Réu Simulado"]}
+  },
+  "questoes": [
+    {
+      "tipo": "preliminar",
+      "tema": "Ilegitimidade passiva",
+      "paragrafos": ["Rejeito a preliminar de ilegitimidade passiva, pois o réu integra a cadeia de fornecimento."],
+      "decisao": "rejeitada"
+    },
+    {
+      "tipo": "mérito",
+      "tema": "Dano moral por negativação indevida",
+      "paragrafos": ["A inscrição indevida em cadastro de inadimplentes gera dano moral in re ipsa."],
+      "decisao": "procedente"
+    }
+  ],
+  "dispositivo": {"paragrafos": ["Julgo procedente o pedido para condenar o réu ao pagamento de R$ 5.000,00."]}
+}`,
+
+	// Análise jurídica / pré-análise (AnaliseJuridicaIA)
+	consts.PROMPT_RAG_ANALISE: `{
+  "tipo": {"evento": 201, "descricao": "análise jurídica"},
+  "identificacao": {"numero_processo": "0000000-00.2026.8.06.0001", "natureza": "Procedimento Comum Cível"},
+  "partes": {"autor": ["Autor Simulado"], "reu": ["Réu Simulado"]},
+  "sintese_fatos": {"autor": "Alega inscrição indevida em cadastro de inadimplentes.", "reu": "Sustenta a regularidade da cobrança."},
+  "pedidos_autor": ["Declaração de inexistência do débito", "Indenização por danos morais"],
+  "defesas_reu": {
+    "preliminares": ["Ilegitimidade passiva"],
+    "prejudiciais_merito": [],
+    "defesa_merito": ["Exercício regular de direito"],
+    "pedidos_reu": ["Improcedência dos pedidos"]
+  },
+  "questoes_controvertidas": [
+    {"descricao": "Existência da relação contratual", "pergunta_ao_usuario": "Há prova da contratação nos autos?"}
+  ],
+  "provas": {"autor": ["Extrato do cadastro de inadimplentes"], "reu": ["Telas do sistema interno"]},
+  "fundamentacao_juridica": {
+    "autor": ["Art. 14 do CDC"],
+    "reu": ["Art. 188, I, do Código Civil"],
+    "jurisprudencia": [
+      {"tribunal": "STJ", "processo": "REsp 0000000", "tema": "Dano moral in re ipsa", "ementa": "A inscrição indevida gera dano moral presumido."}
+    ]
+  },
+  "decisoes_interlocutorias": [],
+  "andamento_processual": ["Citação realizada", "Contestação apresentada"],
+  "valor_da_causa": "R$ 10.000,00",
+  "observacoes": ["Resposta gerada em modo offline"],
+  "rag": [
+    {"tema": "Dano moral por negativação indevida", "descricao": "Responsabilidade do fornecedor pela inscrição indevida.", "relevancia": "alta", "base": "CDC"},
+    {"tema": "Ilegitimidade passiva", "descricao": "Cadeia de fornecimento e solidariedade.", "relevancia": "média", "base": "CDC"}
+  ]
+}`,
+
+	// Verificação das questões controvertidas (ComplementoEvento): nada pendente
+	consts.PROMPT_RAG_COMPLEMENTA_JULGAMENTO: `{"tipo": {"evento": 202, "descricao": "minuta de sentença"}, "faltantes": []}`,
+
+	// Resumo de bloco dos autos (map-reduce dos documentos extensos)
+	consts.PROMPT_RAG_RESUMO_AUTOS: `[modo offline] Resumo: {{TEXTO_USUARIO}}`,
+
+	// Reordenação da base de conhecimentos: somente o primeiro trecho é considerado pertinente
+	consts.PROMPT_RAG_RERANK: `{"notas": [{"indice": 1, "nota": 8}]}`,
+
+	// Respostas às questões controvertidas: a última mensagem responde à primeira pergunta
+	consts.PROMPT_RAG_RESPOSTAS_QUESTOES: `{"respostas": [{"indice": 1, "resposta": "{{TEXTO_USUARIO}}"}]}`,
+
+	// Minuta de sentença (MinutaSentenca)
+	consts.PROMPT_RAG_JULGAMENTO: `{
+  "tipo": {"evento": 202, "descricao": "minuta de sentença"},
+  "processo": {"numero": "0000000-00.2026.8.06.0001", "classe": "Procedimento Comum Cível", "assunto": "Indenização por Dano Moral"},
+  "partes": {"autor": ["Autor Simulado"], "reu": ["Réu Simulado"]},
+  "relatorio": ["Trata-se de ação declaratória cumulada com indenização por danos morais."],
+  "fundamentacao": {
+    "preliminares": ["Rejeito a preliminar de ilegitimidade passiva."],
+    "merito": ["A inscrição indevida em cadastro de inadimplentes gera dano moral in re ipsa."],
+    "doutrina": [],
+    "jurisprudencia": {"sumulas": ["Súmula 385/STJ"], "acordaos": []}
+  },
+  "dispositivo": {
+    "decisao": "Julgo procedentes os pedidos.",
+    "condenacoes": ["Condeno o réu ao pagamento de R$ 5.000,00 a título de danos morais."],
+    "honorarios": "10% sobre o valor da condenação.",
+    "custas": "Pelo réu."
+  },
+  "observacoes": ["Minuta gerada em modo offline"]
+}`,
+
+	// Minuta de decisão interlocutória (MinutaDecisao)
+	consts.PROMPT_RAG_DECISAO: `{
+  "tipo": {"evento": 203, "descricao": "minuta de decisão"},
+  "processo": {"numero": "0000000-00.2026.8.06.0001", "classe": "Procedimento Comum Cível", "assunto": "Indenização por Dano Moral"},
+  "partes": {"autor": ["Autor Simulado"], "reu": ["Réu Simulado"]},
+  "relatorio": ["Trata-se de pedido de tutela de urgência para exclusão do nome do autor do cadastro de inadimplentes."],
+  "questoes": [{"pedido": "Tutela de urgência para exclusão da negativação", "resultado": "deferido"}],
+  "fundamentacao": {
+    "merito": ["Presentes a probabilidade do direito e o perigo de dano, nos termos do art. 300 do CPC."]
+  },
+  "dispositivo": {
+    "decisao": "Defiro a tutela de urgência.",
+    "determinacoes": [
+      {"providencia": "Excluir o nome do autor dos cadastros de inadimplentes", "destinatario": "Réu", "prazo": "5 dias"},
+      {"providencia": "Citar o réu", "destinatario": "Secretaria"}
+    ]
+  },
+  "observacoes": ["Minuta gerada em modo offline"]
+}`,
+
+	// Minuta de despacho (MinutaDespacho)
+	consts.PROMPT_RAG_DESPACHO: `{
+  "tipo": {"evento": 204, "descricao": "minuta de despacho"},
+  "processo": {"numero": "0000000-00.2026.8.06.0001", "classe": "Procedimento Comum Cível", "assunto": "Indenização por Dano Moral"},
+  "finalidade": "Intimação do autor para réplica",
+  "determinacoes": [
+    {"providencia": "Intime-se a parte autora para apresentar réplica à contestação", "destinatario": "Autor", "prazo": "15 dias"}
+  ],
+  "observacoes": ["Minuta gerada em modo offline"]
+}`,
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"ocrserver/internal/config"
 	"ocrserver/internal/services/tools"
@@ -219,28 +220,8 @@ func (obj *OpenaiType) SubmitPromptResponse_openai(
 		defer cancel()
 	}
 
-	items := make([]responses.ResponseInputItemUnionParam, 0, len(msgs))
-	for _, it := range msgs {
-		items = append(items, responses.ResponseInputItemUnionParam{OfMessage: toEasyInputMessage(it)})
-	}
-
-	model := strings.TrimSpace(modelo)
-	if model == "" {
-		model = obj.cfg.OpenOptionModel
-	}
-	logger.Log.Infof("Modelo de IA: %s", modelo)
-
-	params := responses.ResponseNewParams{
-		Model:           model,
-		Reasoning:       responses.ReasoningParam{Effort: effort},
-		MaxOutputTokens: openai.Int(int64(config.GlobalConfig.OpenOptionMaxCompletionTokens)),
-		Input:           responses.ResponseNewParamsInputUnion{OfInputItemList: items},
-		Text:            responses.ResponseTextConfigParam{Verbosity: verbosity},
-		PromptCacheKey:  param.Opt[string]{Value: "24h"},
-	}
-	if prevID != "" {
-		params.PreviousResponseID = openai.String(prevID)
-	}
+	params := obj.montaParamsResponse(msgs, prevID, modelo, effort, verbosity)
+	aplicaFormatoJSON(ctx, &params)
 
 	var resp *responses.Response
 	var err error
@@ -267,7 +248,7 @@ func (obj *OpenaiType) SubmitPromptResponse_openai(
 				time.Sleep(erros.RetryBackoff(attempt))
 				continue
 			}
-			return nil, fmt.Errorf("tempo limite excedido ao aguardar resposta da OpenAI")
+			return nil, fmt.Errorf("tempo limite excedido ao aguardar resposta da OpenAI: %w", err)
 		}
 
 		// 🚦 Rate limit 429 ou erros 5xx → retry
@@ -295,11 +276,64 @@ func (obj *OpenaiType) SubmitPromptResponse_openai(
 	return resp, nil
 }
 
+// aplicaFormatoJSON pede ao modelo a saída estruturada, se o contexto trouxer o formato (WithFormatoJSON).
+func aplicaFormatoJSON(ctx context.Context, params *responses.ResponseNewParams) {
+	formato := FormatoJSONFromContext(ctx)
+	if formato == nil {
+		return
+	}
+	params.Text.Format = responses.ResponseFormatTextConfigUnionParam{
+		OfJSONSchema: &responses.ResponseFormatTextJSONSchemaConfigParam{
+			Name:   formato.Nome,
+			Schema: formato.Schema,
+			Strict: openai.Bool(formato.Estrito),
+		},
+	}
+}
+
+// montaParamsResponse monta os parâmetros da Responses API a partir das mensagens.
+func (obj *OpenaiType) montaParamsResponse(
+	msgs []MessageResponseItem,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+) responses.ResponseNewParams {
+	items := make([]responses.ResponseInputItemUnionParam, 0, len(msgs))
+	for _, it := range msgs {
+		items = append(items, responses.ResponseInputItemUnionParam{OfMessage: toEasyInputMessage(it)})
+	}
+
+	model := strings.TrimSpace(modelo)
+	if model == "" {
+		model = obj.cfg.OpenOptionModel
+	}
+	logger.Log.Infof("Modelo de IA: %s", modelo)
+
+	params := responses.ResponseNewParams{
+		Model:           model,
+		Reasoning:       responses.ReasoningParam{Effort: effort},
+		MaxOutputTokens: openai.Int(int64(config.GlobalConfig.OpenOptionMaxCompletionTokens)),
+		Input:           responses.ResponseNewParamsInputUnion{OfInputItemList: items},
+		Text:            responses.ResponseTextConfigParam{Verbosity: verbosity},
+		PromptCacheKey:  param.Opt[string]{Value: "24h"},
+	}
+	if prevID != "" {
+		params.PreviousResponseID = openai.String(prevID)
+	}
+	return params
+}
+
 /*
 TokensCounter
 Calcula estimativa de tokens em um conjunto de mensagens.
 */
 func (obj *OpenaiType) TokensCounter(inputMsgs MsgGpt) (int, error) {
+	return tokensCounterO200k(inputMsgs)
+}
+
+// tokensCounterO200k usa o encoding o200k_base como estimativa comum a todos os backends.
+func tokensCounterO200k(inputMsgs MsgGpt) (int, error) {
 	msgs := inputMsgs.GetMessages()
 	if len(msgs) == 0 {
 		return 0, fmt.Errorf("lista de mensagens vazia")
@@ -326,6 +360,84 @@ func (obj *OpenaiType) StringTokensCounter(inputStr string) (int, error) {
 	return obj.TokensCounter(msg)
 }
 
+// ContaTokens conta os tokens de um texto (o200k_base), sem o ajuste por mensagem.
+func ContaTokens(texto string) (int, error) {
+	enc, err := tokenizer.Get(tokenizer.Encoding(tokenizer.O200kBase))
+	if err != nil {
+		return 0, fmt.Errorf("falha ao obter tokenizer: %w", err)
+	}
+	ids, _, err := enc.Encode(texto)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao codificar texto: %w", err)
+	}
+	return len(ids), nil
+}
+
+/*
+TruncaTokens devolve o início do texto com no máximo maxTokens tokens. O corte é feito
+na fronteira de tokens e nunca divide um caractere UTF-8.
+*/
+func TruncaTokens(texto string, maxTokens int) (string, error) {
+	enc, err := tokenizer.Get(tokenizer.Encoding(tokenizer.O200kBase))
+	if err != nil {
+		return "", fmt.Errorf("falha ao obter tokenizer: %w", err)
+	}
+	ids, _, err := enc.Encode(texto)
+	if err != nil {
+		return "", fmt.Errorf("falha ao codificar texto: %w", err)
+	}
+	if len(ids) <= maxTokens {
+		return texto, nil
+	}
+	if maxTokens <= 0 {
+		return "", nil
+	}
+	parte, err := enc.Decode(ids[:maxTokens])
+	if err != nil {
+		return "", fmt.Errorf("falha ao decodificar tokens: %w", err)
+	}
+	// Um token pode conter apenas parte de um caractere multibyte
+	for len(parte) > 0 && !utf8.ValidString(parte) {
+		parte = parte[:len(parte)-1]
+	}
+	return parte, nil
+}
+
+/*
+DivideTokens divide o texto em partes consecutivas de no máximo maxTokens tokens. O texto
+é codificado uma única vez; a fronteira de cada parte recua quando cairia no meio de um
+caractere UTF-8.
+*/
+func DivideTokens(texto string, maxTokens int) ([]string, error) {
+	if maxTokens <= 0 {
+		return nil, fmt.Errorf("maxTokens inválido: %d", maxTokens)
+	}
+	enc, err := tokenizer.Get(tokenizer.Encoding(tokenizer.O200kBase))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter tokenizer: %w", err)
+	}
+	ids, _, err := enc.Encode(texto)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao codificar texto: %w", err)
+	}
+
+	var partes []string
+	for ini := 0; ini < len(ids); {
+		fim := min(ini+maxTokens, len(ids))
+		parte, err := enc.Decode(ids[ini:fim])
+		for err == nil && fim < len(ids) && fim > ini+1 && !utf8.ValidString(parte) {
+			fim--
+			parte, err = enc.Decode(ids[ini:fim])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("falha ao decodificar tokens: %w", err)
+		}
+		partes = append(partes, parte)
+		ini = fim
+	}
+	return partes, nil
+}
+
 func (obj *OpenaiType) Float64ToFloat32Slice(input []float64) []float32 {
 	out := make([]float32, len(input))
 	for i, v := range input {
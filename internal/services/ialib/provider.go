@@ -0,0 +1,154 @@
+/*
+---------------------------------------------------------------------------------------
+File: provider.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Define a interface LLMProvider, que abstrai o backend de IA (geração,
+embeddings e contagem de tokens), permitindo escolher, por tarefa, entre a OpenAI e
+um servidor compatível (vLLM/Ollama) hospedado localmente.
+---------------------------------------------------------------------------------------
+*/
+
+package ialib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// Backends disponíveis
+const (
+	PROVIDER_OPENAI = "openai" // API oficial da OpenAI (Responses API)
+	PROVIDER_COMPAT = "compat" // servidor compatível: /v1/chat/completions + /v1/embeddings
+	PROVIDER_FAKE   = "fake"   // respostas determinísticas, sem rede (desenvolvimento/testes)
+)
+
+// Tarefas que podem ser atribuídas a provedores distintos
+const (
+	TAREFA_GERACAO   = "geracao"
+	TAREFA_EMBEDDING = "embedding"
+)
+
+/*
+LLMProvider
+Contrato mínimo que um backend de IA deve cumprir. As respostas de geração são
+sempre devolvidas no formato *responses.Response, de modo que o pipeline
+(OrquestradorType, GeneratorType etc.) não precise conhecer o backend utilizado.
+*/
+type LLMProvider interface {
+	Nome() string
+
+	SubmitPrompt(
+		ctx context.Context,
+		inputMsgs MsgGpt,
+		prevID string,
+		modelo string,
+		effort responses.ReasoningEffort,
+		verbosity responses.ResponseTextConfigVerbosity,
+	) (*responses.Response, error)
+
+	GetEmbedding(
+		ctx context.Context,
+		inputTxt string,
+	) ([]float32, *openai.CreateEmbeddingResponseUsage, error)
+
+	TokensCounter(inputMsgs MsgGpt) (int, error)
+}
+
+/*
+LLMFormatoProvider é implementado pelos provedores que sabem se o backend aceita a
+saída estruturada (JSON Schema, WithFormatoJSON). Os demais recebem o schema no prompt
+e têm a resposta extraída e validada (OpenaiServiceType.SubmitPromptResponse).
+*/
+type LLMFormatoProvider interface {
+	SuportaFormatoJSON() bool
+}
+
+// SuportaFormatoJSON informa se o provedor aceita a saída estruturada
+func SuportaFormatoJSON(p LLMProvider) bool {
+	fp, ok := p.(LLMFormatoProvider)
+	return ok && fp.SuportaFormatoJSON()
+}
+
+// GetProvider devolve o backend global correspondente ao nome informado.
+func GetProvider(nome string) (LLMProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(nome)) {
+	case "", PROVIDER_OPENAI:
+		if OpenaiGlobal == nil {
+			return nil, fmt.Errorf("provedor %q não inicializado", PROVIDER_OPENAI)
+		}
+		return OpenaiGlobal, nil
+	case PROVIDER_COMPAT:
+		if OpenaiCompatGlobal == nil {
+			return nil, fmt.Errorf("provedor %q não inicializado", PROVIDER_COMPAT)
+		}
+		return OpenaiCompatGlobal, nil
+	case PROVIDER_FAKE:
+		if OpenaiFakeGlobal == nil {
+			return nil, fmt.Errorf("provedor %q não inicializado", PROVIDER_FAKE)
+		}
+		return OpenaiFakeGlobal, nil
+	default:
+		return nil, fmt.Errorf("provedor de IA desconhecido: %q", nome)
+	}
+}
+
+/*
+ErroTransitorio indica falha do backend que tende a passar com uma nova tentativa mais
+espaçada: limite de requisições (429), erro do servidor (5xx) ou tempo limite. Os
+provedores já repetem a chamada algumas vezes, em intervalos curtos; o chamador decide
+se vale insistir (ex.: a autuação, documento a documento).
+*/
+func ErroTransitorio(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var httpErr *compatHTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	return false
+}
+
+// ------------------- OpenaiType como LLMProvider -------------------------
+
+func (obj *OpenaiType) Nome() string { return PROVIDER_OPENAI }
+
+// SuportaFormatoJSON: a Responses API aceita o JSON Schema no modo estrito
+func (obj *OpenaiType) SuportaFormatoJSON() bool { return true }
+
+func (obj *OpenaiType) SubmitPrompt(
+	ctx context.Context,
+	inputMsgs MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+) (*responses.Response, error) {
+	return obj.SubmitPromptResponse_openai(ctx, inputMsgs, prevID, modelo, effort, verbosity)
+}
+
+func (obj *OpenaiType) GetEmbedding(
+	ctx context.Context,
+	inputTxt string,
+) ([]float32, *openai.CreateEmbeddingResponseUsage, error) {
+	vec32, resp, err := obj.GetEmbeddingFromText_openai(ctx, inputTxt)
+	if err != nil {
+		return nil, nil, err
+	}
+	usage := resp.Usage
+	return vec32, &usage, nil
+}
@@ -0,0 +1,326 @@
+/*
+---------------------------------------------------------------------------------------
+File: schemaLib.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Saída estruturada (JSON Schema) das chamadas ao modelo. O schema é gerado
+a partir dos tipos Go que recebem a resposta (tags json), no subconjunto aceito pelo
+modo estrito da OpenAI: todos os campos obrigatórios e nenhum campo adicional. O
+formato segue pelo contexto (WithFormatoJSON) até o provedor, que o repassa ao modelo
+quando suportado; ValidaJSON confere a resposta antes do uso.
+---------------------------------------------------------------------------------------
+*/
+
+package ialib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// FormatoJSON descreve a resposta esperada do modelo
+type FormatoJSON struct {
+	Nome    string         // identificador do formato (a-z, A-Z, 0-9, "_" e "-"; até 64)
+	Schema  map[string]any // JSON Schema da resposta
+	Estrito bool           // exige aderência exata ao schema (modo estrito)
+}
+
+// ErrRespostaForaDoSchema indica resposta do modelo que não segue o formato pedido
+var ErrRespostaForaDoSchema = errors.New("resposta do modelo fora do formato JSON exigido")
+
+type ctxKeyFormatoJSON struct{}
+
+// WithFormatoJSON anexa ao contexto o formato da resposta esperada do modelo
+func WithFormatoJSON(ctx context.Context, formato *FormatoJSON) context.Context {
+	return context.WithValue(ctx, ctxKeyFormatoJSON{}, formato)
+}
+
+// FormatoJSONFromContext devolve o formato da resposta anexado ao contexto (nil se ausente)
+func FormatoJSONFromContext(ctx context.Context) *FormatoJSON {
+	f, _ := ctx.Value(ctxKeyFormatoJSON{}).(*FormatoJSON)
+	return f
+}
+
+/*
+SchemaDoTipo gera o JSON Schema do tipo do valor informado (struct ou ponteiro para
+struct), pelos nomes das tags json. Todo campo é obrigatório e os objetos não admitem
+campos adicionais, como exige o modo estrito. Campos com a tag schema:"-" ficam de fora.
+*/
+func SchemaDoTipo(v any) map[string]any {
+	return schemaDe(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+var tipoRawMessage = reflect.TypeOf(json.RawMessage{})
+
+func schemaDe(t reflect.Type, visitados map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == tipoRawMessage {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaDe(t.Elem(), visitados)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaDe(t.Elem(), visitados)}
+	case reflect.Struct:
+		if visitados[t] {
+			// tipo recursivo: não há como expressá-lo sem $ref
+			return map[string]any{"type": "object"}
+		}
+		visitados[t] = true
+		defer delete(visitados, t)
+
+		props := map[string]any{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if f.Tag.Get("schema") == "-" {
+				// campo preenchido pelo servidor, fora da resposta do modelo
+				continue
+			}
+			nome := f.Name
+			if tag := f.Tag.Get("json"); tag != "" {
+				nome, _, _ = strings.Cut(tag, ",")
+				if nome == "-" {
+					continue
+				}
+				if nome == "" {
+					nome = f.Name
+				}
+			}
+			props[nome] = schemaDe(f.Type, visitados)
+			required = append(required, nome)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	return map[string]any{}
+}
+
+/*
+ValidaJSON confere o JSON com o schema (type, properties, required,
+additionalProperties, items e enum) e devolve os erros encontrados, com o caminho
+do campo ("$.partes.autor[0].nome: esperado string"). Lista vazia: JSON válido.
+*/
+func ValidaJSON(schema map[string]any, raw []byte) []string {
+	var valor any
+	if err := json.Unmarshal(raw, &valor); err != nil {
+		return []string{fmt.Sprintf("$: JSON inválido: %v", err)}
+	}
+	var erros []string
+	validaValor(schema, valor, "$", &erros)
+	return erros
+}
+
+// MAX_ERROS_VALIDACAO limita os erros relatados: bastam para o reparo e para o registro
+const MAX_ERROS_VALIDACAO = 20
+
+func validaValor(schema map[string]any, valor any, caminho string, erros *[]string) {
+	if len(*erros) >= MAX_ERROS_VALIDACAO || schema == nil {
+		return
+	}
+	add := func(format string, args ...any) {
+		*erros = append(*erros, caminho+": "+fmt.Sprintf(format, args...))
+	}
+
+	if tipos := tiposDoSchema(schema["type"]); len(tipos) > 0 {
+		atual := tipoJSON(valor)
+		aceito := false
+		for _, t := range tipos {
+			if t == atual || (t == "number" && atual == "integer") {
+				aceito = true
+				break
+			}
+		}
+		if !aceito {
+			add("esperado %s, recebido %s", strings.Join(tipos, " ou "), atual)
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		achou := false
+		for _, e := range enum {
+			if reflect.DeepEqual(normalizaNumero(e), normalizaNumero(valor)) {
+				achou = true
+				break
+			}
+		}
+		if !achou {
+			add("valor fora das opções %v", enum)
+		}
+	}
+
+	switch v := valor.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, nome := range listaStrings(schema["required"]) {
+			if _, ok := v[nome]; !ok {
+				add("campo obrigatório %q ausente", nome)
+			}
+		}
+		nomes := make([]string, 0, len(v))
+		for nome := range v {
+			nomes = append(nomes, nome)
+		}
+		sort.Strings(nomes)
+		for _, nome := range nomes {
+			if sub, ok := props[nome].(map[string]any); ok {
+				validaValor(sub, v[nome], caminho+"."+nome, erros)
+				continue
+			}
+			switch adicional := schema["additionalProperties"].(type) {
+			case bool:
+				if !adicional {
+					add("campo %q não previsto", nome)
+				}
+			case map[string]any:
+				validaValor(adicional, v[nome], caminho+"."+nome, erros)
+			}
+		}
+	case []any:
+		if itens, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validaValor(itens, item, fmt.Sprintf("%s[%d]", caminho, i), erros)
+			}
+		}
+	}
+}
+
+// tiposDoSchema aceita "type" como string ou lista ("string", ["string", "null"])
+func tiposDoSchema(t any) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	default:
+		return listaStrings(v)
+	}
+}
+
+func listaStrings(v any) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []any:
+		out := make([]string, 0, len(l))
+		for _, s := range l {
+			if str, ok := s.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func tipoJSON(valor any) string {
+	switch v := valor.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", valor)
+}
+
+// normalizaNumero iguala os números do schema (int) aos do JSON decodificado (float64)
+func normalizaNumero(v any) any {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return v
+}
+
+/*
+InstrucaoFormatoJSON descreve o formato no próprio prompt, para os provedores sem saída
+estruturada: o modelo recebe o schema e a orientação de responder só com o JSON.
+*/
+func InstrucaoFormatoJSON(formato *FormatoJSON) string {
+	schema, _ := json.Marshal(formato.Schema)
+	return "Responda apenas com um objeto JSON, sem comentários, sem blocos de código e sem " +
+		"texto fora do JSON, com todos os campos do JSON Schema a seguir (campos sem " +
+		"informação ficam vazios: \"\" ou []):\n" + string(schema)
+}
+
+/*
+ExtraiJSON isola o JSON do texto gerado: descarta os blocos de código (```json) e o
+texto antes do primeiro "{" (ou "[") e depois do último "}" (ou "]"). Sem JSON, devolve
+o texto sem os espaços das pontas.
+*/
+func ExtraiJSON(texto string) string {
+	texto = strings.TrimSpace(texto)
+	ini := strings.IndexAny(texto, "{[")
+	if ini < 0 {
+		return texto
+	}
+	fecha := "}"
+	if texto[ini] == '[' {
+		fecha = "]"
+	}
+	fim := strings.LastIndex(texto, fecha)
+	if fim < ini {
+		return texto
+	}
+	return texto[ini : fim+1]
+}
+
+/*
+SubstituiTextoResposta troca o texto gerado na resposta (output_text) pelo informado,
+para os chamadores que leem OutputText ou percorrem Output receberem o JSON extraído.
+*/
+func SubstituiTextoResposta(rsp *responses.Response, texto string) {
+	substituido := false
+	for i := range rsp.Output {
+		for j := range rsp.Output[i].Content {
+			if rsp.Output[i].Content[j].Type != "output_text" {
+				continue
+			}
+			if substituido {
+				rsp.Output[i].Content[j].Text = ""
+				continue
+			}
+			rsp.Output[i].Content[j].Text = texto
+			substituido = true
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package ialib
+
+import (
+	"strings"
+	"testing"
+)
+
+type parteTeste struct {
+	Nome string  `json:"nome"`
+	Cpf  *string `json:"cpf"`
+}
+
+type documentoTeste struct {
+	Tipo   int          `json:"tipo"`
+	Titulo string       `json:"titulo"`
+	Partes []parteTeste `json:"partes"`
+	Valor  float64      `json:"valor"`
+}
+
+func TestValidaJSON(t *testing.T) {
+	schema := SchemaDoTipo(documentoTeste{})
+	schemaEnum := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"evento": map[string]any{"type": "integer", "enum": []any{201, 202, 300}},
+		},
+		"required":             []any{"evento"},
+		"additionalProperties": false,
+	}
+
+	schemaNulo := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"obs": map[string]any{"type": []any{"string", "null"}}},
+	}
+
+	casos := []struct {
+		nome   string
+		schema map[string]any
+		json   string
+		erros  []string // trechos esperados, um por erro; vazio: JSON válido
+	}{
+		{
+			nome:   "válido",
+			schema: schema,
+			json:   `{"tipo":1,"titulo":"Inicial","partes":[{"nome":"Fulano","cpf":"123"}],"valor":10.5}`,
+		},
+		{
+			nome:   "inteiro aceito como number",
+			schema: schema,
+			json:   `{"tipo":1,"titulo":"Inicial","partes":[],"valor":10}`,
+		},
+		{
+			nome:   "JSON inválido",
+			schema: schema,
+			json:   `{"tipo":1,`,
+			erros:  []string{"$: JSON inválido"},
+		},
+		{
+			nome:   "campo obrigatório ausente",
+			schema: schema,
+			json:   `{"tipo":1,"partes":[],"valor":0}`,
+			erros:  []string{`$: campo obrigatório "titulo" ausente`},
+		},
+		{
+			nome:   "campo não previsto",
+			schema: schema,
+			json:   `{"tipo":1,"titulo":"x","partes":[],"valor":0,"extra":true}`,
+			erros:  []string{`$: campo "extra" não previsto`},
+		},
+		{
+			nome:   "tipo errado em item de lista",
+			schema: schema,
+			json:   `{"tipo":1,"titulo":"x","partes":[{"nome":3,"cpf":"123"}],"valor":0}`,
+			erros:  []string{"$.partes[0].nome: esperado string, recebido integer"},
+		},
+		{
+			nome:   "número fracionário onde se espera inteiro",
+			schema: schema,
+			json:   `{"tipo":1.5,"titulo":"x","partes":[],"valor":0}`,
+			erros:  []string{"$.tipo: esperado integer, recebido number"},
+		},
+		{
+			nome:   "null fora da lista de tipos",
+			schema: schema,
+			json:   `{"tipo":1,"titulo":"x","partes":[{"nome":"a","cpf":null}],"valor":0}`,
+			erros:  []string{"$.partes[0].cpf: esperado string, recebido null"},
+		},
+		{
+			nome:   "lista de tipos aceita null",
+			schema: schemaNulo,
+			json:   `{"obs":null}`,
+		},
+		{
+			nome:   "enum atendido",
+			schema: schemaEnum,
+			json:   `{"evento":202}`,
+		},
+		{
+			nome:   "enum não atendido",
+			schema: schemaEnum,
+			json:   `{"evento":999}`,
+			erros:  []string{"$.evento: valor fora das opções"},
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			erros := ValidaJSON(c.schema, []byte(c.json))
+			if len(erros) != len(c.erros) {
+				t.Fatalf("esperados %d erros, obtidos %d: %v", len(c.erros), len(erros), erros)
+			}
+			for i, trecho := range c.erros {
+				if !strings.Contains(erros[i], trecho) {
+					t.Errorf("erro %d = %q, esperado conter %q", i, erros[i], trecho)
+				}
+			}
+		})
+	}
+}
+
+func TestValidaJSONLimitaErros(t *testing.T) {
+	schema := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	json := "[" + strings.TrimSuffix(strings.Repeat("1,", MAX_ERROS_VALIDACAO+10), ",") + "]"
+
+	if erros := ValidaJSON(schema, []byte(json)); len(erros) != MAX_ERROS_VALIDACAO {
+		t.Errorf("esperados %d erros, obtidos %d", MAX_ERROS_VALIDACAO, len(erros))
+	}
+}
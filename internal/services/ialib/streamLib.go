@@ -0,0 +1,301 @@
+/*
+---------------------------------------------------------------------------------------
+File: streamLib.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Suporte a respostas em streaming. O chamador anexa ao contexto uma função
+que recebe os trechos (deltas) do texto gerado; os backends que implementam
+LLMStreamProvider repassam os deltas à medida que chegam do modelo.
+---------------------------------------------------------------------------------------
+*/
+
+package ialib
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/utils/erros"
+	"ocrserver/internal/utils/logger"
+
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// StreamDeltaFunc recebe cada trecho de texto gerado pelo modelo.
+type StreamDeltaFunc func(delta string)
+
+type ctxKeyStream struct{}
+
+// WithStreamDelta anexa ao contexto a função que receberá os deltas do texto gerado.
+func WithStreamDelta(ctx context.Context, onDelta StreamDeltaFunc) context.Context {
+	return context.WithValue(ctx, ctxKeyStream{}, onDelta)
+}
+
+func StreamDeltaFromContext(ctx context.Context) StreamDeltaFunc {
+	f, _ := ctx.Value(ctxKeyStream{}).(StreamDeltaFunc)
+	return f
+}
+
+/*
+LLMStreamProvider
+Interface opcional: backends que conseguem devolver a resposta em streaming. O
+retorno final é o mesmo *responses.Response do SubmitPrompt.
+*/
+type LLMStreamProvider interface {
+	LLMProvider
+
+	SubmitPromptStream(
+		ctx context.Context,
+		inputMsgs MsgGpt,
+		prevID string,
+		modelo string,
+		effort responses.ReasoningEffort,
+		verbosity responses.ResponseTextConfigVerbosity,
+		onDelta StreamDeltaFunc,
+	) (*responses.Response, error)
+}
+
+// ------------------------- OpenAI ----------------------------------------
+
+func (obj *OpenaiType) SubmitPromptStream(
+	ctx context.Context,
+	inputMsgs MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+	onDelta StreamDeltaFunc,
+) (*responses.Response, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("serviço OpenAI não iniciado")
+	}
+	if obj.cfg == nil {
+		return nil, fmt.Errorf("configuração OpenAI ausente")
+	}
+
+	msgs := inputMsgs.GetMessages()
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("lista de mensagens vazia")
+	}
+
+	// Timeout defensivo: se o contexto não tiver deadline, aplica o da config
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		timeout := 180
+		if config.GlobalConfig != nil && config.GlobalConfig.OpenOptionTimeoutSeconds > 0 {
+			timeout = config.GlobalConfig.OpenOptionTimeoutSeconds
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	params := obj.montaParamsResponse(msgs, prevID, modelo, effort, verbosity)
+	aplicaFormatoJSON(ctx, &params)
+
+	stream := obj.client.Responses.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	var final *responses.Response
+	for stream.Next() {
+		ev := stream.Current()
+		switch ev.Type {
+		case "response.output_text.delta":
+			if onDelta != nil && ev.Delta != "" {
+				onDelta(ev.Delta)
+			}
+		case "response.completed", "response.incomplete":
+			r := ev.Response
+			final = &r
+		case "response.failed":
+			return nil, fmt.Errorf("falha na resposta em streaming: %s", ev.Response.Error.Message)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		logger.Log.Errorf("Falha na chamada OpenAI (streaming): %v", err)
+		return nil, err
+	}
+	if final == nil {
+		return nil, fmt.Errorf("streaming encerrado sem resposta final")
+	}
+	if final.IncompleteDetails.Reason == "content_filter" {
+		logger.Log.Errorf("Resposta bloqueada por política de conteúdo")
+		return nil, erros.CreateError("Resposta truncada pela política da OpenAI!")
+	}
+
+	logger.Log.Infof("Modelo: %s - TOKENS (stream) - Input: %d - CachedTokens: %d- Output: %d - Total: %d",
+		final.Model, final.Usage.InputTokens, final.Usage.InputTokensDetails.CachedTokens, final.Usage.OutputTokens, final.Usage.TotalTokens)
+
+	return final, nil
+}
+
+// ------------------------- Compatível ------------------------------------
+
+type compatStreamChunk struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+		TotalTokens      int64 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (obj *OpenaiCompatType) SubmitPromptStream(
+	ctx context.Context,
+	inputMsgs MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+	onDelta StreamDeltaFunc,
+) (*responses.Response, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("serviço OpenaiCompat não iniciado")
+	}
+
+	msgs := inputMsgs.GetMessages()
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("lista de mensagens vazia")
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		timeout := 180
+		if obj.cfg.OpenOptionTimeoutSeconds > 0 {
+			timeout = obj.cfg.OpenOptionTimeoutSeconds
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	conversa, body := obj.montaRequisicao(msgs, prevID, modelo)
+	if obj.SuportaFormatoJSON() {
+		body.ResponseFormat = formatoCompat(ctx)
+	}
+	body.Stream = true
+	body.StreamOptions = &compatStreamOptions{IncludeUsage: true}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar requisição: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, obj.baseURL+"/v1/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao montar requisição: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if obj.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+obj.apiKey)
+	}
+
+	res, err := obj.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro na requisição a /v1/chat/completions: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		raw, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, &compatHTTPError{StatusCode: res.StatusCode, Body: strings.TrimSpace(string(raw))}
+	}
+
+	var (
+		texto  strings.Builder
+		idResp string
+		model  string
+		finish string
+		usage  compatUsage
+	)
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		linha := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(linha, "data:") {
+			continue
+		}
+		dados := strings.TrimSpace(strings.TrimPrefix(linha, "data:"))
+		if dados == "[DONE]" {
+			break
+		}
+
+		var chunk compatStreamChunk
+		if err := json.Unmarshal([]byte(dados), &chunk); err != nil {
+			logger.Log.Warningf("Chunk inválido no streaming: %v", err)
+			continue
+		}
+		if chunk.ID != "" {
+			idResp = chunk.ID
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = compatUsage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+				TotalTokens:      chunk.Usage.TotalTokens,
+			}
+		}
+		for _, ch := range chunk.Choices {
+			if ch.Delta.Content != "" {
+				texto.WriteString(ch.Delta.Content)
+				if onDelta != nil {
+					onDelta(ch.Delta.Content)
+				}
+			}
+			if ch.FinishReason != "" {
+				finish = ch.FinishReason
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler streaming: %w", err)
+	}
+
+	return obj.montaResposta(conversa, idResp, model, texto.String(), finish, usage)
+}
+
+// ------------------------- Fake ------------------------------------------
+
+// SubmitPromptStream do fake entrega a resposta pré-definida em pequenos trechos.
+func (obj *OpenaiFakeType) SubmitPromptStream(
+	ctx context.Context,
+	inputMsgs MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+	onDelta StreamDeltaFunc,
+) (*responses.Response, error) {
+	resp, err := obj.SubmitPrompt(ctx, inputMsgs, prevID, modelo, effort, verbosity)
+	if err != nil || onDelta == nil {
+		return resp, err
+	}
+
+	r := []rune(resp.OutputText())
+	const tamTrecho = 32
+	for i := 0; i < len(r); i += tamTrecho {
+		fim := i + tamTrecho
+		if fim > len(r) {
+			fim = len(r)
+		}
+		onDelta(string(r[i:fim]))
+	}
+	return resp, nil
+}
@@ -0,0 +1,130 @@
+/*
+---------------------------------------------------------------------------------------
+File: eproc.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Importador do eproc (TRF4, TJRS, TJSC, TJTO e outros). O download do
+processo é um ZIP com um PDF por documento, nomeado pelo evento e pela sigla do tipo:
+EVENTO1_INIC1.pdf, EVENTO15_DESPADEC1.pdf. As siglas são convertidas nas naturezas pelos
+sinônimos do perfil "eproc". O id do documento é o código verificador do carimbo de
+assinatura, e a data é a da assinatura.
+---------------------------------------------------------------------------------------
+*/
+package importadores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ocrserver/internal/utils/files"
+	"ocrserver/internal/utils/logger"
+)
+
+var (
+	reArquivoEproc     = regexp.MustCompile(`(?i)^evento[\s_\-]*(\d+)[\s_\-]+([a-zç]+?)[\s_\-]*(\d*)\.pdf$`)
+	reVerificadorEproc = regexp.MustCompile(`(?i)c[óo]digo\s+verificador\s+(\d+v\d+)`)
+	reDataEproc        = regexp.MustCompile(`(?i)(?:assinad[oa]\s+eletronicamente|data\s+e\s+hora:)[^\f]{0,400}?(\d{2}/\d{2}/\d{4}),?\s*(?:[àa]s\s*)?(\d{2}:\d{2})?`)
+)
+
+type ImportadorEproc struct{}
+
+func (imp *ImportadorEproc) Id() string {
+	return FORMATO_EPROC
+}
+
+func (imp *ImportadorEproc) Nome() string {
+	return "eproc - ZIP com os documentos dos eventos"
+}
+
+func (imp *ImportadorEproc) Perfil() string {
+	return FORMATO_EPROC
+}
+
+// Reconhece o ZIP pelos nomes dos arquivos (EVENTO<n>_<SIGLA><n>.pdf)
+func (imp *ImportadorEproc) Reconhece(arq *Arquivo) bool {
+	for _, nome := range arq.Entradas() {
+		if reArquivoEproc.MatchString(path.Base(nome)) {
+			return true
+		}
+	}
+	return false
+}
+
+type arquivoEproc struct {
+	f      files.EntradaZip
+	evento int
+	sigla  string
+	ordem  int
+}
+
+func (imp *ImportadorEproc) Importa(ctx context.Context, arq *Arquivo) ([]Documento, error) {
+	if !arq.EhZip() {
+		return nil, errors.New("o eproc espera o ZIP com os documentos dos eventos")
+	}
+	z, err := abreZip(arq)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	var arquivos []arquivoEproc
+	for _, f := range z.pdfs {
+		m := reArquivoEproc.FindStringSubmatch(path.Base(f.Nome))
+		if m == nil {
+			logger.Log.Infof("eproc - arquivo fora do padrão EVENTO<n>_<SIGLA><n>.pdf ignorado: %s", f.Nome)
+			continue
+		}
+		evento, _ := strconv.Atoi(m[1])
+		ordem, _ := strconv.Atoi(m[3])
+		arquivos = append(arquivos, arquivoEproc{f: f, evento: evento, sigla: strings.ToUpper(m[2]), ordem: ordem})
+	}
+	if len(arquivos) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo EVENTO<n>_<SIGLA><n>.pdf no ZIP (%d PDFs)", len(z.pdfs))
+	}
+	sort.SliceStable(arquivos, func(i, j int) bool {
+		if arquivos[i].evento != arquivos[j].evento {
+			return arquivos[i].evento < arquivos[j].evento
+		}
+		return arquivos[i].ordem < arquivos[j].ordem
+	})
+
+	perfil := perfilImportador(imp.Perfil())
+	docs := make([]Documento, 0, len(arquivos))
+	for _, a := range arquivos {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		paginas, err := z.extrai(ctx, arq, a.f)
+		if err != nil {
+			// um PDF ilegível não impede a importação dos demais documentos
+			logger.Log.Warningf("eproc - %s: %v", a.f.Nome, err)
+			continue
+		}
+		bruto := textoBruto(paginas)
+		doc := Documento{
+			Tipo:    a.sigla,
+			IdNatu:  perfil.CodigoNatureza(a.sigla),
+			Texto:   textoDocumento(perfil, paginas),
+			Fonte:   path.Base(a.f.Nome),
+			Paginas: paginasAutos(paginas),
+		}
+		if m := primeiraOcorrencia(reVerificadorEproc, bruto); m != nil {
+			doc.Id = strings.ToLower(m[0])
+		} else {
+			doc.Id = idSintetico(FORMATO_EPROC, doc.Texto)
+		}
+		if m := primeiraOcorrencia(reDataEproc, bruto); m != nil {
+			doc.Data, doc.Hora = m[0], m[1]
+			doc.DtAssinatura = strings.TrimSpace(m[0] + " " + m[1]) // a data do carimbo é a da assinatura
+		}
+		doc.Signatario = signatario(bruto)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
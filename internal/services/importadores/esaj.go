@@ -0,0 +1,174 @@
+/*
+---------------------------------------------------------------------------------------
+File: esaj.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Importador da pasta digital do e-SAJ (TJSP e demais tribunais do sistema).
+Cada peça começa num marcador (bookmark) do PDF, cujo título é o tipo do documento. As
+páginas trazem o carimbo lateral "Este documento é cópia do original, assinado
+digitalmente por ..., protocolado em dd/mm/aaaa às hh:mm ... e código XXXX", de onde
+vêm a data e o código de conferência (id do documento). Sem marcadores, as peças são
+separadas pela troca do código de conferência entre as páginas.
+---------------------------------------------------------------------------------------
+*/
+package importadores
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ocrserver/internal/services/pdftexto"
+	"ocrserver/internal/utils/logger"
+)
+
+var (
+	reCodigoESAJ    = regexp.MustCompile(`(?i)informe o processo\s+[\d.\-]+\s+e\s+c[óo]digo\s+([0-9A-Za-z]{4,})`)
+	reDataESAJ      = regexp.MustCompile(`(?i)(?:protocolado|liberado nos autos)\s+em\s+(\d{2}/\d{2}/\d{4})\s+[àa]s\s+(\d{2}:\d{2})`)
+	reNumeroTitulo  = regexp.MustCompile(`^\s*\d+\s*[-–.)]\s*`)
+	reFolhasTitulo  = regexp.MustCompile(`(?i)\s*[-–(]?\s*fls?\.?\s*\d+(\s*(?:[-/a]|até)\s*\d+)?\s*\)?\s*$`)
+	errSemPecasESAJ = errors.New("nenhum marcador nem carimbo do e-SAJ encontrado no PDF")
+)
+
+type ImportadorESAJ struct{}
+
+func (imp *ImportadorESAJ) Id() string {
+	return FORMATO_ESAJ
+}
+
+func (imp *ImportadorESAJ) Nome() string {
+	return "e-SAJ - pasta digital (PDF)"
+}
+
+func (imp *ImportadorESAJ) Perfil() string {
+	return FORMATO_ESAJ
+}
+
+// Reconhece o PDF pelos endereços de conferência do e-SAJ no carimbo das páginas
+func (imp *ImportadorESAJ) Reconhece(arq *Arquivo) bool {
+	if arq.EhZip() || len(arq.Paginas) == 0 {
+		return false
+	}
+	return perfilImportador(imp.Perfil()).Ocorrencias(arq.textos()) > 0
+}
+
+// pecaESAJ é a faixa de páginas de uma peça (índices em arq.Paginas)
+type pecaESAJ struct {
+	titulo   string
+	ini, fim int
+}
+
+func (imp *ImportadorESAJ) Importa(ctx context.Context, arq *Arquivo) ([]Documento, error) {
+	if len(arq.Paginas) == 0 {
+		return nil, errors.New("PDF sem páginas")
+	}
+	pecas := imp.pecasPorMarcadores(arq)
+	if len(pecas) == 0 {
+		pecas = imp.pecasPorCodigo(arq.Paginas)
+	}
+	if len(pecas) == 0 {
+		return nil, errSemPecasESAJ
+	}
+
+	perfil := perfilImportador(imp.Perfil())
+	docs := make([]Documento, 0, len(pecas))
+	for _, p := range pecas {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		paginas := arq.Paginas[p.ini : p.fim+1]
+		bruto := textoBruto(paginas)
+		doc := Documento{
+			Tipo:    p.titulo,
+			IdNatu:  perfil.CodigoNatureza(p.titulo),
+			Texto:   textoDocumento(perfil, paginas),
+			Fonte:   arq.NomeOriginal,
+			Paginas: paginasAutos(paginas),
+		}
+		if m := primeiraOcorrencia(reCodigoESAJ, bruto); m != nil {
+			doc.Id = m[0]
+		} else {
+			doc.Id = idSintetico(FORMATO_ESAJ, doc.Texto)
+		}
+		if m := primeiraOcorrencia(reDataESAJ, bruto); m != nil {
+			doc.Data, doc.Hora = m[0], m[1]
+		}
+		doc.Signatario = signatario(bruto)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+/*
+pecasPorMarcadores usa os itens finais do sumário (sem filhos): nas pastas em que as
+peças são agrupadas (por data, por volume), o grupo não é uma peça.
+*/
+func (imp *ImportadorESAJ) pecasPorMarcadores(arq *Arquivo) []pecaESAJ {
+	marcadores, err := pdftexto.Marcadores(arq.Caminho)
+	if err != nil {
+		logger.Log.Warningf("e-SAJ - sumário ilegível em %s: %v", arq.NomeOriginal, err)
+		return nil
+	}
+	total := len(arq.Paginas)
+	var inicios []pecaESAJ
+	for i, m := range marcadores {
+		folha := i+1 == len(marcadores) || marcadores[i+1].Nivel <= m.Nivel
+		if !folha || m.Pagina < 1 || m.Pagina > total {
+			continue
+		}
+		inicios = append(inicios, pecaESAJ{titulo: tituloPecaESAJ(m.Titulo), ini: m.Pagina - 1})
+	}
+	sort.SliceStable(inicios, func(i, j int) bool { return inicios[i].ini < inicios[j].ini })
+
+	var pecas []pecaESAJ
+	for i, p := range inicios {
+		if i > 0 && p.ini == inicios[i-1].ini {
+			continue // dois marcadores na mesma página: vale o primeiro
+		}
+		pecas = append(pecas, p)
+	}
+	if len(pecas) > 0 && pecas[0].ini > 0 {
+		// páginas antes do primeiro marcador (capa, termo de abertura)
+		pecas = append([]pecaESAJ{{titulo: "Outros Documentos", ini: 0}}, pecas...)
+	}
+	for i := range pecas {
+		pecas[i].fim = total - 1
+		if i+1 < len(pecas) {
+			pecas[i].fim = pecas[i+1].ini - 1
+		}
+	}
+	return pecas
+}
+
+// pecasPorCodigo agrupa as páginas consecutivas com o mesmo código de conferência
+func (imp *ImportadorESAJ) pecasPorCodigo(paginas []pdftexto.Pagina) []pecaESAJ {
+	var pecas []pecaESAJ
+	codigoAtual := ""
+	for i, p := range paginas {
+		codigo := ""
+		if m := primeiraOcorrencia(reCodigoESAJ, p.Texto); m != nil {
+			codigo = m[0]
+		}
+		switch {
+		case codigo != "" && codigo != codigoAtual:
+			pecas = append(pecas, pecaESAJ{titulo: "Outros Documentos", ini: i, fim: i})
+			codigoAtual = codigo
+		case len(pecas) > 0:
+			// página sem carimbo (em branco, digitalizada): segue com a peça aberta
+			pecas[len(pecas)-1].fim = i
+		}
+	}
+	return pecas
+}
+
+// tituloPecaESAJ limpa o título do marcador ("3 - Petição Inicial (fls. 1-12)" → "Petição Inicial")
+func tituloPecaESAJ(titulo string) string {
+	titulo = reNumeroTitulo.ReplaceAllString(titulo, "")
+	titulo = strings.TrimSpace(reFolhasTitulo.ReplaceAllString(titulo, ""))
+	if titulo == "" {
+		return "Outros Documentos"
+	}
+	return titulo
+}
@@ -0,0 +1,258 @@
+/*
+---------------------------------------------------------------------------------------
+File: importadores.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Importadores dos autos exportados por outros sistemas processuais. O PDF
+completo do PJe (índice + rodapé "Num. X - Pág. Y") continua com o tratamento próprio
+do uploadService; os importadores deste pacote dividem os demais formatos em documentos
+(id, data, tipo e texto), com o tipo convertido nas naturezas de consts.NATU_DOC_*:
+  - e-SAJ: pasta digital em PDF, com um marcador (bookmark) por peça;
+  - eproc: ZIP com um PDF por documento de cada evento (EVENTO1_INIC1.pdf);
+  - Projudi: ZIP com os arquivos das movimentações (mov_1_1_Peticao_Inicial.pdf).
+
+Os nomes dos tipos e os carimbos removidos do texto vêm dos perfis de tribunal de mesmo
+id (pacote tribunais), que podem ser ajustados em PERFIS_TRIBUNAIS_ARQUIVO.
+---------------------------------------------------------------------------------------
+*/
+package importadores
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/services/pdftexto"
+	"ocrserver/internal/services/tribunais"
+	"ocrserver/internal/utils/files"
+	"ocrserver/internal/utils/logger"
+)
+
+// Formatos dos autos (campo "Formato" do upload)
+const (
+	FORMATO_AUTO    = "auto"
+	FORMATO_PJE     = "pje" // PDF completo do PJe, tratado pelo uploadService
+	FORMATO_ESAJ    = "esaj"
+	FORMATO_EPROC   = "eproc"
+	FORMATO_PROJUDI = "projudi"
+)
+
+// Documento é uma peça extraída dos autos
+type Documento struct {
+	Id      string // identificador no sistema de origem, gravado como id_pje
+	Data    string // dd/mm/aaaa
+	Hora    string // hh:mm
+	Tipo    string // denominação do tipo no sistema de origem
+	IdNatu  int    // natureza (consts.NATU_DOC_*)
+	Texto   string // texto sem os carimbos do sistema de origem
+	Fonte   string // arquivo de origem (o PDF enviado ou a entrada do ZIP)
+	Paginas []consts.PaginaAutos
+
+	Signatario   string // quem assinou, pelo carimbo de assinatura
+	DtAssinatura string // dd/mm/aaaa hh:mm, quando o carimbo a traz
+}
+
+/*
+Arquivo é o arquivo dos autos enviado no upload. Para PDF, Paginas já traz o texto
+extraído; para ZIP, cada PDF contido é extraído por ExtraiPDF (extração e OCR do
+uploadService), depois de descompactado com os limites de LimitesZip.
+*/
+type Arquivo struct {
+	Caminho      string
+	NomeOriginal string
+	Paginas      []pdftexto.Pagina
+	ExtraiPDF    func(ctx context.Context, pdfPath string) ([]pdftexto.Pagina, error)
+	LimitesZip   files.LimitesZip // obrigatórios para ZIP (ZIP_MAX_ENTRADAS, ZIP_MAX_DESCOMPACTADO_MB)
+
+	entradas []string // nomes das entradas do ZIP (carregados em Entradas)
+}
+
+// EhZip indica se o arquivo é um ZIP
+func (a *Arquivo) EhZip() bool {
+	return strings.EqualFold(filepath.Ext(a.Caminho), ".zip")
+}
+
+// Entradas devolve os nomes dos PDFs contidos no ZIP; nil para outros arquivos
+func (a *Arquivo) Entradas() []string {
+	if a.entradas != nil || !a.EhZip() {
+		return a.entradas
+	}
+	nomes, err := listaPDFsZip(a.Caminho, a.LimitesZip.MaxEntradas)
+	if err != nil {
+		logger.Log.Warningf("ZIP ilegível - %s: %v", a.NomeOriginal, err)
+		nomes = []string{}
+	}
+	a.entradas = nomes
+	return a.entradas
+}
+
+// textos devolve o texto das páginas do PDF, usado na detecção
+func (a *Arquivo) textos() []string {
+	out := make([]string, len(a.Paginas))
+	for i, p := range a.Paginas {
+		out[i] = p.Texto
+	}
+	return out
+}
+
+// Importador divide os autos de um sistema processual em documentos
+type Importador interface {
+	Id() string
+	Nome() string
+	Perfil() string // id do perfil de tribunal com os carimbos e os nomes dos tipos
+	Reconhece(arq *Arquivo) bool
+	Importa(ctx context.Context, arq *Arquivo) ([]Documento, error)
+}
+
+var importadores = []Importador{
+	&ImportadorESAJ{},
+	&ImportadorEproc{},
+	&ImportadorProjudi{},
+}
+
+// Formato descreve um formato aceito no upload
+type Formato struct {
+	Id   string `json:"id"`
+	Nome string `json:"nome"`
+}
+
+// Lista devolve os formatos aceitos no upload, com a detecção automática e o PJe
+func Lista() []Formato {
+	out := []Formato{
+		{Id: FORMATO_AUTO, Nome: "Detecção automática"},
+		{Id: FORMATO_PJE, Nome: "PJe - PDF completo dos autos"},
+	}
+	for _, imp := range importadores {
+		out = append(out, Formato{Id: imp.Id(), Nome: imp.Nome()})
+	}
+	return out
+}
+
+// Formatos devolve os ids dos formatos aceitos no upload
+func Formatos() []string {
+	lista := Lista()
+	out := make([]string, len(lista))
+	for i, f := range lista {
+		out[i] = f.Id
+	}
+	return out
+}
+
+/*
+Seleciona devolve o importador do formato informado. Com "auto" (ou vazio), o formato é
+detectado pelo conteúdo; nil indica o PDF do PJe, que segue o fluxo original.
+*/
+func Seleciona(formato string, arq *Arquivo) (Importador, error) {
+	formato = strings.ToLower(strings.TrimSpace(formato))
+	switch formato {
+	case "", FORMATO_AUTO:
+		for _, imp := range importadores {
+			if imp.Reconhece(arq) {
+				return imp, nil
+			}
+		}
+		if arq.EhZip() {
+			return nil, fmt.Errorf("formato do ZIP não reconhecido (%d PDFs); informe o formato: %s", len(arq.Entradas()), strings.Join(Formatos(), ", "))
+		}
+		return nil, nil
+	case FORMATO_PJE:
+		if arq.EhZip() {
+			return nil, fmt.Errorf("o formato %q espera o PDF completo dos autos, não um ZIP", formato)
+		}
+		return nil, nil
+	}
+	for _, imp := range importadores {
+		if imp.Id() == formato {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("formato de autos desconhecido: %q (disponíveis: %s)", formato, strings.Join(Formatos(), ", "))
+}
+
+// ValidaFormato confere o formato informado no upload, sem abrir o arquivo
+func ValidaFormato(formato string) error {
+	formato = strings.ToLower(strings.TrimSpace(formato))
+	if formato == "" {
+		return nil
+	}
+	for _, f := range Formatos() {
+		if f == formato {
+			return nil
+		}
+	}
+	return fmt.Errorf("formato de autos desconhecido: %q (disponíveis: %s)", formato, strings.Join(Formatos(), ", "))
+}
+
+// ============================================================
+// Funções comuns aos importadores
+// ============================================================
+
+// perfilImportador devolve o perfil de tribunal do importador; sem ele, o genérico
+func perfilImportador(id string) *tribunais.Perfil {
+	perfis := tribunais.Global()
+	if p, err := perfis.Perfil(id); err == nil && p != nil {
+		return p
+	}
+	p, _ := perfis.Perfil(tribunais.PERFIL_GENERICO)
+	return p
+}
+
+var (
+	reLinhasVazias = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+\n`)
+	reSignatario   = regexp.MustCompile(`(?i)assinad[oa]\s+(?:(?:eletr[ôo]nica|digital)mente\s+)?por:?\s+([^,\n]{3,120}?)\s*(?:,|\n|\s[-–]\s)`)
+)
+
+// textoDocumento junta as páginas do documento, sem os carimbos e rodapés do sistema de origem
+func textoDocumento(perfil *tribunais.Perfil, paginas []pdftexto.Pagina) string {
+	partes := make([]string, 0, len(paginas))
+	for _, p := range paginas {
+		if t := strings.TrimSpace(perfil.RemoveRodape(strings.ReplaceAll(p.Texto, "\f", ""))); t != "" {
+			partes = append(partes, t)
+		}
+	}
+	return reLinhasVazias.ReplaceAllString(strings.Join(partes, "\n\n"), "\n\n")
+}
+
+// textoBruto junta as páginas sem tratamento, para a busca dos carimbos (id, data)
+func textoBruto(paginas []pdftexto.Pagina) string {
+	return pdftexto.TextoCompleto(paginas)
+}
+
+// paginasAutos converte as páginas extraídas nas páginas gravadas no autos_temp
+func paginasAutos(paginas []pdftexto.Pagina) []consts.PaginaAutos {
+	out := make([]consts.PaginaAutos, len(paginas))
+	for i, p := range paginas {
+		out[i] = consts.PaginaAutos{Numero: p.Numero, Origem: p.Origem}
+	}
+	return out
+}
+
+// signatario devolve o nome no primeiro carimbo de assinatura ("assinado eletronicamente por ...")
+func signatario(bruto string) string {
+	if m := primeiraOcorrencia(reSignatario, bruto); m != nil {
+		return strings.TrimSpace(m[0])
+	}
+	return ""
+}
+
+// primeiraOcorrencia devolve os grupos de captura da primeira ocorrência da expressão
+func primeiraOcorrencia(re *regexp.Regexp, texto string) []string {
+	if m := re.FindStringSubmatch(texto); m != nil {
+		return m[1:]
+	}
+	return nil
+}
+
+/*
+idSintetico identifica documentos sem código de autenticação no carimbo: o hash do texto
+evita duplicar o documento numa nova importação dos mesmos autos.
+*/
+func idSintetico(formato string, texto string) string {
+	h := sha256.Sum256([]byte(texto))
+	return formato + "-" + hex.EncodeToString(h[:8])
+}
@@ -0,0 +1,160 @@
+/*
+---------------------------------------------------------------------------------------
+File: projudi.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Importador do Projudi (TJPR, TJGO, TJBA e outros). O download dos arquivos
+do processo é um ZIP com os documentos de cada movimentação, identificados pelo número
+da movimentação e pela descrição, no nome do arquivo ou da pasta:
+mov_1_1_Peticao_Inicial.pdf, "Movimentacao 12 - Contestacao/arquivo1.pdf". O id do
+documento é o identificador do carimbo de validação, e a data é a da assinatura.
+---------------------------------------------------------------------------------------
+*/
+package importadores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ocrserver/internal/utils/files"
+	"ocrserver/internal/utils/logger"
+)
+
+var (
+	reMovProjudi           = regexp.MustCompile(`(?i)^mov(?:imenta[çc][ãa]o|imento|\.)?[\s_\-]*(\d+)(?:[._\-](\d+))?[\s_.\-]*(.*)$`)
+	reJuntadaProjudi       = regexp.MustCompile(`(?i)^juntada\s+de\s+(?:peti[çc][ãa]o\s+de\s+)?`)
+	reIdentificadorProjudi = regexp.MustCompile(`(?i)identificador:\s*((?:[A-Z0-9]{5}\s?){3,5})`)
+	reDataProjudi          = regexp.MustCompile(`(?i)assinado\s+digitalmente\s+por[^\f]{0,160}?(\d{2}/\d{2}/\d{4})(?:\s+(\d{2}:\d{2}))?`)
+)
+
+type ImportadorProjudi struct{}
+
+func (imp *ImportadorProjudi) Id() string {
+	return FORMATO_PROJUDI
+}
+
+func (imp *ImportadorProjudi) Nome() string {
+	return "Projudi - ZIP com os arquivos das movimentações"
+}
+
+func (imp *ImportadorProjudi) Perfil() string {
+	return FORMATO_PROJUDI
+}
+
+// Reconhece o ZIP pelos nomes das movimentações nos arquivos ou pastas
+func (imp *ImportadorProjudi) Reconhece(arq *Arquivo) bool {
+	for _, nome := range arq.Entradas() {
+		if _, _, _, ok := movimentacaoProjudi(nome); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type arquivoProjudi struct {
+	f         files.EntradaZip
+	mov, seq  int
+	descricao string
+}
+
+func (imp *ImportadorProjudi) Importa(ctx context.Context, arq *Arquivo) ([]Documento, error) {
+	if !arq.EhZip() {
+		return nil, errors.New("o Projudi espera o ZIP com os arquivos das movimentações")
+	}
+	z, err := abreZip(arq)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	var arquivos []arquivoProjudi
+	for _, f := range z.pdfs {
+		mov, seq, descricao, ok := movimentacaoProjudi(f.Nome)
+		if !ok {
+			logger.Log.Infof("Projudi - arquivo sem número de movimentação ignorado: %s", f.Nome)
+			continue
+		}
+		arquivos = append(arquivos, arquivoProjudi{f: f, mov: mov, seq: seq, descricao: descricao})
+	}
+	if len(arquivos) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo de movimentação no ZIP (%d PDFs)", len(z.pdfs))
+	}
+	sort.SliceStable(arquivos, func(i, j int) bool {
+		if arquivos[i].mov != arquivos[j].mov {
+			return arquivos[i].mov < arquivos[j].mov
+		}
+		return arquivos[i].seq < arquivos[j].seq
+	})
+
+	perfil := perfilImportador(imp.Perfil())
+	docs := make([]Documento, 0, len(arquivos))
+	for _, a := range arquivos {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		paginas, err := z.extrai(ctx, arq, a.f)
+		if err != nil {
+			logger.Log.Warningf("Projudi - %s: %v", a.f.Nome, err)
+			continue
+		}
+		bruto := textoBruto(paginas)
+		doc := Documento{
+			Tipo:    a.descricao,
+			IdNatu:  perfil.CodigoNatureza(a.descricao),
+			Texto:   textoDocumento(perfil, paginas),
+			Fonte:   a.f.Nome,
+			Paginas: paginasAutos(paginas),
+		}
+		if m := primeiraOcorrencia(reIdentificadorProjudi, bruto); m != nil {
+			doc.Id = strings.ToUpper(strings.Join(strings.Fields(m[0]), ""))
+		} else {
+			doc.Id = idSintetico(FORMATO_PROJUDI, doc.Texto)
+		}
+		if m := primeiraOcorrencia(reDataProjudi, bruto); m != nil {
+			doc.Data, doc.Hora = m[0], m[1]
+			doc.DtAssinatura = strings.TrimSpace(m[0] + " " + m[1]) // a data do carimbo é a da assinatura
+		}
+		doc.Signatario = signatario(bruto)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+/*
+movimentacaoProjudi lê o número da movimentação, a ordem do arquivo e a descrição do
+caminho da entrada. Vale o primeiro trecho (pasta ou arquivo) que comece por "mov"; sem
+descrição nesse trecho, usa-se o nome do arquivo.
+*/
+func movimentacaoProjudi(nome string) (mov, seq int, descricao string, ok bool) {
+	trechos := strings.Split(strings.TrimSuffix(nome, path.Ext(nome)), "/")
+	for _, t := range trechos {
+		m := reMovProjudi.FindStringSubmatch(strings.TrimSpace(t))
+		if m == nil {
+			continue
+		}
+		mov, _ = strconv.Atoi(m[1])
+		seq, _ = strconv.Atoi(m[2])
+		descricao = m[3]
+		if descricao == "" && t != trechos[len(trechos)-1] {
+			descricao = trechos[len(trechos)-1]
+		}
+		return mov, seq, descricaoProjudi(descricao), true
+	}
+	return 0, 0, "", false
+}
+
+// descricaoProjudi limpa a descrição ("JUNTADA_DE_PETICAO_DE_CONTESTACAO" → "CONTESTACAO")
+func descricaoProjudi(descricao string) string {
+	descricao = strings.Join(strings.Fields(strings.ReplaceAll(descricao, "_", " ")), " ")
+	descricao = reJuntadaProjudi.ReplaceAllString(descricao, "")
+	if descricao == "" {
+		return "Outros Documentos"
+	}
+	return descricao
+}
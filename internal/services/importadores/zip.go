@@ -0,0 +1,96 @@
+/*
+---------------------------------------------------------------------------------------
+File: zip.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Leitura dos PDFs contidos nos ZIPs do eproc e do Projudi. O ZIP é descompac-
+tado por files.DescompactaZip num diretório temporário, com os mesmos limites do upload
+em lote (Arquivo.LimitesZip): quantidade de entradas, tamanho de cada PDF, soma descom-
+pactada e taxa de compressão, medidos nos bytes efetivamente lidos. A detecção do for-
+mato só lê os nomes das entradas, sem descompactá-las.
+---------------------------------------------------------------------------------------
+*/
+package importadores
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"ocrserver/internal/services/pdftexto"
+	"ocrserver/internal/utils/files"
+	"ocrserver/internal/utils/logger"
+)
+
+type leitorZip struct {
+	dir  string             // diretório temporário com os PDFs descompactados
+	pdfs []files.EntradaZip // PDFs gravados, em ordem de nome
+}
+
+// abreZip descompacta os PDFs do ZIP; as entradas recusadas pelos limites são registradas no log
+func abreZip(arq *Arquivo) (*leitorZip, error) {
+	if arq.LimitesZip == (files.LimitesZip{}) {
+		return nil, errors.New("limites de descompactação do ZIP não informados")
+	}
+	dir, err := os.MkdirTemp("", "autos-zip-*")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório temporário: %w", err)
+	}
+	entradas, err := files.DescompactaZip(arq.Caminho, dir, arq.LimitesZip, []string{".pdf"})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	l := &leitorZip{dir: dir}
+	for _, e := range entradas {
+		switch {
+		case e.Caminho != "":
+			l.pdfs = append(l.pdfs, e)
+		case strings.EqualFold(path.Ext(e.Nome), ".pdf"):
+			logger.Log.Warningf("ZIP %s - %s ignorado: %s", arq.NomeOriginal, e.Nome, e.Motivo)
+		}
+	}
+	sort.SliceStable(l.pdfs, func(i, j int) bool { return l.pdfs[i].Nome < l.pdfs[j].Nome })
+	return l, nil
+}
+
+func (l *leitorZip) Close() error {
+	return os.RemoveAll(l.dir)
+}
+
+// listaPDFsZip devolve os nomes dos PDFs do ZIP, ignorando pastas e metadados do macOS
+func listaPDFsZip(caminho string, maxEntradas int) ([]string, error) {
+	zr, err := zip.OpenReader(caminho)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o ZIP: %w", err)
+	}
+	defer zr.Close()
+	if maxEntradas > 0 && len(zr.File) > maxEntradas {
+		return nil, fmt.Errorf("%w: %d entradas (máximo %d)", files.ErrLimiteZip, len(zr.File), maxEntradas)
+	}
+	var nomes []string
+	for _, f := range zr.File {
+		nome := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(nome, ".") {
+			continue
+		}
+		if strings.EqualFold(path.Ext(nome), ".pdf") {
+			nomes = append(nomes, f.Name)
+		}
+	}
+	sort.Strings(nomes)
+	return nomes, nil
+}
+
+// extrai extrai o texto das páginas de um PDF descompactado
+func (l *leitorZip) extrai(ctx context.Context, arq *Arquivo, e files.EntradaZip) ([]pdftexto.Pagina, error) {
+	if arq.ExtraiPDF != nil {
+		return arq.ExtraiPDF(ctx, e.Caminho)
+	}
+	return (&pdftexto.ExtratorNativo{}).Extrai(ctx, e.Caminho)
+}
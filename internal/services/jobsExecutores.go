@@ -0,0 +1,182 @@
+/*
+---------------------------------------------------------------------------------------
+File: jobsExecutores.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Executores dos jobs nativos do pacote services (extração de PDF,
+autuação e embeddings dos autos) e o repasse do progresso do job pelo contexto.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/models"
+)
+
+type ctxKeyJobProgresso struct{}
+
+// WithJobProgresso anexa ao contexto a função de progresso do job em execução.
+func WithJobProgresso(ctx context.Context, f JobProgressoFunc) context.Context {
+	return context.WithValue(ctx, ctxKeyJobProgresso{}, f)
+}
+
+/*
+InformaProgressoJob informa que 'feitos' de 'total' itens foram processados. Fora de
+um job (rotas síncronas), não faz nada.
+*/
+func InformaProgressoJob(ctx context.Context, feitos int, total int, mensagem string) {
+	f, _ := ctx.Value(ctxKeyJobProgresso{}).(JobProgressoFunc)
+	if f == nil || total <= 0 {
+		return
+	}
+	f(feitos*100/total, mensagem)
+}
+
+// Resultado do job JOB_TIPO_EXTRACAO_PDF (mesmos campos da rota síncrona)
+type ResultadoExtracaoPDF struct {
+	ExtractedFiles []string              `json:"extractedFiles"`
+	ExtractedErros []int                 `json:"extractedErros"`
+	Relatorios     []RelatorioImportacao `json:"relatorios"`
+}
+
+func registrarJobsNativos(obj *JobsServiceType) {
+	// Extração: arquivos já processados saem de "uploads", logo a reexecução é segura
+	obj.Registrar(consts.JOB_TIPO_EXTRACAO_PDF, true, executarExtracaoPDF)
+
+	// Autuação: documentos autuados saem de "autos_temp" e a duplicidade é verificada
+	obj.Registrar(consts.JOB_TIPO_AUTUACAO, true, executarAutuacao)
+
+	// Embeddings: documentos já indexados são pulados (exceto com "reindexar")
+	obj.Registrar(consts.JOB_TIPO_EMBEDDINGS_AUTOS, true, executarEmbeddingsAutos)
+}
+
+func executarExtracaoPDF(ctx context.Context, job *models.JobRow, progresso JobProgressoFunc) (any, error) {
+	var params []BodyParamsPDF
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, fmt.Errorf("parâmetros do job inválidos: %w", err)
+	}
+	if UploadServiceGlobal == nil {
+		return nil, fmt.Errorf("objeto global 'UploadServiceGlobal' não foi inicializado")
+	}
+
+	files, errs, relatorios := UploadServiceGlobal.ProcessaPDF(WithJobProgresso(ctx, progresso), params)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ResultadoExtracaoPDF{ExtractedFiles: files, ExtractedErros: errs, Relatorios: relatorios}, nil
+}
+
+func executarAutuacao(ctx context.Context, job *models.JobRow, progresso JobProgressoFunc) (any, error) {
+	var itens []ItemAutuacao
+	if err := json.Unmarshal(job.Params, &itens); err != nil {
+		return nil, fmt.Errorf("parâmetros do job inválidos: %w", err)
+	}
+
+	res := AutuarDocumentos(WithJobProgresso(ctx, progresso), itens)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"sucesso":        len(res.ExtractedErros) == 0,
+		"extractedFiles": res.ExtractedFiles,
+		"extractedErros": res.ExtractedErros,
+	}, nil
+}
+
+// Parâmetros do job JOB_TIPO_EMBEDDINGS_AUTOS
+type ParamsEmbeddingsAutos struct {
+	Contextos []string `json:"contextos"` // vazio: todos os contextos
+	Reindexar bool     `json:"reindexar"` // refaz também os embeddings existentes
+}
+
+// Resultado do job JOB_TIPO_EMBEDDINGS_AUTOS
+type ResultadoEmbeddingsAutos struct {
+	Contextos  int      `json:"contextos"`
+	Documentos int      `json:"documentos"`
+	Indexados  int      `json:"indexados"`
+	Pulados    int      `json:"pulados"`
+	Erros      []string `json:"erros"`
+}
+
+// Tamanho da página na listagem de todos os contextos
+const PAGINA_CONTEXTOS_EMBEDDINGS = 100
+
+/*
+executarEmbeddingsAutos gera os embeddings dos documentos de "autos" nos contextos
+informados (ou em todos), para os autuados antes da indexação em "autos_json_embedding".
+*/
+func executarEmbeddingsAutos(ctx context.Context, job *models.JobRow, progresso JobProgressoFunc) (any, error) {
+	var params ParamsEmbeddingsAutos
+	if len(job.Params) > 0 {
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, fmt.Errorf("parâmetros do job inválidos: %w", err)
+		}
+	}
+	if AutosServiceGlobal == nil || AutosJsonServiceGlobal == nil {
+		return nil, fmt.Errorf("serviços 'autos' e 'autos_json_embedding' não inicializados")
+	}
+
+	contextos := params.Contextos
+	if len(contextos) == 0 {
+		for offset := 0; ; offset += PAGINA_CONTEXTOS_EMBEDDINGS {
+			rows, err := ContextoServiceGlobal.SelectContextos(PAGINA_CONTEXTOS_EMBEDDINGS, offset)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao listar os contextos: %w", err)
+			}
+			for _, r := range rows {
+				contextos = append(contextos, r.IdCtxt)
+			}
+			if len(rows) < PAGINA_CONTEXTOS_EMBEDDINGS {
+				break
+			}
+		}
+	}
+
+	res := ResultadoEmbeddingsAutos{Contextos: len(contextos), Erros: []string{}}
+	for i, idCtxt := range contextos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progresso(i*100/len(contextos), fmt.Sprintf("contexto %s (%d de %d)", idCtxt, i+1, len(contextos)))
+
+		docs, err := AutosServiceGlobal.SelectByContexto(idCtxt)
+		if err != nil {
+			res.Erros = append(res.Erros, fmt.Sprintf("contexto %s: %v", idCtxt, err))
+			continue
+		}
+		indexados := map[string]bool{}
+		if !params.Reindexar {
+			regs, err := AutosJsonServiceGlobal.SelectByContexto(idCtxt)
+			if err != nil {
+				res.Erros = append(res.Erros, fmt.Sprintf("contexto %s: %v", idCtxt, err))
+				continue
+			}
+			for _, reg := range regs {
+				indexados[reg.IdDoc] = true
+			}
+		}
+
+		for j := range docs {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			res.Documentos++
+			if indexados[docs[j].Id] {
+				res.Pulados++
+				continue
+			}
+			if err := AutosJsonServiceGlobal.IndexarAutos(ctx, &docs[j]); err != nil {
+				res.Erros = append(res.Erros, err.Error())
+				continue
+			}
+			res.Indexados++
+		}
+	}
+	progresso(100, fmt.Sprintf("%d documentos indexados", res.Indexados))
+	return res, nil
+}
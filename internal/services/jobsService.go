@@ -0,0 +1,376 @@
+/*
+---------------------------------------------------------------------------------------
+File: jobsService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Fila persistente de jobs assíncronos (extração de PDF, autuação e pipeline
+RAG). Os jobs são gravados na tabela "jobs" e consumidos por um pool de workers; o
+cliente acompanha status, progresso, resultado e erro em /jobs/:id e pode cancelar.
+Jobs interrompidos por uma parada do servidor são retomados (se o tipo permitir e
+houver tentativas disponíveis) ou marcados como falha.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/consts"
+	"ocrserver/internal/models"
+	"ocrserver/internal/utils/logger"
+
+	"github.com/google/uuid"
+)
+
+// Intervalo de renovação do heartbeat e prazo para considerar um job interrompido
+const (
+	jobHeartbeatInterval = 15 * time.Second
+	jobHeartbeatLimite   = 2 * time.Minute
+)
+
+// JobProgressoFunc informa o avanço do job (0 a 100) e uma mensagem curta.
+type JobProgressoFunc func(progresso int, mensagem string)
+
+// JobExecutor executa um job. O retorno é serializado em JSON no campo "resultado".
+type JobExecutor func(ctx context.Context, job *models.JobRow, progresso JobProgressoFunc) (any, error)
+
+type jobTipo struct {
+	executor JobExecutor
+	// retomavel indica se o job pode ser reexecutado após uma parada do servidor
+	retomavel bool
+}
+
+type JobsServiceType struct {
+	Model *models.JobsModelType
+	cfg   *config.Config
+
+	mu         sync.Mutex
+	tipos      map[string]jobTipo
+	executando map[string]context.CancelFunc // jobs em execução nesta instância
+
+	aviso chan struct{} // acorda os workers quando um job é submetido
+}
+
+var JobsServiceGlobal *JobsServiceType
+var onceInitJobsService sync.Once
+
+func InitJobsService(model *models.JobsModelType, cfg *config.Config) {
+	onceInitJobsService.Do(func() {
+		JobsServiceGlobal = NewJobsService(model, cfg)
+
+		registrarJobsNativos(JobsServiceGlobal)
+
+		logger.Log.Info("Global JobsService configurado com sucesso.")
+	})
+}
+
+func NewJobsService(model *models.JobsModelType, cfg *config.Config) *JobsServiceType {
+	return &JobsServiceType{
+		Model:      model,
+		cfg:        cfg,
+		tipos:      make(map[string]jobTipo),
+		executando: make(map[string]context.CancelFunc),
+		aviso:      make(chan struct{}, 1),
+	}
+}
+
+// Registrar associa um tipo de job ao seu executor.
+func (obj *JobsServiceType) Registrar(tipo string, retomavel bool, executor JobExecutor) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	obj.tipos[tipo] = jobTipo{executor: executor, retomavel: retomavel}
+}
+
+func (obj *JobsServiceType) tiposRegistrados() []string {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	tipos := make([]string, 0, len(obj.tipos))
+	for t := range obj.tipos {
+		tipos = append(tipos, t)
+	}
+	return tipos
+}
+
+func (obj *JobsServiceType) getTipo(tipo string) (jobTipo, bool) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	t, ok := obj.tipos[tipo]
+	return t, ok
+}
+
+// Submeter grava um novo job na fila e devolve o registro criado.
+func (obj *JobsServiceType) Submeter(tipo string, params any, userName string) (*models.JobRow, error) {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return nil, fmt.Errorf("serviço de jobs não iniciado")
+	}
+	if _, ok := obj.getTipo(tipo); !ok {
+		return nil, fmt.Errorf("tipo de job desconhecido: %s", tipo)
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar os parâmetros do job: %w", err)
+	}
+
+	row, err := obj.Model.InsertRow(uuid.NewString(), tipo, raw, userName)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.Infof("Job %s (%s) submetido por %s", row.IdJob, tipo, userName)
+
+	// acorda um worker sem bloquear
+	select {
+	case obj.aviso <- struct{}{}:
+	default:
+	}
+	return row, nil
+}
+
+func (obj *JobsServiceType) SelectById(idJob string) (*models.JobRow, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("serviço de jobs não iniciado")
+	}
+	return obj.Model.SelectById(idJob)
+}
+
+func (obj *JobsServiceType) SelectByUser(userName string, limite int) ([]models.JobRow, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("serviço de jobs não iniciado")
+	}
+	return obj.Model.SelectByUser(userName, limite)
+}
+
+/*
+Cancelar solicita o cancelamento do job. Pendentes são cancelados na hora; em execução
+nesta instância, o contexto é cancelado imediatamente; em outra instância, o worker
+percebe o flag no próximo heartbeat. Devolve false se o job já estava finalizado.
+*/
+func (obj *JobsServiceType) Cancelar(idJob string) (bool, error) {
+	if obj == nil {
+		return false, fmt.Errorf("serviço de jobs não iniciado")
+	}
+	ok, err := obj.Model.SolicitarCancelamento(idJob)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	obj.mu.Lock()
+	cancel := obj.executando[idJob]
+	obj.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	logger.Log.Infof("Cancelamento solicitado para o job %s", idJob)
+	return true, nil
+}
+
+// Start recupera os jobs interrompidos e inicia o pool de workers. Para parar, cancele o ctx.
+func (obj *JobsServiceType) Start(ctx context.Context) {
+	if obj == nil || obj.Model == nil {
+		logger.Log.Error("JobsService: serviço não iniciado")
+		return
+	}
+
+	workers := 2
+	poll := 2 * time.Second
+	if obj.cfg != nil {
+		if obj.cfg.JobsWorkers > 0 {
+			workers = obj.cfg.JobsWorkers
+		}
+		if obj.cfg.JobsPollInterval > 0 {
+			poll = obj.cfg.JobsPollInterval
+		}
+	}
+
+	obj.recuperarInterrompidos()
+
+	for i := 0; i < workers; i++ {
+		go obj.worker(ctx, i+1, poll)
+	}
+
+	// Verificação periódica: jobs de instâncias que pararam sem finalizar
+	go func() {
+		ticker := time.NewTicker(jobHeartbeatLimite)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				obj.recuperarInterrompidos()
+			}
+		}
+	}()
+
+	logger.Log.Infof("JobsService: %d workers iniciados", workers)
+}
+
+func (obj *JobsServiceType) maxTentativas() int {
+	if obj.cfg != nil && obj.cfg.JobsMaxTentativas > 0 {
+		return obj.cfg.JobsMaxTentativas
+	}
+	return 3
+}
+
+// recuperarInterrompidos reenfileira ou marca como falha os jobs sem heartbeat.
+func (obj *JobsServiceType) recuperarInterrompidos() {
+	rows, err := obj.Model.SelectInterrompidos(time.Now().Add(-jobHeartbeatLimite))
+	if err != nil {
+		logger.Log.Errorf("JobsService: %v", err)
+		return
+	}
+
+	for _, job := range rows {
+		t, ok := obj.getTipo(job.Tipo)
+		switch {
+		case job.Cancelar:
+			err = obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_CANCELADO, nil, "cancelado durante a interrupção do servidor")
+		case ok && t.retomavel && job.Tentativas < obj.maxTentativas():
+			logger.Log.Warningf("JobsService: job %s (%s) interrompido; retomando", job.IdJob, job.Tipo)
+			err = obj.Model.Reenfileirar(job.IdJob, "interrompido pela parada do servidor; retomado")
+		default:
+			logger.Log.Warningf("JobsService: job %s (%s) interrompido; marcado como falha", job.IdJob, job.Tipo)
+			err = obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_FALHA, nil, "interrompido pela parada do servidor")
+		}
+		if err != nil {
+			logger.Log.Errorf("JobsService: erro ao recuperar o job %s: %v", job.IdJob, err)
+		}
+	}
+}
+
+func (obj *JobsServiceType) worker(ctx context.Context, n int, poll time.Duration) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		// Consome a fila até esvaziar
+		for ctx.Err() == nil {
+			job, err := obj.Model.ReservarProximo(obj.tiposRegistrados())
+			if err != nil {
+				logger.Log.Errorf("JobsService worker %d: %v", n, err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			obj.executar(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Log.Infof("JobsService worker %d: finalizando (ctx cancelado).", n)
+			return
+		case <-obj.aviso:
+		case <-ticker.C:
+		}
+	}
+}
+
+// executar roda o job reservado, mantendo heartbeat e atendendo ao cancelamento.
+func (obj *JobsServiceType) executar(appCtx context.Context, job *models.JobRow) {
+	t, ok := obj.getTipo(job.Tipo)
+	if !ok {
+		_ = obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_FALHA, nil, "tipo de job sem executor: "+job.Tipo)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(appCtx)
+	defer cancel()
+
+	obj.mu.Lock()
+	obj.executando[job.IdJob] = cancel
+	obj.mu.Unlock()
+	defer func() {
+		obj.mu.Lock()
+		delete(obj.executando, job.IdJob)
+		obj.mu.Unlock()
+	}()
+
+	// Heartbeat: sinaliza que o job está vivo e detecta cancelamento feito por outra instância
+	go func() {
+		ticker := time.NewTicker(jobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cancelar, err := obj.Model.Heartbeat(job.IdJob)
+				if err != nil {
+					logger.Log.Warningf("JobsService: %v", err)
+					continue
+				}
+				if cancelar {
+					cancel()
+				}
+			}
+		}
+	}()
+
+	progresso := func(p int, msg string) {
+		if p < 0 {
+			p = 0
+		} else if p > 100 {
+			p = 100
+		}
+		cancelar, err := obj.Model.UpdateProgresso(job.IdJob, p, msg)
+		if err != nil {
+			logger.Log.Warningf("JobsService: %v", err)
+			return
+		}
+		if cancelar {
+			cancel()
+		}
+	}
+
+	logger.Log.Infof("JobsService: iniciando job %s (%s) - tentativa %d", job.IdJob, job.Tipo, job.Tentativas)
+	resultado, err := obj.rodarExecutor(ctx, t.executor, job, progresso)
+
+	// Parada do servidor: o job fica "executando" e será recuperado no próximo início
+	if appCtx.Err() != nil {
+		logger.Log.Warningf("JobsService: job %s interrompido pela parada do servidor", job.IdJob)
+		return
+	}
+
+	if ctx.Err() != nil && errors.Is(ctx.Err(), context.Canceled) {
+		_ = obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_CANCELADO, nil, "cancelado pelo usuário")
+		logger.Log.Infof("JobsService: job %s cancelado", job.IdJob)
+		return
+	}
+
+	if err != nil {
+		logger.Log.Errorf("JobsService: job %s falhou: %v", job.IdJob, err)
+		_ = obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_FALHA, nil, err.Error())
+		return
+	}
+
+	raw, err := json.Marshal(resultado)
+	if err != nil {
+		_ = obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_FALHA, nil, "erro ao serializar o resultado: "+err.Error())
+		return
+	}
+	if err := obj.Model.Finalizar(job.IdJob, consts.JOB_STATUS_CONCLUIDO, raw, ""); err != nil {
+		logger.Log.Errorf("JobsService: %v", err)
+		return
+	}
+	logger.Log.Infof("JobsService: job %s concluído", job.IdJob)
+}
+
+// rodarExecutor protege o worker contra panics no executor.
+func (obj *JobsServiceType) rodarExecutor(ctx context.Context, executor JobExecutor, job *models.JobRow, progresso JobProgressoFunc) (res any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Log.Errorf("panic no job %s: %v\n%s", job.IdJob, r, debug.Stack())
+			err = fmt.Errorf("panic no job: %v", r)
+		}
+	}()
+	return executor(ctx, job, progresso)
+}
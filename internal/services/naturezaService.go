@@ -0,0 +1,291 @@
+/*
+---------------------------------------------------------------------------------------
+File: naturezaService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Classificação local da natureza dos documentos extraídos, antes de recorrer
+ao modelo. Primeiro as regras (marcadores de movimentação, certidão e ato ordinatório,
+os mesmos do prompt de VerificarNaturezaDocumento); depois a pontuação do título (as
+denominações de consts.DescricoesNatureza nas primeiras linhas) e das expressões
+típicas de cada peça. A confiança cai quando a pontuação é baixa ou quando duas
+naturezas empatam; abaixo de NATUREZA_CONFIANCA_MINIMA, a natureza é pedida ao
+modelo. Toda decisão é registrada em "naturezas_classificadas", com a fonte e a
+confiança.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/models"
+	"ocrserver/internal/utils/logger"
+)
+
+// Fonte da natureza atribuída ao documento
+const (
+	NATUREZA_FONTE_REGRA    = "regra"          // marcadores de movimentação, certidão e ato ordinatório
+	NATUREZA_FONTE_PALAVRAS = "palavras-chave" // pontuação do título e das expressões típicas
+	NATUREZA_FONTE_MODELO   = "modelo"         // confiança local baixa: decisão do modelo
+)
+
+const (
+	PESO_TITULO_NATUREZA     = 6  // denominação da natureza como título do documento
+	PESO_EXPRESSAO_NATUREZA  = 1  // cada expressão típica encontrada
+	PONTOS_CONFIANCA_PLENA   = 6  // pontuação a partir da qual só o empate reduz a confiança
+	MAX_LINHAS_TITULO        = 20 // linhas não vazias examinadas em busca do título
+	MAX_TAMANHO_LINHA_TITULO = 80
+	MAX_CARACTERES_CABECALHO = 1500 // início do texto, onde ficam os marcadores das regras
+)
+
+// Naturezas que o classificador atribui (além de outros documentos e movimentação)
+var naturezasClassificaveis = []int{
+	consts.NATU_DOC_INICIAL,
+	consts.NATU_DOC_CONTESTACAO,
+	consts.NATU_DOC_REPLICA,
+	consts.NATU_DOC_DESPACHO,
+	consts.NATU_DOC_PETICAO,
+	consts.NATU_DOC_DECISAO,
+	consts.NATU_DOC_SENTENCA,
+	consts.NATU_DOC_EMBARGOS,
+	consts.NATU_DOC_APELACAO,
+	consts.NATU_DOC_CONTRA_RAZOES,
+	consts.NATU_DOC_PROCURACAO,
+	consts.NATU_DOC_ROL_TESTEMUNHAS,
+	consts.NATU_DOC_CONTRATO,
+	consts.NATU_DOC_LAUDO_PERICIAL,
+	consts.NATU_DOC_TERMO_AUDIENCIA,
+	consts.NATU_DOC_PARECER_MP,
+	consts.NATU_DOC_CERTIDAO,
+}
+
+// Expressões típicas de cada natureza, já sem acentos e em minúsculas
+var expressoesNatureza = map[int][]string{
+	consts.NATU_DOC_INICIAL:         {"vem propor", "propor a presente", "ajuizar a presente", "dos pedidos", "valor da causa", "da-se a causa", "requer a citacao"},
+	consts.NATU_DOC_CONTESTACAO:     {"contestacao", "apresentar contestacao", "preliminarmente", "no merito", "improcedencia dos pedidos", "impugna"},
+	consts.NATU_DOC_REPLICA:         {"replica", "impugnacao a contestacao", "em replica", "argumentos da contestacao"},
+	consts.NATU_DOC_DESPACHO:        {"cite-se", "intime-se", "intimem-se", "cumpra-se", "expeca-se", "manifeste-se", "vista ao", "aguarde-se"},
+	consts.NATU_DOC_PETICAO:         {"requer a juntada", "nestes termos", "pede deferimento", "vem, respeitosamente", "manifestar"},
+	consts.NATU_DOC_DECISAO:         {"decisao", "defiro", "indefiro", "tutela de urgencia", "tutela antecipada", "decido", "liminar"},
+	consts.NATU_DOC_SENTENCA:        {"sentenca", "julgo procedente", "julgo improcedente", "julgo parcialmente procedente", "resolvo o merito", "extingo o processo", "p.r.i", "publique-se. registre-se", "transito em julgado"},
+	consts.NATU_DOC_EMBARGOS:        {"embargos de declaracao", "omissao", "contradicao", "obscuridade", "efeitos infringentes"},
+	consts.NATU_DOC_APELACAO:        {"apelacao", "razoes de apelacao", "recurso de apelacao", "egregio tribunal", "reforma da sentenca"},
+	consts.NATU_DOC_CONTRA_RAZOES:   {"contrarrazoes", "contra-razoes", "contrarazoes", "manutencao da sentenca", "desprovimento do recurso"},
+	consts.NATU_DOC_PROCURACAO:      {"procuracao", "outorgante", "outorgado", "ad judicia", "poderes para"},
+	consts.NATU_DOC_ROL_TESTEMUNHAS: {"rol de testemunhas", "testemunhas"},
+	consts.NATU_DOC_CONTRATO:        {"contratante", "contratada", "clausula", "objeto do contrato"},
+	consts.NATU_DOC_LAUDO_PERICIAL:  {"laudo", "perito", "quesitos", "pericia"},
+	consts.NATU_DOC_TERMO_AUDIENCIA: {"termo de audiencia", "ata de audiencia", "aberta a audiencia", "presentes", "conciliacao"},
+	consts.NATU_DOC_PARECER_MP:      {"ministerio publico", "promotor de justica", "promotora de justica", "procurador de justica", "parecer"},
+	consts.NATU_DOC_CERTIDAO:        {"certidao", "certifico", "dou fe"},
+}
+
+var (
+	// "Mov. 12", "Movimentação 3": linhas do extrato de movimentações
+	reLinhaMovimento = regexp.MustCompile(`(?m)^\s*mov(?:imentacao)?\.?\s*:?\s*\d+`)
+	// corpo de decisão (com fundamentação e conclusão), que afasta a regra da movimentação
+	reCorpoDecisorio = regexp.MustCompile(`\b(julgo|decido|defiro|indefiro|ante o exposto|isto posto|diante do exposto|dispositivo)\b`)
+	reCertidao       = regexp.MustCompile(`certifico|dou fe|o referido e verdade`)
+	reAtoOrdinatorio = regexp.MustCompile(`ato ordinatorio|por ordem d[oa] mm|teor do ato|(edital|relacao) para publicacao`)
+	rePontuacaoLinha = regexp.MustCompile(`^[\s\p{P}]+|[\s\p{P}]+$`)
+)
+
+type NaturezaServiceType struct {
+	Model *models.NaturezaModelType
+}
+
+var NaturezaServiceGlobal *NaturezaServiceType
+var onceInitNaturezaService sync.Once
+
+func InitNaturezaService(model *models.NaturezaModelType) {
+	onceInitNaturezaService.Do(func() {
+		NaturezaServiceGlobal = &NaturezaServiceType{Model: model}
+
+		logger.Log.Info("Global NaturezaService configurado com sucesso.")
+	})
+}
+
+/*
+ClassificaNaturezaLocal classifica o documento sem chamar o modelo, devolvendo a
+natureza, a fonte (regra ou palavras-chave), a confiança (0 a 1) e o motivo.
+*/
+func ClassificaNaturezaLocal(texto string) NaturezaDoc {
+	norm := consts.NormalizaTexto(texto)
+	cabecalho := norm
+	if len(cabecalho) > MAX_CARACTERES_CABECALHO {
+		cabecalho = strings.ToValidUTF8(cabecalho[:MAX_CARACTERES_CABECALHO], "")
+	}
+	titulo, natuTitulo := tituloNatureza(texto)
+
+	// 1) Regras
+	if !reCorpoDecisorio.MatchString(norm) {
+		movimentos := len(reLinhaMovimento.FindAllStringIndex(norm, -1))
+		if movimentos >= 2 || strings.Contains(norm, "observacoes dos movimentos") {
+			return naturezaRegra(consts.NATU_DOC_MOVIMENTACAO, 0.9, fmt.Sprintf("extrato de movimentações (%d linhas \"Mov.\")", movimentos))
+		}
+	}
+	if natuTitulo == consts.NATU_DOC_CERTIDAO || reCertidao.MatchString(cabecalho) {
+		return naturezaRegra(consts.NATU_DOC_CERTIDAO, 0.9, "marcador de certidão")
+	}
+	if m := reAtoOrdinatorio.FindString(cabecalho); m != "" && natuTitulo == 0 {
+		return naturezaRegra(consts.NATU_DOC_OUTROS, 0.85, fmt.Sprintf("ato ordinatório (%q)", m))
+	}
+
+	// 2) Pontuação do título e das expressões
+	pontos := make(map[int]int)
+	achadas := make(map[int][]string)
+	if natuTitulo > 0 {
+		pontos[natuTitulo] += PESO_TITULO_NATUREZA
+	}
+	for natu, expressoes := range expressoesNatureza {
+		for _, e := range expressoes {
+			if strings.Contains(norm, e) {
+				pontos[natu] += PESO_EXPRESSAO_NATUREZA
+				achadas[natu] = append(achadas[natu], e)
+			}
+		}
+	}
+
+	ordem := make([]int, 0, len(pontos))
+	for natu := range pontos {
+		ordem = append(ordem, natu)
+	}
+	sort.Slice(ordem, func(i, j int) bool {
+		if pontos[ordem[i]] != pontos[ordem[j]] {
+			return pontos[ordem[i]] > pontos[ordem[j]]
+		}
+		return ordem[i] < ordem[j]
+	})
+	if len(ordem) == 0 {
+		return NaturezaDoc{
+			Key:         consts.NATU_DOC_OUTROS,
+			Description: consts.GetNaturezaDocumento(consts.NATU_DOC_OUTROS),
+			Fonte:       NATUREZA_FONTE_PALAVRAS,
+			Motivo:      "nenhum título ou expressão típica",
+		}
+	}
+
+	melhor := ordem[0]
+	segundo := 0
+	if len(ordem) > 1 {
+		segundo = pontos[ordem[1]]
+	}
+	confianca := confiancaNatureza(pontos[melhor], segundo)
+
+	var motivo []string
+	if natuTitulo == melhor {
+		motivo = append(motivo, fmt.Sprintf("título %q", titulo))
+	}
+	if len(achadas[melhor]) > 0 {
+		motivo = append(motivo, "expressões: "+strings.Join(achadas[melhor], ", "))
+	}
+	if segundo > 0 {
+		motivo = append(motivo, fmt.Sprintf("segunda: %s (%d pontos)", consts.GetNaturezaDocumento(ordem[1]), segundo))
+	}
+
+	return NaturezaDoc{
+		Key:         melhor,
+		Description: consts.GetNaturezaDocumento(melhor),
+		Fonte:       NATUREZA_FONTE_PALAVRAS,
+		Confianca:   confianca,
+		Motivo:      fmt.Sprintf("%d pontos; %s", pontos[melhor], strings.Join(motivo, "; ")),
+	}
+}
+
+func naturezaRegra(key int, confianca float64, motivo string) NaturezaDoc {
+	return NaturezaDoc{
+		Key:         key,
+		Description: consts.GetNaturezaDocumento(key),
+		Fonte:       NATUREZA_FONTE_REGRA,
+		Confianca:   confianca,
+		Motivo:      motivo,
+	}
+}
+
+/*
+confiancaNatureza combina a margem sobre a segunda natureza com a pontuação absoluta:
+título e expressões sem concorrente dão 1; expressões soltas ou empate, perto de 0.
+*/
+func confiancaNatureza(melhor int, segundo int) float64 {
+	if melhor <= 0 {
+		return 0
+	}
+	margem := float64(melhor-segundo) / float64(melhor)
+	forca := math.Min(1, float64(melhor)/PONTOS_CONFIANCA_PLENA)
+	return math.Round(margem*forca*100) / 100
+}
+
+/*
+tituloNatureza procura, nas primeiras linhas curtas, uma denominação conhecida da
+natureza (a mais longa, para "Embargos de Declaração" prevalecer sobre "Petição").
+*/
+func tituloNatureza(texto string) (string, int) {
+	lidas := 0
+	for _, linha := range strings.Split(texto, "\n") {
+		linha = strings.TrimSpace(linha)
+		if linha == "" {
+			continue
+		}
+		if lidas++; lidas > MAX_LINHAS_TITULO {
+			break
+		}
+		if len(linha) > MAX_TAMANHO_LINHA_TITULO {
+			continue
+		}
+		norm := rePontuacaoLinha.ReplaceAllString(consts.NormalizaTipo(linha), "")
+
+		natu, maior := 0, ""
+		for _, key := range naturezasClassificaveis {
+			for _, desc := range consts.DescricoesNatureza(key) {
+				d := consts.NormalizaTipo(desc)
+				if (norm == d || strings.HasPrefix(norm, d+" ")) && len(d) > len(maior) {
+					natu, maior = key, d
+				}
+			}
+		}
+		if natu > 0 {
+			return maior, natu
+		}
+	}
+	return "", 0
+}
+
+/*
+Registra grava a decisão sobre a natureza do documento, com a sugestão do classificador
+local (quando a decisão é do modelo, mostra por que ele foi chamado).
+*/
+func (obj *NaturezaServiceType) Registra(idCtxt string, idDoc string, natureza NaturezaDoc, local NaturezaDoc) {
+	if obj == nil {
+		return
+	}
+	_, err := obj.Model.InsertRow(models.NaturezaClassificadaRow{
+		IdCtxt:         idCtxt,
+		IdDoc:          idDoc,
+		IdNatu:         natureza.Key,
+		Fonte:          natureza.Fonte,
+		Confianca:      natureza.Confianca,
+		Motivo:         natureza.Motivo,
+		IdNatuLocal:    local.Key,
+		ConfiancaLocal: local.Confianca,
+		DtInc:          time.Now(),
+	})
+	if err != nil {
+		logger.Log.Errorf("Erro ao registrar a classificação do documento %s - contexto=%s: %v", idDoc, idCtxt, err)
+	}
+}
+
+// SelectByContexto lista as classificações registradas no contexto
+func (obj *NaturezaServiceType) SelectByContexto(idCtxt string, limite int) ([]models.NaturezaClassificadaRow, error) {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return nil, fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+	return obj.Model.SelectByContexto(idCtxt, limite)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+
+	"ocrserver/internal/consts"
+)
+
+func TestClassificaNaturezaLocal(t *testing.T) {
+	casos := []struct {
+		nome         string
+		texto        string
+		key          int
+		fonte        string
+		confiancaMin float64
+		confiancaMax float64
+	}{
+		{
+			nome:  "extrato de movimentações",
+			texto: "Mov. 1 - Distribuído\nMov. 2 - Conclusos\nMov. 3 - Juntada de petição",
+			key:   consts.NATU_DOC_MOVIMENTACAO, fonte: NATUREZA_FONTE_REGRA, confiancaMin: 0.9, confiancaMax: 0.9,
+		},
+		{
+			nome:  "movimentação com corpo decisório não é extrato",
+			texto: "SENTENÇA\nMov. 1 - Distribuído\nMov. 2 - Conclusos\nAnte o exposto, julgo procedente o pedido.",
+			key:   consts.NATU_DOC_SENTENCA, fonte: NATUREZA_FONTE_PALAVRAS, confiancaMin: 0.5, confiancaMax: 1,
+		},
+		{
+			nome:  "certidão pelo marcador",
+			texto: "Certifico e dou fé que decorreu o prazo sem manifestação.",
+			key:   consts.NATU_DOC_CERTIDAO, fonte: NATUREZA_FONTE_REGRA, confiancaMin: 0.9, confiancaMax: 0.9,
+		},
+		{
+			nome:  "ato ordinatório",
+			texto: "ATO ORDINATÓRIO\nPor ordem do MM. Juiz, intime-se a parte autora.",
+			key:   consts.NATU_DOC_OUTROS, fonte: NATUREZA_FONTE_REGRA, confiancaMin: 0.85, confiancaMax: 0.85,
+		},
+		{
+			nome:  "sentença pelo título e pelas expressões",
+			texto: "SENTENÇA\n\nVistos etc.\nJulgo procedente o pedido e resolvo o mérito. P.R.I.",
+			key:   consts.NATU_DOC_SENTENCA, fonte: NATUREZA_FONTE_PALAVRAS, confiancaMin: 0.7, confiancaMax: 1,
+		},
+		{
+			nome:  "petição inicial pelas expressões",
+			texto: "Fulano vem propor a presente ação. Dos pedidos: requer a citação do réu. Valor da causa: R$ 1.000,00.",
+			key:   consts.NATU_DOC_INICIAL, fonte: NATUREZA_FONTE_PALAVRAS, confiancaMin: 0.5, confiancaMax: 1,
+		},
+		{
+			nome:  "embargos de declaração prevalecem sobre petição no título",
+			texto: "EMBARGOS DE DECLARAÇÃO\n\nHá omissão e contradição na sentença.",
+			key:   consts.NATU_DOC_EMBARGOS, fonte: NATUREZA_FONTE_PALAVRAS, confiancaMin: 0.5, confiancaMax: 1,
+		},
+		{
+			nome:  "sem título nem expressões",
+			texto: "Lorem ipsum dolor sit amet.",
+			key:   consts.NATU_DOC_OUTROS, fonte: NATUREZA_FONTE_PALAVRAS, confiancaMin: 0, confiancaMax: 0,
+		},
+		{
+			nome:  "empate entre expressões derruba a confiança",
+			texto: "Cite-se. Defiro.",
+			key:   consts.NATU_DOC_DESPACHO, fonte: NATUREZA_FONTE_PALAVRAS, confiancaMin: 0, confiancaMax: 0,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			natu := ClassificaNaturezaLocal(c.texto)
+			if natu.Key != c.key || natu.Fonte != c.fonte {
+				t.Fatalf("natureza = %d (%s), esperada %d (%s); motivo: %s", natu.Key, natu.Fonte, c.key, c.fonte, natu.Motivo)
+			}
+			if natu.Confianca < c.confiancaMin || natu.Confianca > c.confiancaMax {
+				t.Errorf("confiança = %.2f, esperada entre %.2f e %.2f; motivo: %s", natu.Confianca, c.confiancaMin, c.confiancaMax, natu.Motivo)
+			}
+			if natu.Description != consts.GetNaturezaDocumento(c.key) {
+				t.Errorf("descrição = %q, esperada %q", natu.Description, consts.GetNaturezaDocumento(c.key))
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+---------------------------------------------------------------------------------------
+File: ocr.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Reconhecimento de texto (OCR) das páginas digitalizadas dos autos. As peças
+escaneadas (procurações, contratos, laudos) não têm camada de texto no PDF do PJe; a
+imagem da página é entregue ao motor configurado em OCR_MOTOR. O motor padrão é o
+Tesseract, executado pela linha de comando; "nenhum" desativa o OCR.
+---------------------------------------------------------------------------------------
+*/
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Motores de OCR (variável OCR_MOTOR)
+const (
+	MOTOR_TESSERACT = "tesseract"
+	MOTOR_NENHUM    = "nenhum"
+)
+
+// Motor reconhece o texto de uma imagem; formato é a extensão do arquivo (jpg, png, tif, jp2)
+type Motor interface {
+	Nome() string
+	Reconhece(ctx context.Context, imagem []byte, formato string) (string, error)
+}
+
+/*
+NewMotor devolve o motor de OCR configurado. Com "nenhum", devolve nil (OCR desativado).
+O idioma segue a convenção do Tesseract (ex.: "por", "por+eng").
+*/
+func NewMotor(nome string, idioma string) (Motor, error) {
+	switch strings.ToLower(strings.TrimSpace(nome)) {
+	case MOTOR_NENHUM:
+		return nil, nil
+	case "", MOTOR_TESSERACT:
+		return NewMotorTesseract(idioma)
+	}
+	return nil, fmt.Errorf("motor de OCR desconhecido: %q (use tesseract ou nenhum)", nome)
+}
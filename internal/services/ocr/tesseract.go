@@ -0,0 +1,73 @@
+/*
+---------------------------------------------------------------------------------------
+File: tesseract.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Motor de OCR que executa o Tesseract pela linha de comando. A imagem é
+gravada num arquivo temporário (o Tesseract identifica o formato pela extensão) e o
+texto reconhecido é lido da saída padrão.
+---------------------------------------------------------------------------------------
+*/
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// tempo máximo de reconhecimento de uma página
+const timeoutPaginaTesseract = 2 * time.Minute
+
+type MotorTesseract struct {
+	executavel string
+	idioma     string
+}
+
+// NewMotorTesseract localiza o executável "tesseract" no PATH
+func NewMotorTesseract(idioma string) (*MotorTesseract, error) {
+	exe, err := exec.LookPath("tesseract")
+	if err != nil {
+		return nil, fmt.Errorf("tesseract não encontrado no PATH: %w", err)
+	}
+	if strings.TrimSpace(idioma) == "" {
+		idioma = "por"
+	}
+	return &MotorTesseract{executavel: exe, idioma: idioma}, nil
+}
+
+func (m *MotorTesseract) Nome() string {
+	return MOTOR_TESSERACT
+}
+
+func (m *MotorTesseract) Reconhece(ctx context.Context, imagem []byte, formato string) (string, error) {
+	arq, err := os.CreateTemp("", "ocr-*."+formato)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(arq.Name())
+	if _, err := arq.Write(imagem); err != nil {
+		arq.Close()
+		return "", fmt.Errorf("erro ao gravar a imagem: %w", err)
+	}
+	if err := arq.Close(); err != nil {
+		return "", fmt.Errorf("erro ao gravar a imagem: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeoutPaginaTesseract)
+	defer cancel()
+
+	// --psm 1: segmentação automática com detecção de orientação (páginas escaneadas de lado)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, m.executavel, arq.Name(), "stdout", "-l", m.idioma, "--psm", "1")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("erro executando tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(strings.ReplaceAll(stdout.String(), "\f", "")), nil
+}
@@ -53,6 +53,24 @@ func InitOpenaiService(apiKey string, cfg *config.Config) {
 	})
 }
 
+/*
+getProvider
+Seleciona o backend de IA configurado para a tarefa (LLM_PROVIDER_GERACAO ou
+LLM_PROVIDER_EMBEDDING). Na ausência de configuração, usa a OpenAI.
+*/
+func (obj *OpenaiServiceType) getProvider(tarefa string) (ialib.LLMProvider, error) {
+	nome := ialib.PROVIDER_OPENAI
+	if obj.cfg != nil {
+		switch tarefa {
+		case ialib.TAREFA_GERACAO:
+			nome = obj.cfg.LLMProviderGeracao
+		case ialib.TAREFA_EMBEDDING:
+			nome = obj.cfg.LLMProviderEmbedding
+		}
+	}
+	return ialib.GetProvider(nome)
+}
+
 /*
 *
 Obtém a representação vetorial do texto enviado. Quem for utilizar o valor retornadotem
@@ -72,21 +90,29 @@ func (obj *OpenaiServiceType) GetEmbeddingFromText(
 		defer cancel()
 	}
 
-	vec32, resp, err := ialib.OpenaiGlobal.GetEmbeddingFromText_openai(ctx, inputTxt)
+	provider, err := obj.getProvider(ialib.TAREFA_EMBEDDING)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vec32, usage, err := provider.GetEmbedding(ctx, inputTxt)
 	if err != nil {
 		return nil, nil, fmt.Errorf("falha ao obter embedding: %w", err)
 	}
 
-	usage := resp.Usage
 	if SessionServiceGlobal != nil {
 		SessionServiceGlobal.UpdateTokensUso(usage.PromptTokens, usage.TotalTokens-usage.PromptTokens, usage.TotalTokens)
 	}
 
-	return vec32, &usage, nil
+	return vec32, usage, nil
 }
 
 /*
 modelo: nome do modelo a usar, ou uma string vazia("")
+
+Com o formato da resposta no contexto (ialib.WithFormatoJSON), o provedor com saída
+estruturada recebe o JSON Schema no modo estrito; nos demais, o schema segue no prompt
+e a resposta é extraída e validada (submeteExtraindoJSON).
 */
 func (obj *OpenaiServiceType) SubmitPromptResponse(
 	ctx context.Context,
@@ -100,12 +126,46 @@ func (obj *OpenaiServiceType) SubmitPromptResponse(
 		return nil, fmt.Errorf("serviço OpenAI não iniciado")
 	}
 
-	rsp, err := ialib.OpenaiGlobal.SubmitPromptResponse_openai(ctx,
-		inputMsgs,
-		prevID,
-		modelo,
-		effort,
-		verbosity)
+	provider, err := obj.getProvider(ialib.TAREFA_GERACAO)
+	if err != nil {
+		return nil, err
+	}
+
+	if formato := ialib.FormatoJSONFromContext(ctx); formato != nil && !ialib.SuportaFormatoJSON(provider) {
+		return obj.submeteExtraindoJSON(ctx, provider, formato, inputMsgs, prevID, modelo, effort, verbosity)
+	}
+	return obj.submete(ctx, provider, inputMsgs, prevID, modelo, effort, verbosity)
+}
+
+func (obj *OpenaiServiceType) submete(
+	ctx context.Context,
+	provider ialib.LLMProvider,
+	inputMsgs ialib.MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+) (*responses.Response, error) {
+	var rsp *responses.Response
+	var err error
+	if onDelta := ialib.StreamDeltaFromContext(ctx); onDelta != nil {
+		// Chamador pediu streaming: usa o backend em streaming, se disponível
+		if sp, ok := provider.(ialib.LLMStreamProvider); ok {
+			rsp, err = sp.SubmitPromptStream(ctx, inputMsgs, prevID, modelo, effort, verbosity, onDelta)
+		} else {
+			rsp, err = provider.SubmitPrompt(ctx, inputMsgs, prevID, modelo, effort, verbosity)
+			if err == nil {
+				onDelta(rsp.OutputText())
+			}
+		}
+	} else {
+		rsp, err = provider.SubmitPrompt(ctx,
+			inputMsgs,
+			prevID,
+			modelo,
+			effort,
+			verbosity)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("falha ao submeter o prompt: %w", err)
 	}
@@ -118,13 +178,77 @@ func (obj *OpenaiServiceType) SubmitPromptResponse(
 	return rsp, err
 }
 
+// MAX_REPAROS_JSON limita os pedidos de correção da resposta fora do schema (provedor sem saída estruturada)
+const MAX_REPAROS_JSON = 2
+
+/*
+submeteExtraindoJSON atende o provedor sem saída estruturada: o schema segue no prompt,
+o JSON é extraído do texto gerado e validado; fora do schema, o modelo recebe os erros
+e refaz a resposta, até MAX_REPAROS_JSON vezes. A resposta devolvida traz só o JSON e
+soma o uso de tokens de todas as chamadas. Persistindo os erros, devolve também a
+última resposta, com ialib.ErrRespostaForaDoSchema.
+*/
+func (obj *OpenaiServiceType) submeteExtraindoJSON(
+	ctx context.Context,
+	provider ialib.LLMProvider,
+	formato *ialib.FormatoJSON,
+	inputMsgs ialib.MsgGpt,
+	prevID string,
+	modelo string,
+	effort responses.ReasoningEffort,
+	verbosity responses.ResponseTextConfigVerbosity,
+) (*responses.Response, error) {
+	ctx = ialib.WithFormatoJSON(ctx, nil)
+
+	var msgs ialib.MsgGpt
+	for _, m := range inputMsgs.GetMessages() {
+		msgs.AddMessage(m)
+	}
+	msgs.CreateMessage("", ialib.ROLE_USER, ialib.InstrucaoFormatoJSON(formato))
+
+	var uso responses.ResponseUsage
+	for reparo := 0; ; reparo++ {
+		rsp, err := obj.submete(ctx, provider, msgs, prevID, modelo, effort, verbosity)
+		if err != nil {
+			return nil, err
+		}
+		uso.InputTokens += rsp.Usage.InputTokens
+		uso.OutputTokens += rsp.Usage.OutputTokens
+		uso.TotalTokens += rsp.Usage.TotalTokens
+		rsp.Usage = uso
+
+		texto := ialib.ExtraiJSON(rsp.OutputText())
+		ialib.SubstituiTextoResposta(rsp, texto)
+		errosSchema := ialib.ValidaJSON(formato.Schema, []byte(texto))
+		if len(errosSchema) == 0 {
+			return rsp, nil
+		}
+		if len(errosSchema) > 3 {
+			errosSchema = errosSchema[:3]
+		}
+		if reparo >= MAX_REPAROS_JSON {
+			return rsp, fmt.Errorf("%w (%s): %s", ialib.ErrRespostaForaDoSchema, formato.Nome, strings.Join(errosSchema, "; "))
+		}
+		logger.Log.Warningf("Resposta fora do formato %s (reparo %d/%d): %s",
+			formato.Nome, reparo+1, MAX_REPAROS_JSON, strings.Join(errosSchema, "; "))
+
+		msgs.CreateMessage("", ialib.ROLE_ASSISTANT, texto)
+		msgs.CreateMessage("", ialib.ROLE_USER, promptReparo(errosSchema))
+		// a correção não é transmitida ao cliente do streaming
+		ctx = ialib.WithStreamDelta(ctx, nil)
+	}
+}
+
 /*
 Função destinada a calcular a quantidade de tokens constantes de um vetor de mensagtens
 */
 
 func (obj *OpenaiServiceType) TokensCounter(inputMsgs ialib.MsgGpt) (int, error) {
-
-	return ialib.OpenaiGlobal.TokensCounter(inputMsgs)
+	provider, err := obj.getProvider(ialib.TAREFA_GERACAO)
+	if err != nil {
+		return 0, err
+	}
+	return provider.TokensCounter(inputMsgs)
 }
 
 func (obj *OpenaiServiceType) StringTokensCounter(inputStr string) (int, error) {
@@ -305,6 +429,8 @@ func ExtractOutputText(msg responses.ResponseOutputItemUnion) (string, error) {
 //********************************************************
 //               FUNÇÕES RAG
 //********************************************************
+// Observação: as funções com tools dependem da Responses API e, por isso,
+// usam sempre o backend OpenAI, independentemente de LLM_PROVIDER_GERACAO.
 
 /*
 *
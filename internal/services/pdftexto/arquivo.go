@@ -0,0 +1,759 @@
+/*
+---------------------------------------------------------------------------------------
+File: arquivo.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Leitura da estrutura do arquivo PDF: tabela de referências cruzadas
+(clássica ou em fluxo, com atualizações incrementais), fluxos de objetos, filtros de
+compressão e árvore de páginas. Quando a tabela de referências está corrompida, os
+objetos são localizados por varredura do arquivo. Contra os "Flate-bombs", cada fluxo
+e a soma dos fluxos de uma leitura têm limite de bytes descompactados; o arquivo
+maior que PDF_MAX_MB não é carregado.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"ocrserver/internal/config"
+)
+
+// Limites da leitura do PDF
+const (
+	PDF_MAX_MB_PADRAO       = 512       // tamanho do arquivo, sem PDF_MAX_MB na configuração
+	MAX_BYTES_FLUXO_PDF     = 64 << 20  // cada fluxo descompactado
+	MAX_BYTES_DOCUMENTO_PDF = 512 << 20 // soma dos fluxos descompactados numa leitura
+)
+
+// ErrLimitePDF indica PDF grande demais ou com fluxos que descompactam além dos limites
+var ErrLimitePDF = errors.New("limite de leitura do PDF excedido")
+
+type entradaXref struct {
+	comprimido bool
+	offset     int // posição no arquivo ou, se comprimido, número do fluxo de objetos
+	indice     int // posição dentro do fluxo de objetos
+}
+
+type arquivoPDF struct {
+	dados   []byte
+	xref    map[int]entradaXref
+	trailer pdfDict
+	cache   map[int]any
+	objStm  map[int]*fluxoObjetos
+	abertos map[int]bool // proteção contra referências circulares
+
+	descompactados int64 // bytes descompactados na leitura (MAX_BYTES_DOCUMENTO_PDF)
+	errLimite      error // primeiro limite excedido; a leitura deve ser abandonada
+}
+
+type fluxoObjetos struct {
+	dados   []byte
+	offsets map[int]int
+}
+
+var errCriptografado = errors.New("PDF criptografado não suportado")
+
+// limiteArquivoPDF é o maior arquivo carregado em memória, em bytes
+func limiteArquivoPDF() int64 {
+	mb := PDF_MAX_MB_PADRAO
+	if config.GlobalConfig != nil && config.GlobalConfig.PdfMaxMB > 0 {
+		mb = config.GlobalConfig.PdfMaxMB
+	}
+	return int64(mb) << 20
+}
+
+// leArquivoPDF carrega o PDF inteiro, recusando o arquivo acima de PDF_MAX_MB
+func leArquivoPDF(pdfPath string) ([]byte, error) {
+	info, err := os.Stat(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo PDF: %w", err)
+	}
+	if limite := limiteArquivoPDF(); info.Size() > limite {
+		return nil, fmt.Errorf("%w: arquivo com %d MB (máximo %d MB, PDF_MAX_MB)", ErrLimitePDF, info.Size()>>20, limite>>20)
+	}
+	dados, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo PDF: %w", err)
+	}
+	return dados, nil
+}
+
+func abreArquivo(dados []byte) (*arquivoPDF, error) {
+	if !bytes.Contains(dados[:min(len(dados), 1024)], []byte("%PDF")) {
+		return nil, errors.New("arquivo não é PDF")
+	}
+	a := &arquivoPDF{
+		dados:   dados,
+		xref:    map[int]entradaXref{},
+		cache:   map[int]any{},
+		objStm:  map[int]*fluxoObjetos{},
+		abertos: map[int]bool{},
+	}
+	if err := a.leXref(); err != nil || a.trailer["Root"] == nil {
+		a.xref = map[int]entradaXref{}
+		a.trailer = nil
+		a.varre()
+	}
+	if a.trailer == nil || a.trailer["Root"] == nil {
+		return nil, errors.New("catálogo do PDF não encontrado")
+	}
+	if a.trailer["Encrypt"] != nil {
+		return nil, errCriptografado
+	}
+	return a, nil
+}
+
+// ============================================================
+// Tabela de referências cruzadas
+// ============================================================
+
+var reStartxref = regexp.MustCompile(`startxref\s+(\d+)`)
+
+func (a *arquivoPDF) leXref() error {
+	ms := reStartxref.FindAllSubmatch(a.dados, -1)
+	if len(ms) == 0 {
+		return errors.New("startxref não encontrado")
+	}
+	offset, _ := strconv.Atoi(string(ms[len(ms)-1][1]))
+
+	visitados := map[int]bool{}
+	for offset > 0 && !visitados[offset] {
+		visitados[offset] = true
+		if offset >= len(a.dados) {
+			return errors.New("startxref fora do arquivo")
+		}
+		var trailer pdfDict
+		var err error
+		if bytes.HasPrefix(a.dados[offset:], []byte("xref")) {
+			trailer, err = a.leXrefClassica(offset)
+		} else {
+			trailer, err = a.leXrefFluxo(offset)
+		}
+		if err != nil {
+			return err
+		}
+		if a.trailer == nil {
+			a.trailer = trailer
+		}
+		// arquivos híbridos: a tabela clássica aponta também para um fluxo de referências
+		if stm, ok := comoInt(trailer["XRefStm"]); ok {
+			if _, err := a.leXrefFluxo(stm); err != nil {
+				return err
+			}
+		}
+		offset, _ = comoInt(trailer["Prev"])
+	}
+	return nil
+}
+
+// registra a entrada se ainda não houver uma mais recente (as seções mais novas são lidas antes)
+func (a *arquivoPDF) registra(num int, e entradaXref) {
+	if _, ok := a.xref[num]; !ok {
+		a.xref[num] = e
+	}
+}
+
+func (a *arquivoPDF) leXrefClassica(offset int) (pdfDict, error) {
+	l := &lexico{b: a.dados, pos: offset + len("xref"), refs: true}
+	livres := map[int]bool{}
+	for {
+		l.pulaEspacos()
+		if bytes.HasPrefix(a.dados[l.pos:], []byte("trailer")) {
+			l.pos += len("trailer")
+			break
+		}
+		ini, ok1 := l.numero().(int)
+		qtd, ok2 := l.numero().(int)
+		if !ok1 || !ok2 || qtd < 0 {
+			return nil, errors.New("subseção da tabela xref inválida")
+		}
+		for i := 0; i < qtd; i++ {
+			l.pulaEspacos()
+			if l.pos+18 > len(a.dados) {
+				return nil, errFimDados
+			}
+			off, err1 := strconv.Atoi(string(bytes.TrimSpace(a.dados[l.pos : l.pos+10])))
+			tipo := a.dados[l.pos+17]
+			l.pos += 18
+			if err1 != nil {
+				return nil, errors.New("entrada da tabela xref inválida")
+			}
+			num := ini + i
+			if tipo == 'n' && !livres[num] {
+				a.registra(num, entradaXref{offset: off})
+			} else if _, ok := a.xref[num]; !ok {
+				livres[num] = true
+			}
+		}
+	}
+	o, err := l.objeto()
+	if err != nil {
+		return nil, err
+	}
+	trailer, ok := o.(pdfDict)
+	if !ok {
+		return nil, errors.New("trailer inválido")
+	}
+	return trailer, nil
+}
+
+func (a *arquivoPDF) leXrefFluxo(offset int) (pdfDict, error) {
+	_, o, err := a.leObjetoEm(offset)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := o.(*pdfStream)
+	if !ok || s.dict.nome("Type") != "XRef" {
+		return nil, errors.New("fluxo de referências cruzadas inválido")
+	}
+	dados, err := a.decodifica(s)
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := s.dict["W"].(pdfArray)
+	if len(w) < 3 {
+		return nil, errors.New("xref: /W inválido")
+	}
+	larg := make([]int, 3)
+	for i := range larg {
+		larg[i], _ = comoInt(w[i])
+	}
+	tam := larg[0] + larg[1] + larg[2]
+	if tam <= 0 {
+		return nil, errors.New("xref: /W inválido")
+	}
+
+	indices, _ := s.dict["Index"].(pdfArray)
+	if len(indices) == 0 {
+		size, _ := comoInt(s.dict["Size"])
+		indices = pdfArray{0, size}
+	}
+
+	campo := func(b []byte, padrao int) int {
+		if len(b) == 0 {
+			return padrao
+		}
+		v := 0
+		for _, c := range b {
+			v = v<<8 | int(c)
+		}
+		return v
+	}
+
+	pos := 0
+	for i := 0; i+1 < len(indices); i += 2 {
+		ini, _ := comoInt(indices[i])
+		qtd, _ := comoInt(indices[i+1])
+		for k := 0; k < qtd && pos+tam <= len(dados); k++ {
+			reg := dados[pos : pos+tam]
+			pos += tam
+			tipo := campo(reg[:larg[0]], 1)
+			c2 := campo(reg[larg[0]:larg[0]+larg[1]], 0)
+			c3 := campo(reg[larg[0]+larg[1]:], 0)
+			switch tipo {
+			case 1:
+				a.registra(ini+k, entradaXref{offset: c2})
+			case 2:
+				a.registra(ini+k, entradaXref{comprimido: true, offset: c2, indice: c3})
+			default:
+				a.registra(ini+k, entradaXref{offset: -1})
+			}
+		}
+	}
+	return s.dict, nil
+}
+
+var reObj = regexp.MustCompile(`(?m)(?:^|[\r\n\s])(\d+)\s+(\d+)\s+obj\b`)
+
+// varre localiza os objetos diretamente no arquivo (tabela xref ausente ou corrompida)
+func (a *arquivoPDF) varre() {
+	for _, m := range reObj.FindAllSubmatchIndex(a.dados, -1) {
+		num, _ := strconv.Atoi(string(a.dados[m[2]:m[3]]))
+		a.xref[num] = entradaXref{offset: m[2]} // o último prevalece (atualização incremental)
+	}
+	// trailer: o último dicionário "trailer" ou um fluxo /XRef com /Root
+	if i := bytes.LastIndex(a.dados, []byte("trailer")); i >= 0 {
+		l := &lexico{b: a.dados, pos: i + len("trailer"), refs: true}
+		if d, err := l.objeto(); err == nil {
+			if t, ok := d.(pdfDict); ok {
+				a.trailer = t
+			}
+		}
+	}
+	// objetos em fluxos de objetos e, se faltar o trailer, o catálogo
+	for num := range a.xref {
+		o := a.objeto(num)
+		if s, ok := o.(*pdfStream); ok {
+			switch s.dict.nome("Type") {
+			case "ObjStm":
+				if fo := a.fluxoObjetos(num); fo != nil {
+					for n := range fo.offsets {
+						if _, existe := a.xref[n]; !existe {
+							a.xref[n] = entradaXref{comprimido: true, offset: num}
+						}
+					}
+				}
+			case "XRef":
+				if a.trailer == nil || a.trailer["Root"] == nil {
+					a.trailer = s.dict
+				}
+			}
+		}
+	}
+	if a.trailer == nil || a.trailer["Root"] == nil {
+		for num := range a.xref {
+			if d, ok := a.objeto(num).(pdfDict); ok && d.nome("Type") == "Catalog" {
+				a.trailer = pdfDict{"Root": pdfRef{num: num}}
+				break
+			}
+		}
+	}
+}
+
+// ============================================================
+// Objetos
+// ============================================================
+
+// leObjetoEm lê "num ger obj ... endobj" a partir da posição
+func (a *arquivoPDF) leObjetoEm(offset int) (int, any, error) {
+	if offset < 0 || offset >= len(a.dados) {
+		return 0, nil, errors.New("posição de objeto inválida")
+	}
+	l := &lexico{b: a.dados, pos: offset}
+	num, ok := l.numero().(int)
+	if !ok {
+		return 0, nil, errors.New("cabeçalho de objeto inválido")
+	}
+	l.numero()
+	l.pulaEspacos()
+	if !bytes.HasPrefix(a.dados[l.pos:], []byte("obj")) {
+		return 0, nil, errors.New("cabeçalho de objeto inválido")
+	}
+	l.pos += 3
+	l.refs = true
+
+	o, err := l.objeto()
+	if err != nil {
+		return num, nil, err
+	}
+	d, ok := o.(pdfDict)
+	if !ok {
+		return num, o, nil
+	}
+	l.pulaEspacos()
+	if !bytes.HasPrefix(a.dados[l.pos:], []byte("stream")) {
+		return num, d, nil
+	}
+	l.pos += len("stream")
+	if l.pos < len(a.dados) && a.dados[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(a.dados) && a.dados[l.pos] == '\n' {
+		l.pos++
+	}
+	ini := l.pos
+
+	// /Length pode ser referência (e estar errado); confere com "endstream"
+	fim := -1
+	if n, ok := comoInt(a.resolve(d["Length"])); ok && n >= 0 && ini+n <= len(a.dados) {
+		resto := bytes.TrimLeft(a.dados[ini+n:min(ini+n+32, len(a.dados))], "\r\n \t")
+		if bytes.HasPrefix(resto, []byte("endstream")) {
+			fim = ini + n
+		}
+	}
+	if fim < 0 {
+		k := bytes.Index(a.dados[ini:], []byte("endstream"))
+		if k < 0 {
+			return num, nil, errors.New("fluxo sem endstream")
+		}
+		fim = ini + k
+		for fim > ini && (a.dados[fim-1] == '\n' || a.dados[fim-1] == '\r') {
+			fim--
+		}
+	}
+	return num, &pdfStream{dict: d, dados: a.dados[ini:fim]}, nil
+}
+
+// objeto devolve o objeto indireto "num" (nil se inexistente ou ilegível)
+func (a *arquivoPDF) objeto(num int) any {
+	if o, ok := a.cache[num]; ok {
+		return o
+	}
+	e, ok := a.xref[num]
+	if !ok || e.offset < 0 || a.abertos[num] {
+		return nil
+	}
+	a.abertos[num] = true
+	defer delete(a.abertos, num)
+
+	var o any
+	if e.comprimido {
+		if fo := a.fluxoObjetos(e.offset); fo != nil {
+			if off, ok := fo.offsets[num]; ok && off < len(fo.dados) {
+				l := &lexico{b: fo.dados, pos: off, refs: true}
+				o, _ = l.objeto()
+			}
+		}
+	} else {
+		n, obj, err := a.leObjetoEm(e.offset)
+		if err == nil && n == num {
+			o = obj
+		}
+	}
+	a.cache[num] = o
+	return o
+}
+
+func (a *arquivoPDF) fluxoObjetos(num int) *fluxoObjetos {
+	if fo, ok := a.objStm[num]; ok {
+		return fo
+	}
+	a.objStm[num] = nil
+
+	s, ok := a.objeto(num).(*pdfStream)
+	if !ok {
+		return nil
+	}
+	dados, err := a.decodifica(s)
+	if err != nil {
+		return nil
+	}
+	n, _ := comoInt(s.dict["N"])
+	first, _ := comoInt(s.dict["First"])
+	l := &lexico{b: dados}
+	fo := &fluxoObjetos{dados: dados, offsets: map[int]int{}}
+	for i := 0; i < n; i++ {
+		o1, _ := l.numero().(int)
+		l.pulaEspacos()
+		o2, _ := l.numero().(int)
+		l.pulaEspacos()
+		fo.offsets[o1] = first + o2
+	}
+	a.objStm[num] = fo
+	return fo
+}
+
+// resolve segue as referências indiretas
+func (a *arquivoPDF) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		r, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = a.objeto(r.num)
+	}
+	return nil
+}
+
+func (a *arquivoPDF) dict(v any) pdfDict {
+	switch o := a.resolve(v).(type) {
+	case pdfDict:
+		return o
+	case *pdfStream:
+		return o.dict
+	}
+	return nil
+}
+
+func (a *arquivoPDF) array(v any) pdfArray {
+	arr, _ := a.resolve(v).(pdfArray)
+	return arr
+}
+
+// ============================================================
+// Filtros
+// ============================================================
+
+// decodifica aplica os filtros do fluxo; filtros de imagem (DCT, JPX, CCITT, JBIG2) não são tratados
+func (a *arquivoPDF) decodifica(s *pdfStream) ([]byte, error) {
+	var filtros []pdfNome
+	switch f := a.resolve(s.dict["Filter"]).(type) {
+	case pdfNome:
+		filtros = []pdfNome{f}
+	case pdfArray:
+		for _, x := range f {
+			if n, ok := a.resolve(x).(pdfNome); ok {
+				filtros = append(filtros, n)
+			}
+		}
+	}
+	var parms pdfArray
+	switch p := a.resolve(s.dict["DecodeParms"]).(type) {
+	case pdfDict:
+		parms = pdfArray{p}
+	case pdfArray:
+		parms = p
+	}
+
+	dados := s.dados
+	for i, f := range filtros {
+		var p pdfDict
+		if i < len(parms) {
+			p = a.dict(parms[i])
+		}
+		var err error
+		switch f {
+		case "FlateDecode", "Fl":
+			dados, err = a.decodificaFlate(dados)
+			if err == nil {
+				dados, err = aplicaPreditor(dados, p)
+			}
+		case "ASCIIHexDecode", "AHx":
+			l := &lexico{b: dados}
+			dados = l.stringHex()
+		case "ASCII85Decode", "A85":
+			dados, err = decodificaASCII85(dados)
+		case "RunLengthDecode", "RL":
+			dados = decodificaRunLength(dados)
+		default:
+			return nil, fmt.Errorf("filtro não suportado: %s", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dados, nil
+}
+
+/*
+decodificaFlate tolera fluxos truncados, devolvendo o que foi possível descompactar. O
+fluxo maior que MAX_BYTES_FLUXO_PDF, ou que ultrapassa o saldo de MAX_BYTES_DOCUMENTO_PDF,
+é recusado sem ser lido até o fim, e o limite fica registrado em errLimite.
+*/
+func (a *arquivoPDF) decodificaFlate(dados []byte) ([]byte, error) {
+	if a.errLimite != nil {
+		return nil, a.errLimite
+	}
+	limite := min(MAX_BYTES_FLUXO_PDF, MAX_BYTES_DOCUMENTO_PDF-a.descompactados)
+
+	r, err := zlib.NewReader(bytes.NewReader(dados))
+	if err != nil {
+		return nil, fmt.Errorf("FlateDecode: %w", err)
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, limite+1))
+	if int64(len(out)) > limite {
+		if limite < MAX_BYTES_FLUXO_PDF {
+			a.errLimite = fmt.Errorf("%w: mais de %d MB descompactados no documento", ErrLimitePDF, MAX_BYTES_DOCUMENTO_PDF>>20)
+		} else {
+			a.errLimite = fmt.Errorf("%w: fluxo com mais de %d MB descompactados", ErrLimitePDF, MAX_BYTES_FLUXO_PDF>>20)
+		}
+		return nil, a.errLimite
+	}
+	a.descompactados += int64(len(out))
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("FlateDecode: %w", err)
+	}
+	return out, nil
+}
+
+// aplicaPreditor desfaz os preditores PNG (10 a 15) usados nos fluxos de referências cruzadas
+func aplicaPreditor(dados []byte, p pdfDict) ([]byte, error) {
+	pred, _ := comoInt(p["Predictor"])
+	if pred < 10 {
+		if pred == 2 {
+			return nil, errors.New("preditor TIFF não suportado")
+		}
+		return dados, nil
+	}
+	colunas, cores, bpc := 1, 1, 8
+	if v, ok := comoInt(p["Columns"]); ok && v > 0 {
+		colunas = v
+	}
+	if v, ok := comoInt(p["Colors"]); ok && v > 0 {
+		cores = v
+	}
+	if v, ok := comoInt(p["BitsPerComponent"]); ok && v > 0 {
+		bpc = v
+	}
+	bpp := max(1, cores*bpc/8)
+	linha := (colunas*cores*bpc + 7) / 8
+
+	out := make([]byte, 0, len(dados))
+	anterior := make([]byte, linha)
+	for pos := 0; pos+1+linha <= len(dados); pos += 1 + linha {
+		tipo := dados[pos]
+		atual := append([]byte{}, dados[pos+1:pos+1+linha]...)
+		for i := range atual {
+			var esq, acima, diag byte
+			if i >= bpp {
+				esq = atual[i-bpp]
+				diag = anterior[i-bpp]
+			}
+			acima = anterior[i]
+			switch tipo {
+			case 1:
+				atual[i] += esq
+			case 2:
+				atual[i] += acima
+			case 3:
+				atual[i] += byte((int(esq) + int(acima)) / 2)
+			case 4:
+				atual[i] += paeth(esq, acima, diag)
+			}
+		}
+		out = append(out, atual...)
+		anterior = atual
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func decodificaASCII85(dados []byte) ([]byte, error) {
+	var out []byte
+	var grupo [5]byte
+	n := 0
+	for _, c := range dados {
+		switch {
+		case c == '~':
+			goto fim
+		case c == 'z' && n == 0:
+			out = append(out, 0, 0, 0, 0)
+			continue
+		case c < '!' || c > 'u':
+			continue
+		}
+		grupo[n] = c - '!'
+		n++
+		if n == 5 {
+			v := uint32(0)
+			for _, g := range grupo {
+				v = v*85 + uint32(g)
+			}
+			out = append(out, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+			n = 0
+		}
+	}
+fim:
+	if n > 1 {
+		for i := n; i < 5; i++ {
+			grupo[i] = 84
+		}
+		v := uint32(0)
+		for _, g := range grupo {
+			v = v*85 + uint32(g)
+		}
+		b := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+		out = append(out, b[:n-1]...)
+	}
+	return out, nil
+}
+
+func decodificaRunLength(dados []byte) []byte {
+	var out []byte
+	for i := 0; i < len(dados); {
+		n := int(dados[i])
+		i++
+		switch {
+		case n == 128:
+			return out
+		case n < 128:
+			fim := min(i+n+1, len(dados))
+			out = append(out, dados[i:fim]...)
+			i = fim
+		default:
+			if i < len(dados) {
+				out = append(out, bytes.Repeat(dados[i:i+1], 257-n)...)
+				i++
+			}
+		}
+	}
+	return out
+}
+
+// ============================================================
+// Páginas
+// ============================================================
+
+type paginaPDF struct {
+	ref      pdfRef // referência do objeto da página (destino dos marcadores)
+	dict     pdfDict
+	recursos pdfDict
+}
+
+// paginas percorre a árvore de páginas, propagando os recursos herdados
+func (a *arquivoPDF) paginas() []paginaPDF {
+	raiz := a.dict(a.trailer["Root"])
+	var out []paginaPDF
+	visitados := map[any]bool{}
+
+	var percorre func(no any, recursos pdfDict, nivel int)
+	percorre = func(no any, recursos pdfDict, nivel int) {
+		ref, ok := no.(pdfRef)
+		if ok {
+			if visitados[ref] {
+				return
+			}
+			visitados[ref] = true
+		}
+		d := a.dict(no)
+		if d == nil || nivel > 64 {
+			return
+		}
+		if r := a.dict(d["Resources"]); r != nil {
+			recursos = r
+		}
+		kids := a.array(d["Kids"])
+		if d.nome("Type") == "Page" || (kids == nil && d["Contents"] != nil) {
+			out = append(out, paginaPDF{ref: ref, dict: d, recursos: recursos})
+			return
+		}
+		for _, k := range kids {
+			percorre(k, recursos, nivel+1)
+		}
+	}
+	percorre(raiz["Pages"], nil, 0)
+	return out
+}
+
+// conteudo devolve os fluxos de conteúdo da página concatenados
+func (a *arquivoPDF) conteudo(p paginaPDF) []byte {
+	var fluxos []any
+	switch c := a.resolve(p.dict["Contents"]).(type) {
+	case *pdfStream:
+		fluxos = []any{c}
+	case pdfArray:
+		fluxos = c
+	}
+	var buf bytes.Buffer
+	for _, f := range fluxos {
+		s, ok := a.resolve(f).(*pdfStream)
+		if !ok {
+			continue
+		}
+		dados, err := a.decodifica(s)
+		if err != nil {
+			continue
+		}
+		buf.Write(dados)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,159 @@
+package pdftexto
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocrserver/internal/config"
+)
+
+// compacta devolve os dados no formato do filtro FlateDecode
+func compacta(t *testing.T, dados []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(dados); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// fluxo monta o objeto de fluxo com o dicionário e os dados informados
+func fluxo(dict string, dados []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(dados), dados)
+}
+
+/*
+montaPDF monta um PDF de uma página cujo conteúdo é o objeto 4, com a tabela de
+referências cruzadas clássica nas posições reais dos objetos.
+*/
+func montaPDF(conteudo string) []byte {
+	objetos := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		conteudo,
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objetos))
+	for i, obj := range objetos {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objetos)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objetos)+1, xref)
+	return buf.Bytes()
+}
+
+func TestExtraiBytes(t *testing.T) {
+	texto := []byte("BT /F1 12 Tf 72 720 Td (Vistos etc.) Tj ET")
+	pdf := montaPDF(fluxo("/Filter /FlateDecode", compacta(t, texto)))
+
+	// startxref aponta para o meio de um objeto: os objetos são achados por varredura
+	semXref := montaPDF(fluxo("", texto))
+	i := bytes.LastIndex(semXref, []byte("startxref\n"))
+	xrefQuebrada := append(semXref[:i:i], "startxref\n20\n%%EOF\n"...)
+
+	// 65 MB de zeros cabem em poucos KB compactados
+	bomba := montaPDF(fluxo("/Filter /FlateDecode", compacta(t, make([]byte, MAX_BYTES_FLUXO_PDF+(1<<20)))))
+
+	casos := []struct {
+		nome   string
+		dados  []byte
+		texto  string // trecho esperado na página 1
+		erro   string // trecho da mensagem de erro; vazio: sem erro
+		limite bool   // erro deve ser ErrLimitePDF
+	}{
+		{nome: "conteúdo sem filtro", dados: montaPDF(fluxo("", texto)), texto: "Vistos etc."},
+		{nome: "conteúdo em FlateDecode", dados: pdf, texto: "Vistos etc."},
+		{nome: "tabela de referências corrompida", dados: xrefQuebrada, texto: "Vistos etc."},
+		{nome: "fluxo Flate truncado", dados: montaPDF(fluxo("/Filter /FlateDecode", compacta(t, texto)[:20])), texto: ""},
+		{nome: "fluxo Flate inválido", dados: montaPDF(fluxo("/Filter /FlateDecode", []byte("não é zlib"))), texto: ""},
+		{nome: "não é PDF", dados: []byte("PK\x03\x04 planilha"), erro: "não é PDF"},
+		{nome: "PDF truncado antes do catálogo", dados: pdf[:30], erro: "catálogo"},
+		{nome: "PDF truncado antes das páginas", dados: pdf[:len(pdf)/4], erro: "sem páginas"},
+		{nome: "Flate-bomb", dados: bomba, erro: "fluxo com mais de", limite: true},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			paginas, err := ExtraiBytes(context.Background(), c.dados)
+			if c.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), c.erro) {
+					t.Fatalf("erro = %v, esperado conter %q", err, c.erro)
+				}
+				if c.limite != errors.Is(err, ErrLimitePDF) {
+					t.Errorf("errors.Is(%v, ErrLimitePDF) = %v", err, !c.limite)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if len(paginas) != 1 {
+				t.Fatalf("esperada 1 página, obtidas %d", len(paginas))
+			}
+			if !strings.Contains(paginas[0].Texto, c.texto) {
+				t.Errorf("texto = %q, esperado conter %q", paginas[0].Texto, c.texto)
+			}
+		})
+	}
+}
+
+func TestDecodificaFlateSaldoDoDocumento(t *testing.T) {
+	dados := compacta(t, make([]byte, 100))
+
+	a := &arquivoPDF{descompactados: MAX_BYTES_DOCUMENTO_PDF - 150}
+	if out, err := a.decodificaFlate(dados); err != nil || len(out) != 100 {
+		t.Fatalf("primeiro fluxo: %d bytes, erro %v", len(out), err)
+	}
+	if _, err := a.decodificaFlate(dados); !errors.Is(err, ErrLimitePDF) {
+		t.Fatalf("segundo fluxo: erro = %v, esperado ErrLimitePDF", err)
+	}
+	// Excedido o saldo, nenhum outro fluxo é descompactado
+	if _, err := a.decodificaFlate(compacta(t, []byte("x"))); !errors.Is(err, ErrLimitePDF) {
+		t.Errorf("fluxo após o limite: erro = %v, esperado ErrLimitePDF", err)
+	}
+}
+
+func TestLeArquivoPDFLimite(t *testing.T) {
+	anterior := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = anterior })
+	config.GlobalConfig = &config.Config{PdfMaxMB: 1}
+
+	dir := t.TempDir()
+	pequeno := filepath.Join(dir, "pequeno.pdf")
+	grande := filepath.Join(dir, "grande.pdf")
+	if err := os.WriteFile(pequeno, montaPDF(fluxo("", []byte("BT ET"))), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(grande, make([]byte, 1<<20+1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := leArquivoPDF(pequeno); err != nil {
+		t.Errorf("arquivo dentro do limite: %v", err)
+	}
+	if _, err := leArquivoPDF(grande); !errors.Is(err, ErrLimitePDF) {
+		t.Errorf("arquivo acima de PDF_MAX_MB: erro = %v, esperado ErrLimitePDF", err)
+	}
+	if _, err := (&ExtratorNativo{}).Extrai(context.Background(), grande); !errors.Is(err, ErrLimitePDF) {
+		t.Errorf("Extrai acima de PDF_MAX_MB: erro = %v, esperado ErrLimitePDF", err)
+	}
+}
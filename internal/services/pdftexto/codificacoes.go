@@ -0,0 +1,107 @@
+/*
+---------------------------------------------------------------------------------------
+File: codificacoes.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Tabelas das codificações de fontes simples (WinAnsi, MacRoman, Standard)
+e dos nomes de glifos usados em /Differences.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Nomes dos glifos da WinAnsiEncoding, do código 0x20 ao 0xFF ("" = sem glifo)
+var nomesWinAnsi = strings.Fields(`space exclam quotedbl numbersign dollar percent ampersand
+quotesingle parenleft parenright asterisk plus comma hyphen period slash zero one two three four
+five six seven eight nine colon semicolon less equal greater question at A B C D E F G H I J K L M
+N O P Q R S T U V W X Y Z bracketleft backslash bracketright asciicircum underscore grave a b c d
+e f g h i j k l m n o p q r s t u v w x y z braceleft bar braceright asciitilde _ Euro _
+quotesinglbase florin quotedblbase ellipsis dagger daggerdbl circumflex perthousand Scaron
+guilsinglleft OE _ Zcaron _ _ quoteleft quoteright quotedblleft quotedblright bullet endash emdash
+tilde trademark scaron guilsinglright oe _ zcaron Ydieresis space exclamdown cent sterling currency
+yen brokenbar section dieresis copyright ordfeminine guillemotleft logicalnot hyphen registered
+macron degree plusminus twosuperior threesuperior acute mu paragraph periodcentered cedilla
+onesuperior ordmasculine guillemotright onequarter onehalf threequarters questiondown Agrave Aacute
+Acircumflex Atilde Adieresis Aring AE Ccedilla Egrave Eacute Ecircumflex Edieresis Igrave Iacute
+Icircumflex Idieresis Eth Ntilde Ograve Oacute Ocircumflex Otilde Odieresis multiply Oslash Ugrave
+Uacute Ucircumflex Udieresis Yacute Thorn germandbls agrave aacute acircumflex atilde adieresis
+aring ae ccedilla egrave eacute ecircumflex edieresis igrave iacute icircumflex idieresis eth ntilde
+ograve oacute ocircumflex otilde odieresis divide oslash ugrave uacute ucircumflex udieresis yacute
+thorn ydieresis`)
+
+// Caracteres 0x80 a 0x9F da WinAnsi (cp1252); os demais coincidem com o Latin-1
+const winAnsi80 = "€\u0081‚ƒ„…†‡ˆ‰Š‹Œ\u008DŽ\u008F\u0090‘’“”•–—˜™š›œ\u009DžŸ"
+
+// Caracteres 0x80 a 0xFF da MacRomanEncoding
+const macRoman80 = "ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø" +
+	"¿¡¬√ƒ≈∆«»… ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄€‹›ﬁﬂ‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ"
+
+var (
+	codWinAnsi  [256]rune
+	codMacRoman [256]rune
+	codStandard [256]rune
+	glifos      = map[string]rune{}
+)
+
+func init() {
+	for i := 0; i < 256; i++ {
+		codWinAnsi[i] = rune(i)
+	}
+	for i, r := range []rune(winAnsi80) {
+		codWinAnsi[0x80+i] = r
+	}
+	codMacRoman = codWinAnsi
+	for i, r := range []rune(macRoman80) {
+		codMacRoman[0x80+i] = r
+	}
+	// StandardEncoding: as diferenças relevantes para texto em relação ao ASCII
+	codStandard = codWinAnsi
+	for c, r := range map[int]rune{0x27: '’', 0x60: '‘', 0xA9: '\'', 0xAA: '“', 0xAE: 'ﬁ', 0xAF: 'ﬂ',
+		0xB1: '–', 0xB7: '•', 0xBA: '”', 0xBC: '…', 0xD0: '—', 0xE1: 'Æ', 0xF1: 'æ', 0xF5: 'ı', 0xFB: 'ß'} {
+		codStandard[c] = r
+	}
+
+	for i, nome := range nomesWinAnsi {
+		if nome != "_" {
+			if _, ok := glifos[nome]; !ok {
+				glifos[nome] = codWinAnsi[0x20+i]
+			}
+		}
+	}
+	for nome, r := range map[string]rune{
+		"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ', "nbspace": ' ', "sfthyphen": '­',
+		"minus": '−', "fraction": '⁄', "dotlessi": 'ı', "middot": '·', "Euro": '€', "quotedblbase": '„',
+		"Lslash": 'Ł', "lslash": 'ł', "ring": '˚', "caron": 'ˇ', "breve": '˘', "dotaccent": '˙',
+		"ogonek": '˛', "hungarumlaut": '˝', "copyrightserif": '©', "registerserif": '®', "trademarkserif": '™',
+		"arrowright": '→', "arrowleft": '←', "checkmark": '✓', "notequal": '≠', "lessequal": '≤',
+		"greaterequal": '≥', "infinity": '∞', "periodcentered": '·', "mu1": 'µ', "Omega": 'Ω',
+	} {
+		glifos[nome] = r
+	}
+}
+
+// runaDoGlifo converte o nome do glifo (inclusive uniXXXX e uXXXX) no caractere Unicode
+func runaDoGlifo(nome string) (rune, bool) {
+	if r, ok := glifos[nome]; ok {
+		return r, true
+	}
+	// variantes como "a.sc", "one.oldstyle" ou "f_i"
+	if i := strings.IndexByte(nome, '.'); i > 0 {
+		return runaDoGlifo(nome[:i])
+	}
+	if strings.HasPrefix(nome, "uni") && len(nome) >= 7 {
+		if v, err := strconv.ParseUint(nome[3:7], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	if strings.HasPrefix(nome, "u") && len(nome) >= 5 && len(nome) <= 7 {
+		if v, err := strconv.ParseUint(nome[1:], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	return 0, false
+}
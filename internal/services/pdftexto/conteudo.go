@@ -0,0 +1,291 @@
+/*
+---------------------------------------------------------------------------------------
+File: conteudo.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Interpretação dos fluxos de conteúdo das páginas (operadores de texto,
+estado gráfico e XObjects de formulário), produzindo cada caractere exibido com a sua
+posição na página.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"bytes"
+	"math"
+)
+
+// matriz de transformação [a b c d e f]
+type matriz [6]float64
+
+var identidade = matriz{1, 0, 0, 1, 0, 0}
+
+// multiplica devolve m × n
+func (m matriz) multiplica(n matriz) matriz {
+	return matriz{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// area devolve a área ocupada pelo quadrado unitário transformado pela matriz
+func (m matriz) area() float64 {
+	return math.Abs(m[0]*m[3] - m[1]*m[2])
+}
+
+// caractere exibido na página, em coordenadas do espaço do dispositivo
+type caractere struct {
+	x, y    float64 // origem do glifo
+	largura float64 // avanço ao longo da linha de base
+	corpo   float64 // tamanho efetivo da fonte
+	angulo  int     // direção da linha de base: 0, 90, 180 ou 270 graus
+	texto   string
+}
+
+type estadoGrafico struct {
+	ctm      matriz
+	fonte    *fontePDF
+	corpo    float64
+	espChar  float64 // Tc
+	espPal   float64 // Tw
+	escalaH  float64 // Tz / 100
+	entrelin float64 // TL
+	elevacao float64 // Ts
+}
+
+// imagem desenhada na página; a área é a do quadrado unitário transformado pela CTM
+type imagemDesenhada struct {
+	fluxo *pdfStream // nil nas imagens embutidas (BI ... EI)
+	area  float64
+}
+
+type interpretador struct {
+	arq     *arquivoPDF
+	fontes  map[any]*fontePDF
+	chars   []caractere
+	imagens []imagemDesenhada // usado na detecção de páginas digitalizadas
+	profund int
+}
+
+const maxProfundidadeForm = 12
+
+// executa interpreta o fluxo de conteúdo com os recursos e o estado gráfico informados
+func (it *interpretador) executa(conteudo []byte, recursos pdfDict, gs estadoGrafico) {
+	l := &lexico{b: conteudo}
+	var pilhaGS []estadoGrafico
+	var operandos []any
+	tm, tlm := identidade, identidade
+
+	num := func(i int) float64 {
+		if i < len(operandos) {
+			v, _ := comoNumero(operandos[i])
+			return v
+		}
+		return 0
+	}
+	td := func(tx, ty float64) {
+		tlm = matriz{1, 0, 0, 1, tx, ty}.multiplica(tlm)
+		tm = tlm
+	}
+
+	for {
+		o, err := l.objeto()
+		if err != nil {
+			return
+		}
+		op, ok := o.(pdfOperador)
+		if !ok {
+			if _, fim := o.(pdfFim); !fim {
+				operandos = append(operandos, o)
+			}
+			continue
+		}
+
+		switch op {
+		case "q":
+			pilhaGS = append(pilhaGS, gs)
+		case "Q":
+			if n := len(pilhaGS); n > 0 {
+				gs = pilhaGS[n-1]
+				pilhaGS = pilhaGS[:n-1]
+			}
+		case "cm":
+			if len(operandos) >= 6 {
+				gs.ctm = matriz{num(0), num(1), num(2), num(3), num(4), num(5)}.multiplica(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = identidade, identidade
+		case "Tf":
+			if len(operandos) >= 2 {
+				gs.fonte = it.fonte(recursos, operandos[0])
+				gs.corpo = num(1)
+			}
+		case "Tc":
+			gs.espChar = num(0)
+		case "Tw":
+			gs.espPal = num(0)
+		case "Tz":
+			gs.escalaH = num(0) / 100
+		case "TL":
+			gs.entrelin = num(0)
+		case "Ts":
+			gs.elevacao = num(0)
+		case "Td":
+			td(num(0), num(1))
+		case "TD":
+			gs.entrelin = -num(1)
+			td(num(0), num(1))
+		case "Tm":
+			if len(operandos) >= 6 {
+				tlm = matriz{num(0), num(1), num(2), num(3), num(4), num(5)}
+				tm = tlm
+			}
+		case "T*":
+			td(0, -gs.entrelin)
+		case "Tj":
+			if len(operandos) > 0 {
+				it.exibe(operandos[len(operandos)-1], &tm, gs)
+			}
+		case "'":
+			td(0, -gs.entrelin)
+			if len(operandos) > 0 {
+				it.exibe(operandos[len(operandos)-1], &tm, gs)
+			}
+		case "\"":
+			if len(operandos) >= 3 {
+				gs.espPal = num(0)
+				gs.espChar = num(1)
+				td(0, -gs.entrelin)
+				it.exibe(operandos[2], &tm, gs)
+			}
+		case "TJ":
+			if len(operandos) > 0 {
+				if arr, ok := operandos[len(operandos)-1].(pdfArray); ok {
+					for _, item := range arr {
+						if n, ok := comoNumero(item); ok {
+							tx := -n / 1000 * gs.corpo * gs.escalaH
+							tm = matriz{1, 0, 0, 1, tx, 0}.multiplica(tm)
+							continue
+						}
+						it.exibe(item, &tm, gs)
+					}
+				}
+			}
+		case "Do":
+			if len(operandos) > 0 {
+				it.xobjeto(recursos, operandos[0], gs)
+			}
+		case "BI":
+			it.pulaImagemEmbutida(l)
+			it.imagens = append(it.imagens, imagemDesenhada{area: gs.ctm.area()})
+		}
+		operandos = operandos[:0]
+	}
+}
+
+// fonte localiza (e guarda em cache) a fonte do recurso /Font
+func (it *interpretador) fonte(recursos pdfDict, nome any) *fontePDF {
+	n, _ := nome.(pdfNome)
+	ref := it.arq.dict(recursos["Font"])[n]
+	chave := any(ref)
+	if _, ehRef := ref.(pdfRef); !ehRef {
+		chave = string(n)
+	}
+	if f, ok := it.fontes[chave]; ok {
+		return f
+	}
+	f := it.arq.carregaFonte(ref)
+	it.fontes[chave] = f
+	return f
+}
+
+// exibe registra os caracteres da string e avança a matriz de texto
+func (it *interpretador) exibe(v any, tm *matriz, gs estadoGrafico) {
+	s, ok := v.(pdfString)
+	if !ok || gs.fonte == nil {
+		return
+	}
+	for _, g := range gs.fonte.decodifica(s) {
+		tx := g.largura/1000*gs.corpo + gs.espChar
+		if g.espaco {
+			tx += gs.espPal
+		}
+		tx *= gs.escalaH
+
+		if g.texto != "" {
+			m := tm.multiplica(gs.ctm)
+			trm := matriz{gs.corpo * gs.escalaH, 0, 0, gs.corpo, 0, gs.elevacao}.multiplica(m)
+			ang := math.Atan2(trm[1], trm[0]) * 180 / math.Pi
+			it.chars = append(it.chars, caractere{
+				x:       trm[4],
+				y:       trm[5],
+				largura: g.largura / 1000 * gs.corpo * gs.escalaH * math.Hypot(m[0], m[1]),
+				corpo:   math.Hypot(trm[2], trm[3]),
+				angulo:  (int(math.Round(ang/90))*90 + 360) % 360,
+				texto:   g.texto,
+			})
+		}
+		*tm = matriz{1, 0, 0, 1, tx, 0}.multiplica(*tm)
+	}
+}
+
+// xobjeto desenha um XObject: formulários são interpretados; imagens são apenas registradas
+func (it *interpretador) xobjeto(recursos pdfDict, nome any, gs estadoGrafico) {
+	n, _ := nome.(pdfNome)
+	s, ok := it.arq.resolve(it.arq.dict(recursos["XObject"])[n]).(*pdfStream)
+	if !ok {
+		return
+	}
+	switch s.dict.nome("Subtype") {
+	case "Image":
+		it.imagens = append(it.imagens, imagemDesenhada{fluxo: s, area: gs.ctm.area()})
+	case "Form":
+		if it.profund >= maxProfundidadeForm {
+			return
+		}
+		dados, err := it.arq.decodifica(s)
+		if err != nil {
+			return
+		}
+		if m := it.arq.array(s.dict["Matrix"]); len(m) == 6 {
+			var fm matriz
+			for i := range fm {
+				fm[i], _ = comoNumero(it.arq.resolve(m[i]))
+			}
+			gs.ctm = fm.multiplica(gs.ctm)
+		}
+		rec := it.arq.dict(s.dict["Resources"])
+		if rec == nil {
+			rec = recursos
+		}
+		it.profund++
+		it.executa(dados, rec, gs)
+		it.profund--
+	}
+}
+
+// pulaImagemEmbutida avança até o "EI" que encerra a imagem embutida (BI ... ID dados EI)
+func (it *interpretador) pulaImagemEmbutida(l *lexico) {
+	i := bytes.Index(l.b[l.pos:], []byte("ID"))
+	if i < 0 {
+		l.pos = len(l.b)
+		return
+	}
+	l.pos += i + 3
+	for l.pos < len(l.b) {
+		k := bytes.Index(l.b[l.pos:], []byte("EI"))
+		if k < 0 {
+			l.pos = len(l.b)
+			return
+		}
+		l.pos += k + 2
+		antes := l.b[l.pos-3]
+		if ehEspaco(antes) && (l.pos == len(l.b) || ehEspaco(l.b[l.pos])) {
+			return
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+---------------------------------------------------------------------------------------
+File: extrator.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Extração do texto dos PDFs dos autos, página a página. O extrator padrão
+("nativo") é escrito em Go puro e roda no próprio processo, preservando o leiaute das
+linhas (colunas separadas por espaços, como o "pdftotext -layout"). O utilitário
+"pdftotext"(poppler) continua disponível como backend opcional e, quando instalado,
+serve de segunda opção para os arquivos que o extrator nativo não consegue ler.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"ocrserver/internal/utils/logger"
+)
+
+// Backends de extração (variável PDF_EXTRATOR)
+const (
+	EXTRATOR_NATIVO    = "nativo"
+	EXTRATOR_PDFTOTEXT = "pdftotext"
+)
+
+// Origem do texto da página
+const (
+	ORIGEM_TEXTO = "texto" // camada de texto do PDF
+	ORIGEM_OCR   = "ocr"   // reconhecimento da imagem da página digitalizada
+)
+
+// Pagina é o texto de uma página do PDF; Numero é a posição da página no arquivo (1, 2, ...)
+type Pagina struct {
+	Numero int    `json:"numero"`
+	Texto  string `json:"texto"`
+	Origem string `json:"origem"` // ORIGEM_TEXTO ou ORIGEM_OCR
+}
+
+// Extrator converte um arquivo PDF no texto de cada uma das suas páginas
+type Extrator interface {
+	Nome() string
+	Extrai(ctx context.Context, pdfPath string) ([]Pagina, error)
+}
+
+/*
+NewExtrator devolve o extrator configurado. O nativo é combinado com o "pdftotext"
+(se presente no PATH), usado somente quando a leitura nativa falha.
+*/
+func NewExtrator(nome string) (Extrator, error) {
+	switch strings.ToLower(strings.TrimSpace(nome)) {
+	case "", EXTRATOR_NATIVO:
+		if _, err := exec.LookPath("pdftotext"); err == nil {
+			return &extratorEncadeado{extratores: []Extrator{&ExtratorNativo{}, &ExtratorPdftotext{}}}, nil
+		}
+		return &ExtratorNativo{}, nil
+	case EXTRATOR_PDFTOTEXT:
+		return &ExtratorPdftotext{}, nil
+	}
+	return nil, fmt.Errorf("extrator de PDF desconhecido: %q (use nativo ou pdftotext)", nome)
+}
+
+// TextoCompleto junta as páginas separadas por form-feed, como no arquivo gerado pelo pdftotext
+func TextoCompleto(paginas []Pagina) string {
+	textos := make([]string, len(paginas))
+	for i, p := range paginas {
+		textos[i] = p.Texto
+	}
+	return strings.Join(textos, "\f")
+}
+
+// Linhas devolve as linhas do texto da página
+func (p Pagina) Linhas() []string {
+	if p.Texto == "" {
+		return nil
+	}
+	return strings.Split(p.Texto, "\n")
+}
+
+// extratorEncadeado tenta cada extrator, na ordem, até o primeiro que funcionar
+type extratorEncadeado struct {
+	extratores []Extrator
+}
+
+func (e *extratorEncadeado) Nome() string {
+	return e.extratores[0].Nome()
+}
+
+func (e *extratorEncadeado) Extrai(ctx context.Context, pdfPath string) ([]Pagina, error) {
+	var erro error
+	for _, ext := range e.extratores {
+		paginas, err := ext.Extrai(ctx, pdfPath)
+		if err == nil {
+			return paginas, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.Log.Warningf("Extrator %s falhou em %s: %v", ext.Nome(), pdfPath, err)
+		erro = err
+	}
+	return nil, erro
+}
@@ -0,0 +1,294 @@
+/*
+---------------------------------------------------------------------------------------
+File: fontes.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Decodificação das fontes do PDF: conversão dos códigos das strings em
+texto Unicode (ToUnicode, /Encoding e /Differences) e larguras dos glifos, necessárias
+para posicionar o texto na página.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"unicode/utf16"
+)
+
+type faixaCodigos struct {
+	bytes    int
+	ini, fim uint32
+}
+
+type fontePDF struct {
+	composta  bool           // Type0 (códigos de 1 a 4 bytes, normalmente 2)
+	faixas    []faixaCodigos // codespace do ToUnicode (define o tamanho de cada código)
+	toUnicode map[uint32]string
+	codificac [256]rune          // fontes simples
+	larguras  map[uint32]float64 // unidades de texto (1/1000 do corpo)
+	padrao    float64            // largura dos códigos sem /Widths ou /W
+	fatorLarg float64            // Type3: escala da FontMatrix
+}
+
+// glifo é um código decodificado da string exibida
+type glifo struct {
+	texto   string
+	largura float64 // em unidades de texto (1/1000 do corpo)
+	espaco  bool    // código de 1 byte igual a 32 (recebe o espaçamento de palavras Tw)
+}
+
+func (a *arquivoPDF) carregaFonte(v any) *fontePDF {
+	d := a.dict(v)
+	f := &fontePDF{larguras: map[uint32]float64{}, padrao: 500, fatorLarg: 1, codificac: codWinAnsi}
+	if d == nil {
+		return f
+	}
+
+	if s, ok := a.resolve(d["ToUnicode"]).(*pdfStream); ok {
+		if dados, err := a.decodifica(s); err == nil {
+			f.leCMap(dados)
+		}
+	}
+
+	switch d.nome("Subtype") {
+	case "Type0":
+		f.composta = true
+		f.padrao = 1000
+		desc := a.array(d["DescendantFonts"])
+		if len(desc) > 0 {
+			cid := a.dict(desc[0])
+			if dw, ok := comoNumero(a.resolve(cid["DW"])); ok {
+				f.padrao = dw
+			}
+			f.leW(a, a.array(cid["W"]))
+		}
+		return f
+	case "Type3":
+		if m := a.array(d["FontMatrix"]); len(m) > 0 {
+			if v, ok := comoNumero(a.resolve(m[0])); ok {
+				f.fatorLarg = v * 1000
+			}
+		}
+	}
+
+	// fontes simples: codificação base + /Differences
+	base := d.nome("BaseFont")
+	if base == "Symbol" || base == "ZapfDingbats" {
+		for i := range f.codificac {
+			f.codificac[i] = rune(i)
+		}
+	}
+	switch enc := a.resolve(d["Encoding"]).(type) {
+	case pdfNome:
+		f.codificacaoBase(enc)
+	case pdfDict:
+		f.codificacaoBase(enc.nome("BaseEncoding"))
+		codigo := 0
+		for _, item := range a.array(enc["Differences"]) {
+			switch x := a.resolve(item).(type) {
+			case int:
+				codigo = x
+			case pdfNome:
+				if codigo >= 0 && codigo < 256 {
+					if r, ok := runaDoGlifo(string(x)); ok {
+						f.codificac[codigo] = r
+					} else {
+						f.codificac[codigo] = 0
+					}
+				}
+				codigo++
+			}
+		}
+	}
+
+	first, _ := comoInt(a.resolve(d["FirstChar"]))
+	for i, w := range a.array(d["Widths"]) {
+		if v, ok := comoNumero(a.resolve(w)); ok {
+			f.larguras[uint32(first+i)] = v
+		}
+	}
+	if fd := a.dict(d["FontDescriptor"]); fd != nil {
+		if mw, ok := comoNumero(a.resolve(fd["MissingWidth"])); ok && mw > 0 {
+			f.padrao = mw
+		}
+	}
+	if len(base) >= 7 && base[:7] == "Courier" {
+		f.padrao = 600
+	}
+	return f
+}
+
+func (f *fontePDF) codificacaoBase(nome pdfNome) {
+	switch nome {
+	case "WinAnsiEncoding":
+		f.codificac = codWinAnsi
+	case "MacRomanEncoding":
+		f.codificac = codMacRoman
+	case "StandardEncoding":
+		f.codificac = codStandard
+	}
+}
+
+// leW interpreta o array /W das fontes CID: "c [w1 w2 ...]" ou "cIni cFim w"
+func (f *fontePDF) leW(a *arquivoPDF, w pdfArray) {
+	for i := 0; i < len(w); {
+		c1, ok := comoInt(a.resolve(w[i]))
+		if !ok || i+1 >= len(w) {
+			return
+		}
+		if lista, ok := a.resolve(w[i+1]).(pdfArray); ok {
+			for k, v := range lista {
+				if n, ok := comoNumero(a.resolve(v)); ok {
+					f.larguras[uint32(c1+k)] = n
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		c2, _ := comoInt(a.resolve(w[i+1]))
+		n, _ := comoNumero(a.resolve(w[i+2]))
+		for c := c1; c <= c2 && c-c1 < 65536; c++ {
+			f.larguras[uint32(c)] = n
+		}
+		i += 3
+	}
+}
+
+// ============================================================
+// CMap ToUnicode
+// ============================================================
+
+func codigoDe(b []byte) uint32 {
+	v := uint32(0)
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func textoUTF16(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+func (f *fontePDF) leCMap(dados []byte) {
+	f.toUnicode = map[uint32]string{}
+	l := &lexico{b: dados}
+	var pilha []any
+	for {
+		o, err := l.objeto()
+		if err != nil {
+			return
+		}
+		op, ehOp := o.(pdfOperador)
+		if !ehOp {
+			pilha = append(pilha, o)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(pilha); i += 2 {
+				ini, ok1 := pilha[i].(pdfString)
+				fim, ok2 := pilha[i+1].(pdfString)
+				if ok1 && ok2 && len(ini) > 0 {
+					f.faixas = append(f.faixas, faixaCodigos{bytes: len(ini), ini: codigoDe(ini), fim: codigoDe(fim)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(pilha); i += 2 {
+				src, ok1 := pilha[i].(pdfString)
+				dst, ok2 := pilha[i+1].(pdfString)
+				if ok1 && ok2 {
+					f.toUnicode[codigoDe(src)] = textoUTF16(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(pilha); i += 3 {
+				s1, ok1 := pilha[i].(pdfString)
+				s2, ok2 := pilha[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				c1, c2 := codigoDe(s1), codigoDe(s2)
+				if c2 < c1 || c2-c1 > 65535 {
+					continue
+				}
+				switch dst := pilha[i+2].(type) {
+				case pdfString:
+					// o último caractere do destino é incrementado a cada código
+					base := []rune(textoUTF16(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for c := c1; c <= c2; c++ {
+						r := append([]rune{}, base...)
+						r[len(r)-1] += rune(c - c1)
+						f.toUnicode[c] = string(r)
+					}
+				case pdfArray:
+					for k, item := range dst {
+						if s, ok := item.(pdfString); ok && c1+uint32(k) <= c2 {
+							f.toUnicode[c1+uint32(k)] = textoUTF16(s)
+						}
+					}
+				}
+			}
+		}
+		// qualquer operador encerra os operandos acumulados
+		pilha = pilha[:0]
+	}
+}
+
+// tamanhoCodigo define quantos bytes o próximo código ocupa
+func (f *fontePDF) tamanhoCodigo(b []byte) int {
+	if !f.composta {
+		return 1
+	}
+	for _, fx := range f.faixas {
+		if fx.bytes <= len(b) {
+			c := codigoDe(b[:fx.bytes])
+			if c >= fx.ini && c <= fx.fim {
+				return fx.bytes
+			}
+		}
+	}
+	return min(2, len(b))
+}
+
+// decodifica converte os bytes de uma string exibida nos glifos correspondentes
+func (f *fontePDF) decodifica(b []byte) []glifo {
+	out := make([]glifo, 0, len(b))
+	for i := 0; i < len(b); {
+		n := max(1, f.tamanhoCodigo(b[i:]))
+		codigo := codigoDe(b[i : i+n])
+		i += n
+
+		g := glifo{espaco: n == 1 && codigo == 32}
+		if w, ok := f.larguras[codigo]; ok {
+			g.largura = w * f.fatorLarg
+		} else {
+			g.largura = f.padrao * f.fatorLarg
+		}
+
+		if t, ok := f.toUnicode[codigo]; ok {
+			g.texto = t
+		} else if f.composta {
+			// sem ToUnicode, o CID é a melhor aproximação disponível
+			if codigo >= 32 && codigo < 0xD800 {
+				g.texto = string(rune(codigo))
+			}
+		} else if r := f.codificac[codigo&0xFF]; r >= 32 {
+			g.texto = string(r)
+		}
+		out = append(out, g)
+	}
+	return out
+}
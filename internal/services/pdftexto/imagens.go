@@ -0,0 +1,470 @@
+/*
+---------------------------------------------------------------------------------------
+File: imagens.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Acesso às imagens das páginas, usado no OCR das páginas digitalizadas.
+A imagem principal da página (a de maior área desenhada) é devolvida num formato que
+o motor de OCR consegue ler: JPEG e JPEG 2000 são copiados sem recompressão, CCITT G3/G4
+é embrulhado num TIFF e os demais casos (Flate ou sem filtro) são convertidos em PNG.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// Formatos das imagens devolvidas para o OCR (também usados como extensão do arquivo)
+const (
+	IMAGEM_JPEG  = "jpg"
+	IMAGEM_JP2   = "jp2"
+	IMAGEM_TIFF  = "tif"
+	IMAGEM_PNG   = "png"
+	maxPixelsPNG = 80_000_000 // proteção contra dimensões absurdas declaradas no PDF
+)
+
+var errImagemNaoSuportada = errors.New("formato de imagem não suportado para OCR")
+
+// Imagem é a imagem principal de uma página do PDF
+type Imagem struct {
+	Dados     []byte
+	Formato   string  // IMAGEM_JPEG, IMAGEM_JP2, IMAGEM_TIFF ou IMAGEM_PNG
+	Largura   int     // pixels
+	Altura    int     // pixels
+	Cobertura float64 // fração da área da página ocupada pela imagem (0 a 1)
+}
+
+// LeitorImagens mantém o PDF aberto para a leitura das imagens de várias páginas
+type LeitorImagens struct {
+	arq     *arquivoPDF
+	paginas []paginaPDF
+	fontes  map[any]*fontePDF
+}
+
+// AbreImagens carrega o PDF para a leitura das imagens das páginas
+func AbreImagens(pdfPath string) (*LeitorImagens, error) {
+	dados, err := leArquivoPDF(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	arq, err := abreArquivo(dados)
+	if err != nil {
+		return nil, err
+	}
+	return &LeitorImagens{arq: arq, paginas: arq.paginas(), fontes: map[any]*fontePDF{}}, nil
+}
+
+/*
+ImagemPrincipal devolve a maior imagem desenhada na página (numeração a partir de 1).
+Páginas sem imagem devolvem nil, sem erro.
+*/
+func (l *LeitorImagens) ImagemPrincipal(numero int) (img *Imagem, err error) {
+	if numero < 1 || numero > len(l.paginas) {
+		return nil, fmt.Errorf("página %d inexistente (o PDF tem %d)", numero, len(l.paginas))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			img, err = nil, fmt.Errorf("página %d malformada: %v", numero, r)
+		}
+	}()
+
+	// Cada página tem o seu saldo de MAX_BYTES_DOCUMENTO_PDF: as imagens são lidas uma a uma
+	l.arq.descompactados, l.arq.errLimite = 0, nil
+
+	p := l.paginas[numero-1]
+	it := &interpretador{arq: l.arq, fontes: l.fontes}
+	it.executa(l.arq.conteudo(p), p.recursos, estadoGrafico{ctm: identidade, escalaH: 1})
+
+	var maior *imagemDesenhada
+	for i := range it.imagens {
+		if maior == nil || it.imagens[i].area > maior.area {
+			maior = &it.imagens[i]
+		}
+	}
+	if maior == nil {
+		return nil, nil
+	}
+	if maior.fluxo == nil {
+		return nil, fmt.Errorf("página %d: imagem embutida no conteúdo: %w", numero, errImagemNaoSuportada)
+	}
+
+	img, err = l.arq.converteImagem(maior.fluxo)
+	if err != nil {
+		return nil, fmt.Errorf("página %d: %w", numero, err)
+	}
+	if area := l.arq.areaPagina(p); area > 0 {
+		img.Cobertura = min(maior.area/area, 1)
+	}
+	return img, nil
+}
+
+// areaPagina calcula a área da /MediaBox, que pode ser herdada dos nós superiores
+func (a *arquivoPDF) areaPagina(p paginaPDF) float64 {
+	d := p.dict
+	for nivel := 0; d != nil && nivel < 64; nivel++ {
+		if caixa := a.array(d["MediaBox"]); len(caixa) == 4 {
+			var v [4]float64
+			for i := range v {
+				v[i], _ = comoNumero(a.resolve(caixa[i]))
+			}
+			return math.Abs((v[2] - v[0]) * (v[3] - v[1]))
+		}
+		d = a.dict(d["Parent"])
+	}
+	return 612 * 792 // Carta, padrão quando a página não declara a caixa
+}
+
+// filtrosDe separa a lista de filtros e os respectivos parâmetros do fluxo
+func (a *arquivoPDF) filtrosDe(s *pdfStream) ([]pdfNome, []pdfDict) {
+	var filtros []pdfNome
+	switch f := a.resolve(s.dict["Filter"]).(type) {
+	case pdfNome:
+		filtros = []pdfNome{f}
+	case pdfArray:
+		for _, x := range f {
+			if n, ok := a.resolve(x).(pdfNome); ok {
+				filtros = append(filtros, n)
+			}
+		}
+	}
+	parms := make([]pdfDict, len(filtros))
+	switch p := a.resolve(s.dict["DecodeParms"]).(type) {
+	case pdfDict:
+		if len(parms) > 0 {
+			parms[0] = p
+		}
+	case pdfArray:
+		for i := range parms {
+			if i < len(p) {
+				parms[i] = a.dict(p[i])
+			}
+		}
+	}
+	return filtros, parms
+}
+
+// converteImagem converte o XObject de imagem num arquivo legível pelo motor de OCR
+func (a *arquivoPDF) converteImagem(s *pdfStream) (*Imagem, error) {
+	larg, _ := comoInt(a.resolve(s.dict["Width"]))
+	alt, _ := comoInt(a.resolve(s.dict["Height"]))
+	if larg <= 0 || alt <= 0 {
+		return nil, errors.New("imagem sem dimensões")
+	}
+	img := &Imagem{Largura: larg, Altura: alt}
+
+	filtros, parms := a.filtrosDe(s)
+	if n := len(filtros); n > 0 {
+		// filtros de imagem são sempre os últimos da cadeia; os anteriores são decodificados aqui
+		final := filtros[n-1]
+		anteriores := &pdfStream{dict: pdfDict{}, dados: s.dados}
+		if n > 1 {
+			f, dp := make(pdfArray, n-1), make(pdfArray, n-1)
+			for i := range f {
+				f[i] = filtros[i]
+				if parms[i] != nil {
+					dp[i] = parms[i]
+				}
+			}
+			anteriores.dict["Filter"], anteriores.dict["DecodeParms"] = f, dp
+		}
+		switch final {
+		case "DCTDecode", "DCT", "JPXDecode", "CCITTFaxDecode", "CCF":
+			dados, err := a.decodifica(anteriores)
+			if err != nil {
+				return nil, err
+			}
+			switch final {
+			case "DCTDecode", "DCT":
+				img.Dados, img.Formato = dados, IMAGEM_JPEG
+			case "JPXDecode":
+				img.Dados, img.Formato = dados, IMAGEM_JP2
+			default:
+				img.Dados, img.Formato = tiffCCITT(dados, parms[n-1], larg, alt), IMAGEM_TIFF
+			}
+			return img, nil
+		case "JBIG2Decode":
+			return nil, fmt.Errorf("JBIG2: %w", errImagemNaoSuportada)
+		}
+	}
+
+	pixels, err := a.decodifica(s)
+	if err != nil {
+		return nil, err
+	}
+	dados, err := a.pngDosPixels(s.dict, pixels, larg, alt)
+	if err != nil {
+		return nil, err
+	}
+	img.Dados, img.Formato = dados, IMAGEM_PNG
+	return img, nil
+}
+
+// ============================================================
+// Amostras decodificadas → PNG
+// ============================================================
+
+// pngDosPixels monta o PNG a partir das amostras, conforme o espaço de cores da imagem
+func (a *arquivoPDF) pngDosPixels(d pdfDict, pixels []byte, larg, alt int) ([]byte, error) {
+	if larg*alt > maxPixelsPNG {
+		return nil, fmt.Errorf("imagem grande demais (%dx%d)", larg, alt)
+	}
+	bpc, _ := comoInt(a.resolve(d["BitsPerComponent"]))
+	mascara, _ := a.resolve(d["ImageMask"]).(bool)
+	if mascara {
+		bpc = 1
+	}
+	if bpc != 1 && bpc != 2 && bpc != 4 && bpc != 8 {
+		return nil, fmt.Errorf("%d bits por componente: %w", bpc, errImagemNaoSuportada)
+	}
+
+	comps, paleta := 1, []byte(nil)
+	if !mascara {
+		comps, paleta = a.espacoCores(d["ColorSpace"])
+		if comps == 0 {
+			return nil, fmt.Errorf("espaço de cores: %w", errImagemNaoSuportada)
+		}
+	}
+	// /Decode [1 0] inverte as amostras (comum em digitalizações de 1 bit)
+	inverte := false
+	if dec := a.array(d["Decode"]); len(dec) >= 2 {
+		v0, _ := comoNumero(a.resolve(dec[0]))
+		v1, _ := comoNumero(a.resolve(dec[1]))
+		inverte = v0 > v1
+	}
+
+	amostrasLinha := larg
+	if paleta == nil {
+		amostrasLinha = larg * comps
+	}
+	bytesLinha := (amostrasLinha*bpc + 7) / 8
+	if len(pixels) < bytesLinha*alt {
+		// fluxo truncado: completa com zeros em vez de descartar a página
+		pixels = append(pixels, make([]byte, bytesLinha*alt-len(pixels))...)
+	}
+	amostra := func(linha []byte, i int) int {
+		if bpc == 8 {
+			return int(linha[i])
+		}
+		bit := i * bpc
+		return int(linha[bit/8]>>(8-bpc-bit%8)) & (1<<bpc - 1)
+	}
+	maxAmostra := 1<<bpc - 1
+	escala := func(v int) uint8 {
+		if inverte {
+			v = maxAmostra - v
+		}
+		return uint8(v * 255 / maxAmostra)
+	}
+
+	var saida image.Image
+	switch {
+	case paleta != nil:
+		im := image.NewRGBA(image.Rect(0, 0, larg, alt))
+		for y := 0; y < alt; y++ {
+			linha := pixels[y*bytesLinha:]
+			for x := 0; x < larg; x++ {
+				k := amostra(linha, x) * 3
+				if k+2 < len(paleta) {
+					im.Set(x, y, color.RGBA{paleta[k], paleta[k+1], paleta[k+2], 255})
+				}
+			}
+		}
+		saida = im
+	case comps == 1:
+		im := image.NewGray(image.Rect(0, 0, larg, alt))
+		for y := 0; y < alt; y++ {
+			linha := pixels[y*bytesLinha:]
+			for x := 0; x < larg; x++ {
+				// nas máscaras, a amostra 0 pinta a página: sai preta, como no cinza de 1 bit
+				im.Pix[y*im.Stride+x] = escala(amostra(linha, x))
+			}
+		}
+		saida = im
+	default:
+		im := image.NewRGBA(image.Rect(0, 0, larg, alt))
+		for y := 0; y < alt; y++ {
+			linha := pixels[y*bytesLinha:]
+			for x := 0; x < larg; x++ {
+				var c [4]uint8
+				for k := 0; k < comps && k < 4; k++ {
+					c[k] = escala(amostra(linha, x*comps+k))
+				}
+				if comps == 4 {
+					// CMYK → RGB (conversão simples, suficiente para o OCR)
+					k := int(c[3])
+					c[0] = uint8(255 - min(255, int(c[0])+k))
+					c[1] = uint8(255 - min(255, int(c[1])+k))
+					c[2] = uint8(255 - min(255, int(c[2])+k))
+				}
+				im.Set(x, y, color.RGBA{c[0], c[1], c[2], 255})
+			}
+		}
+		saida = im
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, saida); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+espacoCores devolve o número de componentes por amostra e, nas imagens indexadas, a
+paleta já convertida em RGB. Espaços desconhecidos devolvem 0 componentes.
+*/
+func (a *arquivoPDF) espacoCores(v any) (int, []byte) {
+	switch cs := a.resolve(v).(type) {
+	case nil:
+		return 1, nil
+	case pdfNome:
+		switch cs {
+		case "DeviceGray", "G", "CalGray":
+			return 1, nil
+		case "DeviceRGB", "RGB", "CalRGB":
+			return 3, nil
+		case "DeviceCMYK", "CMYK":
+			return 4, nil
+		}
+	case pdfArray:
+		if len(cs) == 0 {
+			return 0, nil
+		}
+		switch a.resolve(cs[0]) {
+		case pdfNome("CalGray"):
+			return 1, nil
+		case pdfNome("CalRGB"), pdfNome("Lab"):
+			return 3, nil
+		case pdfNome("ICCBased"):
+			if len(cs) > 1 {
+				if s, ok := a.resolve(cs[1]).(*pdfStream); ok {
+					if n, ok := comoInt(a.resolve(s.dict["N"])); ok && n > 0 {
+						return n, nil
+					}
+					return a.espacoCores(s.dict["Alternate"])
+				}
+			}
+		case pdfNome("Indexed"), pdfNome("I"):
+			if len(cs) < 4 {
+				return 0, nil
+			}
+			base, _ := a.espacoCores(cs[1])
+			var tabela []byte
+			switch t := a.resolve(cs[3]).(type) {
+			case pdfString:
+				tabela = t
+			case *pdfStream:
+				tabela, _ = a.decodifica(t)
+			}
+			return 1, paletaRGB(tabela, base)
+		}
+	}
+	return 0, nil
+}
+
+// paletaRGB converte a tabela de cores de uma imagem indexada em trios RGB
+func paletaRGB(tabela []byte, comps int) []byte {
+	if comps != 1 && comps != 3 && comps != 4 {
+		return nil
+	}
+	n := len(tabela) / comps
+	out := make([]byte, 0, n*3)
+	for i := 0; i < n; i++ {
+		c := tabela[i*comps : (i+1)*comps]
+		switch comps {
+		case 1:
+			out = append(out, c[0], c[0], c[0])
+		case 3:
+			out = append(out, c...)
+		case 4:
+			k := int(c[3])
+			out = append(out, uint8(255-min(255, int(c[0])+k)), uint8(255-min(255, int(c[1])+k)), uint8(255-min(255, int(c[2])+k)))
+		}
+	}
+	return out
+}
+
+// ============================================================
+// CCITT G3/G4 → TIFF
+// ============================================================
+
+/*
+tiffCCITT embrulha os dados CCITT num TIFF de uma faixa, sem decodificá-los. Os parâmetros
+seguem o /DecodeParms do PDF: K < 0 é G4, K >= 0 é G3; BlackIs1 inverte a interpretação.
+*/
+func tiffCCITT(dados []byte, p pdfDict, larg, alt int) []byte {
+	k, _ := comoInt(p["K"])
+	if c, ok := comoInt(p["Columns"]); ok && c > 0 {
+		larg = c
+	}
+	if r, ok := comoInt(p["Rows"]); ok && r > 0 {
+		alt = r
+	}
+	pretoUm, _ := p["BlackIs1"].(bool)
+
+	compressao, opcoesTag, opcoes := uint16(4), uint16(293), uint32(0) // G4 (T6Options)
+	if k >= 0 {
+		compressao, opcoesTag = 3, 292 // G3 (T4Options)
+		if k > 0 {
+			opcoes = 1 // codificação 2D
+		}
+	}
+	fotometria := uint16(0) // WhiteIsZero, como nos TIFF de fax; BlackIs1 inverte
+	if pretoUm {
+		fotometria = 1
+	}
+
+	type entrada struct {
+		tag, tipo uint16
+		valor     uint32
+	}
+	entradas := []entrada{
+		{256, 4, uint32(larg)},       // ImageWidth
+		{257, 4, uint32(alt)},        // ImageLength
+		{258, 3, 1},                  // BitsPerSample
+		{259, 3, uint32(compressao)}, // Compression
+		{262, 3, uint32(fotometria)}, // PhotometricInterpretation
+		{273, 4, 0},                  // StripOffsets (preenchido abaixo)
+		{277, 3, 1},                  // SamplesPerPixel
+		{278, 4, uint32(alt)},        // RowsPerStrip
+		{279, 4, uint32(len(dados))}, // StripByteCounts
+		{opcoesTag, 4, opcoes},       // T4Options / T6Options (as tags vão em ordem crescente)
+	}
+
+	inicioIFD := 8
+	tamIFD := 2 + 12*len(entradas) + 4
+	inicioDados := inicioIFD + tamIFD
+	for i := range entradas {
+		if entradas[i].tag == 273 {
+			entradas[i].valor = uint32(inicioDados)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("II")
+	binary.Write(&buf, binary.LittleEndian, uint16(42))
+	binary.Write(&buf, binary.LittleEndian, uint32(inicioIFD))
+	binary.Write(&buf, binary.LittleEndian, uint16(len(entradas)))
+	for _, e := range entradas {
+		binary.Write(&buf, binary.LittleEndian, e.tag)
+		binary.Write(&buf, binary.LittleEndian, e.tipo)
+		binary.Write(&buf, binary.LittleEndian, uint32(1))
+		if e.tipo == 3 {
+			binary.Write(&buf, binary.LittleEndian, uint16(e.valor))
+			binary.Write(&buf, binary.LittleEndian, uint16(0))
+		} else {
+			binary.Write(&buf, binary.LittleEndian, e.valor)
+		}
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // sem próximo IFD
+	buf.Write(dados)
+	return buf.Bytes()
+}
@@ -0,0 +1,152 @@
+/*
+---------------------------------------------------------------------------------------
+File: leiaute.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Reconstrução das linhas de texto da página a partir das posições dos
+caracteres. Como no "pdftotext -layout", a posição horizontal é convertida em coluna
+de texto, de modo que tabelas (ex.: o índice de documentos do PJe) mantêm as colunas
+separadas por dois ou mais espaços. Textos girados (ex.: a tarja lateral de assinatura)
+são lidos na própria direção e vêm depois do texto horizontal.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	limiarPalavra = 0.12 // distância mínima (fração do corpo) entre caracteres de palavras diferentes
+	limiarLinha   = 0.5  // diferença máxima da linha de base (fração do corpo) na mesma linha
+	limiarVazia   = 2.0  // distância entre linhas (fração do corpo) que gera uma linha em branco
+)
+
+var ligaduras = strings.NewReplacer("ﬀ", "ff", "ﬁ", "fi", "ﬂ", "fl", "ﬃ", "ffi", "ﬄ", "ffl", " ", " ")
+
+type linhaTexto struct {
+	y     float64
+	corpo float64
+	chars []caractere
+}
+
+// montaTexto devolve o texto da página, linha a linha
+func montaTexto(chars []caractere) string {
+	grupos := map[int][]caractere{}
+	for _, c := range chars {
+		grupos[c.angulo] = append(grupos[c.angulo], c)
+	}
+	var blocos []string
+	for _, ang := range []int{0, 90, 270, 180} {
+		if len(grupos[ang]) == 0 {
+			continue
+		}
+		if t := montaGrupo(giraPara(grupos[ang], ang)); t != "" {
+			blocos = append(blocos, t)
+		}
+	}
+	return ligaduras.Replace(strings.Join(blocos, "\n\n"))
+}
+
+// giraPara leva os caracteres de direção "ang" para a horizontal
+func giraPara(chars []caractere, ang int) []caractere {
+	out := make([]caractere, len(chars))
+	for i, c := range chars {
+		switch ang {
+		case 90:
+			c.x, c.y = c.y, -c.x
+		case 180:
+			c.x, c.y = -c.x, -c.y
+		case 270:
+			c.x, c.y = -c.y, c.x
+		}
+		out[i] = c
+	}
+	return out
+}
+
+func montaGrupo(chars []caractere) string {
+	// linhas: de cima para baixo
+	sort.SliceStable(chars, func(i, j int) bool { return chars[i].y > chars[j].y })
+	var linhas []*linhaTexto
+	for _, c := range chars {
+		if n := len(linhas); n > 0 {
+			l := linhas[n-1]
+			if math.Abs(l.y-c.y) <= limiarLinha*math.Max(l.corpo, 1) {
+				l.chars = append(l.chars, c)
+				continue
+			}
+		}
+		linhas = append(linhas, &linhaTexto{y: c.y, corpo: c.corpo, chars: []caractere{c}})
+	}
+
+	// largura de uma coluna de texto e margem esquerda do grupo
+	xMin, soma, qtd := math.Inf(1), 0.0, 0
+	for _, c := range chars {
+		xMin = math.Min(xMin, c.x)
+		if c.texto != " " && c.largura > 0 {
+			soma += c.largura / float64(max(1, utf8.RuneCountInString(c.texto)))
+			qtd++
+		}
+	}
+	coluna := 5.0
+	if qtd > 0 {
+		coluna = math.Max(soma/float64(qtd), 1)
+	}
+
+	var sb strings.Builder
+	for i, l := range linhas {
+		if i > 0 {
+			sb.WriteByte('\n')
+			if linhas[i-1].y-l.y > limiarVazia*math.Max(l.corpo, linhas[i-1].corpo) {
+				sb.WriteByte('\n')
+			}
+		}
+		sb.WriteString(montaLinha(l.chars, xMin, coluna))
+	}
+	return strings.TrimRight(sb.String(), "\n ")
+}
+
+func montaLinha(chars []caractere, xMin, coluna float64) string {
+	sort.SliceStable(chars, func(i, j int) bool { return chars[i].x < chars[j].x })
+
+	var sb strings.Builder
+	col := 0     // coluna atual na linha de saída
+	brancos := 0 // espaços no fim da linha de saída
+	var ant *caractere
+	for i := range chars {
+		c := &chars[i]
+		// negrito simulado: o mesmo glifo desenhado de novo quase na mesma posição
+		if ant != nil && c.texto == ant.texto && math.Abs(c.x-ant.x) < 0.2*c.corpo {
+			continue
+		}
+		alvo := int(math.Round((c.x - xMin) / coluna))
+		if c.texto != " " {
+			n := 0
+			if ant == nil {
+				n = alvo
+			} else if lacuna := c.x - (ant.x + ant.largura); lacuna > 2*coluna {
+				// afastamento maior que um espaço comum: colunas de tabela, tabulações
+				n = max(alvo-col, 2-brancos)
+			} else if lacuna > limiarPalavra*c.corpo {
+				n = 1 - brancos
+			}
+			if n > 0 {
+				sb.WriteString(strings.Repeat(" ", n))
+				col += n
+			}
+		}
+		sb.WriteString(c.texto)
+		col += utf8.RuneCountInString(c.texto)
+		if strings.TrimSpace(c.texto) == "" {
+			brancos++
+		} else {
+			brancos = 0
+		}
+		ant = c
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
@@ -0,0 +1,183 @@
+/*
+---------------------------------------------------------------------------------------
+File: marcadores.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Leitura do sumário (marcadores/bookmarks) do PDF, ISO 32000-1, seção 12.3.3.
+A pasta digital do e-SAJ marca o início de cada peça com um marcador; o destino de
+cada item é convertido no número da página (a partir de 1).
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// proteção contra sumários cíclicos ou gigantes
+const maxMarcadores = 20000
+
+// Marcador é um item do sumário do PDF
+type Marcador struct {
+	Titulo string
+	Pagina int // página de destino (a partir de 1); 0 quando o destino não foi resolvido
+	Nivel  int // 1 para os itens do primeiro nível
+}
+
+// Marcadores lê o sumário do PDF, na ordem em que os itens aparecem
+func Marcadores(pdfPath string) ([]Marcador, error) {
+	dados, err := leArquivoPDF(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	return MarcadoresBytes(dados)
+}
+
+// MarcadoresBytes lê o sumário de um PDF já carregado em memória; sem sumário, devolve nil
+func MarcadoresBytes(dados []byte) (marcadores []Marcador, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			marcadores, err = nil, fmt.Errorf("PDF malformado: %v", r)
+		}
+	}()
+	arq, err := abreArquivo(dados)
+	if err != nil {
+		return nil, err
+	}
+	marcadores = arq.marcadores()
+	if arq.errLimite != nil {
+		return nil, arq.errLimite
+	}
+	return marcadores, nil
+}
+
+func (a *arquivoPDF) marcadores() []Marcador {
+	raiz := a.dict(a.trailer["Root"])
+	sumario := a.dict(raiz["Outlines"])
+	if sumario == nil {
+		return nil
+	}
+	indice := map[pdfRef]int{}
+	for i, p := range a.paginas() {
+		if p.ref != (pdfRef{}) {
+			indice[p.ref] = i + 1
+		}
+	}
+
+	var out []Marcador
+	visitados := map[pdfRef]bool{}
+	var percorre func(no any, nivel int)
+	percorre = func(no any, nivel int) {
+		for no != nil && nivel <= 32 && len(out) < maxMarcadores {
+			if r, ok := no.(pdfRef); ok {
+				if visitados[r] {
+					return
+				}
+				visitados[r] = true
+			}
+			item := a.dict(no)
+			if item == nil {
+				return
+			}
+			destino := item["Dest"]
+			if acao := a.dict(item["A"]); destino == nil && acao != nil && acao.nome("S") == "GoTo" {
+				destino = acao["D"]
+			}
+			titulo, _ := a.resolve(item["Title"]).(pdfString)
+			out = append(out, Marcador{
+				Titulo: strings.TrimSpace(textoPDF(titulo)),
+				Pagina: a.paginaDoDestino(raiz, destino, indice),
+				Nivel:  nivel,
+			})
+			percorre(item["First"], nivel+1)
+			no = item["Next"]
+		}
+	}
+	percorre(sumario["First"], 1)
+	return out
+}
+
+// paginaDoDestino resolve destinos explícitos ([página /XYZ ...]) e nomeados
+func (a *arquivoPDF) paginaDoDestino(raiz pdfDict, destino any, indice map[pdfRef]int) int {
+	for i := 0; i < 4; i++ {
+		switch d := a.resolve(destino).(type) {
+		case pdfNome:
+			destino = a.destinoNomeado(raiz, string(d))
+		case pdfString:
+			destino = a.destinoNomeado(raiz, string(d))
+		case pdfDict:
+			destino = d["D"]
+		case pdfArray:
+			if len(d) == 0 {
+				return 0
+			}
+			if r, ok := d[0].(pdfRef); ok {
+				return indice[r]
+			}
+			// destinos em outro arquivo trazem o número da página (a partir de 0)
+			if n, ok := comoInt(d[0]); ok {
+				return n + 1
+			}
+			return 0
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// destinoNomeado procura o nome no dicionário /Dests (PDF 1.1) e na árvore /Names /Dests
+func (a *arquivoPDF) destinoNomeado(raiz pdfDict, nome string) any {
+	if d := a.dict(raiz["Dests"]); d != nil {
+		if v, ok := d[pdfNome(nome)]; ok {
+			return v
+		}
+	}
+	return a.buscaArvoreNomes(a.dict(raiz["Names"])["Dests"], nome, 0)
+}
+
+func (a *arquivoPDF) buscaArvoreNomes(no any, nome string, nivel int) any {
+	d := a.dict(no)
+	if d == nil || nivel > 32 {
+		return nil
+	}
+	nomes := a.array(d["Names"])
+	for i := 0; i+1 < len(nomes); i += 2 {
+		if s, ok := a.resolve(nomes[i]).(pdfString); ok && string(s) == nome {
+			return nomes[i+1]
+		}
+	}
+	for _, k := range a.array(d["Kids"]) {
+		if v := a.buscaArvoreNomes(k, nome, nivel+1); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// caracteres 0x80 a 0x9E da PDFDocEncoding (ISO 32000-1, anexo D.2)
+var pdfDocEncoding = []rune("•†‡…—–ƒ⁄‹›−‰„“”‘’‚™ﬁﬂŁŒŠŸŽıłœšž")
+
+// textoPDF decodifica uma string de texto do PDF (UTF-16BE com BOM, UTF-8 com BOM ou PDFDocEncoding)
+func textoPDF(b pdfString) string {
+	switch {
+	case len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF:
+		return textoUTF16(b[2:])
+	case len(b) >= 3 && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF && utf8.Valid(b[3:]):
+		return string(b[3:])
+	}
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c >= 0x80 && int(c-0x80) < len(pdfDocEncoding):
+			sb.WriteRune(pdfDocEncoding[c-0x80])
+		case c == 0xA0:
+			sb.WriteRune('€')
+		default:
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
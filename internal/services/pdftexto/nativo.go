@@ -0,0 +1,95 @@
+/*
+---------------------------------------------------------------------------------------
+File: nativo.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Extrator de texto de PDF em Go puro (sem dependências externas). Lê a
+estrutura do arquivo, interpreta o conteúdo de cada página e reconstrói as linhas
+preservando o leiaute. PDFs criptografados ou com filtros não suportados devolvem erro
+(o extrator encadeado recorre então ao pdftotext, se instalado).
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ocrserver/internal/utils/logger"
+)
+
+type ExtratorNativo struct{}
+
+func (e *ExtratorNativo) Nome() string {
+	return EXTRATOR_NATIVO
+}
+
+func (e *ExtratorNativo) Extrai(ctx context.Context, pdfPath string) ([]Pagina, error) {
+	dados, err := leArquivoPDF(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	return ExtraiBytes(ctx, dados)
+}
+
+// ExtraiBytes extrai o texto das páginas de um PDF já carregado em memória
+func ExtraiBytes(ctx context.Context, dados []byte) (paginas []Pagina, err error) {
+	// PDFs malformados não podem derrubar o servidor
+	defer func() {
+		if r := recover(); r != nil {
+			paginas, err = nil, fmt.Errorf("PDF malformado: %v", r)
+		}
+	}()
+
+	arq, err := abreArquivo(dados)
+	if err != nil {
+		return nil, err
+	}
+	lista := arq.paginas()
+	if len(lista) == 0 {
+		return nil, errors.New("PDF sem páginas")
+	}
+
+	fontes := map[any]*fontePDF{}
+	paginas = make([]Pagina, 0, len(lista))
+	for i, p := range lista {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		paginas = append(paginas, Pagina{Numero: i + 1, Texto: extraiPagina(arq, p, fontes, i+1), Origem: ORIGEM_TEXTO})
+		if arq.errLimite != nil {
+			return nil, arq.errLimite
+		}
+	}
+	return paginas, nil
+}
+
+// extraiPagina interpreta a página; uma página ilegível resulta em texto vazio
+func extraiPagina(arq *arquivoPDF, p paginaPDF, fontes map[any]*fontePDF, numero int) (texto string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Log.Warningf("Página %d do PDF ilegível: %v", numero, r)
+			texto = ""
+		}
+	}()
+
+	it := &interpretador{arq: arq, fontes: fontes}
+	gs := estadoGrafico{ctm: rotacaoPagina(arq, p), escalaH: 1}
+	it.executa(arq.conteudo(p), p.recursos, gs)
+	return montaTexto(it.chars)
+}
+
+// rotacaoPagina aplica o /Rotate da página, para que o texto seja lido na orientação de exibição
+func rotacaoPagina(arq *arquivoPDF, p paginaPDF) matriz {
+	rot, _ := comoInt(arq.resolve(p.dict["Rotate"]))
+	switch ((rot % 360) + 360) % 360 {
+	case 90:
+		return matriz{0, -1, 1, 0, 0, 0}
+	case 180:
+		return matriz{-1, 0, 0, -1, 0, 0}
+	case 270:
+		return matriz{0, 1, -1, 0, 0, 0}
+	}
+	return identidade
+}
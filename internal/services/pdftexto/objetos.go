@@ -0,0 +1,384 @@
+/*
+---------------------------------------------------------------------------------------
+File: objetos.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Analisador léxico e sintático dos objetos PDF (ISO 32000-1, seção 7.3),
+usado tanto no corpo do arquivo quanto nos fluxos de conteúdo das páginas.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// Tipos dos objetos PDF. Inteiros são int, reais são float64, booleanos são bool e null é nil.
+type (
+	pdfNome     string
+	pdfString   []byte
+	pdfArray    []any
+	pdfDict     map[pdfNome]any
+	pdfOperador string // palavra-chave dos fluxos de conteúdo (Tj, BT, cm...)
+	pdfRef      struct{ num, ger int }
+	pdfStream   struct {
+		dict  pdfDict
+		dados []byte // conteúdo ainda codificado (ver decodifica)
+	}
+)
+
+// marcadores de fim de array/dicionário devolvidos pelo léxico
+type pdfFim byte
+
+var errFimDados = errors.New("fim inesperado dos dados")
+
+func ehEspaco(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func ehDelimitador(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+type lexico struct {
+	b   []byte
+	pos int
+	// refs habilita o reconhecimento de "num ger R" (desligado nos fluxos de conteúdo)
+	refs bool
+}
+
+func (l *lexico) pulaEspacos() {
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		if ehEspaco(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// palavra lê uma sequência de caracteres regulares
+func (l *lexico) palavra() []byte {
+	ini := l.pos
+	for l.pos < len(l.b) && !ehEspaco(l.b[l.pos]) && !ehDelimitador(l.b[l.pos]) {
+		l.pos++
+	}
+	return l.b[ini:l.pos]
+}
+
+// objeto lê o próximo objeto (ou operador/marcador de fim)
+func (l *lexico) objeto() (any, error) {
+	l.pulaEspacos()
+	if l.pos >= len(l.b) {
+		return nil, errFimDados
+	}
+	c := l.b[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		return l.nome(), nil
+	case c == '(':
+		l.pos++
+		return l.stringLiteral(), nil
+	case c == '<':
+		if l.pos+1 < len(l.b) && l.b[l.pos+1] == '<' {
+			l.pos += 2
+			return l.dicionario()
+		}
+		l.pos++
+		return l.stringHex(), nil
+	case c == '>':
+		if l.pos+1 < len(l.b) && l.b[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfFim('>'), nil
+		}
+		l.pos++
+		return l.objeto()
+	case c == '[':
+		l.pos++
+		return l.array()
+	case c == ']':
+		l.pos++
+		return pdfFim(']'), nil
+	case c == '{' || c == '}' || c == ')':
+		l.pos++
+		return pdfOperador(c), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.numero(), nil
+	}
+
+	p := l.palavra()
+	if len(p) == 0 {
+		l.pos++
+		return l.objeto()
+	}
+	switch string(p) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfOperador(p), nil
+}
+
+func (l *lexico) nome() pdfNome {
+	p := l.palavra()
+	if bytes.IndexByte(p, '#') < 0 {
+		return pdfNome(p)
+	}
+	out := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == '#' && i+2 < len(p) {
+			if v, err := strconv.ParseUint(string(p[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, p[i])
+	}
+	return pdfNome(out)
+}
+
+func (l *lexico) numero() any {
+	l.pulaEspacos()
+	p := l.palavra()
+	if len(p) == 0 {
+		l.pos++
+		return 0
+	}
+	if bytes.IndexByte(p, '.') < 0 {
+		n, err := strconv.Atoi(string(p))
+		if err != nil {
+			return 0
+		}
+		if l.refs && n >= 0 {
+			if r, ok := l.referencia(n); ok {
+				return r
+			}
+		}
+		return n
+	}
+	// reais malformados ("--1", "1.2.3") são comuns em geradores antigos: aproveita o que der
+	s := string(p)
+	for len(s) > 0 {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		s = s[:len(s)-1]
+	}
+	return 0.0
+}
+
+// referencia reconhece "num ger R" a partir de um inteiro já lido
+func (l *lexico) referencia(num int) (pdfRef, bool) {
+	salva := l.pos
+	l.pulaEspacos()
+	ini := l.pos
+	for l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > ini && l.pos < len(l.b) && ehEspaco(l.b[l.pos]) {
+		ger, _ := strconv.Atoi(string(l.b[ini:l.pos]))
+		l.pulaEspacos()
+		if l.pos < len(l.b) && l.b[l.pos] == 'R' && (l.pos+1 == len(l.b) || ehEspaco(l.b[l.pos+1]) || ehDelimitador(l.b[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: num, ger: ger}, true
+		}
+	}
+	l.pos = salva
+	return pdfRef{}, false
+}
+
+func (l *lexico) stringLiteral() pdfString {
+	var out []byte
+	nivel := 1
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			nivel++
+		case ')':
+			nivel--
+			if nivel == 0 {
+				return out
+			}
+		case '\r':
+			// fim de linha dentro da string equivale a \n
+			if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+				l.pos++
+			}
+			c = '\n'
+		case '\\':
+			if l.pos >= len(l.b) {
+				return out
+			}
+			e := l.b[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for k := 0; k < 2 && l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '7'; k++ {
+						v = v*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func valorHex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func (l *lexico) stringHex() pdfString {
+	var out []byte
+	var alto byte
+	meio := false
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := valorHex(c)
+		if !ok {
+			continue
+		}
+		if meio {
+			out = append(out, alto<<4|v)
+		} else {
+			alto = v
+		}
+		meio = !meio
+	}
+	if meio {
+		out = append(out, alto<<4)
+	}
+	return out
+}
+
+func (l *lexico) array() (pdfArray, error) {
+	var arr pdfArray
+	for {
+		o, err := l.objeto()
+		if err != nil {
+			return arr, err
+		}
+		if f, ok := o.(pdfFim); ok {
+			if f == ']' {
+				return arr, nil
+			}
+			continue
+		}
+		arr = append(arr, o)
+	}
+}
+
+func (l *lexico) dicionario() (pdfDict, error) {
+	d := pdfDict{}
+	for {
+		o, err := l.objeto()
+		if err != nil {
+			return d, err
+		}
+		if f, ok := o.(pdfFim); ok {
+			if f == '>' {
+				return d, nil
+			}
+			continue
+		}
+		chave, ok := o.(pdfNome)
+		if !ok {
+			continue
+		}
+		v, err := l.objeto()
+		if err != nil {
+			return d, err
+		}
+		if f, ok := v.(pdfFim); ok {
+			if f == '>' {
+				return d, nil
+			}
+			continue
+		}
+		d[chave] = v
+	}
+}
+
+// ============================================================
+// Acessores tolerantes a tipos (os geradores de PDF variam muito)
+// ============================================================
+
+func comoNumero(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func comoInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func (d pdfDict) nome(chave pdfNome) pdfNome {
+	n, _ := d[chave].(pdfNome)
+	return n
+}
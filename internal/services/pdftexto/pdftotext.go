@@ -0,0 +1,46 @@
+/*
+---------------------------------------------------------------------------------------
+File: pdftotext.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Backend opcional de extração que usa o utilitário "pdftotext -layout" do
+poppler. A saída é lida diretamente do stdout (nenhum arquivo .txt é criado ao lado do
+upload) e dividida em páginas pelo form-feed.
+---------------------------------------------------------------------------------------
+*/
+package pdftexto
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type ExtratorPdftotext struct{}
+
+func (e *ExtratorPdftotext) Nome() string {
+	return EXTRATOR_PDFTOTEXT
+}
+
+func (e *ExtratorPdftotext) Extrai(ctx context.Context, pdfPath string) ([]Pagina, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "pdftotext", "-layout", "-enc", "UTF-8", pdfPath, "-")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("erro executando pdftotext: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// O pdftotext encerra cada página com \f; o último elemento (vazio) é descartado
+	partes := strings.Split(stdout.String(), "\f")
+	if n := len(partes); n > 1 && strings.TrimSpace(partes[n-1]) == "" {
+		partes = partes[:n-1]
+	}
+	paginas := make([]Pagina, len(partes))
+	for i, texto := range partes {
+		paginas[i] = Pagina{Numero: i + 1, Texto: strings.TrimRight(texto, "\n"), Origem: ORIGEM_TEXTO}
+	}
+	return paginas, nil
+}
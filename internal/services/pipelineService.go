@@ -11,24 +11,55 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
 
 	"ocrserver/internal/config"
 	"ocrserver/internal/consts"
 
 	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/services/rag/parsers"
 
 	"ocrserver/internal/utils/erros"
 	"ocrserver/internal/utils/logger"
-	"strings"
+
+	"github.com/openai/openai-go/v3/responses"
 )
 
+// ErrAutuacaoEmAndamento indica documento já em autuação nesta instância (pedido repetido)
+var ErrAutuacaoEmAndamento = errors.New("documento já está em autuação")
+
+// Documentos em autuação nesta instância, pelo id em "autos_temp"
+var emAutuacao sync.Map
+
+/*
+ChaveAutuacao é a chave de idempotência do documento: o id do registro em "autos",
+derivado do contexto, do id em "autos_temp" e do hash do texto. A mesma versão do
+documento, autuada de novo (retentativa, job reexecutado, pedido repetido), grava
+sempre o mesmo registro, em vez de duplicá-lo.
+*/
+func ChaveAutuacao(idCtxt string, idDoc string, hashTexto string) string {
+	h := sha256.Sum256([]byte(idCtxt + "|" + idDoc + "|" + hashTexto))
+	return "aut-" + hex.EncodeToString(h[:16])
+}
+
 /*
 **  Pipeline de ingestão dos documentos do processo, sendo salvos nas tabelas "autos", "autos_json_embedding"
+**
+**  A autuação grava o documento em "autos" (na chave de idempotência) e só depois o apaga
+**  de "autos_temp". Interrompida entre os dois passos, é retomada sem nova chamada ao
+**  modelo: o documento já gravado na mesma chave é reconhecido e só falta a exclusão.
+**  O cancelamento do contexto interrompe a chamada ao modelo e as retentativas, nunca a
+**  gravação já iniciada.
  */
-func ProcessarDocumento(IdContexto string, IdDoc string) error {
-	ctx := context.Background()
+func ProcessarDocumento(ctx context.Context, IdContexto string, IdDoc string) error {
 	if IdContexto == "" || IdDoc == "" {
 		//return fmt.Errorf("idContexto ou idDoc vazio")
 		logger.Log.Error("IdContexto ou IdDoc vazio.")
@@ -38,6 +69,10 @@ func ProcessarDocumento(IdContexto string, IdDoc string) error {
 		logger.Log.Error("Objeto global 'AutosTempServiceGlobal' não foi inicializado.")
 		return erros.CreateError("Objeto global 'AutosTempServiceGlobal' não foi inicializado.")
 	}
+	if _, emCurso := emAutuacao.LoadOrStore(IdDoc, struct{}{}); emCurso {
+		return fmt.Errorf("idDoc=%s: %w", IdDoc, ErrAutuacaoEmAndamento)
+	}
+	defer emAutuacao.Delete(IdDoc)
 
 	msg := fmt.Sprintf("Processando documento: IdContexto=%s - IdDoc=%s", IdContexto, IdDoc)
 	logger.Log.Info(msg)
@@ -45,20 +80,28 @@ func ProcessarDocumento(IdContexto string, IdDoc string) error {
 	/*01 - AUTOS_TEMP: Recupero o registro do índice "autos_temp" */
 
 	row, err := AutosTempServiceGlobal.SelectById(IdDoc)
-	if err != nil {
+	if err != nil || row == nil {
 		return fmt.Errorf("Documento  não encontrato no índice 'autos_temp' - idDoc=%s - IdContexto=%s", IdDoc, IdContexto)
 	}
 	logger.Log.Infof("\nID PJe: %s - INÍCIO", row.IdPje)
-	/*02 - DUPLICIDADE: Verifica, pelo id_pje se o documentos está sendo inserido em duplicidade*/
+	hash := HashTexto(row.Doc)
+	chave := ChaveAutuacao(IdContexto, IdDoc, hash)
+
+	/*02 - DUPLICIDADE: Verifica, pelo id_pje e pelo hash do texto, se o documento está sendo
+	inserido em duplicidade. Uma nova versão (texto alterado) substitui a autuada. */
 
-	isAutuado, err := AutosServiceGlobal.IsDocAutuado(IdContexto, row.IdPje)
+	autuado, err := AutosServiceGlobal.SelectByIdPje(IdContexto, row.IdPje)
 	if err != nil {
 		logger.Log.Infof("Erro ao verificar a existência do documento em 'autos': %v", err)
 		return erros.CreateErrorf("Erro ao verificar a existência do documento em 'autos': %v", err.Error())
 	}
-	if isAutuado {
-		logger.Log.Errorf("Documento %s já existe no índice 'autos'", IdDoc)
-		return erros.CreateErrorf("Documento %s já existe no índice 'autos'", IdDoc)
+	if autuado != nil && (autuado.Id == chave || HashTexto(autuado.Doc) == hash) {
+		// Autuação anterior interrompida antes da exclusão em "autos_temp": só falta concluí-la
+		logger.Log.Infof("Documento %s já gravado em 'autos' (id=%s): concluindo a autuação", IdDoc, autuado.Id)
+		if regs, err := AutosJsonServiceGlobal.SelectByIdDoc(autuado.Id); err == nil && len(regs) == 0 {
+			indexaEmbeddingAutos(ctx, autuado)
+		}
+		return concluiAutuacao(IdDoc, row.IdPje, nil)
 	}
 
 	/*03 - PROMPT: Recupero o natuPrompt da tabela "prompts"*/
@@ -79,17 +122,18 @@ func ProcessarDocumento(IdContexto string, IdDoc string) error {
 	messages.CreateMessage("", "user", prompt)
 	messages.CreateMessage("", "user", row.Doc)
 
-	/*04 - CHATGPT:  Extrai o JSON utilizando o prompt */
-
-	retSubmit, err := OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
-		messages,
-		"",
-		config.GlobalConfig.OpenOptionModel,
-		ialib.REASONING_LOW,
-		ialib.VERBOSITY_LOW)
-	if err != nil {
-		return erros.CreateErrorf("idDoc=%s : %s", IdDoc, err.Error())
+	/*04 - CHATGPT:  Extrai o JSON utilizando o prompt, com novas tentativas nas falhas transitórias */
+
+	formato := parsers.FormatoAutuacao(row.IdNatu)
+	ctxPrompt := ialib.WithDocumento(ialib.WithNaturezaPrompt(ctx, natuPrompt), row.IdNatu, row.IdPje)
+	ctxPrompt = ialib.WithFormatoJSON(ctxPrompt, formato)
+	maxReparos := config.GlobalConfig.AutuacaoMaxReparos
+	retSubmit, err := submeteComRetentativas(ctxPrompt, IdDoc, messages)
+	if errors.Is(err, ialib.ErrRespostaForaDoSchema) {
+		// Os reparos já foram feitos por SubmitPromptResponse: resta a quarentena
+		maxReparos = 0
+	} else if err != nil {
+		return fmt.Errorf("idDoc=%s : %w", IdDoc, err)
 	}
 	usage := retSubmit.Usage
 
@@ -103,68 +147,77 @@ func ProcessarDocumento(IdContexto string, IdDoc string) error {
 
 	// 06 - Limpa e prepara a resposta JSON
 
-	item, err := FirstMessageFromSubmit(retSubmit)
+	rspJson, err := textoRespostaAutuacao(retSubmit)
 	if err != nil {
-		return erros.CreateErrorf("resposta do modelo sem texto: %v", err)
+		return erros.CreateErrorf("%v", err)
 	}
-	rspJson, err := ExtractOutputText(item)
+	//logger.Log.Infof("json=%s", rspJson)
 
+	// 07 - Confere o JSON com o schema da natureza: fora dele, reparo ou quarentena
+	rspJson, err = validaRespostaAutuacao(ctxPrompt, IdContexto, IdDoc, row, formato, messages, rspJson, maxReparos)
 	if err != nil {
-		return erros.CreateErrorf("falha ao extrair texto da resposta: %v", err)
+		return err
 	}
-
-	rspJson = strings.Trim(rspJson, "`\"")
-	//logger.Log.Infof("json=%s", rspJson)
-
-	// 07 - Verifica se o JSON é válido
 	var objJson DocumentoBase
 	if err := json.Unmarshal([]byte(rspJson), &objJson); err != nil {
 		return erros.CreateErrorf("ERROR: Erro ao fazer o parse do JSON: %w", err)
 	}
 
-	/*06 - AUTOS: Faz a inclusão do documentos na índice "autos" */
+	/*06 - AUTOS: Faz a inclusão do documentos na índice "autos", na chave de idempotência */
 
 	idCtxt := IdContexto
-	idNatu := objJson.Tipo.Key
+	idNatu := row.IdNatu
+	if objJson.Tipo != nil && objJson.Tipo.Key != idNatu {
+		// Reclassificação pelo modelo: só se o JSON também seguir o schema da nova natureza
+		if errosNatu := parsers.ValidaDocumentoJson(objJson.Tipo.Key, rspJson); len(errosNatu) == 0 {
+			idNatu = objJson.Tipo.Key
+		} else {
+			logger.Log.Warningf("idDoc=%s: natureza %d indicada pelo modelo ignorada (JSON fora do schema)", IdDoc, objJson.Tipo.Key)
+		}
+	}
 	idPje := objJson.IdPje
+	if idPje == "" {
+		idPje = row.IdPje
+	}
 
-	// rowAutos, err := AutosServiceGlobal.InserirAutos(idCtxt, idNatu, idPje, row.Doc, rspJson)
-	_, err = AutosServiceGlobal.InserirAutos(idCtxt, idNatu, idPje, row.Doc, rspJson)
+	rowAutos, err := AutosServiceGlobal.InserirAutos(idCtxt, idNatu, idPje, row.Doc, rspJson, row.Procedencia, chave)
 	if err != nil {
 		logger.Log.Error("Erro ao inserir documento no índice 'autos'")
 		return erros.CreateError("Erro ao inserir documento no índice 'autos'")
 	}
-	//************************************************************************************************
-	// CRIAR O EMBEDDING a partir do texto do documento inserido em "autos"
-	// Vamos criar embedding apenas para identificar a causa, a partir da
-	// petição inicial, contestação, réplica e demais petições
-	// if idNatu == consts.NATU_DOC_INICIAL ||
-	// 	idNatu == consts.NATU_DOC_CONTESTACAO ||
-	// 	idNatu == consts.NATU_DOC_REPLICA ||
-	// 	idNatu == consts.NATU_DOC_PETICAO ||
-	// 	idNatu == consts.NATU_DOC_PARECER_MP {
-	// if false {
-
-	// 	jsonRaw, _ := parsers.ParserDocumentosJson(idNatu, json.RawMessage(rspJson)) // se parser espera RawMessage
-
-	// 	embVector, err := ialib.GetDocumentoEmbeddings(jsonRaw)
-	// 	if err != nil {
-	// 		logger.Log.Errorf("Erro ao extrair os embeddings do documento: %v", err)
-	// 		return erros.CreateErrorf("Erro ao extrair o embedding: Contexto: %d - IdDoc: %s", idCtxt, rowAutos.Id)
-	// 	}
-
-	// 	/* Insere o registro no índice "autos_json_embedding" */
-
-	// 	_, err = AutosJsonServiceGlobal.InserirEmbedding(rowAutos.Id, idCtxt, idNatu, embVector)
-	// 	if err != nil {
-	// 		logger.Log.Errorf("ERROR: Erro na inclusão do documento no índice 'autos_json_embedding'")
-	// 		return fmt.Errorf("ERROR: Erro na inclusão do documento no índice 'autos_json_embedding'")
-	// 	}
-	// }
-
-	/*07 - DELETA TEMP_AUTOS:  Faz a deleção do registro na tabela temp_autos  */
-
-	err = AutosTempServiceGlobal.DeletaAutos(IdDoc)
+
+	/*07 - EMBEDDING: Inclui o embedding do documento no índice "autos_json_embedding" */
+
+	indexaEmbeddingAutos(ctx, rowAutos)
+
+	return concluiAutuacao(IdDoc, row.IdPje, autuado)
+}
+
+/*
+indexaEmbeddingAutos grava o embedding do documento autuado. A falha não desfaz a
+autuação: o documento fica sem embedding até a reindexação (POST /admin/embeddings/autos).
+*/
+func indexaEmbeddingAutos(ctx context.Context, row *consts.ResponseAutosRow) {
+	if err := AutosJsonServiceGlobal.IndexarAutos(ctx, row); err != nil {
+		logger.Log.Warningf("Documento %s autuado sem embedding: %v", row.Id, err)
+	}
+}
+
+/*
+concluiAutuacao apaga a versão anterior do documento (texto alterado na reimportação),
+com os seus embeddings, e o registro de "autos_temp". Pode ser repetida: o documento já
+está gravado em "autos".
+*/
+func concluiAutuacao(IdDoc string, idPje string, anterior *consts.ResponseAutosRow) error {
+	if anterior != nil {
+		if err := AutosServiceGlobal.DeletaAutos(anterior.Id); err != nil {
+			logger.Log.Errorf("Erro ao apagar a versão anterior do documento %s (id=%s): %v", idPje, anterior.Id, err)
+		}
+	}
+
+	/*08 - DELETA TEMP_AUTOS:  Faz a deleção do registro na tabela temp_autos  */
+
+	err := AutosTempServiceGlobal.DeletaAutos(IdDoc)
 	if err != nil {
 		logger.Log.Errorf("ERROR: Erro ao deletar registro no índice 'temp_autos'")
 		return fmt.Errorf("ERROR: Erro ao deletar registro no índice 'temp_autos'")
@@ -172,7 +225,168 @@ func ProcessarDocumento(IdContexto string, IdDoc string) error {
 
 	//msg = "Concluído com sucesso!"
 	//logger.Log.Info(msg)
-	logger.Log.Infof("\nID PJe: %s - CONCLUÍDO", row.IdPje)
+	logger.Log.Infof("\nID PJe: %s - CONCLUÍDO", idPje)
 	return nil
+}
+
+const (
+	ESPERA_INICIAL_AUTUACAO = 2 * time.Second
+	ESPERA_MAXIMA_AUTUACAO  = 30 * time.Second
+)
+
+/*
+submeteComRetentativas envia o prompt da autuação e repete o envio nas falhas
+transitórias (429/5xx/timeout), com espera exponencial (2s, 4s, 8s... até 30s), até
+AUTUACAO_MAX_TENTATIVAS. O cancelamento do contexto interrompe a espera.
+*/
+func submeteComRetentativas(ctx context.Context, IdDoc string, messages ialib.MsgGpt) (*responses.Response, error) {
+	tentativas := config.GlobalConfig.AutuacaoMaxTentativas
+	for tentativa := 1; ; tentativa++ {
+		retSubmit, err := OpenaiServiceGlobal.SubmitPromptResponse(
+			ctx,
+			messages,
+			"",
+			config.GlobalConfig.OpenOptionModel,
+			ialib.REASONING_LOW,
+			ialib.VERBOSITY_LOW)
+		if err == nil {
+			return retSubmit, nil
+		}
+		if errors.Is(err, ialib.ErrRespostaForaDoSchema) {
+			// Provedor sem saída estruturada: o JSON já passou pelos reparos do serviço (MAX_REPAROS_JSON)
+			return retSubmit, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !ialib.ErroTransitorio(err) || tentativa >= tentativas {
+			return nil, err
+		}
+
+		espera := esperaRetentativa(tentativa)
+		logger.Log.Warningf("Autuação idDoc=%s: falha transitória (tentativa %d/%d), nova tentativa em %v: %v",
+			IdDoc, tentativa, tentativas, espera, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(espera):
+		}
+	}
+}
+
+// esperaRetentativa dobra a espera a cada tentativa, com variação aleatória de até 25%
+func esperaRetentativa(tentativa int) time.Duration {
+	espera := ESPERA_INICIAL_AUTUACAO << (tentativa - 1)
+	if espera <= 0 || espera > ESPERA_MAXIMA_AUTUACAO {
+		espera = ESPERA_MAXIMA_AUTUACAO
+	}
+	return espera + time.Duration(rand.Int63n(int64(espera/4)+1))
+}
+
+// Documento a ser autuado (registro de "autos_temp")
+type ItemAutuacao struct {
+	IdContexto string
+	IdDoc      string
+}
+
+type ResultadoAutuacao struct {
+	ExtractedFiles []string
+	ExtractedErros []string
+}
+
+/*
+AutuarDocumentos executa ProcessarDocumento para cada item, com AUTUACAO_WORKERS
+documentos em paralelo, agregando os documentos autuados e as mensagens de erro. Usado
+pela rota síncrona e pelo job JOB_TIPO_AUTUACAO (que acompanha o progresso por
+InformaProgressoJob). Cancelado o contexto, os documentos ainda não iniciados não são
+processados e constam dos erros; a nova autuação dos mesmos itens é segura.
+*/
+func AutuarDocumentos(ctx context.Context, itens []ItemAutuacao) ResultadoAutuacao {
+	type resultadoProcessamento struct {
+		IdDoc string
+		Erro  error
+	}
+
+	workers := config.GlobalConfig.AutuacaoWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(itens) {
+		workers = len(itens)
+	}
+
+	filaChan := make(chan ItemAutuacao)
+	resultChan := make(chan resultadoProcessamento, len(itens))
+
+	var wg sync.WaitGroup
+
+	// Consumidor: agrega resultados enquanto os workers rodam
+	var (
+		res     ResultadoAutuacao
+		feitos  int
+		doneAgg = make(chan struct{})
+	)
+
+	go func() {
+		defer close(doneAgg)
+		for r := range resultChan {
+			if r.Erro != nil {
+				msg := fmt.Sprintf("Erro ao processar documento IdDoc=%s: %v", r.IdDoc, r.Erro)
+				logger.Log.Error(msg)
+				res.ExtractedErros = append(res.ExtractedErros, r.Erro.Error())
+			} else {
+				res.ExtractedFiles = append(res.ExtractedFiles, r.IdDoc)
+			}
+			feitos++
+			InformaProgressoJob(ctx, feitos, len(itens), fmt.Sprintf("%d de %d documentos processados", feitos, len(itens)))
+		}
+	}()
+
+	processa := func(idCtxt, idDoc string) (err error) {
+		// RECOVER para evitar derrubar o processo inteiro
+		defer func() {
+			if r := recover(); r != nil {
+				// stacktrace completo para diagnosticar o nil
+				stack := debug.Stack()
+				err = fmt.Errorf("panic em ProcessarDocumento idCtxt=%s idDoc=%s: %v", idCtxt, idDoc, r)
+				logger.Log.Errorf("%v\n%s", err, stack)
+			}
+		}()
+
+		// validação mínima
+		if idCtxt == "" || idDoc == "" {
+			return fmt.Errorf("idCtxt ou idDoc vazio (idCtxt=%q idDoc=%q)", idCtxt, idDoc)
+		}
+
+		// cancelado antes de começar: não inicia o documento
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("autuação cancelada - idDoc=%s: %w", idDoc, err)
+		}
+
+		return ProcessarDocumento(ctx, idCtxt, idDoc)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for reg := range filaChan {
+				resultChan <- resultadoProcessamento{
+					IdDoc: reg.IdDoc,
+					Erro:  processa(reg.IdContexto, reg.IdDoc),
+				}
+			}
+		}()
+	}
+
+	for _, reg := range itens {
+		filaChan <- reg
+	}
+	close(filaChan)
+
+	wg.Wait()
+	close(resultChan)
+	<-doneAgg
 
+	return res
 }
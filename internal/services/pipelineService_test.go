@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/consts"
+	"ocrserver/internal/models"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/services/rag/parsers"
+	"ocrserver/internal/utils/logger"
+)
+
+/*
+Autuação de ponta a ponta sem rede: o modelo é o provedor fake (LLM_PROVIDER_GERACAO e
+LLM_PROVIDER_EMBEDDING = fake), o OpenSearch é um servidor HTTP em memória e a tabela
+"prompts" vem de um driver database/sql mínimo.
+*/
+
+// ------------------------- OpenSearch em memória -------------------------
+
+type openSearchMemoria struct {
+	mu     sync.Mutex
+	docs   map[string]map[string]json.RawMessage // índice -> _id -> _source
+	server *httptest.Server
+}
+
+func novoOpenSearchMemoria() *openSearchMemoria {
+	srv := &openSearchMemoria{docs: map[string]map[string]json.RawMessage{}}
+	srv.server = httptest.NewServer(http.HandlerFunc(srv.atende))
+	return srv
+}
+
+func (srv *openSearchMemoria) atende(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	partes := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/":
+		io.WriteString(w, `{"name":"teste","cluster_name":"teste","version":{"distribution":"opensearch","number":"2.11.0"},"tagline":"The OpenSearch Project"}`)
+	case len(partes) == 2 && partes[1] == "_search":
+		// Nenhum documento autuado anteriormente
+		io.WriteString(w, `{"took":1,"timed_out":false,"hits":{"total":{"value":0,"relation":"eq"},"max_score":null,"hits":[]}}`)
+	case len(partes) == 3 && partes[1] == "_doc":
+		srv.documento(w, r, partes[0], partes[2])
+	default:
+		http.Error(w, `{"error":"rota não prevista no teste"}`, http.StatusNotFound)
+	}
+}
+
+func (srv *openSearchMemoria) documento(w http.ResponseWriter, r *http.Request, indice, id string) {
+	if srv.docs[indice] == nil {
+		srv.docs[indice] = map[string]json.RawMessage{}
+	}
+	resposta := map[string]any{"_index": indice, "_id": id}
+	switch r.Method {
+	case http.MethodGet:
+		src, ok := srv.docs[indice][id]
+		resposta["found"] = ok
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			resposta["_source"] = src
+		}
+	case http.MethodPut, http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		srv.docs[indice][id] = body
+		resposta["result"] = "created"
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if _, ok := srv.docs[indice][id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			resposta["result"] = "not_found"
+		} else {
+			delete(srv.docs[indice], id)
+			resposta["result"] = "deleted"
+		}
+	}
+	json.NewEncoder(w).Encode(resposta)
+}
+
+func (srv *openSearchMemoria) grava(indice, id string, doc any) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.docs[indice] == nil {
+		srv.docs[indice] = map[string]json.RawMessage{}
+	}
+	b, _ := json.Marshal(doc)
+	srv.docs[indice][id] = b
+}
+
+func (srv *openSearchMemoria) le(indice, id string) (json.RawMessage, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	src, ok := srv.docs[indice][id]
+	return src, ok
+}
+
+// ------------------------- Tabela "prompts" -------------------------
+
+// driverPrompts responde a qualquer consulta com um único prompt da natureza pedida
+type driverPrompts struct{}
+type conexaoPrompts struct{}
+type stmtPrompts struct{}
+
+type linhasPrompts struct {
+	idNat int64
+	lido  bool
+}
+
+func (driverPrompts) Open(string) (driver.Conn, error)     { return conexaoPrompts{}, nil }
+func (conexaoPrompts) Prepare(string) (driver.Stmt, error) { return stmtPrompts{}, nil }
+func (conexaoPrompts) Close() error                        { return nil }
+func (conexaoPrompts) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+func (stmtPrompts) Close() error                           { return nil }
+func (stmtPrompts) NumInput() int                          { return -1 }
+func (stmtPrompts) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+func (stmtPrompts) Query(args []driver.Value) (driver.Rows, error) {
+	l := &linhasPrompts{}
+	if len(args) > 0 {
+		l.idNat, _ = args[0].(int64)
+	}
+	return l, nil
+}
+
+func (l *linhasPrompts) Columns() []string {
+	return []string{"id_prompt", "id_nat", "id_doc", "id_classe", "id_assunto", "nm_desc", "txt_prompt", "dt_inc", "status"}
+}
+func (l *linhasPrompts) Close() error { return nil }
+func (l *linhasPrompts) Next(dest []driver.Value) error {
+	if l.lido {
+		return io.EOF
+	}
+	l.lido = true
+	copy(dest, []driver.Value{int64(1), l.idNat, int64(0), int64(0), int64(0), "teste", "Extraia os dados do documento em JSON.", time.Now(), "S"})
+	return nil
+}
+
+var onceDriverPrompts sync.Once
+
+// ------------------------- Ambiente do teste -------------------------
+
+var (
+	onceAmbienteAutuacao sync.Once
+	osAutuacao           *openSearchMemoria
+)
+
+func iniciaAmbienteAutuacao(t *testing.T) *openSearchMemoria {
+	t.Helper()
+	onceAmbienteAutuacao.Do(func() {
+		logger.InitLoggerGlobal("", false)
+		osAutuacao = novoOpenSearchMemoria()
+
+		u, _ := url.Parse(osAutuacao.server.URL)
+		config.GlobalConfig = &config.Config{
+			LLMProviderGeracao:    ialib.PROVIDER_FAKE,
+			LLMProviderEmbedding:  ialib.PROVIDER_FAKE,
+			OpenSearchHost:        u.Scheme + "://" + u.Hostname(),
+			OpenSearchPort:        u.Port(),
+			AutuacaoMaxTentativas: 1,
+			AutuacaoMaxReparos:    1,
+		}
+		if err := opensearch.InitOpenSearchService(); err != nil {
+			t.Fatalf("OpenSearch em memória: %v", err)
+		}
+
+		onceDriverPrompts.Do(func() { sql.Register("prompts_teste", driverPrompts{}) })
+		db, err := sql.Open("prompts_teste", "")
+		if err != nil {
+			t.Fatalf("driver de prompts: %v", err)
+		}
+
+		ialib.InitOpenaiFake(config.GlobalConfig)
+		InitOpenaiService("", config.GlobalConfig)
+		InitPromptService(&models.PromptModelType{Db: db})
+		InitAutos_tempService(opensearch.NewAutos_tempIndex())
+		InitAutosService(opensearch.NewAutosIndex())
+		// Sem índice "contexto": a contagem de tokens só registra o erro
+		ContextoServiceGlobal = &ContextoServiceType{}
+	})
+	if osAutuacao == nil {
+		t.Fatal("ambiente da autuação não iniciado")
+	}
+	return osAutuacao
+}
+
+func TestProcessarDocumentoComProvedorFake(t *testing.T) {
+	srv := iniciaAmbienteAutuacao(t)
+
+	casos := []struct {
+		nome   string
+		idNatu int
+		texto  string
+	}{
+		{"petição inicial", consts.NATU_DOC_INICIAL, "EXCELENTÍSSIMO SENHOR JUIZ. Fulano de Tal vem propor a presente ação de cobrança contra Banco X. Dá-se à causa o valor de R$ 10.000,00."},
+		{"sentença", consts.NATU_DOC_SENTENCA, "SENTENÇA. Vistos etc. Ante o exposto, julgo procedente o pedido. Publique-se. Registre-se. Intimem-se."},
+		{"certidão", consts.NATU_DOC_CERTIDAO, "CERTIDÃO. Certifico e dou fé que decorreu o prazo sem manifestação das partes."},
+	}
+	const idCtxt = "ctx-teste"
+
+	for i, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			idDoc := "temp-" + string(rune('a'+i))
+			srv.grava("autos_temp", idDoc, consts.AutosTempRow{
+				IdCtxt: idCtxt,
+				IdNatu: c.idNatu,
+				IdPje:  "1000" + string(rune('0'+i)),
+				Doc:    c.texto,
+			})
+
+			if err := ProcessarDocumento(context.Background(), idCtxt, idDoc); err != nil {
+				t.Fatalf("ProcessarDocumento: %v", err)
+			}
+
+			if _, ok := srv.le("autos_temp", idDoc); ok {
+				t.Errorf("documento %s continua em autos_temp", idDoc)
+			}
+			chave := ChaveAutuacao(idCtxt, idDoc, HashTexto(c.texto))
+			src, ok := srv.le("autos", chave)
+			if !ok {
+				t.Fatalf("documento não gravado em autos na chave %s", chave)
+			}
+			var row consts.AutosRow
+			if err := json.Unmarshal(src, &row); err != nil {
+				t.Fatalf("registro de autos ilegível: %v", err)
+			}
+			if erros := parsers.ValidaDocumentoJson(row.IdNatu, row.DocJsonRaw); len(erros) > 0 {
+				t.Errorf("JSON gravado fora do schema da natureza %d: %v", row.IdNatu, erros)
+			}
+		})
+	}
+}
+
+func TestProcessarDocumentoInexistente(t *testing.T) {
+	iniciaAmbienteAutuacao(t)
+
+	if err := ProcessarDocumento(context.Background(), "ctx-teste", "nao-existe"); err == nil {
+		t.Fatal("esperado erro para documento ausente de autos_temp")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+---------------------------------------------------------------------------------------
+File: procedenciaService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Abertura do arquivo original de um documento dos autos, pela procedência
+gravada na extração (chave no armazenamento e, nos ZIPs do eproc e do Projudi, o PDF
+contido). A interface abre o PDF na página inicial do documento ("#page=<pag_ini>"),
+conferindo a citação "fls./Num. X, pág. Y" das análises e minutas.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/utils/logger"
+)
+
+// ErrOriginalIndisponivel indica documento sem procedência ou arquivo já apagado do armazenamento
+var ErrOriginalIndisponivel = errors.New("arquivo original indisponível")
+
+// ArquivoOriginal é o PDF de origem do documento, aberto para leitura
+type ArquivoOriginal struct {
+	io.ReadCloser
+	Nome    string
+	Tamanho int64 // -1 se desconhecido
+	Pagina  int   // página inicial do documento no PDF
+}
+
+/*
+AbreArquivoOriginal devolve o arquivo de origem do documento. Nos ZIPs, devolve o PDF
+contido (proc.Fonte), lido de uma cópia local apagada no Close.
+*/
+func AbreArquivoOriginal(ctx context.Context, proc *consts.ProcedenciaAutos) (*ArquivoOriginal, error) {
+	if proc == nil || proc.ChaveArquivo == "" {
+		return nil, ErrOriginalIndisponivel
+	}
+	store := armazenamento.Global()
+
+	if strings.EqualFold(path.Ext(proc.ChaveArquivo), ".zip") && proc.Fonte != "" {
+		return abrePDFdoZip(ctx, store, proc)
+	}
+
+	r, err := store.Le(ctx, proc.ChaveArquivo)
+	if errors.Is(err, armazenamento.ErrNaoEncontrado) {
+		return nil, ErrOriginalIndisponivel
+	}
+	if err != nil {
+		logger.Log.Errorf("Erro ao ler o arquivo original %s: %v", proc.ChaveArquivo, err)
+		return nil, err
+	}
+	return &ArquivoOriginal{ReadCloser: r, Nome: proc.Arquivo, Tamanho: -1, Pagina: proc.PagIni}, nil
+}
+
+// leitorZip fecha, além da entrada, o ZIP e a sua cópia local
+type leitorZip struct {
+	io.ReadCloser
+	zr    *zip.ReadCloser
+	limpa func()
+}
+
+func (l *leitorZip) Close() error {
+	err := l.ReadCloser.Close()
+	l.zr.Close()
+	l.limpa()
+	return err
+}
+
+func abrePDFdoZip(ctx context.Context, store armazenamento.BlobStore, proc *consts.ProcedenciaAutos) (*ArquivoOriginal, error) {
+	caminho, limpa, err := armazenamento.CopiaLocal(ctx, store, proc.ChaveArquivo)
+	if errors.Is(err, armazenamento.ErrNaoEncontrado) {
+		return nil, ErrOriginalIndisponivel
+	}
+	if err != nil {
+		logger.Log.Errorf("Erro ao ler o arquivo original %s: %v", proc.ChaveArquivo, err)
+		return nil, err
+	}
+
+	zr, err := zip.OpenReader(caminho)
+	if err != nil {
+		limpa()
+		return nil, fmt.Errorf("erro ao abrir o ZIP %s: %w", proc.Arquivo, err)
+	}
+	for _, f := range zr.File {
+		if f.Name != proc.Fonte && path.Base(f.Name) != proc.Fonte {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			zr.Close()
+			limpa()
+			return nil, fmt.Errorf("erro ao abrir %s no ZIP: %w", proc.Fonte, err)
+		}
+		return &ArquivoOriginal{
+			ReadCloser: &leitorZip{ReadCloser: rc, zr: zr, limpa: limpa},
+			Nome:       path.Base(f.Name),
+			Tamanho:    int64(f.UncompressedSize64),
+			Pagina:     proc.PagIni,
+		}, nil
+	}
+	zr.Close()
+	limpa()
+	return nil, ErrOriginalIndisponivel
+}
@@ -0,0 +1,154 @@
+/*
+---------------------------------------------------------------------------------------
+File: quarentenaService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Validação do JSON extraído na autuação, pelo schema da natureza do
+documento (parsers.FormatoAutuacao). A resposta fora do schema volta ao modelo com os
+erros encontrados, para o reparo (até AUTUACAO_MAX_REPAROS vezes); persistindo os
+erros, o documento vai para a quarentena ("autuacoes_quarentena") e não é gravado em
+"autos".
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/models"
+	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/utils/logger"
+
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// ErrAutuacaoQuarentena indica JSON extraído fora do schema, mesmo após o reparo
+var ErrAutuacaoQuarentena = errors.New("JSON extraído fora do schema: documento em quarentena")
+
+type QuarentenaServiceType struct {
+	Model *models.QuarentenaModelType
+}
+
+var QuarentenaServiceGlobal *QuarentenaServiceType
+var onceInitQuarentenaService sync.Once
+
+func InitQuarentenaService(model *models.QuarentenaModelType) {
+	onceInitQuarentenaService.Do(func() {
+		QuarentenaServiceGlobal = &QuarentenaServiceType{Model: model}
+
+		logger.Log.Info("Global QuarentenaService configurado com sucesso.")
+	})
+}
+
+// Registra grava o documento em quarentena
+func (obj *QuarentenaServiceType) Registra(row models.QuarentenaRow) {
+	if obj == nil {
+		logger.Log.Warningf("Quarentena não iniciada: documento %s não registrado", row.IdDoc)
+		return
+	}
+	if _, err := obj.Model.InsertRow(row); err != nil {
+		logger.Log.Errorf("Erro ao registrar a quarentena do documento %s - contexto=%s: %v", row.IdDoc, row.IdCtxt, err)
+	}
+}
+
+// SelectByContexto lista os documentos em quarentena no contexto
+func (obj *QuarentenaServiceType) SelectByContexto(idCtxt string, limite int) ([]models.QuarentenaRow, error) {
+	if obj == nil {
+		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
+		return nil, fmt.Errorf("Tentativa de uso de serviço não iniciado.")
+	}
+	return obj.Model.SelectByContexto(idCtxt, limite)
+}
+
+/*
+validaRespostaAutuacao confere o JSON extraído com o schema e, fora dele, pede ao modelo
+a correção, informando os erros, até maxReparos vezes. Devolve o JSON válido ou
+ErrAutuacaoQuarentena, com o documento registrado na quarentena. Nos provedores sem
+saída estruturada, o reparo é feito por SubmitPromptResponse (MAX_REPAROS_JSON) e o
+chamador informa maxReparos = 0: uma só camada repete a chamada ao modelo.
+*/
+func validaRespostaAutuacao(
+	ctx context.Context,
+	IdContexto string,
+	IdDoc string,
+	row *consts.ResponseAutosTempRow,
+	formato *ialib.FormatoJSON,
+	messages ialib.MsgGpt,
+	rspJson string,
+	maxReparos int,
+) (string, error) {
+	errosSchema := ialib.ValidaJSON(formato.Schema, []byte(rspJson))
+
+	for reparo := 1; len(errosSchema) > 0 && reparo <= maxReparos; reparo++ {
+		logger.Log.Warningf("Autuação idDoc=%s: JSON fora do schema %s (reparo %d/%d): %s",
+			IdDoc, formato.Nome, reparo, maxReparos, strings.Join(errosSchema, "; "))
+
+		var conversa ialib.MsgGpt
+		for _, m := range messages.GetMessages() {
+			conversa.AddMessage(m)
+		}
+		conversa.CreateMessage("", ialib.ROLE_ASSISTANT, rspJson)
+		conversa.CreateMessage("", ialib.ROLE_USER, promptReparo(errosSchema))
+
+		retSubmit, err := submeteComRetentativas(ctx, IdDoc, conversa)
+		foraDoSchema := errors.Is(err, ialib.ErrRespostaForaDoSchema)
+		if err != nil && !foraDoSchema {
+			return "", err
+		}
+		ContextoServiceGlobal.UpdateTokenUso(IdContexto, int(retSubmit.Usage.InputTokens), int(retSubmit.Usage.OutputTokens))
+
+		if rspJson, err = textoRespostaAutuacao(retSubmit); err != nil {
+			return "", err
+		}
+		errosSchema = ialib.ValidaJSON(formato.Schema, []byte(rspJson))
+		if foraDoSchema {
+			break // o serviço já esgotou os próprios reparos
+		}
+	}
+
+	if len(errosSchema) == 0 {
+		return rspJson, nil
+	}
+
+	QuarentenaServiceGlobal.Registra(models.QuarentenaRow{
+		IdCtxt:   IdContexto,
+		IdDoc:    IdDoc,
+		IdNatu:   row.IdNatu,
+		IdPje:    row.IdPje,
+		Formato:  formato.Nome,
+		Resposta: rspJson,
+		Erros:    strings.Join(errosSchema, "\n"),
+		DtInc:    time.Now(),
+	})
+	resumo := errosSchema
+	if len(resumo) > 3 {
+		resumo = resumo[:3]
+	}
+	return "", fmt.Errorf("idDoc=%s: %w (%s)", IdDoc, ErrAutuacaoQuarentena, strings.Join(resumo, "; "))
+}
+
+func promptReparo(errosSchema []string) string {
+	return "O JSON acima não segue o formato exigido. Erros encontrados:\n- " +
+		strings.Join(errosSchema, "\n- ") +
+		"\n\nDevolva o JSON completo e corrigido, com todos os campos do formato exigido " +
+		"(campos sem informação ficam vazios: \"\" ou []), sem comentários e sem texto fora do JSON."
+}
+
+// textoRespostaAutuacao extrai o JSON do texto da resposta do modelo
+func textoRespostaAutuacao(retSubmit *responses.Response) (string, error) {
+	item, err := FirstMessageFromSubmit(retSubmit)
+	if err != nil {
+		return "", fmt.Errorf("resposta do modelo sem texto: %v", err)
+	}
+	rspJson, err := ExtractOutputText(item)
+	if err != nil {
+		return "", fmt.Errorf("falha ao extrair texto da resposta: %v", err)
+	}
+	return strings.Trim(rspJson, "`\""), nil
+}
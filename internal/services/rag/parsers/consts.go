@@ -252,3 +252,60 @@ type TermoAudiencia struct {
 	Descricao     string         `json:"descricao"`
 	Manifestacoes []Manifestacao `json:"manifestacoes"`
 }
+
+// -----------------------
+// m) Sentença extraída dos autos (autuação pelo prompt PROMPT_AUTUACAO_SENTENCA), lida
+// por IngestorType.StartAddSentencaBase para a inclusão na Base de Conhecimentos
+type SentencaAutos struct {
+	Tipo           *TipoDocumento     `json:"tipo"`
+	Processo       string             `json:"processo"`
+	IdPje          string             `json:"id_pje"`
+	AssinaturaData string             `json:"assinatura_data"`
+	AssinaturaPor  string             `json:"assinatura_por"`
+	Metadados      *MetadadosSentenca `json:"metadados"`
+
+	Questoes    []QuestaoSentenca   `json:"questoes"`
+	Dispositivo DispositivoSentenca `json:"dispositivo"`
+}
+
+type MetadadosSentenca struct {
+	Classe  string          `json:"classe"`
+	Assunto string          `json:"assunto"`
+	Juizo   string          `json:"juizo"`
+	Partes  *PartesSentenca `json:"partes"`
+}
+
+type PartesSentenca struct {
+	Autor []string `json:"autor,omitempty"`
+	Reu   []string `json:"reu,omitempty"`
+}
+
+type QuestaoSentenca struct {
+	Tipo       string   `json:"tipo"` // "preliminar" ou "mérito"
+	Tema       string   `json:"tema"`
+	Paragrafos []string `json:"paragrafos"`
+	Decisao    string   `json:"decisao"`
+}
+
+type DispositivoSentenca struct {
+	Paragrafos []string `json:"paragrafos"`
+}
+
+// -----------------------
+// n) Certidão (autuação pelo prompt PROMPT_AUTUACAO_CERTIDAO)
+type Certidao struct {
+	Tipo           TipoDocumento `json:"tipo"`
+	Processo       string        `json:"processo"`
+	IdPje          string        `json:"id_pje"`
+	AssinaturaData string        `json:"assinatura_data"`
+	AssinaturaPor  string        `json:"assinatura_por"`
+	Conteudo       string        `json:"conteudo"`
+}
+
+// -----------------------
+// Demais naturezas (contrato, parecer do MP, contrarrazões, outros): só a identificação
+type DocumentoGenerico struct {
+	Tipo     TipoDocumento `json:"tipo"`
+	Processo string        `json:"processo"`
+	IdPje    string        `json:"id_pje"`
+}
@@ -0,0 +1,33 @@
+package parsers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"ocrserver/internal/utils/erros"
+	"ocrserver/internal/utils/logger"
+)
+
+// formatarJsonCertidao monta texto para embedding a partir da certidão
+func formatarJsonCertidao(doc Certidao) string {
+	var sb strings.Builder
+
+	sb.WriteString(doc.Tipo.Description + ": ")
+	sb.WriteString(doc.Conteudo)
+	if doc.AssinaturaPor != "" {
+		sb.WriteString("\nAssinada por: " + doc.AssinaturaPor)
+	}
+
+	return sb.String()
+}
+
+// ParserCertidaoJson desserializa e formata JSON do tipo Certidão
+func ParserCertidaoJson(idNatu int, docJson json.RawMessage) (string, error) {
+	var doc Certidao
+	err := json.Unmarshal(docJson, &doc)
+	if err != nil {
+		logger.Log.Errorf("Erro ao realizar Unmarshal do JSON da certidão: %v", err)
+		return "", erros.CreateError("Erro ao realizar Unmarshal de JSON da certidão")
+	}
+	return formatarJsonCertidao(doc), nil
+}
@@ -10,36 +10,39 @@ import (
 	"ocrserver/internal/consts"
 )
 
+// ParserDocumentosJson devolve o texto do documento para o embedding, conforme a natureza.
+// Naturezas sem parser próprio seguem com o JSON original.
 func ParserDocumentosJson(idNatu int, docJson json.RawMessage) (string, error) {
-	jsonRaw := ""
 	switch idNatu {
-
 	case consts.NATU_DOC_INICIAL:
-		jsonRaw, _ = ParserInicialJson(idNatu, docJson)
+		return ParserInicialJson(idNatu, docJson)
 	case consts.NATU_DOC_CONTESTACAO:
-		jsonRaw, _ = ParserContestacaoJson(idNatu, docJson)
+		return ParserContestacaoJson(idNatu, docJson)
 	case consts.NATU_DOC_REPLICA:
-		jsonRaw, _ = ParserReplicaJson(idNatu, docJson)
+		return ParserReplicaJson(idNatu, docJson)
 	case consts.NATU_DOC_DESPACHO:
-		jsonRaw, _ = ParserDespachoJson(idNatu, docJson)
+		return ParserDespachoJson(idNatu, docJson)
 	case consts.NATU_DOC_PETICAO:
-		jsonRaw, _ = ParserPeticaoJson(idNatu, docJson)
+		return ParserPeticaoJson(idNatu, docJson)
 	case consts.NATU_DOC_DECISAO:
-		jsonRaw, _ = ParserDecisaoJson(idNatu, docJson)
+		return ParserDecisaoJson(idNatu, docJson)
 	case consts.NATU_DOC_SENTENCA:
-		jsonRaw, _ = ParserSentencaJson(idNatu, docJson)
+		return ParserSentencaJson(idNatu, docJson)
 	case consts.NATU_DOC_EMBARGOS:
-		jsonRaw, _ = ParserEmbargosDeclaracaoJson(idNatu, docJson)
+		return ParserEmbargosDeclaracaoJson(idNatu, docJson)
 	case consts.NATU_DOC_APELACAO:
-		jsonRaw, _ = ParserApelacaoJson(idNatu, docJson)
+		return ParserApelacaoJson(idNatu, docJson)
 	case consts.NATU_DOC_PROCURACAO:
-		jsonRaw, _ = ParserProcuracaoJson(idNatu, docJson)
+		return ParserProcuracaoJson(idNatu, docJson)
 	case consts.NATU_DOC_ROL_TESTEMUNHAS:
-		jsonRaw, _ = ParserRolTestemunhasJson(idNatu, docJson)
+		return ParserRolTestemunhasJson(idNatu, docJson)
 	case consts.NATU_DOC_LAUDO_PERICIAL:
-		jsonRaw, _ = ParserLaudoPericialJson(idNatu, docJson)
+		return ParserLaudoPericialJson(idNatu, docJson)
+	case consts.NATU_DOC_TERMO_AUDIENCIA:
+		return ParserTermoAudienciaJson(idNatu, docJson)
+	case consts.NATU_DOC_CERTIDAO:
+		return ParserCertidaoJson(idNatu, docJson)
 	default:
-		jsonRaw = string(docJson)
+		return string(docJson), nil
 	}
-	return jsonRaw, nil
 }
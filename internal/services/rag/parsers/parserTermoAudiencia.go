@@ -0,0 +1,45 @@
+package parsers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"ocrserver/internal/utils/erros"
+	"ocrserver/internal/utils/logger"
+)
+
+// formatarJsonTermoAudiencia monta texto para embedding a partir do termo de audiência
+func formatarJsonTermoAudiencia(doc TermoAudiencia) string {
+	var sb strings.Builder
+
+	sb.WriteString(doc.Tipo.Description + ": ")
+	sb.WriteString("Data: " + doc.Data + " " + doc.Hora + "; ")
+
+	// Presentes
+	if len(doc.Presentes) > 0 {
+		sb.WriteString("Presentes: ")
+		for _, p := range doc.Presentes {
+			sb.WriteString(p.Nome + "; ")
+		}
+	}
+
+	sb.WriteString("\n" + doc.Descricao)
+
+	// Manifestações
+	for _, m := range doc.Manifestacoes {
+		sb.WriteString("\n" + m.Nome + ": " + m.Manifestacao)
+	}
+
+	return sb.String()
+}
+
+// ParserTermoAudienciaJson desserializa e formata JSON do tipo Termo de Audiência
+func ParserTermoAudienciaJson(idNatu int, docJson json.RawMessage) (string, error) {
+	var doc TermoAudiencia
+	err := json.Unmarshal(docJson, &doc)
+	if err != nil {
+		logger.Log.Errorf("Erro ao realizar Unmarshal do JSON do termo de audiência: %v", err)
+		return "", erros.CreateError("Erro ao realizar Unmarshal de JSON do termo de audiência")
+	}
+	return formatarJsonTermoAudiencia(doc), nil
+}
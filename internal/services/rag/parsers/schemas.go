@@ -0,0 +1,66 @@
+/*
+File: schemas.go
+Schemas (JSON Schema) do JSON extraído de cada documento na autuação, gerados dos tipos
+de consts.go. O schema segue para o modelo como saída estruturada e confere a resposta
+antes da gravação em "autos", evitando JSON malformado nos parsers e na ingestão.
+Data: 18-10-2026
+*/
+package parsers
+
+import (
+	"reflect"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/services/ialib"
+)
+
+// Tipo do JSON extraído, por natureza do documento
+var tiposAutuacao = map[int]any{
+	consts.NATU_DOC_INICIAL:         PeticaoInicial{},
+	consts.NATU_DOC_CONTESTACAO:     Contestacao{},
+	consts.NATU_DOC_REPLICA:         Replica{},
+	consts.NATU_DOC_DESPACHO:        Despacho{},
+	consts.NATU_DOC_PETICAO:         PeticaoDiversa{},
+	consts.NATU_DOC_DECISAO:         DecisaoInterlocutoria{},
+	consts.NATU_DOC_SENTENCA:        SentencaAutos{}, // o prompt da autuação de sentenças extrai as questões e o dispositivo
+	consts.NATU_DOC_EMBARGOS:        EmbargosDeclaracao{},
+	consts.NATU_DOC_APELACAO:        RecursoApelacao{},
+	consts.NATU_DOC_PROCURACAO:      Procuracao{},
+	consts.NATU_DOC_ROL_TESTEMUNHAS: RolTestemunhas{},
+	consts.NATU_DOC_LAUDO_PERICIAL:  LaudoPericial{},
+	consts.NATU_DOC_TERMO_AUDIENCIA: TermoAudiencia{},
+	consts.NATU_DOC_CERTIDAO:        Certidao{},
+}
+
+var (
+	formatosAutuacao = map[int]*ialib.FormatoJSON{}
+	formatoGenerico  *ialib.FormatoJSON
+)
+
+func init() {
+	for natu, tipo := range tiposAutuacao {
+		formatosAutuacao[natu] = &ialib.FormatoJSON{
+			Nome:    "autuacao_" + reflect.TypeOf(tipo).Name(),
+			Schema:  ialib.SchemaDoTipo(tipo),
+			Estrito: true,
+		}
+	}
+
+	// Naturezas sem tipo próprio: exige a identificação e admite os demais campos
+	schema := ialib.SchemaDoTipo(DocumentoGenerico{})
+	schema["additionalProperties"] = true
+	formatoGenerico = &ialib.FormatoJSON{Nome: "autuacao_DocumentoGenerico", Schema: schema}
+}
+
+// FormatoAutuacao devolve o formato do JSON extraído do documento da natureza informada
+func FormatoAutuacao(idNatu int) *ialib.FormatoJSON {
+	if f, ok := formatosAutuacao[idNatu]; ok {
+		return f
+	}
+	return formatoGenerico
+}
+
+// ValidaDocumentoJson confere o JSON extraído com o schema da natureza; lista vazia: válido
+func ValidaDocumentoJson(idNatu int, docJson string) []string {
+	return ialib.ValidaJSON(FormatoAutuacao(idNatu).Schema, []byte(docJson))
+}
@@ -0,0 +1,436 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/utils/logger"
+)
+
+/*
+Validação das citações das minutas. Depois da geração, cada acórdão, processo e súmula
+citado é procurado nos trechos da base de conhecimentos efetivamente enviados ao modelo,
+nos autos e no índice "modelos". A citação é:
+  - verificada: o número aparece numa das fontes, atribuído ao mesmo tribunal (ou sem
+    tribunal próximo que o contradiga);
+  - contradita: o número só aparece atribuído a outro tribunal, ou é um número CNJ com
+    dígito verificador inválido (número inventado);
+  - nao_verificada: não foi localizado em nenhuma fonte.
+A conferência é textual e não chama o modelo.
+*/
+
+const (
+	CITACAO_VERIFICADA     = "verificada"
+	CITACAO_NAO_VERIFICADA = "nao_verificada"
+	CITACAO_CONTRADITA     = "contradita"
+)
+
+const (
+	FONTE_CITACAO_BASE    = "base_conhecimentos"
+	FONTE_CITACAO_AUTOS   = "autos"
+	FONTE_CITACAO_MODELOS = "modelos"
+)
+
+// Máximo de citações conferidas no índice "modelos" por minuta
+const MAX_CITACOES_CONSULTA_MODELOS = 30
+
+// Distância (em bytes) da ocorrência do número em que se procura o tribunal
+const JANELA_TRIBUNAL_CITACAO = 160
+
+var (
+	reTribunal = regexp.MustCompile(`\b(STF|STJ|TST|TSE|STM|TNU|TJDFT|TRF[ \-/]?[1-6]|TRT[ \-/]?\d{1,2}|TJ[ \-/]?[A-Z]{2})\b`)
+	reSumula   = regexp.MustCompile(`(?i)súmula\s+(vinculante\s+)?(?:n[º°o]?\.?\s*)?(\d{1,4})`)
+	reCNJ      = regexp.MustCompile(`\b\d{7}-\d{2}\.\d{4}\.\d\.\d{2}\.\d{4}\b`)
+	reRecurso  = regexp.MustCompile(`\b(?:(?:AgInt|AgRg|EDcl)\s+n[oa]s?\s+)?(REsp|AREsp|EREsp|RE|ARE|AI|HC|RHC|MS|RMS|ADI|ADC|ADO|ADPF|Rcl|CC)\s+(?:n[º°o]?\.?\s*)?(\d{1,3}(?:\.\d{3})+|\d{3,})`)
+	reNumero   = regexp.MustCompile(`\d[\d.\-]*\d`)
+)
+
+// Tribunal presumido pela classe do recurso ou ação, quando a citação não o informa
+var tribunalPorClasse = map[string]string{
+	"REsp": "STJ", "AREsp": "STJ", "EREsp": "STJ", "RMS": "STJ", "CC": "STJ",
+	"RE": "STF", "ARE": "STF", "ADI": "STF", "ADC": "STF", "ADO": "STF", "ADPF": "STF",
+}
+
+type citacao struct {
+	CitacaoVerificada
+	numero string // somente os dígitos da referência
+}
+
+type fonteCitacao struct {
+	nome  string
+	texto string
+}
+
+func somenteDigitos(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func normalizaTribunal(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "", "-", "", "/", "", ".", "").Replace(s)
+}
+
+// tribunalApos devolve o primeiro tribunal mencionado logo após a posição informada.
+func tribunalApos(texto string, pos int) string {
+	fim := min(len(texto), pos+40)
+	if m := reTribunal.FindString(texto[pos:fim]); m != "" {
+		return normalizaTribunal(m)
+	}
+	return ""
+}
+
+// tribunalSumula devolve o tribunal da súmula localizada por reSumula (vinculante: STF).
+func tribunalSumula(texto string, loc []int) string {
+	if loc[2] >= 0 {
+		return "STF"
+	}
+	return tribunalApos(texto, loc[1])
+}
+
+// tribunalProximo devolve o tribunal mencionado mais perto do trecho [ini, fim).
+func tribunalProximo(texto string, ini, fim int) string {
+	base := max(0, ini-JANELA_TRIBUNAL_CITACAO)
+	janela := texto[base:min(len(texto), fim+JANELA_TRIBUNAL_CITACAO)]
+	tribunal, menor := "", -1
+	for _, loc := range reTribunal.FindAllStringIndex(janela, -1) {
+		dist := max(ini-(base+loc[1]), base+loc[0]-fim, 0)
+		if menor < 0 || dist < menor {
+			tribunal, menor = normalizaTribunal(janela[loc[0]:loc[1]]), dist
+		}
+	}
+	return tribunal
+}
+
+// dvCNJValido confere o dígito verificador do número único (Resolução CNJ 65/2008).
+func dvCNJValido(numero string) bool {
+	if len(numero) != 20 {
+		return true
+	}
+	resto := 0
+	for _, r := range numero[0:7] + numero[9:] + "00" {
+		resto = (resto*10 + int(r-'0')) % 97
+	}
+	return fmt.Sprintf("%02d", 98-resto) == numero[7:9]
+}
+
+// ============================================================
+// Extração
+// ============================================================
+
+type coletorCitacoes struct {
+	itens  []citacao
+	vistos map[string]bool
+}
+
+func (c *coletorCitacoes) add(tipo, tribunal, referencia, origem string) {
+	referencia = strings.TrimSpace(referencia)
+	if referencia == "" {
+		return
+	}
+	ct := citacao{
+		CitacaoVerificada: CitacaoVerificada{
+			Tipo:       tipo,
+			Tribunal:   normalizaTribunal(tribunal),
+			Referencia: referencia,
+			Origem:     origem,
+			Situacao:   CITACAO_NAO_VERIFICADA,
+		},
+		numero: somenteDigitos(referencia),
+	}
+	if tipo == "sumula" {
+		if m := reSumula.FindStringSubmatch(referencia); m != nil {
+			ct.numero = m[2]
+		}
+	}
+
+	chave := ct.Tipo + "|" + ct.Tribunal + "|" + ct.numero
+	if ct.numero == "" {
+		chave += "|" + strings.ToLower(referencia)
+	}
+	if c.vistos == nil {
+		c.vistos = map[string]bool{}
+	}
+	if c.vistos[chave] {
+		return
+	}
+	c.vistos[chave] = true
+	c.itens = append(c.itens, ct)
+}
+
+// sumula registra uma súmula citada num campo próprio (ex.: "Súmula 385 do STJ").
+func (c *coletorCitacoes) sumula(s, origem string) {
+	loc := reSumula.FindStringSubmatchIndex(s)
+	if loc == nil {
+		c.add("sumula", "", s, origem)
+		return
+	}
+	c.add("sumula", tribunalSumula(s, loc), s[loc[0]:loc[1]], origem)
+}
+
+// texto procura citações em texto livre (fundamentação, mérito etc.).
+func (c *coletorCitacoes) texto(s, origem string) {
+	for _, loc := range reSumula.FindAllStringSubmatchIndex(s, -1) {
+		c.add("sumula", tribunalSumula(s, loc), s[loc[0]:loc[1]], origem)
+	}
+	for _, loc := range reRecurso.FindAllStringSubmatchIndex(s, -1) {
+		classe := s[loc[2]:loc[3]]
+		tribunal := tribunalPorClasse[classe]
+		if tribunal == "" {
+			tribunal = tribunalApos(s, loc[1])
+		}
+		c.add("acordao", tribunal, s[loc[0]:loc[1]], origem)
+	}
+	for _, loc := range reCNJ.FindAllStringIndex(s, -1) {
+		c.add("processo", tribunalApos(s, loc[1]), s[loc[0]:loc[1]], origem)
+	}
+}
+
+func (c *coletorCitacoes) textos(lista []string, origem string) {
+	for _, s := range lista {
+		c.texto(s, origem)
+	}
+}
+
+func (c *coletorCitacoes) fundamentacao(f *Fundamentacao) {
+	if f == nil {
+		return
+	}
+	c.textos(f.Preliminares, "fundamentacao.preliminares")
+	c.textos(f.Merito, "fundamentacao.merito")
+	c.textos(f.Doutrina, "fundamentacao.doutrina")
+	if f.Jurisprudencia == nil {
+		return
+	}
+	for _, s := range f.Jurisprudencia.Sumulas {
+		c.sumula(s, "fundamentacao.jurisprudencia.sumulas")
+	}
+	for _, a := range f.Jurisprudencia.Acordaos {
+		if a.Processo == nil {
+			continue
+		}
+		tribunal := ""
+		if a.Tribunal != nil {
+			tribunal = *a.Tribunal
+		}
+		c.add("acordao", tribunal, *a.Processo, "fundamentacao.jurisprudencia.acordaos")
+	}
+}
+
+func citacoesSentenca(m *MinutaSentenca) []citacao {
+	var c coletorCitacoes
+	c.fundamentacao(m.Fundamentacao)
+	return c.itens
+}
+
+func citacoesDecisao(m *MinutaDecisao) []citacao {
+	var c coletorCitacoes
+	c.fundamentacao(m.Fundamentacao)
+	return c.itens
+}
+
+func citacoesAnalise(a *AnaliseJuridicaIA) []citacao {
+	var c coletorCitacoes
+	c.textos(a.FundamentacaoJuridica.Autor, "fundamentacao_juridica.autor")
+	c.textos(a.FundamentacaoJuridica.Reu, "fundamentacao_juridica.reu")
+	for _, j := range a.FundamentacaoJuridica.Jurisprudencia {
+		c.add("acordao", j.Tribunal, j.Processo, "fundamentacao_juridica.jurisprudencia")
+	}
+	return c.itens
+}
+
+// ============================================================
+// Conferência
+// ============================================================
+
+/*
+localizaNoTexto procura o número da citação no texto. Devolve se foi encontrado e, nesse
+caso, se o tribunal confere; quando não confere, informa o tribunal encontrado. Fora das
+súmulas, só vale o número citado como recurso ou ação (classe imediatamente antes do
+número, como em reRecurso) ou como número único do CNJ: o mesmo número solto no texto
+(valores, datas, folhas) não localiza a citação.
+*/
+func localizaNoTexto(c *citacao, texto string) (achou bool, confere bool, outro string) {
+	if c.Tipo == "sumula" {
+		for _, loc := range reSumula.FindAllStringSubmatchIndex(texto, -1) {
+			if texto[loc[4]:loc[5]] != c.numero {
+				continue
+			}
+			achou = true
+			tribunal := tribunalSumula(texto, loc)
+			if c.Tribunal == "" || tribunal == "" || tribunal == c.Tribunal {
+				return true, true, ""
+			}
+			outro = tribunal
+		}
+		return achou, false, outro
+	}
+	if c.numero == "" {
+		return false, false, ""
+	}
+
+	// Classe da citação (REsp, HC...): a da fonte deve ser a mesma
+	classe := ""
+	if m := reRecurso.FindStringSubmatch(c.Referencia); m != nil {
+		classe = m[1]
+	}
+
+	confereTribunal := func(tribunal string) bool {
+		if c.Tribunal == "" || tribunal == "" || tribunal == c.Tribunal {
+			return true
+		}
+		outro = tribunal
+		return false
+	}
+
+	for _, loc := range reRecurso.FindAllStringSubmatchIndex(texto, -1) {
+		classeFonte := texto[loc[2]:loc[3]]
+		if (classe != "" && classeFonte != classe) || somenteDigitos(texto[loc[4]:loc[5]]) != c.numero {
+			continue
+		}
+		achou = true
+		tribunal := tribunalPorClasse[classeFonte]
+		if tribunal == "" {
+			tribunal = tribunalProximo(texto, loc[0], loc[1])
+		}
+		if confereTribunal(tribunal) {
+			return true, true, ""
+		}
+	}
+	for _, loc := range reCNJ.FindAllStringIndex(texto, -1) {
+		if somenteDigitos(texto[loc[0]:loc[1]]) != c.numero {
+			continue
+		}
+		achou = true
+		if confereTribunal(tribunalProximo(texto, loc[0], loc[1])) {
+			return true, true, ""
+		}
+	}
+	return achou, false, outro
+}
+
+// confere procura a citação nas fontes e registra a situação. Devolve true se concluída.
+func (c *citacao) confere(fontes []fonteCitacao) bool {
+	for _, f := range fontes {
+		achou, confere, outro := localizaNoTexto(c, f.texto)
+		if !achou {
+			continue
+		}
+		if confere {
+			c.Situacao = CITACAO_VERIFICADA
+			c.Fonte = f.nome
+			c.Observacao = ""
+			return true
+		}
+		if c.Observacao == "" {
+			c.Situacao = CITACAO_CONTRADITA
+			c.Fonte = f.nome
+			c.Observacao = fmt.Sprintf("número localizado em %s atribuído a %s, não a %s", f.nome, outro, c.Tribunal)
+		}
+	}
+	return false
+}
+
+// variantesConsulta devolve as formas do número usadas na busca no índice "modelos".
+func (c *citacao) variantesConsulta() []string {
+	if c.Tipo == "sumula" {
+		return []string{"súmula " + c.numero}
+	}
+	variantes := []string{c.numero}
+	if len(c.numero) > 3 && len(c.numero) <= 9 {
+		// 1234567 -> 1.234.567
+		var partes []string
+		n := c.numero
+		for len(n) > 3 {
+			partes = append([]string{n[len(n)-3:]}, partes...)
+			n = n[:len(n)-3]
+		}
+		variantes = append(variantes, strings.Join(append([]string{n}, partes...), "."))
+	}
+	if m := reNumero.FindString(c.Referencia); m != "" && m != c.numero {
+		variantes = append(variantes, m)
+	}
+	return variantes
+}
+
+/*
+validaCitacoes confere as citações extraídas da minuta com os trechos da base de
+conhecimentos enviados ao modelo, os autos e o índice "modelos".
+*/
+func validaCitacoes(
+	ctx context.Context,
+	idCtxt string,
+	citacoes []citacao,
+	autos []consts.ResponseAutosRow,
+	ragBase []opensearch.ResponseBaseRow,
+) *ValidacaoCitacoes {
+
+	fontes := make([]fonteCitacao, 0, len(ragBase)+len(autos))
+	for _, doc := range ragBase {
+		fontes = append(fontes, fonteCitacao{nome: FONTE_CITACAO_BASE, texto: doc.Fonte + "\n" + doc.Texto})
+	}
+	for _, doc := range autos {
+		fontes = append(fontes, fonteCitacao{nome: FONTE_CITACAO_AUTOS, texto: doc.Doc})
+	}
+
+	consultas := 0
+	for i := range citacoes {
+		c := &citacoes[i]
+		if c.numero == "" || c.confere(fontes) {
+			continue
+		}
+
+		if consultas < MAX_CITACOES_CONSULTA_MODELOS && ctx.Err() == nil {
+			consultas++
+			modelos, err := opensearch.ModelosServiceGlobal.ConsultaCitacao(c.variantesConsulta(), 5)
+			if err != nil {
+				logger.Log.Warningf("[id_ctxt=%s] Erro ao consultar citação %q nos modelos: %v", idCtxt, c.Referencia, err)
+			}
+			fontesModelos := make([]fonteCitacao, 0, len(modelos))
+			for _, m := range modelos {
+				fontesModelos = append(fontesModelos, fonteCitacao{nome: FONTE_CITACAO_MODELOS, texto: m.Ementa + "\n" + m.Inteiro_teor})
+			}
+			if c.confere(fontesModelos) {
+				continue
+			}
+		}
+
+		if c.Situacao == CITACAO_NAO_VERIFICADA && !dvCNJValido(c.numero) {
+			c.Situacao = CITACAO_CONTRADITA
+			c.Observacao = "número CNJ com dígito verificador inválido"
+		}
+	}
+
+	out := &ValidacaoCitacoes{
+		Citacoes:      make([]CitacaoVerificada, 0, len(citacoes)),
+		DataValidacao: time.Now().Format("02/01/2006 15:04:05"),
+	}
+	for _, c := range citacoes {
+		switch c.Situacao {
+		case CITACAO_VERIFICADA:
+			out.Verificadas++
+		case CITACAO_CONTRADITA:
+			out.Contraditas++
+		default:
+			out.NaoVerificadas++
+		}
+		out.Citacoes = append(out.Citacoes, c.CitacaoVerificada)
+	}
+
+	logger.Log.Infof("[id_ctxt=%s] Citações conferidas: %d (verificadas=%d, não verificadas=%d, contraditas=%d)",
+		idCtxt, len(citacoes), out.Verificadas, out.NaoVerificadas, out.Contraditas)
+	notificaProgresso(ctx, ETAPA_CITACOES, "Citações conferidas", map[string]any{
+		"verificadas": out.Verificadas, "nao_verificadas": out.NaoVerificadas, "contraditas": out.Contraditas,
+	})
+	return out
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import "testing"
+
+func TestLocalizaNoTexto(t *testing.T) {
+	casos := []struct {
+		nome       string
+		tipo       string
+		tribunal   string
+		referencia string
+		texto      string
+		achou      bool
+		confere    bool
+		outro      string
+	}{
+		{
+			nome: "recurso com a mesma classe e número", tipo: "acordao", tribunal: "STJ", referencia: "REsp 1.234.567",
+			texto: "Nesse sentido: AgInt no REsp 1.234.567/SP, Rel. Min. Fulano.", achou: true, confere: true,
+		},
+		{
+			nome: "número do recurso em valor monetário", tipo: "acordao", tribunal: "STJ", referencia: "REsp 5.000",
+			texto: "Condeno o réu ao pagamento de R$ 5.000,00.",
+		},
+		{
+			nome: "mesmo número com outra classe", tipo: "acordao", tribunal: "STJ", referencia: "REsp 5.000",
+			texto: "Conforme o RE 5.000, de relatoria do Min. Beltrano.",
+		},
+		{
+			nome: "número solto em folhas e datas", tipo: "acordao", tribunal: "", referencia: "HC 123456",
+			texto: "Vide fls. 123456 e o documento de 12/34/56.",
+		},
+		{
+			nome: "classe presume o tribunal divergente", tipo: "acordao", tribunal: "TJSP", referencia: "REsp 1.234.567",
+			texto: "REsp 1.234.567", achou: true, outro: "STJ",
+		},
+		{
+			nome: "classe sem tribunal presumido usa o tribunal próximo", tipo: "acordao", tribunal: "STJ", referencia: "HC 98.765",
+			texto: "O STF, no HC 98.765, concedeu a ordem.", achou: true, outro: "STF",
+		},
+		{
+			nome: "número CNJ no texto", tipo: "processo", tribunal: "TJMG", referencia: "0001234-56.2020.8.13.0024",
+			texto: "Apelação Cível 0001234-56.2020.8.13.0024 (TJMG).", achou: true, confere: true,
+		},
+		{
+			nome: "dígitos do CNJ fora do formato", tipo: "processo", tribunal: "", referencia: "0001234-56.2020.8.13.0024",
+			texto: "Protocolo 00012345620208130024.",
+		},
+		{
+			nome: "súmula do mesmo tribunal", tipo: "sumula", tribunal: "STJ", referencia: "Súmula 385",
+			texto: "Aplica-se a Súmula nº 385 do STJ.", achou: true, confere: true,
+		},
+		{
+			nome: "súmula de outro tribunal", tipo: "sumula", tribunal: "STJ", referencia: "Súmula 385",
+			texto: "Súmula 385 do TST.", achou: true, outro: "TST",
+		},
+		{
+			nome: "súmula vinculante é do STF", tipo: "sumula", tribunal: "STF", referencia: "Súmula 13",
+			texto: "Nos termos da Súmula Vinculante 13.", achou: true, confere: true,
+		},
+		{
+			nome: "súmula com outro número", tipo: "sumula", tribunal: "STJ", referencia: "Súmula 385",
+			texto: "Súmula 38 do STJ.",
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var col coletorCitacoes
+			col.add(c.tipo, c.tribunal, c.referencia, "teste")
+			if len(col.itens) != 1 {
+				t.Fatalf("citação %q não registrada", c.referencia)
+			}
+
+			achou, confere, outro := localizaNoTexto(&col.itens[0], c.texto)
+			if achou != c.achou || confere != c.confere || outro != c.outro {
+				t.Errorf("localizaNoTexto = (%v, %v, %q), esperado (%v, %v, %q)", achou, confere, outro, c.achou, c.confere, c.outro)
+			}
+		})
+	}
+}
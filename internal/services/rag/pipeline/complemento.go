@@ -0,0 +1,282 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/services"
+	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/utils/erros"
+	"ocrserver/internal/utils/logger"
+
+	"github.com/openai/openai-go/v3/responses"
+)
+
+/*
+Complementação das questões controvertidas. A análise jurídica traz, para cada questão
+controvertida, uma pergunta dirigida ao usuário; as respostas ficam num único evento
+(NATU_DOC_IA_COMPLEMENTO) por análise, atualizado a cada complementação. A verificação
+que antecede a minuta de sentença lê esse registro e grava nele os faltantes apontados.
+*/
+
+// novasRespostasQuestoes cria o registro de respostas com as perguntas da análise jurídica.
+func novasRespostasQuestoes(idAnalise string, analise AnaliseJuridicaIA) *RespostasQuestoes {
+	obj := &RespostasQuestoes{
+		Tipo:      &TipoEvento{Evento: EVENTO_COMPLEMENTO, Descricao: "respostas às questões controvertidas"},
+		IdAnalise: idAnalise,
+		Questoes:  []RespostaQuestao{},
+	}
+	obj.mesclaAnalise(analise)
+	return obj
+}
+
+func normalizaPergunta(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func (r *RespostasQuestoes) indicePergunta(pergunta string) int {
+	alvo := normalizaPergunta(pergunta)
+	for i, q := range r.Questoes {
+		if normalizaPergunta(q.Pergunta) == alvo {
+			return i
+		}
+	}
+	return -1
+}
+
+// mesclaAnalise inclui as perguntas da análise que ainda não constam do registro.
+func (r *RespostasQuestoes) mesclaAnalise(analise AnaliseJuridicaIA) {
+	for _, q := range analise.QuestoesControvertidas {
+		if strings.TrimSpace(q.PerguntaAoUsuario) == "" || r.indicePergunta(q.PerguntaAoUsuario) >= 0 {
+			continue
+		}
+		r.Questoes = append(r.Questoes, RespostaQuestao{
+			Descricao: q.Descricao,
+			Pergunta:  strings.TrimSpace(q.PerguntaAoUsuario),
+		})
+	}
+}
+
+// Perguntas incluídas a partir dos faltantes da verificação, no máximo MAX_QUESTOES_VERIFICACAO
+// de cada vez; os faltantes excedentes continuam pendentes e entram nas rodadas seguintes
+const (
+	DESCRICAO_QUESTAO_VERIFICACAO = "apontada na verificação que antecede a sentença"
+	MAX_QUESTOES_VERIFICACAO      = 3
+)
+
+// Proporção mínima de palavras em comum para o faltante equivaler a uma pergunta existente
+const SIMILARIDADE_PERGUNTA = 0.5
+
+// palavrasPergunta devolve as palavras significativas (mais de 3 letras) da pergunta
+func palavrasPergunta(s string) map[string]bool {
+	palavras := map[string]bool{}
+	for _, p := range strings.FieldsFunc(normalizaPergunta(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(p) > 3 {
+			palavras[p] = true
+		}
+	}
+	return palavras
+}
+
+/*
+perguntaSemelhante devolve o índice da pergunta equivalente ao faltante: a mesma, após a
+normalização, ou com ao menos SIMILARIDADE_PERGUNTA das palavras do menor dos dois
+textos em comum. O modelo costuma reformular o mesmo faltante a cada verificação.
+*/
+func (r *RespostasQuestoes) perguntaSemelhante(faltante string) int {
+	if i := r.indicePergunta(faltante); i >= 0 {
+		return i
+	}
+	alvo := palavrasPergunta(faltante)
+	if len(alvo) == 0 {
+		return -1
+	}
+	for i, q := range r.Questoes {
+		palavras := palavrasPergunta(q.Pergunta)
+		if len(palavras) == 0 {
+			continue
+		}
+		comuns := 0
+		for p := range alvo {
+			if palavras[p] {
+				comuns++
+			}
+		}
+		if float64(comuns) >= SIMILARIDADE_PERGUNTA*float64(min(len(alvo), len(palavras))) {
+			return i
+		}
+	}
+	return -1
+}
+
+// registraPendentes guarda os faltantes apontados pela verificação e inclui as novas perguntas.
+func (r *RespostasQuestoes) registraPendentes(faltantes []string) {
+	r.Pendentes = nil
+	for _, f := range faltantes {
+		if f = strings.TrimSpace(f); f != "" {
+			r.Pendentes = append(r.Pendentes, f)
+		}
+	}
+	r.incluiPendentes()
+}
+
+/*
+incluiPendentes transforma em novas perguntas os faltantes sem pergunta equivalente no
+registro (ex.: honorários advocatícios), para que possam ser respondidos, no máximo
+MAX_QUESTOES_VERIFICACAO por vez. Devolve quantas perguntas foram incluídas.
+*/
+func (r *RespostasQuestoes) incluiPendentes() int {
+	incluidas := 0
+	for _, f := range r.pendentesSemPergunta() {
+		if incluidas >= MAX_QUESTOES_VERIFICACAO {
+			break
+		}
+		r.Questoes = append(r.Questoes, RespostaQuestao{Descricao: DESCRICAO_QUESTAO_VERIFICACAO, Pergunta: f})
+		incluidas++
+	}
+	return incluidas
+}
+
+// pendentesSemPergunta devolve os faltantes que ainda não equivalem a nenhuma pergunta.
+func (r *RespostasQuestoes) pendentesSemPergunta() []string {
+	var out []string
+	for _, f := range r.Pendentes {
+		if r.perguntaSemelhante(f) < 0 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+/*
+Respondidas informa se há perguntas no registro, todas respondidas, e se nenhum faltante da
+última verificação ficou sem pergunta (excedentes de MAX_QUESTOES_VERIFICACAO).
+*/
+func (r *RespostasQuestoes) Respondidas() bool {
+	return r != nil && len(r.Questoes) > 0 && len(r.SemResposta()) == 0 && len(r.pendentesSemPergunta()) == 0
+}
+
+// aplicaRespostas grava as respostas extraídas e devolve quantas foram aplicadas.
+func (r *RespostasQuestoes) aplicaRespostas(extraidas []RespostaExtraida) int {
+	n := 0
+	for _, e := range extraidas {
+		resp := strings.TrimSpace(e.Resposta)
+		if e.Indice < 1 || e.Indice > len(r.Questoes) || resp == "" {
+			continue
+		}
+		r.Questoes[e.Indice-1].Resposta = resp
+		n++
+	}
+	return n
+}
+
+// SemResposta devolve as perguntas ainda não respondidas.
+func (r *RespostasQuestoes) SemResposta() []string {
+	faltantes := []string{}
+	for _, q := range r.Questoes {
+		if strings.TrimSpace(q.Resposta) == "" {
+			faltantes = append(faltantes, q.Pergunta)
+		}
+	}
+	return faltantes
+}
+
+// textoQuestoes lista as perguntas numeradas, com as respostas já registradas.
+func (r *RespostasQuestoes) textoQuestoes() string {
+	var sb strings.Builder
+	sb.WriteString("QUESTÕES CONTROVERTIDAS E RESPOSTAS:\n")
+	for i, q := range r.Questoes {
+		resp := strings.TrimSpace(q.Resposta)
+		if resp == "" {
+			resp = "(sem resposta)"
+		}
+		fmt.Fprintf(&sb, "\n%d) Pergunta: %s\n   Resposta do usuário: %s\n", i+1, q.Pergunta, resp)
+	}
+	return sb.String()
+}
+
+/*
+salvarRespostasQuestoes grava o registro de respostas: atualiza o evento existente ou,
+na primeira complementação, insere um novo evento vinculado à análise.
+*/
+func (service *OrquestradorType) salvarRespostasQuestoes(
+	ctx context.Context,
+	idCtxt string,
+	row *opensearch.ResponseEventosRow,
+	obj *RespostasQuestoes,
+	userName string,
+) (*opensearch.ResponseEventosRow, error) {
+
+	obj.DataAtualizacao = time.Now().Format("02/01/2006 15:04:05")
+	docJson, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		logger.Log.Errorf("Erro ao serializar respostas às questões: %v", err)
+		return nil, erros.CreateError("Erro ao serializar respostas às questões: %s", err.Error())
+	}
+	doc := obj.textoQuestoes()
+
+	if row == nil {
+		row, err = services.EventosServiceGlobal.InserirEvento(idCtxt, consts.NATU_DOC_IA_COMPLEMENTO, "", doc, string(docJson), userName)
+	} else {
+		atual := *row
+		atual.Doc = doc
+		atual.DocJsonRaw = string(docJson)
+		row, err = services.EventosServiceGlobal.UpdateEvento(atual)
+	}
+	if err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao gravar respostas às questões: %v", idCtxt, err)
+		return nil, erros.CreateError("Erro ao gravar respostas às questões: %s", err.Error())
+	}
+
+	notificaProgresso(ctx, ETAPA_EVENTO_SALVO, "Respostas às questões registradas",
+		map[string]any{"id": row.Id, "id_natu": consts.NATU_DOC_IA_COMPLEMENTO})
+	return row, nil
+}
+
+/*
+createOutPutRespostasRegistradas informa ao cliente que todas as perguntas foram respondi-
+das. A minuta de sentença não é gerada aqui: o usuário a solicita quando quiser.
+*/
+func createOutPutRespostasRegistradas() ([]responses.ResponseOutputItemUnion, error) {
+	obj := ComplementoEvento{
+		Tipo: TipoEvento{
+			Evento:    EVENTO_COMPLEMENTO,
+			Descricao: "Respostas registradas — solicite a minuta de sentença para prosseguir.",
+		},
+		Faltantes: []string{},
+	}
+	rspJson, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return nil, erros.CreateError("Erro ao serializar respostas registradas: %s", err.Error())
+	}
+	outputItem := ialib.NewResponseOutputItemExample()
+	outputItem.Content[0].Text = string(rspJson)
+	return []responses.ResponseOutputItemUnion{outputItem}, nil
+}
+
+// createOutPutComplemento devolve ao cliente as perguntas pendentes no formato ComplementoEvento.
+func createOutPutComplemento(faltantes []string) ([]responses.ResponseOutputItemUnion, error) {
+	obj := ComplementoEvento{
+		Tipo: TipoEvento{
+			Evento:    EVENTO_COMPLEMENTO,
+			Descricao: "Respostas incompletas — o usuário deve complementar as informações.",
+		},
+		Faltantes: faltantes,
+	}
+	rspJson, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return nil, erros.CreateError("Erro ao serializar questões pendentes: %s", err.Error())
+	}
+	outputItem := ialib.NewResponseOutputItemExample()
+	outputItem.Content[0].Text = string(rspJson)
+	return []responses.ResponseOutputItemUnion{outputItem}, nil
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import "testing"
+
+func TestRegistraPendentes(t *testing.T) {
+	respondida := RespostaQuestao{Pergunta: "Qual o valor dos danos materiais comprovados?", Resposta: "R$ 5.000,00"}
+
+	casos := []struct {
+		nome        string
+		questoes    []RespostaQuestao
+		faltantes   []string
+		novas       int  // perguntas incluídas pela verificação
+		respondidas bool // Respondidas() após a verificação
+		segunda     int  // perguntas incluídas por incluiPendentes na rodada seguinte
+	}{
+		{
+			nome:        "faltante equivalente a pergunta respondida",
+			questoes:    []RespostaQuestao{respondida},
+			faltantes:   []string{"Qual o valor dos danos materiais?"},
+			respondidas: true,
+		},
+		{
+			nome:      "faltante novo vira pergunta",
+			questoes:  []RespostaQuestao{respondida},
+			faltantes: []string{"Há pedido de honorários advocatícios contratuais?"},
+			novas:     1,
+		},
+		{
+			nome:     "faltantes além do limite continuam pendentes",
+			questoes: []RespostaQuestao{respondida},
+			faltantes: []string{
+				"Houve citação válida do réu revel?",
+				"Existe comprovante da negativação indevida?",
+				"Qual a data do vencimento contratual original?",
+				"Há pedido de justiça gratuita pendente?",
+				"Foi juntada procuração com poderes especiais?",
+			},
+			novas:   MAX_QUESTOES_VERIFICACAO,
+			segunda: 2,
+		},
+		{
+			nome:        "faltantes em branco são descartados",
+			questoes:    []RespostaQuestao{respondida},
+			faltantes:   []string{"", "   "},
+			respondidas: true,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			r := &RespostasQuestoes{Questoes: append([]RespostaQuestao{}, c.questoes...)}
+			r.registraPendentes(c.faltantes)
+
+			if novas := len(r.Questoes) - len(c.questoes); novas != c.novas {
+				t.Errorf("perguntas incluídas = %d, esperadas %d", novas, c.novas)
+			}
+			if r.Respondidas() != c.respondidas {
+				t.Errorf("Respondidas() = %v, esperado %v", r.Respondidas(), c.respondidas)
+			}
+
+			// Respondidas as novas perguntas, os excedentes ainda impedem a sentença
+			for i := range r.Questoes {
+				if r.Questoes[i].Resposta == "" {
+					r.Questoes[i].Resposta = "respondida"
+				}
+			}
+			if excedentes := len(r.pendentesSemPergunta()); r.Respondidas() != (excedentes == 0) {
+				t.Errorf("Respondidas() = %v com %d faltantes sem pergunta", r.Respondidas(), excedentes)
+			}
+			if segunda := r.incluiPendentes(); segunda != c.segunda {
+				t.Errorf("incluiPendentes() = %d, esperado %d", segunda, c.segunda)
+			}
+		})
+	}
+}
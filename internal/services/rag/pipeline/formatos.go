@@ -0,0 +1,38 @@
+/*
+---------------------------------------------------------------------------------------
+File: formatos.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Formatos (JSON Schema) das respostas do modelo no pipeline RAG, gerados dos
+tipos que as recebem e enviados como saída estruturada (ialib.WithFormatoJSON).
+---------------------------------------------------------------------------------------
+*/
+package pipeline
+
+import "ocrserver/internal/services/ialib"
+
+// Formato (JSON Schema) das respostas do modelo no pipeline, gerado dos tipos que as recebem
+var (
+	formatoConfirmaEvento     = formatoResposta("rag_confirma_evento", ConfirmaEvento{})
+	formatoAnaliseJuridica    = formatoResposta("rag_analise_juridica", AnaliseJuridicaIA{})
+	formatoMinutaSentenca     = formatoResposta("rag_minuta_sentenca", MinutaSentenca{})
+	formatoMinutaDecisao      = formatoResposta("rag_minuta_decisao", MinutaDecisao{})
+	formatoMinutaDespacho     = formatoResposta("rag_minuta_despacho", MinutaDespacho{})
+	formatoComplementoEvento  = formatoResposta("rag_complemento_evento", ComplementoEvento{})
+	formatoRespostasExtraidas = formatoResposta("rag_respostas_questoes", respostasExtraidas{})
+	formatoNotasRerank        = formatoResposta("rag_notas_rerank", notasRerank{})
+)
+
+// Resposta de ExtraiRespostasQuestoes
+type respostasExtraidas struct {
+	Respostas []RespostaExtraida `json:"respostas"`
+}
+
+// Resposta do RerankerLLM
+type notasRerank struct {
+	Notas []notaRerank `json:"notas"`
+}
+
+func formatoResposta(nome string, tipo any) *ialib.FormatoJSON {
+	return &ialib.FormatoJSON{Nome: nome, Schema: ialib.SchemaDoTipo(tipo), Estrito: true}
+}
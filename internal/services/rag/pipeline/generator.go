@@ -61,7 +61,9 @@ func (service *GeneratorType) ExecutaAnaliseProcesso(
 	// ============================================================
 	// 04 - Autos Processuais
 	// ============================================================
-	service.appendAutos(&messages, autos)
+	if err := service.appendAutos(ctx, &messages, autos, msgs, idCtxt, config.GlobalConfig.OpenOptionModel); err != nil {
+		return "", nil, err
+	}
 
 	// ============================================================
 	// 05 - Mensagens do Usuário (continuação)
@@ -82,7 +84,7 @@ func (service *GeneratorType) ExecutaAnaliseProcesso(
 	// 06 - Envio ao modelo OpenAI
 	// ============================================================
 	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_ANALISE), formatoAnaliseJuridica),
 		messages,
 		prevID,
 		//config.GlobalConfig.OpenOptionModelTop, //Usando o modelo 'OPENAI_OPTION_MODEL_TOP'
@@ -148,7 +150,9 @@ func (service *GeneratorType) ExecutaAnaliseJulgamento(
 	// ============================================================
 	// 04 - Autos processuais
 	// ============================================================
-	service.appendAutos(&messages, autos)
+	if err := service.appendAutos(ctx, &messages, autos, msgs, idCtxt, config.GlobalConfig.OpenOptionModelTop); err != nil {
+		return "", nil, err
+	}
 
 	// ============================================================
 	// 05 - Mensagens do usuário
@@ -159,7 +163,7 @@ func (service *GeneratorType) ExecutaAnaliseJulgamento(
 	// 06 - Execução do modelo OpenAI
 	// ============================================================
 	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_JULGAMENTO), formatoMinutaSentenca),
 		messages,
 		prevID,
 		config.GlobalConfig.OpenOptionModelTop, //Usando o modelo 'OPENAI_OPTION_MODEL_TOP'
@@ -190,12 +194,135 @@ func (service *GeneratorType) ExecutaAnaliseJulgamento(
 	return resp.ID, resp.Output, nil
 }
 
+// ============================================================
+// Minuta de decisão interlocutória
+// ============================================================
+func (service *GeneratorType) ExecutaAnaliseDecisao(
+	ctx context.Context,
+	idCtxt string,
+	msgs ialib.MsgGpt,
+	prevID string,
+	autos []consts.ResponseAutosRow,
+	ragBase []opensearch.ResponseBaseRow,
+) (string, []responses.ResponseOutputItemUnion, error) {
+
+	messages := ialib.MsgGpt{}
+
+	// 01 - Developer Prompt
+	service.appendDeveloperDecisao(&messages)
+
+	// 02 - RAG Base (quando houver análise jurídica)
+	service.appendBaseAnalise(&messages, ragBase)
+
+	// 03 - Prompt Jurídico (modelo da decisão)
+	if err := service.appendPromptNatureza(&messages, consts.PROMPT_RAG_DECISAO, idCtxt); err != nil {
+		return "", nil, err
+	}
+
+	// 04 - Autos processuais
+	if err := service.appendAutos(ctx, &messages, autos, msgs, idCtxt, config.GlobalConfig.OpenOptionModelTop); err != nil {
+		return "", nil, err
+	}
+
+	// 05 - Mensagens do usuário (o pedido a ser decidido vem aqui)
+	appendUserMessages(&messages, msgs)
+
+	// 06 - Execução do modelo
+	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_DECISAO), formatoMinutaDecisao),
+		messages,
+		prevID,
+		config.GlobalConfig.OpenOptionModelTop, //Usando o modelo 'OPENAI_OPTION_MODEL_TOP'
+		ialib.REASONING_LOW,
+		ialib.VERBOSITY_LOW,
+	)
+	if err != nil {
+		logger.Log.Errorf("Erro ao submeter decisão (id_ctxt=%s): %v", idCtxt, err)
+		return "", nil, erros.CreateError("Erro ao submeter decisão: %s", err.Error())
+	}
+	if resp == nil {
+		return "", nil, erros.CreateError("Resposta nula recebida do serviço OpenAI")
+	}
+
+	// 07 - Atualiza uso de tokens
+	logger.Log.Infof("\n\n[CTX=%s] Decisão concluída — input=%d, output=%d tokens\n\n",
+		idCtxt, resp.Usage.InputTokens, resp.Usage.OutputTokens)
+
+	services.ContextoServiceGlobal.UpdateTokenUso(
+		idCtxt,
+		int(resp.Usage.InputTokens),
+		int(resp.Usage.OutputTokens),
+	)
+
+	return resp.ID, resp.Output, nil
+}
+
+// ============================================================
+// Minuta de despacho (mero expediente: sem RAG)
+// ============================================================
+func (service *GeneratorType) ExecutaAnaliseDespacho(
+	ctx context.Context,
+	idCtxt string,
+	msgs ialib.MsgGpt,
+	prevID string,
+	autos []consts.ResponseAutosRow,
+) (string, []responses.ResponseOutputItemUnion, error) {
+
+	messages := ialib.MsgGpt{}
+
+	// 01 - Developer Prompt
+	service.appendDeveloperDespacho(&messages)
+
+	// 02 - Prompt Jurídico (modelo do despacho)
+	if err := service.appendPromptNatureza(&messages, consts.PROMPT_RAG_DESPACHO, idCtxt); err != nil {
+		return "", nil, err
+	}
+
+	// 03 - Autos processuais
+	if err := service.appendAutos(ctx, &messages, autos, msgs, idCtxt, config.GlobalConfig.OpenOptionModel); err != nil {
+		return "", nil, err
+	}
+
+	// 04 - Mensagens do usuário
+	appendUserMessages(&messages, msgs)
+
+	// 05 - Execução do modelo
+	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_DESPACHO), formatoMinutaDespacho),
+		messages,
+		prevID,
+		config.GlobalConfig.OpenOptionModel, //Usando o modelo 'OPENAI_OPTION_MODEL'
+		ialib.REASONING_LOW,
+		ialib.VERBOSITY_LOW,
+	)
+	if err != nil {
+		logger.Log.Errorf("Erro ao submeter despacho (id_ctxt=%s): %v", idCtxt, err)
+		return "", nil, erros.CreateError("Erro ao submeter despacho: %s", err.Error())
+	}
+	if resp == nil {
+		return "", nil, erros.CreateError("Resposta nula recebida do serviço OpenAI")
+	}
+
+	// 06 - Atualiza uso de tokens
+	logger.Log.Infof("\n\n[CTX=%s] Despacho concluído — input=%d, output=%d tokens\n\n",
+		idCtxt, resp.Usage.InputTokens, resp.Usage.OutputTokens)
+
+	services.ContextoServiceGlobal.UpdateTokenUso(
+		idCtxt,
+		int(resp.Usage.InputTokens),
+		int(resp.Usage.OutputTokens),
+	)
+
+	return resp.ID, resp.Output, nil
+}
+
 func (service *GeneratorType) VerificaQuestoesControvertidas(
 	ctx context.Context,
 	id_ctxt string,
 	msgs ialib.MsgGpt,
 	prevID string,
 	rawsAnalise []opensearch.ResponseEventosRow,
+	respostas *RespostasQuestoes,
 ) (int, string, []responses.ResponseOutputItemUnion, error) {
 
 	if rawsAnalise == nil {
@@ -234,12 +361,23 @@ func (service *GeneratorType) VerificaQuestoesControvertidas(
 		return -1, "", nil, erros.CreateError("Erro ao decodificar análise jurídica.")
 	}
 
-	// 🔹 Adiciona questões controvertidas como mensagens de usuário
-	for _, q := range objAnalise.QuestoesControvertidas {
-		texto := fmt.Sprintf("Pergunta: %s", q.PerguntaAoUsuario)
+	// 🔹 Sem registro de complementação, as perguntas da análise seguem sem resposta
+	if respostas == nil {
+		respostas = novasRespostasQuestoes(rawsAnalise[0].Id, objAnalise)
+	} else {
+		respostas.mesclaAnalise(objAnalise)
+	}
+
+	// 🔹 Adiciona cada questão controvertida, com a resposta registrada, como mensagem de usuário
+	for i, q := range respostas.Questoes {
+		resposta := strings.TrimSpace(q.Resposta)
+		if resposta == "" {
+			resposta = "(sem resposta)"
+		}
+		texto := fmt.Sprintf("%d) Pergunta: %s\n   Resposta do usuário: %s", i+1, q.Pergunta, resposta)
 		tokens, _ := ialib.OpenaiGlobal.StringTokensCounter(texto)
 		if tokens > MAX_DOC_TOKENS {
-			texto = texto[:MAX_DOC_TOKENS] + "...(truncado)"
+			texto = truncaTokens(texto, MAX_DOC_TOKENS)
 			logger.Log.Infof("[id_ctxt=%s] Questão truncada (%d tokens > %d)", id_ctxt, tokens, MAX_DOC_TOKENS)
 		}
 		msgsAtual.AddMessage(ialib.MessageResponseItem{
@@ -250,7 +388,7 @@ func (service *GeneratorType) VerificaQuestoesControvertidas(
 
 	// 🔹 Submete o histórico completo (sem sobrescrever msgs)
 	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_COMPLEMENTA_JULGAMENTO), formatoComplementoEvento),
 		msgsAtual, // ← mantém todas as mensagens acumuladas
 		prevID,
 		config.GlobalConfig.OpenOptionModel,
@@ -261,16 +399,17 @@ func (service *GeneratorType) VerificaQuestoesControvertidas(
 		logger.Log.Errorf("[id_ctxt=%s] Erro ao submeter prompt de verificação: %v", id_ctxt, err)
 		return -1, "", nil, erros.CreateError("Erro ao submeter prompt: %s", err.Error())
 	}
+	if resp == nil {
+		return -1, "", nil, erros.CreateError("Resposta nula recebida do modelo")
+	}
 
 	// 🔹 Atualiza uso de tokens
-	if resp != nil {
-		usage := resp.Usage
-		services.ContextoServiceGlobal.UpdateTokenUso(
-			id_ctxt,
-			int(usage.InputTokens),
-			int(usage.OutputTokens),
-		)
-	}
+	usage := resp.Usage
+	services.ContextoServiceGlobal.UpdateTokenUso(
+		id_ctxt,
+		int(usage.InputTokens),
+		int(usage.OutputTokens),
+	)
 
 	//---------------   EXTRAI APENAS O OBJETO JSON DA RESPOSTA
 
@@ -296,10 +435,73 @@ func (service *GeneratorType) VerificaQuestoesControvertidas(
 
 	//---------------------------------------------------------
 
-	// 🔹 Retorna resultado do modelo
-	if resp == nil {
-		return -1, "", nil, erros.CreateError("Resposta nula recebida do modelo")
+	// 🔹 Registra os faltantes apontados (o chamador decide se grava o registro)
+	if verif.Tipo.Evento == EVENTO_COMPLEMENTO {
+		respostas.registraPendentes(verif.Faltantes)
+	} else {
+		respostas.Pendentes = nil
 	}
 
 	return verif.Tipo.Evento, resp.ID, resp.Output, err
 }
+
+/*
+ExtraiRespostasQuestoes identifica, nas mensagens do usuário, as respostas às perguntas
+numeradas do registro de complementação. Devolve apenas as respostas encontradas.
+*/
+func (service *GeneratorType) ExtraiRespostasQuestoes(
+	ctx context.Context,
+	id_ctxt string,
+	msgs ialib.MsgGpt,
+	respostas *RespostasQuestoes,
+) ([]RespostaExtraida, error) {
+
+	const prompt = `Você receberá, numeradas, as perguntas dirigidas ao usuário sobre as questões
+	controvertidas do processo, com as respostas já registradas, seguidas das mensagens do usuário.
+	
+	Identifique nas mensagens do usuário a resposta a cada pergunta.
+	Regras obrigatórias:
+	1. Informe em "indice" o número da pergunta respondida.
+	2. Transcreva em "resposta" a resposta do usuário, de forma fiel e completa, sem interpretar o mérito.
+	3. Não inclua perguntas que o usuário não respondeu nas mensagens.
+	4. Se o usuário corrigir resposta já registrada, devolva a nova resposta.
+	5. Responda apenas com um JSON no formato: {"respostas": [{"indice": int, "resposta": string}]}.`
+
+	var messages ialib.MsgGpt
+	messages.AddMessage(ialib.MessageResponseItem{
+		Role: "developer",
+		Text: prompt,
+	})
+	messages.AddMessage(ialib.MessageResponseItem{
+		Role: "user",
+		Text: respostas.textoQuestoes(),
+	})
+	appendUserMessages(&messages, msgs)
+
+	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_RESPOSTAS_QUESTOES), formatoRespostasExtraidas),
+		messages,
+		"",
+		config.GlobalConfig.OpenOptionModel,
+		ialib.REASONING_LOW,
+		ialib.VERBOSITY_LOW,
+	)
+	if err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao extrair respostas às questões: %v", id_ctxt, err)
+		return nil, erros.CreateError("Erro ao submeter prompt: %s", err.Error())
+	}
+	if resp == nil {
+		return nil, erros.CreateError("Resposta nula recebida do modelo")
+	}
+
+	services.ContextoServiceGlobal.UpdateTokenUso(id_ctxt, int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens))
+
+	var obj respostasExtraidas
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp.OutputText())), &obj); err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao interpretar respostas às questões: %v", id_ctxt, err)
+		return nil, erros.CreateError("Erro ao decodificar as respostas às questões controvertidas.")
+	}
+
+	logger.Log.Infof("[id_ctxt=%s] Respostas identificadas: %d", id_ctxt, len(obj.Respostas))
+	return obj.Respostas, nil
+}
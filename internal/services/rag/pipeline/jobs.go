@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ocrserver/internal/consts"
+	"ocrserver/internal/models"
+	"ocrserver/internal/services"
+	"ocrserver/internal/services/ialib"
+)
+
+// Parâmetros do job JOB_TIPO_PIPELINE_RAG (mesmo body da rota /contexto/query/analise)
+type JobPipelineParams struct {
+	IdCtxt   string                      `json:"id_ctxt"`
+	Messages []ialib.MessageResponseItem `json:"messages"`
+	PrevID   string                      `json:"prev_id"`
+}
+
+// Percentual aproximado de cada etapa, para o progresso do job
+var progressoEtapa = map[string]int{
+	ETAPA_EVENTO_IDENTIFICADO: 10,
+	ETAPA_AUTOS_RECUPERADOS:   25,
+	ETAPA_RAG_CONCLUIDO:       45,
+	ETAPA_VERIFICACAO:         55,
+	ETAPA_GERACAO:             60,
+	ETAPA_EVENTO_SALVO:        95,
+}
+
+/*
+RegistrarJobs registra o executor do pipeline RAG na fila de jobs. O pipeline não é
+retomável: após uma parada do servidor, o job é marcado como falha (a geração tem
+custo e depende do encadeamento prevID da conversa).
+*/
+func RegistrarJobs(svc *services.JobsServiceType) {
+	if svc == nil {
+		return
+	}
+	svc.Registrar(consts.JOB_TIPO_PIPELINE_RAG, false, executarJobPipeline)
+}
+
+func executarJobPipeline(ctx context.Context, job *models.JobRow, progresso services.JobProgressoFunc) (any, error) {
+	var params JobPipelineParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, fmt.Errorf("parâmetros do job inválidos: %w", err)
+	}
+	if params.IdCtxt == "" || len(params.Messages) == 0 {
+		return nil, fmt.Errorf("id_ctxt e messages são obrigatórios")
+	}
+
+	var messages ialib.MsgGpt
+	for _, msg := range params.Messages {
+		messages.AddMessage(msg)
+	}
+
+	// Etapas do pipeline viram progresso do job (tokens não são gravados)
+	ctx = WithProgresso(ctx, func(ev ProgressoEvento) {
+		if p, ok := progressoEtapa[ev.Etapa]; ok {
+			progresso(p, ev.Mensagem)
+		}
+	})
+
+	orch := NewOrquestradorType()
+	res, err := orch.StartPipelineResult(ctx, params.IdCtxt, messages, params.PrevID, job.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return res.Resumo(), nil
+}
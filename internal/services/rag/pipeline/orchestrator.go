@@ -50,10 +50,29 @@ type PipelineResult struct {
 	// Metadados opcionais úteis para frontend/telemetria
 	EventCode int
 	EventDesc string
+
+	// Conferência das citações da minuta (quando houver)
+	Citacoes *ValidacaoCitacoes
 }
 
 func (r PipelineResult) IsTerminal() bool { return r.Status != StatusOK }
 
+// Resumo é o objeto devolvido ao cliente (rotas JSON, SSE e resultado do job).
+func (r PipelineResult) Resumo() map[string]any {
+	return map[string]any{
+		"message":   r.Message,
+		"status":    r.Status.String(),
+		"ok":        r.Status == StatusOK,
+		"blocked":   r.Status == StatusBlocked,
+		"invalid":   r.Status == StatusInvalid,
+		"id":        r.ID,
+		"output":    r.Output,
+		"eventCode": r.EventCode,
+		"eventDesc": r.EventDesc,
+		"citacoes":  r.Citacoes,
+	}
+}
+
 // Helpers de construção de resultado
 func okResult(id string, out []responses.ResponseOutputItemUnion, msg string) PipelineResult {
 	return PipelineResult{Status: StatusOK, ID: id, Output: out, Message: msg}
@@ -105,6 +124,7 @@ func (service *OrquestradorType) StartPipelineResult(
 	}
 
 	logger.Log.Infof("\nEvento solicitado: %d - %s\n", objTipo.Tipo.Evento, objTipo.Tipo.Descricao)
+	notificaProgresso(ctx, ETAPA_EVENTO_IDENTIFICADO, objTipo.Tipo.Descricao, objTipo.Tipo)
 
 	// Se for confirmação pendente (cod=300), isso é fluxo normal (BLOCKED)
 	if objTipo.Tipo.Evento == EVENTO_CONFIRMACAO {
@@ -171,7 +191,7 @@ func (service *OrquestradorType) getNaturezaEventoSubmit(
 	}
 
 	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
+		ialib.WithFormatoJSON(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_IDENTIFICA), formatoConfirmaEvento),
 		messages,
 		prevID,
 		config.GlobalConfig.OpenOptionModel,
@@ -219,10 +239,17 @@ func (service *OrquestradorType) handleEventoResult(
 		logger.Log.Info("\nEvento identificado: RAG_EVENTO_SENTENCA\n")
 		return service.pipelineAnaliseSentencaResult(ctx, id_ctxt, msgs, prevID, userName)
 
+	case EVENTO_DECISAO:
+		logger.Log.Info("\nEvento identificado: RAG_EVENTO_DECISAO\n")
+		return service.pipelineDecisaoResult(ctx, id_ctxt, msgs, prevID, userName)
+
+	case EVENTO_DESPACHO:
+		logger.Log.Info("\nEvento identificado: RAG_EVENTO_DESPACHO\n")
+		return service.pipelineDespachoResult(ctx, id_ctxt, msgs, prevID, userName)
+
 	case EVENTO_COMPLEMENTO:
 		logger.Log.Info("\nEvento identificado: RAG_EVENTO_COMPLEMENTO\n")
-		// “não implementado” -> inválido (não é falha técnica)
-		return invalidResult("", nil, "Submit de Complemento não implementado"), nil
+		return service.pipelineComplementoResult(ctx, id_ctxt, msgs, prevID, userName)
 
 	case EVENTO_OUTROS, EVENTO_CONCEITOS:
 		logger.Log.Info("\nEvento identificado: RAG_EVENTO_OUTROS\n")
@@ -267,6 +294,7 @@ func (service *OrquestradorType) pipelineAnaliseProcessoResult(
 		logger.Log.Warningf("Os autos do processo estão vazios (id_ctxt=%s)", id_ctxt)
 		return invalidResult("", nil, "Os autos do processo estão vazios"), nil
 	}
+	notificaProgresso(ctx, ETAPA_AUTOS_RECUPERADOS, "Autos recuperados", map[string]any{"quantidade": len(autos)})
 	//***   Recupera pré-análise
 	//Obs. A pré-an-análise é ncessária para identificar os pontos controvertidos e usá-los para
 	//buscar na base de conhecimentos subsídios para realizar uma análise jurídica completa do
@@ -297,9 +325,11 @@ func (service *OrquestradorType) pipelineAnaliseProcessoResult(
 		natuAnalise = consts.NATU_DOC_IA_PREANALISE
 		ragBase = []opensearch.ResponseBaseRow{}
 	}
+	notificaProgresso(ctx, ETAPA_RAG_CONCLUIDO, "Base de conhecimentos consultada", map[string]any{"quantidade": len(ragBase)})
 
 	//***   Executa análise IA
-	ID, output, err := genObj.ExecutaAnaliseProcesso(ctx, id_ctxt, msgs, prevID, autos, ragBase)
+	notificaProgresso(ctx, ETAPA_GERACAO, "Gerando análise jurídica", nil)
+	ID, output, err := genObj.ExecutaAnaliseProcesso(comStreaming(ctx), id_ctxt, msgs, prevID, autos, ragBase)
 	if err != nil {
 		logger.Log.Errorf("Erro ao executar análise jurídica do processo: %v", err)
 		return PipelineResult{}, fmt.Errorf("ExecutaAnaliseProcesso: %w", err)
@@ -323,12 +353,14 @@ func (service *OrquestradorType) pipelineAnaliseProcessoResult(
 	objAnalise.DataGeracao = time.Now().Format("02/01/2006 15:04:05")
 	logger.Log.Infof("Data de geração atribuída automaticamente: %s", objAnalise.DataGeracao)
 
+	objAnalise.ValidacaoCitacoes = validaCitacoes(ctx, id_ctxt, citacoesAnalise(&objAnalise), autos, ragBase)
+
 	updatedJson, err := json.MarshalIndent(objAnalise, "", "  ")
 	if err != nil {
 		return PipelineResult{}, fmt.Errorf("marshal AnaliseJuridicaIA: %w", err)
 	}
 
-	ok, err := service.salvarAnalise(id_ctxt, natuAnalise, "", string(updatedJson), userName)
+	ok, err := service.salvarAnalise(ctx, id_ctxt, natuAnalise, "", string(updatedJson), userName)
 	if err != nil {
 		logger.Log.Errorf("Erro ao salvar análise (id_ctxt=%s): %v", id_ctxt, err)
 		return PipelineResult{}, fmt.Errorf("salvarAnalise: %w", err)
@@ -338,7 +370,9 @@ func (service *OrquestradorType) pipelineAnaliseProcessoResult(
 		return PipelineResult{}, nil // falha lógica/inesperada -> pode ser error se preferir
 	}
 
-	return okResult(ID, output, "Análise salva com sucesso"), nil
+	res := okResult(ID, output, "Análise salva com sucesso")
+	res.Citacoes = objAnalise.ValidacaoCitacoes
+	return res, nil
 }
 
 // ==========================================
@@ -374,21 +408,59 @@ func (service *OrquestradorType) pipelineAnaliseSentencaResult(
 
 	// =============================================================
 	// 1️⃣ Verificação prévia das questões controvertidas. Será chamadas enquanto houve
-	// questões controvertidas.
+	// questões controvertidas. As respostas já dadas pelo usuário (EVENTO_COMPLEMENTO)
+	// são enviadas junto com as perguntas.
 	// =============================================================
-	codEvento, idVerif, outputVerif, err := genObj.VerificaQuestoesControvertidas(ctx, id_ctxt, msgs, prevID, analise)
+	rowResp, respostas, err := retriObj.RecuperaRespostasQuestoes(ctx, id_ctxt, analise[0].Id)
 	if err != nil {
-		logger.Log.Errorf("[id_ctxt=%s] Erro ao verificar questões controvertidas: %v", id_ctxt, err)
-		return PipelineResult{}, fmt.Errorf("VerificaQuestoesControvertidas: %w", err)
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao recuperar respostas às questões: %v", id_ctxt, err)
+		return PipelineResult{}, fmt.Errorf("RecuperaRespostasQuestoes: %w", err)
+	}
+	if respostas == nil {
+		respostas = novasRespostasQuestoes(analise[0].Id, AnaliseJuridicaIA{})
+	}
+	haviaPendentes := len(respostas.Pendentes) > 0
+
+	var (
+		codEvento   int
+		idVerif     string
+		outputVerif []responses.ResponseOutputItemUnion
+	)
+	if rowResp != nil && respostas.Respondidas() {
+		// Todas as perguntas respondidas: a sentença não depende de nova verificação pelo modelo
+		logger.Log.Infof("[id_ctxt=%s] Questões controvertidas respondidas — verificação dispensada", id_ctxt)
+		codEvento = EVENTO_SENTENCA
+		respostas.Pendentes = nil
+	} else {
+		codEvento, idVerif, outputVerif, err = genObj.VerificaQuestoesControvertidas(ctx, id_ctxt, msgs, prevID, analise, respostas)
+		if err != nil {
+			logger.Log.Errorf("[id_ctxt=%s] Erro ao verificar questões controvertidas: %v", id_ctxt, err)
+			return PipelineResult{}, fmt.Errorf("VerificaQuestoesControvertidas: %w", err)
+		}
+		if codEvento == EVENTO_COMPLEMENTO && respostas.Respondidas() {
+			// Todos os faltantes equivalem a perguntas já respondidas: não há o que perguntar
+			logger.Log.Warningf("[id_ctxt=%s] Verificação sem pergunta nova a responder — prosseguindo para a sentença", id_ctxt)
+			codEvento = EVENTO_SENTENCA
+			respostas.Pendentes = nil
+		}
 	}
 
 	switch codEvento {
 	case EVENTO_COMPLEMENTO:
 		logger.Log.Warningf("Há questões controvertidas — aguardando complementação: %v", codEvento)
+		if _, err := service.salvarRespostasQuestoes(ctx, id_ctxt, rowResp, respostas, userName); err != nil {
+			return PipelineResult{}, fmt.Errorf("salvarRespostasQuestoes: %w", err)
+		}
 		return blockedResult(idVerif, outputVerif, EVENTO_COMPLEMENTO, "Há questões controvertidas — aguardando complementação"), nil
 
 	case EVENTO_SENTENCA:
 		logger.Log.Infof("Verificação concluída — prosseguindo para geração da sentença: %v.", codEvento)
+		if rowResp != nil && haviaPendentes {
+			if _, err := service.salvarRespostasQuestoes(ctx, id_ctxt, rowResp, respostas, userName); err != nil {
+				return PipelineResult{}, fmt.Errorf("salvarRespostasQuestoes: %w", err)
+			}
+		}
+		notificaProgresso(ctx, ETAPA_VERIFICACAO, "Questões controvertidas resolvidas", nil)
 
 	default:
 		msg := fmt.Sprintf("Código inesperado (%d) na verificação de controvérsias.", codEvento)
@@ -405,6 +477,7 @@ func (service *OrquestradorType) pipelineAnaliseSentencaResult(
 		logger.Log.Warningf("Os autos do processo estão vazios (id_ctxt=%s)", id_ctxt)
 		return invalidResult("", nil, "Os autos do processo estão vazios"), nil
 	}
+	notificaProgresso(ctx, ETAPA_AUTOS_RECUPERADOS, "Autos recuperados", map[string]any{"quantidade": len(autos)})
 
 	ragBase, err := retriObj.RecuperaBaseConhecimentos(ctx, id_ctxt, analise[0])
 	if err != nil {
@@ -414,8 +487,10 @@ func (service *OrquestradorType) pipelineAnaliseSentencaResult(
 	if len(ragBase) == 0 {
 		logger.Log.Infof("Nenhuma doutrina recuperada (id_ctxt=%s)", id_ctxt)
 	}
+	notificaProgresso(ctx, ETAPA_RAG_CONCLUIDO, "Base de conhecimentos consultada", map[string]any{"quantidade": len(ragBase)})
 
-	ID, output, err := genObj.ExecutaAnaliseJulgamento(ctx, id_ctxt, msgs, prevID, autos, ragBase)
+	notificaProgresso(ctx, ETAPA_GERACAO, "Gerando minuta de sentença", nil)
+	ID, output, err := genObj.ExecutaAnaliseJulgamento(comStreaming(ctx), id_ctxt, msgs, prevID, autos, ragBase)
 	if err != nil {
 		logger.Log.Errorf("Erro ao executar análise jurídica do processo: %v", err)
 		return PipelineResult{}, fmt.Errorf("ExecutaAnaliseJulgamento: %w", err)
@@ -435,13 +510,15 @@ func (service *OrquestradorType) pipelineAnaliseSentencaResult(
 	objMinuta.DataGeracao = time.Now().Format("02/01/2006 15:04:05")
 	logger.Log.Infof("[id_ctxt=%s] Data de geração da minuta definida: %s", id_ctxt, objMinuta.DataGeracao)
 
+	objMinuta.ValidacaoCitacoes = validaCitacoes(ctx, id_ctxt, citacoesSentenca(&objMinuta), autos, ragBase)
+
 	updatedJson, err := json.MarshalIndent(objMinuta, "", "  ")
 	if err != nil {
 		logger.Log.Errorf("Erro ao serializar minuta de sentença: %v", err)
 		return PipelineResult{}, fmt.Errorf("marshal MinutaSentenca: %w", err)
 	}
 
-	ok, err := service.salvarAnalise(id_ctxt, consts.NATU_DOC_IA_SENTENCA, "", string(updatedJson), userName)
+	ok, err := service.salvarAnalise(ctx, id_ctxt, consts.NATU_DOC_IA_SENTENCA, "", string(updatedJson), userName)
 	if err != nil {
 		logger.Log.Errorf("Erro ao salvar minuta (id_ctxt=%s): %v", id_ctxt, err)
 		return PipelineResult{}, fmt.Errorf("salvarAnalise minuta: %w", err)
@@ -451,7 +528,263 @@ func (service *OrquestradorType) pipelineAnaliseSentencaResult(
 		return invalidResult(ID, output, "Falha ao salvar minuta"), nil
 	}
 
-	return okResult(ID, output, "Minuta salva com sucesso"), nil
+	res := okResult(ID, output, "Minuta salva com sucesso")
+	res.Citacoes = objMinuta.ValidacaoCitacoes
+	return res, nil
+}
+
+// ==========================================
+// pipelineComplemento no padrão PipelineResult
+// ==========================================
+/*
+Registra as respostas do usuário às questões controvertidas da análise jurídica. Enquanto
+houver pergunta sem resposta, devolve BLOCKED com as pendentes, incluídos os faltantes da
+última verificação que excederam MAX_QUESTOES_VERIFICACAO; respondidas todas, devolve OK
+sem gerar a minuta de sentença, que o usuário solicita em seguida e cuja verificação
+avalia a suficiência das respostas.
+*/
+func (service *OrquestradorType) pipelineComplementoResult(
+	ctx context.Context,
+	id_ctxt string,
+	msgs ialib.MsgGpt,
+	prevID string,
+	userName string,
+) (PipelineResult, error) {
+
+	logger.Log.Infof("\nIniciando pipelineComplemento...\n")
+	startTime := time.Now()
+	defer func() {
+		logger.Log.Infof("\nFinalizando pipelineComplemento - duração=%s.\n", time.Since(startTime))
+	}()
+
+	retriObj := NewRetrieverType()
+	genObj := NewGeneratorType()
+
+	analise, err := retriObj.RecuperaAnaliseJuridica(ctx, id_ctxt)
+	if err != nil {
+		logger.Log.Errorf("Erro ao realizar busca de análise jurídica: %v", err)
+		return PipelineResult{}, fmt.Errorf("RecuperaAnaliseJuridica: %w", err)
+	}
+	if len(analise) == 0 {
+		logger.Log.Warningf("[id_ctxt=%s] Nenhuma análise jurídica encontrada", id_ctxt)
+		return invalidResult("", nil, "Não foi realizada a análise jurídica."), nil
+	}
+
+	var objAnalise AnaliseJuridicaIA
+	if err := json.Unmarshal([]byte(analise[0].DocJsonRaw), &objAnalise); err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao realizar unmarshal da análise jurídica: %v", id_ctxt, err)
+		return PipelineResult{}, fmt.Errorf("unmarshal AnaliseJuridicaIA: %w", err)
+	}
+
+	rowResp, respostas, err := retriObj.RecuperaRespostasQuestoes(ctx, id_ctxt, analise[0].Id)
+	if err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao recuperar respostas às questões: %v", id_ctxt, err)
+		return PipelineResult{}, fmt.Errorf("RecuperaRespostasQuestoes: %w", err)
+	}
+	if respostas == nil {
+		respostas = novasRespostasQuestoes(analise[0].Id, objAnalise)
+	} else {
+		respostas.mesclaAnalise(objAnalise)
+	}
+
+	if len(respostas.Questoes) == 0 && len(respostas.Pendentes) == 0 {
+		logger.Log.Infof("[id_ctxt=%s] A análise jurídica não tem questões controvertidas", id_ctxt)
+		return okResult("", nil, "A análise jurídica não tem questões controvertidas — solicite a minuta de sentença"), nil
+	}
+
+	extraidas, err := genObj.ExtraiRespostasQuestoes(ctx, id_ctxt, msgs, respostas)
+	if err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao extrair respostas às questões: %v", id_ctxt, err)
+		return PipelineResult{}, fmt.Errorf("ExtraiRespostasQuestoes: %w", err)
+	}
+
+	n := respostas.aplicaRespostas(extraidas)
+	if n == 0 {
+		logger.Log.Warningf("[id_ctxt=%s] Nenhuma resposta identificada nas mensagens do usuário", id_ctxt)
+	}
+	// Faltantes excedentes da última verificação viram as próximas perguntas
+	if novas := respostas.incluiPendentes(); n > 0 || novas > 0 {
+		if _, err := service.salvarRespostasQuestoes(ctx, id_ctxt, rowResp, respostas, userName); err != nil {
+			return PipelineResult{}, fmt.Errorf("salvarRespostasQuestoes: %w", err)
+		}
+	}
+
+	if faltantes := respostas.SemResposta(); len(faltantes) > 0 {
+		output, err := createOutPutComplemento(faltantes)
+		if err != nil {
+			return PipelineResult{}, fmt.Errorf("createOutPutComplemento: %w", err)
+		}
+		return blockedResult("", output, EVENTO_COMPLEMENTO, "Há questões controvertidas sem resposta — aguardando complementação"), nil
+	}
+
+	logger.Log.Infof("[id_ctxt=%s] Todas as questões controvertidas respondidas — aguardando a solicitação da sentença", id_ctxt)
+	output, err := createOutPutRespostasRegistradas()
+	if err != nil {
+		return PipelineResult{}, fmt.Errorf("createOutPutRespostasRegistradas: %w", err)
+	}
+	return okResult("", output, "Questões controvertidas respondidas — solicite a minuta de sentença"), nil
+}
+
+// ==========================================
+// pipelineDecisao no padrão PipelineResult
+// ==========================================
+func (service *OrquestradorType) pipelineDecisaoResult(
+	ctx context.Context,
+	id_ctxt string,
+	msgs ialib.MsgGpt,
+	prevID string,
+	userName string,
+) (PipelineResult, error) {
+
+	logger.Log.Infof("\nIniciando pipelineDecisao...\n")
+	startTime := time.Now()
+	defer func() {
+		logger.Log.Infof("\nFinalizando pipelineDecisao - duração=%s.\n", time.Since(startTime))
+	}()
+
+	retriObj := NewRetrieverType()
+	genObj := NewGeneratorType()
+
+	autos, err := retriObj.RecuperaAutosProcesso(ctx, id_ctxt)
+	if err != nil {
+		logger.Log.Errorf("Erro ao recuperar os autos do processo: %v", err)
+		return PipelineResult{}, fmt.Errorf("RecuperaAutosProcesso: %w", err)
+	}
+	if len(autos) == 0 {
+		logger.Log.Warningf("Os autos do processo estão vazios (id_ctxt=%s)", id_ctxt)
+		return invalidResult("", nil, "Os autos do processo estão vazios"), nil
+	}
+	notificaProgresso(ctx, ETAPA_AUTOS_RECUPERADOS, "Autos recuperados", map[string]any{"quantidade": len(autos)})
+
+	// A análise jurídica não é pré-requisito da decisão: quando existe, seus temas
+	// são usados para consultar a base de conhecimentos.
+	analise, err := retriObj.RecuperaAnaliseJuridica(ctx, id_ctxt)
+	if err != nil {
+		logger.Log.Errorf("Erro ao realizar busca de análise jurídica: %v", err)
+		return PipelineResult{}, fmt.Errorf("RecuperaAnaliseJuridica: %w", err)
+	}
+
+	ragBase := []opensearch.ResponseBaseRow{}
+	if len(analise) > 0 {
+		ragBase, err = retriObj.RecuperaBaseConhecimentos(ctx, id_ctxt, analise[0])
+		if err != nil {
+			logger.Log.Errorf("Erro ao realizar RAG de doutrina: %v", err)
+			return PipelineResult{}, fmt.Errorf("RecuperaBaseConhecimentos: %w", err)
+		}
+	} else {
+		logger.Log.Infof("Decisão sem análise jurídica prévia: base de conhecimentos não consultada (id_ctxt=%s)", id_ctxt)
+	}
+	notificaProgresso(ctx, ETAPA_RAG_CONCLUIDO, "Base de conhecimentos consultada", map[string]any{"quantidade": len(ragBase)})
+
+	notificaProgresso(ctx, ETAPA_GERACAO, "Gerando minuta de decisão", nil)
+	ID, output, err := genObj.ExecutaAnaliseDecisao(comStreaming(ctx), id_ctxt, msgs, prevID, autos, ragBase)
+	if err != nil {
+		logger.Log.Errorf("Erro ao executar a minuta de decisão: %v", err)
+		return PipelineResult{}, fmt.Errorf("ExecutaAnaliseDecisao: %w", err)
+	}
+
+	docJson := extractOutputText(output)
+	if strings.TrimSpace(docJson) == "" {
+		return invalidResult(ID, output, "Resposta da IA não contém texto"), nil
+	}
+
+	var objMinuta MinutaDecisao
+	if err := json.Unmarshal([]byte(docJson), &objMinuta); err != nil {
+		logger.Log.Errorf("Erro ao realizar unmarshal da minuta de decisão: %v", err)
+		return PipelineResult{}, fmt.Errorf("unmarshal MinutaDecisao: %w", err)
+	}
+
+	objMinuta.DataGeracao = time.Now().Format("02/01/2006 15:04:05")
+	objMinuta.ValidacaoCitacoes = validaCitacoes(ctx, id_ctxt, citacoesDecisao(&objMinuta), autos, ragBase)
+
+	updatedJson, err := json.MarshalIndent(objMinuta, "", "  ")
+	if err != nil {
+		logger.Log.Errorf("Erro ao serializar minuta de decisão: %v", err)
+		return PipelineResult{}, fmt.Errorf("marshal MinutaDecisao: %w", err)
+	}
+
+	ok, err := service.salvarAnalise(ctx, id_ctxt, consts.NATU_DOC_IA_DECISAO, "", string(updatedJson), userName)
+	if err != nil {
+		logger.Log.Errorf("Erro ao salvar minuta de decisão (id_ctxt=%s): %v", id_ctxt, err)
+		return PipelineResult{}, fmt.Errorf("salvarAnalise decisão: %w", err)
+	}
+	if !ok {
+		return invalidResult(ID, output, "Falha ao salvar minuta de decisão"), nil
+	}
+
+	res := okResult(ID, output, "Minuta de decisão salva com sucesso")
+	res.Citacoes = objMinuta.ValidacaoCitacoes
+	return res, nil
+}
+
+// ==========================================
+// pipelineDespacho no padrão PipelineResult
+// ==========================================
+func (service *OrquestradorType) pipelineDespachoResult(
+	ctx context.Context,
+	id_ctxt string,
+	msgs ialib.MsgGpt,
+	prevID string,
+	userName string,
+) (PipelineResult, error) {
+
+	logger.Log.Infof("\nIniciando pipelineDespacho...\n")
+	startTime := time.Now()
+	defer func() {
+		logger.Log.Infof("\nFinalizando pipelineDespacho - duração=%s.\n", time.Since(startTime))
+	}()
+
+	retriObj := NewRetrieverType()
+	genObj := NewGeneratorType()
+
+	autos, err := retriObj.RecuperaAutosProcesso(ctx, id_ctxt)
+	if err != nil {
+		logger.Log.Errorf("Erro ao recuperar os autos do processo: %v", err)
+		return PipelineResult{}, fmt.Errorf("RecuperaAutosProcesso: %w", err)
+	}
+	if len(autos) == 0 {
+		logger.Log.Warningf("Os autos do processo estão vazios (id_ctxt=%s)", id_ctxt)
+		return invalidResult("", nil, "Os autos do processo estão vazios"), nil
+	}
+	notificaProgresso(ctx, ETAPA_AUTOS_RECUPERADOS, "Autos recuperados", map[string]any{"quantidade": len(autos)})
+
+	// Despacho é mero expediente: dispensa a base de conhecimentos
+	notificaProgresso(ctx, ETAPA_GERACAO, "Gerando minuta de despacho", nil)
+	ID, output, err := genObj.ExecutaAnaliseDespacho(comStreaming(ctx), id_ctxt, msgs, prevID, autos)
+	if err != nil {
+		logger.Log.Errorf("Erro ao executar a minuta de despacho: %v", err)
+		return PipelineResult{}, fmt.Errorf("ExecutaAnaliseDespacho: %w", err)
+	}
+
+	docJson := extractOutputText(output)
+	if strings.TrimSpace(docJson) == "" {
+		return invalidResult(ID, output, "Resposta da IA não contém texto"), nil
+	}
+
+	var objMinuta MinutaDespacho
+	if err := json.Unmarshal([]byte(docJson), &objMinuta); err != nil {
+		logger.Log.Errorf("Erro ao realizar unmarshal da minuta de despacho: %v", err)
+		return PipelineResult{}, fmt.Errorf("unmarshal MinutaDespacho: %w", err)
+	}
+
+	objMinuta.DataGeracao = time.Now().Format("02/01/2006 15:04:05")
+
+	updatedJson, err := json.MarshalIndent(objMinuta, "", "  ")
+	if err != nil {
+		logger.Log.Errorf("Erro ao serializar minuta de despacho: %v", err)
+		return PipelineResult{}, fmt.Errorf("marshal MinutaDespacho: %w", err)
+	}
+
+	ok, err := service.salvarAnalise(ctx, id_ctxt, consts.NATU_DOC_IA_DESPACHO, "", string(updatedJson), userName)
+	if err != nil {
+		logger.Log.Errorf("Erro ao salvar minuta de despacho (id_ctxt=%s): %v", id_ctxt, err)
+		return PipelineResult{}, fmt.Errorf("salvarAnalise despacho: %w", err)
+	}
+	if !ok {
+		return invalidResult(ID, output, "Falha ao salvar minuta de despacho"), nil
+	}
+
+	return okResult(ID, output, "Minuta de despacho salva com sucesso"), nil
 }
 
 // ==========================================
@@ -486,8 +819,9 @@ func (service *OrquestradorType) pipelineDialogoOutrosResult(
 
 	appendUserMessages(&messages, msgs)
 
+	notificaProgresso(ctx, ETAPA_GERACAO, "Gerando resposta", nil)
 	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
-		ctx,
+		ialib.WithNaturezaPrompt(comStreaming(ctx), consts.PROMPT_RAG_OUTROS),
 		messages,
 		prevID,
 		config.GlobalConfig.OpenOptionModel,
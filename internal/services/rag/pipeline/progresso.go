@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+
+	"ocrserver/internal/services/ialib"
+)
+
+/*
+Notificação de progresso do pipeline. O chamador (ex.: handler SSE) anexa ao contexto
+uma ProgressoFunc; o orquestrador informa cada etapa concluída e, durante a geração,
+repassa os trechos de texto produzidos pelo modelo. Sem ProgressoFunc, nada muda.
+*/
+
+// Etapas notificadas
+const (
+	ETAPA_EVENTO_IDENTIFICADO = "evento_identificado"
+	ETAPA_AUTOS_RECUPERADOS   = "autos_recuperados"
+	ETAPA_RAG_CONCLUIDO       = "rag_concluido"
+	ETAPA_VERIFICACAO         = "verificacao_concluida"
+	ETAPA_GERACAO             = "geracao_iniciada"
+	ETAPA_CITACOES            = "citacoes_conferidas"
+	ETAPA_TOKEN               = "token"
+	ETAPA_EVENTO_SALVO        = "evento_salvo"
+)
+
+type ProgressoEvento struct {
+	Etapa    string `json:"etapa"`
+	Mensagem string `json:"mensagem,omitempty"`
+	Dados    any    `json:"dados,omitempty"`
+}
+
+type ProgressoFunc func(ev ProgressoEvento)
+
+type ctxKeyProgresso struct{}
+
+// WithProgresso anexa ao contexto a função que receberá as notificações do pipeline.
+func WithProgresso(ctx context.Context, f ProgressoFunc) context.Context {
+	return context.WithValue(ctx, ctxKeyProgresso{}, f)
+}
+
+func progressoFromContext(ctx context.Context) ProgressoFunc {
+	f, _ := ctx.Value(ctxKeyProgresso{}).(ProgressoFunc)
+	return f
+}
+
+func notificaProgresso(ctx context.Context, etapa string, msg string, dados any) {
+	if f := progressoFromContext(ctx); f != nil {
+		f(ProgressoEvento{Etapa: etapa, Mensagem: msg, Dados: dados})
+	}
+}
+
+// comStreaming liga o streaming de tokens do modelo quando há alguém acompanhando o progresso.
+func comStreaming(ctx context.Context) context.Context {
+	f := progressoFromContext(ctx)
+	if f == nil {
+		return ctx
+	}
+	return ialib.WithStreamDelta(ctx, func(delta string) {
+		f(ProgressoEvento{Etapa: ETAPA_TOKEN, Dados: delta})
+	})
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/consts"
+	"ocrserver/internal/opensearch"
+	"ocrserver/internal/services"
+	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/utils/logger"
+)
+
+// Tamanho máximo de cada trecho enviado ao reranker
+const MAX_TOKENS_TRECHO_RERANK = 500
+
+/*
+RerankerBase reordena os candidatos recuperados da base de conhecimentos para uma
+consulta, devolvendo-os por pertinência decrescente e sem os que não atingem a nota
+mínima. A implementação é escolhida por RAG_RERANKER.
+*/
+type RerankerBase interface {
+	Reordena(ctx context.Context, idCtxt string, consulta string, docs []opensearch.ResponseBaseRow) ([]opensearch.ResponseBaseRow, error)
+}
+
+func NewReranker(nome string, limiar float64) RerankerBase {
+	switch nome {
+	case "llm":
+		return &RerankerLLM{Limiar: limiar}
+	default:
+		return RerankerNenhum{}
+	}
+}
+
+// RerankerNenhum mantém a ordem da busca (fusão ou kNN), sem aplicar nota mínima.
+type RerankerNenhum struct{}
+
+func (RerankerNenhum) Reordena(ctx context.Context, idCtxt string, consulta string, docs []opensearch.ResponseBaseRow) ([]opensearch.ResponseBaseRow, error) {
+	return docs, nil
+}
+
+/*
+RerankerLLM pede ao modelo secundário uma nota de 0 a 10 para a pertinência de cada
+trecho à questão jurídica. O Score passa a ser nota/10 e os trechos abaixo do limiar são
+descartados. Em caso de falha, a ordem da busca é mantida para não interromper a minuta.
+*/
+type RerankerLLM struct {
+	Limiar float64 // 0 a 1
+}
+
+type notaRerank struct {
+	Indice int     `json:"indice"`
+	Nota   float64 `json:"nota"`
+}
+
+func (r *RerankerLLM) Reordena(ctx context.Context, idCtxt string, consulta string, docs []opensearch.ResponseBaseRow) ([]opensearch.ResponseBaseRow, error) {
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	const prompt = `Você receberá uma questão jurídica e trechos numerados da base de conhecimentos
+	(doutrina, jurisprudência e modelos).
+
+	Atribua a cada trecho uma nota de 0 a 10 para a pertinência à questão:
+	- 10: trata diretamente da questão e pode fundamentar a decisão;
+	- 5: trata de tema próximo, com utilidade apenas parcial;
+	- 0: não tem relação com a questão.
+	Não avalie a qualidade do texto, apenas a pertinência.
+	Responda apenas com um JSON no formato: {"notas": [{"indice": int, "nota": number}]}.`
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "QUESTÃO JURÍDICA: %s\n\nTRECHOS:\n", consulta)
+	for i, doc := range docs {
+		fmt.Fprintf(&sb, "\n[%d] Tema: %s | Fonte: %s\n%s\n", i+1, doc.Tema, doc.Fonte, truncaTokens(doc.Texto, MAX_TOKENS_TRECHO_RERANK))
+	}
+
+	var messages ialib.MsgGpt
+	messages.AddMessage(ialib.MessageResponseItem{Role: "developer", Text: prompt})
+	messages.AddMessage(ialib.MessageResponseItem{Role: "user", Text: sb.String()})
+
+	ctx = ialib.WithStreamDelta(ialib.WithNaturezaPrompt(ctx, consts.PROMPT_RAG_RERANK), nil)
+	ctx = ialib.WithFormatoJSON(ctx, formatoNotasRerank)
+	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
+		ctx,
+		messages,
+		"",
+		config.GlobalConfig.OpenOptionModelSecundary,
+		ialib.REASONING_LOW,
+		ialib.VERBOSITY_LOW,
+	)
+	if err != nil || resp == nil {
+		logger.Log.Warningf("[id_ctxt=%s] Reranker indisponível, mantida a ordem da busca: %v", idCtxt, err)
+		return docs, nil
+	}
+	services.ContextoServiceGlobal.UpdateTokenUso(idCtxt, int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens))
+
+	var obj notasRerank
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp.OutputText())), &obj); err != nil {
+		logger.Log.Warningf("[id_ctxt=%s] Resposta do reranker inválida, mantida a ordem da busca: %v", idCtxt, err)
+		return docs, nil
+	}
+
+	// Trechos sem nota ficam com zero
+	notas := make([]float64, len(docs))
+	for _, n := range obj.Notas {
+		if n.Indice >= 1 && n.Indice <= len(docs) {
+			notas[n.Indice-1] = min(max(n.Nota, 0), 10) / 10
+		}
+	}
+
+	out := make([]opensearch.ResponseBaseRow, 0, len(docs))
+	for i, doc := range docs {
+		if notas[i] < r.Limiar {
+			continue
+		}
+		doc.Score = notas[i]
+		out = append(out, doc)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+
+	logger.Log.Infof("[id_ctxt=%s] Reranker: %d de %d trechos acima do limiar %.2f", idCtxt, len(out), len(docs), r.Limiar)
+	return out, nil
+}
@@ -0,0 +1,364 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/consts"
+	"ocrserver/internal/services"
+	"ocrserver/internal/services/ialib"
+	"ocrserver/internal/utils/erros"
+	"ocrserver/internal/utils/logger"
+)
+
+/*
+Ingestão dos autos dentro do orçamento de contexto do modelo (LLM_CONTEXT_BUDGET e
+LLM_CONTEXT_BUDGET_MODELOS). Quando os autos cabem no orçamento, seguem integrais.
+Caso contrário, o orçamento é repartido entre as peças: as menores seguem integrais e
+as que excedem a sua cota são divididas em blocos (AUTOS_CHUNK_TOKENS), nas fronteiras
+de parágrafo, e resumidas bloco a bloco (map). Se a junção dos resumos ainda exceder a
+cota, os resumos são resumidos novamente (reduce). Os resumos por bloco ficam em cache
+no próprio registro do índice "autos" (doc_resumos), identificados pelo hash do texto.
+*/
+
+// Piso da cota de cada documento quando o orçamento é repartido entre muitas peças
+const MIN_TOKENS_DOC = 300
+
+// Rodadas de reduce antes do corte final por tokens
+const MAX_RODADAS_REDUCE = 3
+
+// Chamadas simultâneas ao modelo na etapa de map
+const MAX_RESUMOS_PARALELOS = 4
+
+// Separadores usados na divisão em blocos, do mais forte ao mais fraco
+var separadoresBloco = []string{"\n\n", "\n", ". "}
+
+func contaTokens(texto string) int {
+	n, err := ialib.ContaTokens(texto)
+	if err != nil {
+		// Estimativa grosseira, apenas para não interromper o pipeline
+		return len(texto) / 3
+	}
+	return n
+}
+
+// truncaTokens corta o texto em maxTokens tokens, sem dividir caracteres UTF-8.
+func truncaTokens(texto string, maxTokens int) string {
+	parte, err := ialib.TruncaTokens(texto, maxTokens)
+	if err != nil {
+		logger.Log.Warningf("Erro ao truncar texto por tokens: %v", err)
+		return strings.ToValidUTF8(texto[:min(len(texto), maxTokens*3)], "")
+	}
+	if len(parte) < len(texto) {
+		parte += "...(truncado)"
+	}
+	return parte
+}
+
+func tokensMensagens(msgs ialib.MsgGpt) int {
+	total := 0
+	for _, m := range msgs.Messages {
+		total += contaTokens(m.Text) + ialib.OPENAI_TOKENS_AJUSTE
+	}
+	return total
+}
+
+/*
+dividirEmBlocos divide o texto em blocos de até maxTokens tokens, preferindo as fronteiras
+de parágrafo; só recorre a quebras de linha, frases e, por fim, ao corte por tokens
+quando um trecho isolado excede o limite.
+*/
+func dividirEmBlocos(texto string, maxTokens int, seps ...string) []string {
+	if contaTokens(texto) <= maxTokens {
+		return []string{texto}
+	}
+	if len(seps) == 0 {
+		return cortarPorTokens(texto, maxTokens)
+	}
+
+	sep := seps[0]
+	partes := strings.Split(texto, sep)
+	if len(partes) == 1 {
+		return dividirEmBlocos(texto, maxTokens, seps[1:]...)
+	}
+
+	var (
+		blocos     []string
+		atual      strings.Builder
+		tokAtual   int
+		tokSep     = contaTokens(sep)
+		descarrega = func() {
+			if atual.Len() > 0 {
+				blocos = append(blocos, atual.String())
+				atual.Reset()
+				tokAtual = 0
+			}
+		}
+	)
+	for _, p := range partes {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		tok := contaTokens(p)
+		if tok > maxTokens {
+			descarrega()
+			blocos = append(blocos, dividirEmBlocos(p, maxTokens, seps[1:]...)...)
+			continue
+		}
+		if atual.Len() > 0 && tokAtual+tokSep+tok > maxTokens {
+			descarrega()
+		}
+		if atual.Len() > 0 {
+			atual.WriteString(sep)
+			tokAtual += tokSep
+		}
+		atual.WriteString(p)
+		tokAtual += tok
+	}
+	descarrega()
+	return blocos
+}
+
+func cortarPorTokens(texto string, maxTokens int) []string {
+	blocos, err := ialib.DivideTokens(texto, maxTokens)
+	if err != nil {
+		logger.Log.Warningf("Erro ao dividir texto por tokens: %v", err)
+		return []string{truncaTokens(texto, maxTokens)}
+	}
+	return blocos
+}
+
+// ============================================================
+// Orçamento
+// ============================================================
+
+/*
+orcamentoAutos devolve quantos tokens restam para os autos, descontados do orçamento do
+modelo as mensagens já montadas, as mensagens do usuário e a reserva de saída.
+*/
+func orcamentoAutos(messages ialib.MsgGpt, msgs ialib.MsgGpt, modelo string) int {
+	cfg := config.GlobalConfig
+	return cfg.ContextBudget(modelo) - tokensMensagens(messages) - tokensMensagens(msgs) - cfg.OpenOptionMaxCompletionTokens
+}
+
+/*
+ajustaAutosOrcamento devolve o texto de cada peça (na ordem recebida) de modo que o
+conjunto caiba no orçamento. As peças são percorridas da menor para a maior; cada uma
+recebe como cota o orçamento restante dividido pelas peças ainda não atendidas.
+*/
+func (service *GeneratorType) ajustaAutosOrcamento(
+	ctx context.Context,
+	idCtxt string,
+	autos []consts.ResponseAutosRow,
+	orcamento int,
+) ([]string, error) {
+
+	textos := make([]string, len(autos))
+	tokens := make([]int, len(autos))
+	total := 0
+	for i, doc := range autos {
+		textos[i] = doc.DocJsonRaw
+		tokens[i] = contaTokens(doc.DocJsonRaw) + ialib.OPENAI_TOKENS_AJUSTE
+		total += tokens[i]
+	}
+	if total <= orcamento {
+		return textos, nil
+	}
+
+	logger.Log.Infof("[id_ctxt=%s] Autos excedem o orçamento de contexto (%d > %d tokens): peças extensas serão resumidas",
+		idCtxt, total, orcamento)
+
+	ordem := make([]int, len(autos))
+	for i := range ordem {
+		ordem[i] = i
+	}
+	sort.SliceStable(ordem, func(a, b int) bool { return tokens[ordem[a]] < tokens[ordem[b]] })
+
+	restante := orcamento
+	for k, i := range ordem {
+		cota := restante / (len(ordem) - k)
+		if cota < MIN_TOKENS_DOC {
+			cota = MIN_TOKENS_DOC
+		}
+		if tokens[i] <= cota {
+			restante -= tokens[i]
+			continue
+		}
+
+		texto, err := service.condensaDocumento(ctx, idCtxt, autos[i], cota-ialib.OPENAI_TOKENS_AJUSTE)
+		if err != nil {
+			return nil, err
+		}
+		textos[i] = texto
+		restante -= contaTokens(texto) + ialib.OPENAI_TOKENS_AJUSTE
+		logger.Log.Infof("[id_ctxt=%s] %s - Peça resumida (%d tokens → cota de %d)", idCtxt, autos[i].IdPje, tokens[i], cota)
+	}
+	return textos, nil
+}
+
+// ============================================================
+// Map-reduce
+// ============================================================
+
+/*
+condensaDocumento reduz a peça à cota informada: resumos por bloco (com cache) e, se
+necessário, novas rodadas de resumo sobre os próprios resumos.
+*/
+func (service *GeneratorType) condensaDocumento(
+	ctx context.Context,
+	idCtxt string,
+	doc consts.ResponseAutosRow,
+	cota int,
+) (string, error) {
+
+	cfg := config.GlobalConfig
+
+	resumos, err := service.resumosDocumento(ctx, idCtxt, doc)
+	if err != nil {
+		return "", err
+	}
+	texto := strings.Join(resumos, "\n\n")
+
+	for rodada := 0; rodada < MAX_RODADAS_REDUCE && contaTokens(texto) > cota; rodada++ {
+		blocos := dividirEmBlocos(texto, cfg.AutosChunkTokens, separadoresBloco...)
+		alvo := max(cota/len(blocos), MIN_TOKENS_DOC/2)
+		parciais, err := service.resumirBlocos(ctx, idCtxt, blocos, alvo)
+		if err != nil {
+			return "", err
+		}
+		texto = strings.Join(parciais, "\n\n")
+	}
+	if contaTokens(texto) > cota {
+		texto = truncaTokens(texto, cota)
+	}
+
+	return fmt.Sprintf("RESUMO POR PARTES DO DOCUMENTO %s (%s), em razão da extensão:\n\n%s",
+		doc.IdPje, consts.GetNaturezaDocumento(doc.IdNatu), texto), nil
+}
+
+/*
+resumosDocumento devolve os resumos por bloco da peça. Reaproveita o cache gravado no
+índice "autos" quando o hash confere; do contrário, resume e atualiza o cache.
+*/
+func (service *GeneratorType) resumosDocumento(
+	ctx context.Context,
+	idCtxt string,
+	doc consts.ResponseAutosRow,
+) ([]string, error) {
+
+	cfg := config.GlobalConfig
+	hash := GetHashFromTexto(fmt.Sprintf("%s|%d|%d", doc.DocJsonRaw, cfg.AutosChunkTokens, cfg.AutosResumoTokens))
+
+	if doc.DocResumoHash == hash && len(doc.DocResumos) > 0 {
+		logger.Log.Infof("[id_ctxt=%s] %s - Resumos por bloco recuperados do cache (%d)", idCtxt, doc.IdPje, len(doc.DocResumos))
+		return doc.DocResumos, nil
+	}
+
+	blocos := dividirEmBlocos(doc.DocJsonRaw, cfg.AutosChunkTokens, separadoresBloco...)
+	logger.Log.Infof("[id_ctxt=%s] %s - Documento dividido em %d blocos", idCtxt, doc.IdPje, len(blocos))
+
+	resumos, err := service.resumirBlocos(ctx, idCtxt, blocos, cfg.AutosResumoTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// Falha no cache não impede o uso dos resumos
+	if doc.Id != "" {
+		if err := services.AutosServiceGlobal.AtualizaResumos(doc.Id, hash, resumos); err != nil {
+			logger.Log.Warningf("[id_ctxt=%s] %s - Resumos não gravados em cache: %v", idCtxt, doc.IdPje, err)
+		}
+	}
+	return resumos, nil
+}
+
+// resumirBlocos resume os blocos em paralelo (limitado), preservando a ordem.
+func (service *GeneratorType) resumirBlocos(
+	ctx context.Context,
+	idCtxt string,
+	blocos []string,
+	alvoTokens int,
+) ([]string, error) {
+
+	resumos := make([]string, len(blocos))
+	falhas := make([]error, len(blocos))
+	sem := make(chan struct{}, MAX_RESUMOS_PARALELOS)
+	var wg sync.WaitGroup
+
+	for i, bloco := range blocos {
+		wg.Add(1)
+		go func(i int, bloco string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			resumos[i], falhas[i] = service.resumirBloco(ctx, idCtxt, bloco, i+1, len(blocos), alvoTokens)
+		}(i, bloco)
+	}
+	wg.Wait()
+
+	for _, err := range falhas {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resumos, nil
+}
+
+func (service *GeneratorType) resumirBloco(
+	ctx context.Context,
+	idCtxt string,
+	bloco string,
+	parte int,
+	partes int,
+	alvoTokens int,
+) (string, error) {
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	prompt := fmt.Sprintf(`Você receberá a parte %d de %d de uma peça processual (texto ou JSON extraído).
+	Elabore um resumo fiel dessa parte, com no máximo %d tokens, para subsidiar a análise jurídica do processo.
+
+	Regras obrigatórias:
+	1. Preserve partes, pedidos, fatos, datas, valores, números de documentos e de páginas, provas e fundamentos legais.
+	2. Não interprete, não complete e não presuma informações ausentes.
+	3. Não inclua comentários sobre o próprio resumo.
+	4. Responda apenas com o texto do resumo.`, parte, partes, alvoTokens)
+
+	var messages ialib.MsgGpt
+	messages.AddMessage(ialib.MessageResponseItem{
+		Role: "developer",
+		Text: prompt,
+	})
+	messages.AddMessage(ialib.MessageResponseItem{
+		Role: "user",
+		Text: bloco,
+	})
+
+	// Os resumos são internos: não vão para o streaming acompanhado pelo cliente
+	ctxResumo := ialib.WithStreamDelta(ctx, nil)
+
+	resp, err := services.OpenaiServiceGlobal.SubmitPromptResponse(
+		ialib.WithNaturezaPrompt(ctxResumo, consts.PROMPT_RAG_RESUMO_AUTOS),
+		messages,
+		"",
+		config.GlobalConfig.OpenOptionModel,
+		ialib.REASONING_LOW,
+		ialib.VERBOSITY_LOW,
+	)
+	if err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao resumir bloco %d/%d: %v", idCtxt, parte, partes, err)
+		return "", erros.CreateError("Erro ao resumir bloco dos autos: %s", err.Error())
+	}
+	if resp == nil {
+		return "", erros.CreateError("Resposta nula recebida do modelo")
+	}
+
+	services.ContextoServiceGlobal.UpdateTokenUso(idCtxt, int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens))
+
+	return strings.TrimSpace(resp.OutputText()), nil
+}
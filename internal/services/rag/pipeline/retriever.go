@@ -7,6 +7,7 @@ import (
 
 	"fmt"
 
+	"ocrserver/internal/config"
 	"ocrserver/internal/consts"
 	"ocrserver/internal/opensearch"
 	"ocrserver/internal/services"
@@ -58,7 +59,7 @@ func (service *RetrieverType) RecuperaAutosProcessoAsMessages(ctx context.Contex
 		messages.AddMessage(ialib.MessageResponseItem{
 			Id:   "",
 			Role: "user",
-			Text: msg.DocJsonRaw,
+			Text: textoComReferencia(msg, msg.DocJsonRaw),
 		})
 	}
 
@@ -166,6 +167,51 @@ func (service *RetrieverType) RecuperaAnaliseJuridica(
 	return documentos, nil
 }
 
+/*
+Devolve o registro de respostas às questões controvertidas vinculado à análise jurídica
+informada. Retorna nil (sem erro) quando o usuário ainda não respondeu às questões.
+*/
+func (service *RetrieverType) RecuperaRespostasQuestoes(
+	ctx context.Context,
+	idCtxt string,
+	idAnalise string,
+) (*opensearch.ResponseEventosRow, *RespostasQuestoes, error) {
+
+	eventos, err := services.EventosServiceGlobal.GetEventosByContexto(idCtxt)
+	if err != nil {
+		logger.Log.Errorf("[id_ctxt=%s] Erro ao recuperar eventos do contexto: %v", idCtxt, err)
+		return nil, nil, fmt.Errorf("erro ao recuperar eventos do contexto: %w", err)
+	}
+
+	var (
+		rowSel *opensearch.ResponseEventosRow
+		objSel *RespostasQuestoes
+	)
+	for i, row := range eventos {
+		if row.IdNatu != consts.NATU_DOC_IA_COMPLEMENTO {
+			continue
+		}
+		var obj RespostasQuestoes
+		if err := json.Unmarshal([]byte(row.DocJsonRaw), &obj); err != nil {
+			logger.Log.Warningf("[id_ctxt=%s] Respostas às questões (id=%s) com JSON inválido: %v", idCtxt, row.Id, err)
+			continue
+		}
+		if obj.IdAnalise != idAnalise {
+			continue
+		}
+		// Havendo mais de um registro para a mesma análise, prevalece o mais recente
+		if rowSel == nil || row.DtInc.After(rowSel.DtInc) {
+			rowSel = &eventos[i]
+			objSel = &obj
+		}
+	}
+
+	if rowSel != nil {
+		logger.Log.Infof("[id_ctxt=%s] Respostas às questões controvertidas recuperadas (id=%s).", idCtxt, rowSel.Id)
+	}
+	return rowSel, objSel, nil
+}
+
 func (service *RetrieverType) RecuperaDoutrinaRAG_(ctx context.Context, idCtxt string) ([]opensearch.ResponseModelos, error) {
 
 	//***   Recupera pré-análise
@@ -265,10 +311,11 @@ func (service *RetrieverType) RecuperaSumulaRAG(ctx context.Context, idCtxt stri
 	return docs, nil
 }
 
-// RecuperaBaseConhecimentos executa buscas semânticas concorrentes controladas
-// para cada tema jurídico do campo RAG identificado durante a análise jurídica
-// pelo modelo de IA. O campo  "DocJsonRaw" possui o objeto JSON gerado e con-
-// verte para um objeto Go.
+// RecuperaBaseConhecimentos executa buscas concorrentes controladas para cada tema
+// jurídico do campo RAG identificado durante a análise jurídica pelo modelo de IA.
+// O campo  "DocJsonRaw" possui o objeto JSON gerado e converte para um objeto Go.
+// Cada busca é híbrida (BM25 + kNN, ver RAG_BUSCA_MODO), restrita à classe/assunto
+// do contexto, e passa pelo reranker antes de manter os primeiros por tema.
 // Usa semáforo para limitar goroutines simultâneas e realiza deduplicação global ao final.
 func (service *RetrieverType) RecuperaBaseConhecimentos(
 	ctx context.Context,
@@ -289,6 +336,9 @@ func (service *RetrieverType) RecuperaBaseConhecimentos(
 		return nil, nil
 	}
 
+	filtro := filtroContexto(idCtxt)
+	reranker := NewReranker(config.GlobalConfig.RagReranker, config.GlobalConfig.RagRerankLimiar)
+
 	// 3️⃣ Configuração de concorrência
 	maxConcurrent := 10 // limite de goroutines simultâneas
 	sema := make(chan struct{}, maxConcurrent)
@@ -320,17 +370,20 @@ func (service *RetrieverType) RecuperaBaseConhecimentos(
 				return
 			}
 
-			// 🔹 Executa consulta semântica no índice base_doc_embedding
-			docs, err := opensearch.BaseIndexGlobal.ConsultaSemantica(
-				vec32,
-				//opensearch.GetNaturezaModelo(opensearch.MODELO_NATUREZA_SENTENCA),
-				"",
-			)
+			// 🔹 Executa a consulta no índice base_doc_embedding
+			docs, err := consultaBase(queryText, vec32, filtro)
 			if err != nil {
 				logger.Log.Errorf("Erro ao consultar base RAG (%s): %v", item.Tema, err)
 				return
 			}
 
+			// 🔹 Reordena por pertinência e descarta os trechos abaixo do limiar
+			docs, err = reranker.Reordena(ctx, idCtxt, queryText, docs)
+			if err != nil {
+				logger.Log.Errorf("Erro ao reordenar base RAG (%s): %v", item.Tema, err)
+				return
+			}
+
 			if len(docs) == 0 {
 				logger.Log.Infof("Nenhum documento retornado para tema '%s'", item.Tema)
 				return
@@ -387,3 +440,25 @@ func (service *RetrieverType) RecuperaBaseConhecimentos(
 
 	return resultadosUnicos, nil
 }
+
+// consultaBase executa a busca híbrida ou, com RAG_BUSCA_MODO=semantica, apenas a vetorial.
+func consultaBase(texto string, vector []float32, filtro opensearch.FiltroBase) ([]opensearch.ResponseBaseRow, error) {
+	cfg := config.GlobalConfig
+	if cfg.RagBuscaModo == "semantica" {
+		return opensearch.BaseIndexGlobal.ConsultaVetorial(vector, filtro, cfg.RagCandidatos)
+	}
+	return opensearch.BaseIndexGlobal.ConsultaHibrida(texto, vector, filtro, cfg.RagCandidatos, cfg.RagRRFK)
+}
+
+// filtroContexto devolve a classe e o assunto do processo para filtrar a base de conhecimentos.
+func filtroContexto(idCtxt string) opensearch.FiltroBase {
+	if !config.GlobalConfig.RagFiltroClasseAssunto {
+		return opensearch.FiltroBase{}
+	}
+	rows, err := services.ContextoServiceGlobal.SelectContextoByIdCtxt(idCtxt)
+	if err != nil || len(rows) == 0 {
+		logger.Log.Warningf("[id_ctxt=%s] Contexto não localizado; base de conhecimentos sem filtro de classe/assunto: %v", idCtxt, err)
+		return opensearch.FiltroBase{}
+	}
+	return opensearch.FiltroBase{Classe: rows[0].Classe, Assunto: rows[0].Assunto}
+}
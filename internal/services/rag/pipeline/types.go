@@ -1,5 +1,7 @@
 package pipeline
 
+import "ocrserver/internal/services/rag/parsers"
+
 // Tipo que representa o tipo básico para identificar os eventos na comunicação com
 // o modelo de IA
 type TipoEvento struct {
@@ -100,8 +102,10 @@ type AnaliseJuridicaIA struct {
 	} `json:"rag"`
 
 	// Campo opcional para armazenamento dos vetores de embeddings (gerados posteriormente)
-	RagEmbedding []float64 `json:"rag_embedding"`
-	DataGeracao  string    `json:"data_geracao"`
+	RagEmbedding []float64 `json:"rag_embedding" schema:"-"`
+	DataGeracao  string    `json:"data_geracao" schema:"-"`
+
+	ValidacaoCitacoes *ValidacaoCitacoes `json:"validacao_citacoes,omitempty" schema:"-"`
 }
 
 // SENTENÇA
@@ -115,7 +119,9 @@ type MinutaSentenca struct {
 	Fundamentacao *Fundamentacao `json:"fundamentacao,omitempty"`
 	Dispositivo   *Dispositivo   `json:"dispositivo,omitempty"`
 	Observacoes   []string       `json:"observacoes,omitempty"`
-	DataGeracao   string         `json:"data_geracao"`
+	DataGeracao   string         `json:"data_geracao" schema:"-"`
+
+	ValidacaoCitacoes *ValidacaoCitacoes `json:"validacao_citacoes,omitempty" schema:"-"`
 }
 
 type Processo struct {
@@ -156,41 +162,102 @@ type Assinatura struct {
 	Cargo *string `json:"cargo,omitempty"`
 }
 
-//*****   SENTENÇA - Extraída dos Autos do Processo.
+// DECISÃO INTERLOCUTÓRIA
+// Tipo que representa a estrutura do objeto JSON devolvido pelo modelo de IA
+// quando conclui uma minuta de decisão interlocutória (tutela, saneamento etc.)
+type MinutaDecisao struct {
+	Tipo          *TipoEvento         `json:"tipo,omitempty"`
+	Processo      *Processo           `json:"processo,omitempty"`
+	Partes        *TPartes            `json:"partes,omitempty"`
+	Relatorio     []string            `json:"relatorio,omitempty"`
+	Questoes      []QuestaoDecisao    `json:"questoes,omitempty"`
+	Fundamentacao *Fundamentacao      `json:"fundamentacao,omitempty"`
+	Dispositivo   *DispositivoDecisao `json:"dispositivo,omitempty"`
+	Observacoes   []string            `json:"observacoes,omitempty"`
+	DataGeracao   string              `json:"data_geracao" schema:"-"`
+
+	ValidacaoCitacoes *ValidacaoCitacoes `json:"validacao_citacoes,omitempty" schema:"-"`
+}
 
+// Pedido ou questão incidental apreciada na decisão
+type QuestaoDecisao struct {
+	Pedido    string `json:"pedido"`
+	Resultado string `json:"resultado"` // "deferido", "indeferido", "parcialmente deferido"
+}
+
+type DispositivoDecisao struct {
+	Decisao       *string        `json:"decisao,omitempty"`
+	Determinacoes []Determinacao `json:"determinacoes,omitempty"`
+}
+
+// DESPACHO
 // Tipo que representa a estrutura do objeto JSON devolvido pelo modelo de IA
-// quando ele faz a extração de uma sentença judicial dos autos do processo.
-// Essa estrutura é útil para adicionar a sentença à Base de Conhecimentos.
-type SentencaAutos struct {
-	Tipo *struct {
-		Key         int    `json:"key"`
-		Description string `json:"description"`
-	} `json:"tipo"`
+// quando conclui uma minuta de despacho (mero expediente, sem conteúdo decisório)
+type MinutaDespacho struct {
+	Tipo          *TipoEvento    `json:"tipo,omitempty"`
+	Processo      *Processo      `json:"processo,omitempty"`
+	Finalidade    string         `json:"finalidade,omitempty"` // ex.: "intimação para réplica"
+	Determinacoes []Determinacao `json:"determinacoes,omitempty"`
+	Observacoes   []string       `json:"observacoes,omitempty"`
+	DataGeracao   string         `json:"data_geracao" schema:"-"`
+}
 
-	Processo       string     `json:"processo"`
-	IdPje          string     `json:"id_pje"`
-	AssinaturaData string     `json:"assinatura_data"`
-	AssinaturaPor  string     `json:"assinatura_por"`
-	Metadados      *metadados `json:"metadados"`
+// Providência determinada pelo juízo (decisões e despachos)
+type Determinacao struct {
+	Providencia  string  `json:"providencia"`
+	Destinatario *string `json:"destinatario,omitempty"` // parte, secretaria, perito, MP...
+	Prazo        *string `json:"prazo,omitempty"`
+}
+
+// COMPLEMENTO
+// Respostas do usuário às questões controvertidas de uma análise jurídica. É registrado
+// como evento (NATU_DOC_IA_COMPLEMENTO) vinculado à análise pelo campo "id_analise" e
+// consultado na verificação que antecede a minuta de sentença.
+type RespostasQuestoes struct {
+	Tipo            *TipoEvento       `json:"tipo,omitempty"`
+	IdAnalise       string            `json:"id_analise"`
+	Questoes        []RespostaQuestao `json:"questoes"`
+	Pendentes       []string          `json:"pendentes,omitempty"` // faltantes apontados na última verificação
+	DataAtualizacao string            `json:"data_atualizacao"`
+}
 
-	Questoes    []questao   `json:"questoes"`
-	Dispositivo dispositivo `json:"dispositivo"`
+type RespostaQuestao struct {
+	Descricao string `json:"descricao,omitempty"`
+	Pergunta  string `json:"pergunta"`
+	Resposta  string `json:"resposta,omitempty"`
 }
 
-type metadados struct {
-	Classe  string   `json:"classe"`
-	Assunto string   `json:"assunto"`
-	Juizo   string   `json:"juizo"`
-	Partes  *TPartes `json:"partes"`
+// Resposta identificada pelo modelo na mensagem do usuário (índice da pergunta, a partir de 1)
+type RespostaExtraida struct {
+	Indice   int    `json:"indice"`
+	Resposta string `json:"resposta"`
 }
 
-type questao struct {
-	Tipo       string   `json:"tipo"` // "preliminar" ou "mérito"
-	Tema       string   `json:"tema"`
-	Paragrafos []string `json:"paragrafos"`
-	Decisao    string   `json:"decisao"`
+// CITAÇÕES
+// Resultado da conferência das citações (acórdãos, processos e súmulas) de uma minuta
+// ou análise. É gravado no próprio evento e devolvido na resposta do pipeline.
+type ValidacaoCitacoes struct {
+	Citacoes       []CitacaoVerificada `json:"citacoes"`
+	Verificadas    int                 `json:"verificadas"`
+	NaoVerificadas int                 `json:"nao_verificadas"`
+	Contraditas    int                 `json:"contraditas"`
+	DataValidacao  string              `json:"data_validacao"`
 }
 
-type dispositivo struct {
-	Paragrafos []string `json:"paragrafos"`
+type CitacaoVerificada struct {
+	Tipo       string `json:"tipo"` // "acordao", "processo" ou "sumula"
+	Tribunal   string `json:"tribunal,omitempty"`
+	Referencia string `json:"referencia"`           // como citada na minuta
+	Origem     string `json:"origem"`               // campo em que a citação aparece
+	Situacao   string `json:"situacao"`             // "verificada", "nao_verificada" ou "contradita"
+	Fonte      string `json:"fonte,omitempty"`      // "base_conhecimentos", "autos" ou "modelos"
+	Observacao string `json:"observacao,omitempty"` // motivo da contradição
 }
+
+//*****   SENTENÇA - Extraída dos Autos do Processo.
+
+// Tipo que representa a estrutura do objeto JSON devolvido pelo modelo de IA
+// quando ele faz a extração de uma sentença judicial dos autos do processo.
+// Essa estrutura é útil para adicionar a sentença à Base de Conhecimentos.
+// Definida no pacote parsers, que valida o JSON da autuação antes da gravação em "autos".
+type SentencaAutos = parsers.SentencaAutos
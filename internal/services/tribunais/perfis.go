@@ -0,0 +1,434 @@
+/*
+---------------------------------------------------------------------------------------
+File: perfis.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Perfis de tribunal usados na importação dos autos do PJe. Cada tribunal (e
+cada instância do PJe) tem o seu rodapé, a sua URL de consulta, o marcador que identifica
+o documento em cada página ("Num. <id> - Pág. <n>") e os nomes dos tipos documentais do
+índice. O perfil reúne essas expressões; os perfis padrão (perfisPadrao.go) podem ser
+complementados ou substituídos pelo arquivo JSON indicado em PERFIS_TRIBUNAIS_ARQUIVO.
+---------------------------------------------------------------------------------------
+*/
+package tribunais
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/consts"
+	"ocrserver/internal/utils/logger"
+)
+
+// Perfis especiais
+const (
+	PERFIL_AUTO     = "auto" // detecção automática pelo texto do PDF
+	PERFIL_GENERICO = "pje"  // usado quando nenhum perfil é detectado
+)
+
+// quantidade de páginas examinadas na detecção automática
+const maxPaginasDeteccao = 30
+
+// Signatário e data/hora na linha de assinatura ("... por: NOME - dd/mm/aaaa hh:mm:ss")
+var reDadosAssinatura = regexp.MustCompile(`(?i)\bpor:?\s*(.+?)(?:\s*[-–,]\s*(?:em\s+)?(\d{2}/\d{2}/\d{4})(?:,?\s*(?:[àa]s\s+)?(\d{2}:\d{2}(?::\d{2})?))?)?\s*$`)
+
+// Substituicao é uma troca por expressão regular aplicada a cada linha (ex.: "pje 1 grau" → "pje1grau")
+type Substituicao struct {
+	De   string `json:"de"`
+	Para string `json:"para"`
+}
+
+/*
+PerfilJSON é o formato do perfil no arquivo de configuração. Campos omitidos herdam os
+valores do perfil genérico ("pje"); os sinônimos são somados aos dele. As expressões de "deteccao" são aplicadas ao texto
+das páginas sem espaços e em minúsculas (o leiaute do PDF quebra as URLs).
+*/
+type PerfilJSON struct {
+	Id              string            `json:"id"`
+	Nome            string            `json:"nome"`
+	Deteccao        []string          `json:"deteccao,omitempty"`
+	Rodape          []string          `json:"rodape,omitempty"`        // blocos removidos do texto dos documentos
+	LinhasRodape    []string          `json:"linhas_rodape,omitempty"` // linhas do rodapé/tarja (ignoradas na detecção de páginas digitalizadas)
+	Normalizacoes   []Substituicao    `json:"normalizacoes,omitempty"`
+	MarcadorId      string            `json:"marcador_id,omitempty"`      // um grupo de captura: o id do documento
+	LinhaPagina     string            `json:"linha_pagina,omitempty"`     // (?m), grupo 1: a linha de numeração da página
+	LinhaAssinatura string            `json:"linha_assinatura,omitempty"` // (?m), grupo 1: a linha da assinatura eletrônica
+	LinhaIndice     string            `json:"linha_indice,omitempty"`     // três grupos: id, data, restante da linha
+	TamanhoId       *int              `json:"tamanho_id,omitempty"`       // dígitos finais do id usados como id_pje (0: id completo)
+	Sinonimos       map[string]string `json:"sinonimos,omitempty"`        // tipo no índice do tribunal → denominação conhecida
+}
+
+type normalizacao struct {
+	de   *regexp.Regexp
+	para string
+}
+
+// Perfil é o perfil de tribunal com as expressões já compiladas
+type Perfil struct {
+	Id              string
+	Nome            string
+	deteccao        []*regexp.Regexp
+	rodape          []*regexp.Regexp
+	linhasRodape    []*regexp.Regexp
+	normalizacoes   []normalizacao
+	marcadorId      *regexp.Regexp
+	linhaPagina     *regexp.Regexp
+	linhaAssinatura *regexp.Regexp
+	linhaIndice     *regexp.Regexp
+	tamanhoId       int
+	sinonimos       map[string]string
+}
+
+type PerfisType struct {
+	perfis []*Perfil // na ordem de preferência da detecção; o genérico fica por último
+	porId  map[string]*Perfil
+}
+
+var PerfisGlobal *PerfisType
+var onceInitPerfis sync.Once
+
+// InitPerfis carrega os perfis padrão e os do arquivo PERFIS_TRIBUNAIS_ARQUIVO
+func InitPerfis(cfg *config.Config) {
+	onceInitPerfis.Do(func() {
+		arquivo := ""
+		if cfg != nil {
+			arquivo = cfg.PerfisTribunaisArquivo
+		}
+		perfis, err := NewPerfis(arquivo)
+		if err != nil {
+			logger.Log.Errorf("Erro nos perfis de tribunal (%s), usando os padrões: %v", arquivo, err)
+			perfis, _ = NewPerfis("")
+		}
+		PerfisGlobal = perfis
+		logger.Log.Infof("Perfis de tribunal configurados: %s", strings.Join(perfis.Ids(), ", "))
+		if cfg != nil {
+			if _, err := perfis.Perfil(cfg.PerfilTribunal); err != nil {
+				logger.Log.Errorf("PERFIL_TRIBUNAL inválido, os uploads sem perfil vão falhar: %v", err)
+			}
+		}
+	})
+}
+
+// Global devolve os perfis globais, inicializando-os com a configuração corrente se preciso
+func Global() *PerfisType {
+	if PerfisGlobal == nil {
+		InitPerfis(config.GlobalConfig)
+	}
+	return PerfisGlobal
+}
+
+// NewPerfis monta os perfis padrão, acrescidos (ou substituídos, pelo id) pelos do arquivo
+func NewPerfis(arquivo string) (*PerfisType, error) {
+	defs := perfisPadrao()
+	if arquivo != "" {
+		b, err := os.ReadFile(arquivo)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler o arquivo: %w", err)
+		}
+		var doArquivo []PerfilJSON
+		if err := json.Unmarshal(b, &doArquivo); err != nil {
+			return nil, fmt.Errorf("JSON inválido: %w", err)
+		}
+		// os perfis do arquivo têm preferência na detecção
+		defs = append(doArquivo, defs...)
+	}
+
+	var generico PerfilJSON
+	for _, d := range defs {
+		if d.Id == PERFIL_GENERICO {
+			generico = d
+			break
+		}
+	}
+
+	obj := &PerfisType{porId: map[string]*Perfil{}}
+	for _, d := range defs {
+		d.Id = strings.ToLower(strings.TrimSpace(d.Id))
+		if d.Id == "" || d.Id == PERFIL_AUTO {
+			return nil, fmt.Errorf("perfil sem id ou com id reservado (%q)", d.Id)
+		}
+		if _, repetido := obj.porId[d.Id]; repetido {
+			continue // o primeiro (arquivo) prevalece sobre o padrão
+		}
+		p, err := compila(d, generico)
+		if err != nil {
+			return nil, fmt.Errorf("perfil %s: %w", d.Id, err)
+		}
+		obj.porId[p.Id] = p
+		if p.Id != PERFIL_GENERICO {
+			obj.perfis = append(obj.perfis, p)
+		}
+	}
+	if g, ok := obj.porId[PERFIL_GENERICO]; ok {
+		obj.perfis = append(obj.perfis, g)
+	}
+	return obj, nil
+}
+
+// compila valida as expressões do perfil; campos vazios herdam do perfil genérico
+func compila(d PerfilJSON, g PerfilJSON) (*Perfil, error) {
+	if len(d.Rodape) == 0 {
+		d.Rodape = g.Rodape
+	}
+	if len(d.LinhasRodape) == 0 {
+		d.LinhasRodape = g.LinhasRodape
+	}
+	if len(d.Normalizacoes) == 0 {
+		d.Normalizacoes = g.Normalizacoes
+	}
+	if d.MarcadorId == "" {
+		d.MarcadorId = g.MarcadorId
+	}
+	if d.LinhaPagina == "" {
+		d.LinhaPagina = g.LinhaPagina
+	}
+	if d.LinhaAssinatura == "" {
+		d.LinhaAssinatura = g.LinhaAssinatura
+	}
+	if d.LinhaIndice == "" {
+		d.LinhaIndice = g.LinhaIndice
+	}
+	if d.TamanhoId == nil {
+		d.TamanhoId = g.TamanhoId
+	}
+
+	p := &Perfil{Id: d.Id, Nome: d.Nome, sinonimos: map[string]string{}}
+	if d.TamanhoId != nil {
+		p.tamanhoId = *d.TamanhoId
+	}
+	if p.Nome == "" {
+		p.Nome = d.Id
+	}
+	var err error
+	if p.deteccao, err = compilaLista(d.Deteccao); err != nil {
+		return nil, fmt.Errorf("deteccao: %w", err)
+	}
+	if p.rodape, err = compilaLista(d.Rodape); err != nil {
+		return nil, fmt.Errorf("rodape: %w", err)
+	}
+	if p.linhasRodape, err = compilaLista(d.LinhasRodape); err != nil {
+		return nil, fmt.Errorf("linhas_rodape: %w", err)
+	}
+	for _, n := range d.Normalizacoes {
+		re, err := regexp.Compile(n.De)
+		if err != nil {
+			return nil, fmt.Errorf("normalizacoes: %w", err)
+		}
+		p.normalizacoes = append(p.normalizacoes, normalizacao{de: re, para: n.Para})
+	}
+	if p.marcadorId, err = regexp.Compile(d.MarcadorId); err != nil {
+		return nil, fmt.Errorf("marcador_id: %w", err)
+	}
+	if p.marcadorId.NumSubexp() < 1 {
+		return nil, fmt.Errorf("marcador_id deve ter um grupo de captura com o id do documento")
+	}
+	if p.linhaPagina, err = regexp.Compile(d.LinhaPagina); err != nil {
+		return nil, fmt.Errorf("linha_pagina: %w", err)
+	}
+	if p.linhaAssinatura, err = regexp.Compile(d.LinhaAssinatura); err != nil {
+		return nil, fmt.Errorf("linha_assinatura: %w", err)
+	}
+	if p.linhaPagina.NumSubexp() < 1 || p.linhaAssinatura.NumSubexp() < 1 {
+		return nil, fmt.Errorf("linha_pagina e linha_assinatura devem ter um grupo de captura com a linha")
+	}
+	if p.linhaIndice, err = regexp.Compile(d.LinhaIndice); err != nil {
+		return nil, fmt.Errorf("linha_indice: %w", err)
+	}
+	if p.linhaIndice.NumSubexp() < 3 {
+		return nil, fmt.Errorf("linha_indice deve ter três grupos de captura (id, data, restante)")
+	}
+	// sinônimos: os do perfil genérico, acrescidos (ou substituídos) pelos do próprio perfil
+	for _, sin := range []map[string]string{g.Sinonimos, d.Sinonimos} {
+		for tipo, desc := range sin {
+			p.sinonimos[consts.NormalizaTipo(tipo)] = desc
+		}
+	}
+	return p, nil
+}
+
+func compilaLista(padroes []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(padroes))
+	for _, s := range padroes {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// Ids devolve os ids dos perfis, na ordem de preferência
+func (obj *PerfisType) Ids() []string {
+	ids := make([]string, len(obj.perfis))
+	for i, p := range obj.perfis {
+		ids[i] = p.Id
+	}
+	return ids
+}
+
+// Lista devolve os perfis configurados, na ordem de preferência
+func (obj *PerfisType) Lista() []*Perfil {
+	return obj.perfis
+}
+
+// Perfil devolve o perfil pelo id; "auto" ou vazio devolvem nil (detecção automática)
+func (obj *PerfisType) Perfil(id string) (*Perfil, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" || id == PERFIL_AUTO {
+		return nil, nil
+	}
+	p, ok := obj.porId[id]
+	if !ok {
+		return nil, fmt.Errorf("perfil de tribunal desconhecido: %q (disponíveis: %s)", id, strings.Join(obj.Ids(), ", "))
+	}
+	return p, nil
+}
+
+/*
+Detecta escolhe o perfil pelo texto das primeiras páginas: vence o perfil com mais
+ocorrências das suas expressões de detecção. Sem ocorrência, devolve o perfil genérico.
+*/
+func (obj *PerfisType) Detecta(paginas []string) *Perfil {
+	texto := textoCompacto(paginas)
+
+	var melhor *Perfil
+	maior := 0
+	for _, p := range obj.perfis {
+		if p.Id == PERFIL_GENERICO {
+			continue
+		}
+		if n := p.ocorrencias(texto); n > maior {
+			melhor, maior = p, n
+		}
+	}
+	if melhor != nil {
+		return melhor
+	}
+	return obj.porId[PERFIL_GENERICO]
+}
+
+// textoCompacto junta as primeiras páginas sem espaços e em minúsculas
+func textoCompacto(paginas []string) string {
+	var compacto strings.Builder
+	for i, t := range paginas {
+		if i >= maxPaginasDeteccao {
+			break
+		}
+		for _, r := range t {
+			if !unicode.IsSpace(r) {
+				compacto.WriteRune(unicode.ToLower(r))
+			}
+		}
+		compacto.WriteByte('\n')
+	}
+	return compacto.String()
+}
+
+// ============================================================
+// Operações do perfil
+// ============================================================
+
+// Ocorrencias conta as ocorrências das expressões de detecção do perfil nas primeiras páginas
+func (p *Perfil) Ocorrencias(paginas []string) int {
+	return p.ocorrencias(textoCompacto(paginas))
+}
+
+func (p *Perfil) ocorrencias(compacto string) int {
+	n := 0
+	for _, re := range p.deteccao {
+		n += len(re.FindAllStringIndex(compacto, -1))
+	}
+	return n
+}
+
+// NormalizaLinha aplica as correções específicas do tribunal (ex.: "pje 1 grau" → "pje1grau")
+func (p *Perfil) NormalizaLinha(linha string) string {
+	for _, n := range p.normalizacoes {
+		linha = n.de.ReplaceAllString(linha, n.para)
+	}
+	return linha
+}
+
+// IdDocumento extrai o id do documento do marcador de página; "" se a linha não o contém
+func (p *Perfil) IdDocumento(linha string) string {
+	if m := p.marcadorId.FindStringSubmatch(linha); len(m) >= 2 {
+		return m[1]
+	}
+	return ""
+}
+
+// IdPje reduz o id do documento aos dígitos finais usados como id_pje
+func (p *Perfil) IdPje(id string) string {
+	if p.tamanhoId > 0 && len(id) > p.tamanhoId {
+		return id[len(id)-p.tamanhoId:]
+	}
+	return id
+}
+
+// RemoveRodape retira do texto os blocos de rodapé do tribunal
+func (p *Perfil) RemoveRodape(texto string) string {
+	for _, re := range p.rodape {
+		texto = re.ReplaceAllString(texto, "")
+	}
+	return texto
+}
+
+// EhLinhaRodape indica se a linha pertence ao rodapé/tarja aplicados pelo PJe
+func (p *Perfil) EhLinhaRodape(linha string) bool {
+	for _, re := range p.linhasRodape {
+		if re.MatchString(linha) {
+			return true
+		}
+	}
+	return false
+}
+
+// LinhaPagina devolve a expressão da linha de numeração das páginas ("Num. ... - Pág. ...")
+func (p *Perfil) LinhaPagina() *regexp.Regexp {
+	return p.linhaPagina
+}
+
+// LinhaAssinatura devolve a expressão da linha de assinatura eletrônica
+func (p *Perfil) LinhaAssinatura() *regexp.Regexp {
+	return p.linhaAssinatura
+}
+
+/*
+Assinatura devolve o signatário e a data/hora ("dd/mm/aaaa hh:mm:ss") da primeira linha
+de assinatura eletrônica do texto; vazios se não houver.
+*/
+func (p *Perfil) Assinatura(texto string) (signatario, dataHora string) {
+	m := p.linhaAssinatura.FindStringSubmatch(texto)
+	if len(m) < 2 {
+		return "", ""
+	}
+	d := reDadosAssinatura.FindStringSubmatch(strings.TrimSpace(m[1]))
+	if d == nil {
+		return "", ""
+	}
+	return strings.TrimSpace(d[1]), strings.TrimSpace(d[2] + " " + d[3])
+}
+
+// LinhaIndice reconhece a linha do índice de documentos, devolvendo id, data e o restante
+func (p *Perfil) LinhaIndice(linha string) (id, data, resto string, ok bool) {
+	m := p.linhaIndice.FindStringSubmatch(linha)
+	if len(m) < 4 {
+		return "", "", "", false
+	}
+	return m[1], m[2], m[3], true
+}
+
+// CodigoNatureza converte o tipo documental do índice na natureza, aplicando os sinônimos do tribunal
+func (p *Perfil) CodigoNatureza(tipo string) int {
+	if desc, ok := p.sinonimos[consts.NormalizaTipo(tipo)]; ok {
+		tipo = desc
+	}
+	return consts.GetCodigoNatureza(tipo)
+}
@@ -0,0 +1,171 @@
+/*
+---------------------------------------------------------------------------------------
+File: perfisPadrao.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Perfis de tribunal embutidos no servidor. O "pje" é o perfil genérico do
+PJe nacional (CNJ), cujo rodapé é comum aos TJs e TRFs; os demais acrescentam a
+detecção e as correções próprias do tribunal. O perfil "tjce" reproduz o tratamento
+original da importação. Os perfis "esaj", "eproc" e "projudi" atendem aos importadores
+desses sistemas (pacote importadores): carimbos removidos do texto e nomes dos tipos.
+---------------------------------------------------------------------------------------
+*/
+package tribunais
+
+// expressões comuns do PJe nacional
+const (
+	rodapePJe          = `(?m)Este documento foi gerado pelo usuário\s+[\d*.\-]+ em \d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2}\nNúmero do documento:\s*\d+\nhttps?://[^\n]+\n?`
+	marcadorIdPJe      = `Num\.?\s*(\d{6,12})\s*[-–—]\s*Pág\.?`
+	linhaPaginaPJe     = `(?m)^(Num\.\s*\d+\s*-\s*Pág\.\s*\d+)$`
+	linhaAssinaturaPJe = `(?m)^(Assinado eletronicamente por:[^\n]+)$`
+	linhaIndicePJe     = `^[\f\t\r ]*(\d+)\s+(\d{2}/\d{2}/\d{4})\s+(.*)$`
+)
+
+// o id_pje guarda os 9 dígitos finais do id do documento
+var tamanhoIdPJe = 9
+
+var linhasRodapePJe = []string{
+	`(?i)este documento foi gerado pelo usu`,
+	`(?i)n[úu]mero do documento`,
+	`(?i)assinado eletronicamente por`,
+	`(?i)https?\s*:`,
+	`(?i)num\.?\s*\d{6,12}\s*[-–—]\s*p[áa]g`,
+}
+
+// carimbos e rodapés dos outros sistemas processuais
+const (
+	carimboESAJ      = `(?is)Este documento [ée] c[óo]pia do original.{0,900}?e\s+c[óo]digo\s+[0-9A-Za-z]+\.?`
+	folhaESAJ        = `(?im)^[ \t]*fls\.?[ \t]*\d+[ \t]*$`
+	assinaturaEproc  = `(?is)Documento eletr[ôo]nico assinado por.{0,900}?c[óo]digo CRC\s+[0-9a-fA-F]+\.?`
+	adicionaisEproc  = `(?is)Informa[çc][õo]es adicionais da assinatura:.{0,300}?Data e Hora:\s*[\d/]+,?\s*[\d:]+`
+	processoEproc    = `(?m)^[ \t]*\d{7}-\d{2}\.\d{4}\.\d\.\d{2}\.\d{4}[ \t]+\d+[ \t]*\.V\d+[ \t]*$`
+	carimboProjudi   = `(?is)Documento assinado digitalmente, conforme MP.{0,400}?Identificador:\s*(?:[A-Z0-9]{5}\s?){3,5}`
+	cabecalhoProjudi = `(?im)^[ \t]*PROJUDI\s*-\s*Processo:[^\n]*$`
+)
+
+func perfisPadrao() []PerfilJSON {
+	return []PerfilJSON{
+		{
+			Id:       "tjce",
+			Nome:     "TJCE - PJe 1º e 2º graus",
+			Deteccao: []string{`pje\.tjce\.jus\.br`},
+			Normalizacoes: []Substituicao{
+				{De: `pje\s+([12])`, Para: `pje$1`},
+				{De: `pje([12])\s+grau`, Para: `pje${1}grau`},
+			},
+		},
+		{
+			Id:       "trf",
+			Nome:     "Tribunais Regionais Federais - PJe",
+			Deteccao: []string{`pje\d?g?\.trf\d\.jus\.br`},
+			Normalizacoes: []Substituicao{
+				{De: `pje\s+([12])\s*g\b`, Para: `pje${1}g`},
+			},
+		},
+		{
+			Id:       "esaj",
+			Nome:     "e-SAJ - pasta digital",
+			Deteccao: []string{`esaj\.tj\w+\.jus\.br`, `pastadigital`, `abrirconferenciadocumento`},
+			Rodape:   []string{carimboESAJ, folhaESAJ},
+			LinhasRodape: []string{
+				`(?i)este documento [ée] c[óo]pia do original`,
+				`(?i)para conferir o original`,
+				`(?i)protocolado em|liberado nos autos em`,
+				`(?i)informe o processo`,
+				`(?i)https?\s*:`,
+				`(?i)^\s*fls\.?\s*\d+\s*$`,
+			},
+			Sinonimos: map[string]string{
+				"Ato Ordinatório":          "Despacho Ordinatório",
+				"Decisão Interlocutória":   "Decisão",
+				"Impugnação à Contestação": "Réplica",
+				"Laudo Pericial":           "Laudo",
+				"Termo de Audiência":       "Termo de Audiencia",
+				"Parecer do MP":            "Manifestação do MP",
+			},
+		},
+		{
+			Id:       "eproc",
+			Nome:     "eproc - documentos dos eventos",
+			Deteccao: []string{`eproc`, `verifica\.php`, `c[óo]digoverificador`},
+			Rodape:   []string{assinaturaEproc, adicionaisEproc, processoEproc},
+			LinhasRodape: []string{
+				`(?i)documento eletr[ôo]nico assinado por`,
+				`(?i)c[óo]digo verificador`,
+				`(?i)informa[çc][õo]es adicionais da assinatura`,
+				`(?i)https?\s*:`,
+			},
+			// siglas dos documentos nos nomes dos arquivos (EVENTO1_INIC1.pdf)
+			Sinonimos: map[string]string{
+				"INIC":       "Petição Inicial",
+				"EMENDAINIC": "Emenda à Inicial",
+				"CONT":       "Contestação",
+				"CONTES":     "Contestação",
+				"REPLICA":    "Réplica",
+				"DESP":       "Despacho",
+				"DESPADEC":   "Decisão",
+				"DEC":        "Decisão",
+				"SENT":       "Sentença",
+				"APELACAO":   "Apelação",
+				"CONTRAZ":    "Contra-razões",
+				"CONTRAZAP":  "Contra-razões",
+				"EMBDECL":    "Embargos de Declaração",
+				"PARECER":    "Manifestação do MP",
+				"PROMOCAO":   "Manifestação do MP",
+				"LAUDO":      "Laudo",
+				"LAUDOPERIC": "Laudo de Perícia",
+				"TERMOAUD":   "Termo de Audiencia",
+				"ATA":        "Ata de Audiência",
+				"CERT":       "Certidão",
+				"PET":        "Petição",
+				"PROC":       "Procuração",
+				"CONTR":      "Contrato",
+				"ROL":        "Rol de Testemunhas",
+				"ALEGFIN":    "Alegações Finais",
+				"MEMORIAIS":  "Memoriais",
+				"INF":        "Informações",
+			},
+		},
+		{
+			Id:       "projudi",
+			Nome:     "Projudi - arquivos das movimentações",
+			Deteccao: []string{`projudi`},
+			Rodape:   []string{carimboProjudi, cabecalhoProjudi},
+			LinhasRodape: []string{
+				`(?i)documento assinado digitalmente, conforme mp`,
+				`(?i)valida[çc][ãa]o deste em`,
+				`(?i)identificador:`,
+				`(?i)^\s*projudi\s*-\s*processo:`,
+			},
+			Sinonimos: map[string]string{
+				"Inicial":                     "Petição Inicial",
+				"Decisão Interlocutória":      "Decisão",
+				"Despacho de Mero Expediente": "Despacho",
+				"Impugnação à Contestação":    "Réplica",
+				"Laudo Pericial":              "Laudo",
+				"Termo de Audiência":          "Termo de Audiencia",
+			},
+		},
+		{
+			Id:              PERFIL_GENERICO,
+			Nome:            "PJe nacional (genérico)",
+			Deteccao:        []string{`pje[^/]*\.jus\.br`},
+			Rodape:          []string{rodapePJe},
+			LinhasRodape:    linhasRodapePJe,
+			Normalizacoes:   []Substituicao{{De: `pje\s+([12])`, Para: `pje$1`}, {De: `pje([12])\s+grau`, Para: `pje${1}grau`}},
+			MarcadorId:      marcadorIdPJe,
+			LinhaPagina:     linhaPaginaPJe,
+			LinhaAssinatura: linhaAssinaturaPJe,
+			LinhaIndice:     linhaIndicePJe,
+			TamanhoId:       &tamanhoIdPJe,
+			Sinonimos: map[string]string{
+				"Contrarrazões":                 "Contra-razões",
+				"Contrarrazões de Apelação":     "Contra-razões",
+				"Contrarrazões ao Recurso":      "Contra-razões",
+				"Emenda da Inicial":             "Emenda à Inicial",
+				"Apelação Cível":                "Apelação",
+				"Parecer do Ministério Público": "Manifestação do MP",
+			},
+		},
+	}
+}
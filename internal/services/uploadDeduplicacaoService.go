@@ -0,0 +1,194 @@
+/*
+---------------------------------------------------------------------------------------
+File: uploadDeduplicacaoService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Deduplicação dos arquivos enviados e dos documentos extraídos, por contexto.
+O arquivo é identificado pelo hash SHA-256 do conteúdo (a chave do armazenamento): o
+reenvio de um arquivo que ainda aguarda a extração não gera novo registro, e o de um
+arquivo já extraído no contexto é apontado no upload e no relatório. Cada documento
+extraído é identificado pelo hash do texto normalizado e comparado com a versão do
+mesmo contexto em "autos_temp" ou em "autos": o relatório informa se é novo,
+inalterado ou alterado desde a última importação. O mesmo documento juntado a outro
+contexto (processo relacionado) é importado normalmente.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ocrserver/internal/models"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/utils/logger"
+)
+
+// Situação do documento extraído em relação à importação anterior no contexto
+const (
+	DOC_NOVO       = "novo"
+	DOC_INALTERADO = "inalterado" // mesmo texto: não é gravado de novo
+	DOC_ALTERADO   = "alterado"   // nova versão do documento: substitui a anterior
+)
+
+// RegistroUpload é o resultado do registro do arquivo enviado em "uploads"
+type RegistroUpload struct {
+	IdFile               int        `json:"id_file"`
+	Sha256               string     `json:"sha256"`
+	Pendente             bool       `json:"pendente,omitempty"` // o mesmo arquivo já aguardava a extração: nenhum registro novo
+	DtImportacaoAnterior *time.Time `json:"dt_importacao_anterior,omitempty"`
+}
+
+// DocumentoImportado é a linha do relatório de importação
+type DocumentoImportado struct {
+	IdPje    string `json:"id_pje"`
+	IdNatu   int    `json:"id_natu"`
+	Situacao string `json:"situacao"`
+	Sha256   string `json:"sha256"`            // hash do texto normalizado
+	Autuado  bool   `json:"autuado,omitempty"` // a versão anterior já estava autuada ("autos")
+}
+
+// RelatorioImportacao descreve a extração de um arquivo de "uploads"
+type RelatorioImportacao struct {
+	IdFile               int                  `json:"id_file"`
+	Arquivo              string               `json:"arquivo"`
+	Sha256               string               `json:"sha256"`
+	Repetido             bool                 `json:"repetido"` // o mesmo arquivo já tinha sido extraído no contexto
+	DtImportacaoAnterior *time.Time           `json:"dt_importacao_anterior,omitempty"`
+	Novos                int                  `json:"novos"`
+	Inalterados          int                  `json:"inalterados"`
+	Alterados            int                  `json:"alterados"`
+	Documentos           []DocumentoImportado `json:"documentos"`
+}
+
+func (r *RelatorioImportacao) adiciona(doc DocumentoImportado) {
+	if r == nil {
+		return
+	}
+	switch doc.Situacao {
+	case DOC_NOVO:
+		r.Novos++
+	case DOC_INALTERADO:
+		r.Inalterados++
+	case DOC_ALTERADO:
+		r.Alterados++
+	}
+	r.Documentos = append(r.Documentos, doc)
+}
+
+/*
+RegistraUpload grava o arquivo local (com a extensão do arquivo enviado) no armazenamento
+e o registra em "uploads". Se o mesmo arquivo (hash) já aguarda a extração no contexto,
+devolve aquele registro, com Pendente. Um arquivo já extraído no contexto é registrado,
+com a data da importação anterior.
+*/
+func (obj *UploadServiceType) RegistraUpload(ctx context.Context, idCtxt string, caminho string, nmFileOri string) (RegistroUpload, error) {
+	chave, hash, err := armazenamento.GravaArquivo(ctx, armazenamento.Global(), caminho, filepath.Ext(caminho))
+	if err != nil {
+		logger.Log.Errorf("Erro ao armazenar o arquivo %s: %v", nmFileOri, err)
+		return RegistroUpload{}, err
+	}
+	reg := RegistroUpload{Sha256: hash}
+
+	pendente, err := obj.Model.SelectRowPendente(idCtxt, chave)
+	if err != nil {
+		return reg, err
+	}
+	if pendente != nil {
+		logger.Log.Infof("Arquivo %s idêntico ao id_file=%d, aguardando a extração - contexto=%s", nmFileOri, pendente.IdFile, idCtxt)
+		reg.IdFile, reg.Pendente = pendente.IdFile, true
+		return reg, nil
+	}
+
+	if anterior := obj.importacaoAnterior(idCtxt, reg.Sha256); anterior != nil {
+		logger.Log.Infof("Arquivo %s já extraído no contexto=%s em %s", nmFileOri, idCtxt, anterior.DtInc.Format("02/01/2006 15:04"))
+		reg.DtImportacaoAnterior = &anterior.DtInc
+	}
+
+	id, err := obj.InserirRegistro(idCtxt, chave, nmFileOri)
+	if err != nil {
+		obj.liberaArquivo(ctx, chave, false)
+		return reg, err
+	}
+	reg.IdFile = int(id)
+	return reg, nil
+}
+
+// importacaoAnterior devolve a última extração do arquivo no contexto; nil se não houve (ou na falha da consulta)
+func (obj *UploadServiceType) importacaoAnterior(idCtxt string, hash string) *models.UploadImportadoRow {
+	if hash == "" {
+		return nil
+	}
+	anterior, err := obj.Model.SelectUltimoImportado(idCtxt, hash)
+	if err != nil {
+		logger.Log.Warningf("Não foi possível consultar as importações anteriores do contexto=%s: %v", idCtxt, err)
+		return nil
+	}
+	return anterior
+}
+
+/*
+iniciaRelatorio monta o relatório da extração do arquivo, com o hash do conteúdo (da
+chave ou, nos arquivos gravados antes do armazenamento por conteúdo, calculado) e a
+importação anterior do mesmo arquivo no contexto.
+*/
+func (obj *UploadServiceType) iniciaRelatorio(idCtxt string, row *models.UploadRow, caminho string) *RelatorioImportacao {
+	rel := &RelatorioImportacao{IdFile: row.IdFile, Arquivo: row.NmFileOri, Documentos: []DocumentoImportado{}}
+	rel.Sha256 = armazenamento.HashDaChave(row.NmFileNew)
+	if rel.Sha256 == "" {
+		hash, err := armazenamento.HashArquivo(caminho)
+		if err != nil {
+			logger.Log.Warningf("Hash do arquivo %s não calculado: %v", filepath.Base(caminho), err)
+		}
+		rel.Sha256 = hash
+	}
+	if anterior := obj.importacaoAnterior(idCtxt, rel.Sha256); anterior != nil {
+		rel.Repetido = true
+		rel.DtImportacaoAnterior = &anterior.DtInc
+	}
+	return rel
+}
+
+// registraImportacao guarda a extração concluída, para a comparação nas próximas importações
+func (obj *UploadServiceType) registraImportacao(idCtxt string, rel *RelatorioImportacao) {
+	if rel.Sha256 == "" {
+		return
+	}
+	_, err := obj.Model.InsertImportado(models.UploadImportadoRow{
+		IdCtxt:      idCtxt,
+		Sha256:      rel.Sha256,
+		NmFileOri:   rel.Arquivo,
+		DtInc:       time.Now(),
+		Novos:       rel.Novos,
+		Inalterados: rel.Inalterados,
+		Alterados:   rel.Alterados,
+	})
+	if err != nil {
+		logger.Log.Errorf("Erro ao registrar a importação de %s - contexto=%s: %v", rel.Arquivo, idCtxt, err)
+	}
+}
+
+/*
+HashTexto calcula o hash SHA-256 do texto normalizado do documento: espaços, tabulações
+e quebras de linha são reduzidos a um espaço, para que diferenças só de diagramação na
+extração não contem como alteração.
+*/
+func HashTexto(texto string) string {
+	normalizado := strings.Join(strings.Fields(strings.ToValidUTF8(texto, "")), " ")
+	h := sha256.Sum256([]byte(normalizado))
+	return hex.EncodeToString(h[:])
+}
+
+// resumoRelatorio descreve a contagem do relatório para o log
+func resumoRelatorio(rel *RelatorioImportacao) string {
+	resumo := fmt.Sprintf("novos=%d, inalterados=%d, alterados=%d", rel.Novos, rel.Inalterados, rel.Alterados)
+	if rel.Repetido {
+		resumo += fmt.Sprintf(" (arquivo já extraído em %s)", rel.DtImportacaoAnterior.Format("02/01/2006 15:04"))
+	}
+	return resumo
+}
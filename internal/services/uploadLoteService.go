@@ -0,0 +1,242 @@
+/*
+---------------------------------------------------------------------------------------
+File: uploadLoteService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Upload em lote: vários arquivos numa só requisição e ZIPs com os documentos
+recebidos das partes. Cada arquivo (ou entrada do ZIP) é gravado no armazenamento,
+registrado em "uploads" e passa pela extração de ProcessaPDF; o relatório informa,
+arquivo a arquivo, se foi extraído (com os documentos novos, inalterados ou alterados),
+ignorado (com o motivo, como o mesmo arquivo já aguardando a extração) ou se falhou
+(com o erro). ZIPs exportados pelo eproc ou pelo Projudi são autos completos e seguem
+inteiros para o importador do sistema.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/services/importadores"
+	"ocrserver/internal/utils/files"
+	"ocrserver/internal/utils/logger"
+)
+
+// Situação de cada arquivo do lote
+const (
+	LOTE_REGISTRADO = "registrado" // registrado em "uploads", aguardando a extração (job assíncrono)
+	LOTE_EXTRAIDO   = "extraido"
+	LOTE_IGNORADO   = "ignorado"
+	LOTE_FALHA      = "falha"
+)
+
+const (
+	maxTaxaCompressaoZip = 100       // razão descompactado/compactado acima da qual a entrada é recusada
+	maxBytesArquivoZip   = 200 << 20 // 200 MB por arquivo descompactado
+)
+
+// extensões aceitas no lote (além do .zip)
+var extensoesLote = []string{".pdf", ".txt"}
+
+// ArquivoRecebido é um arquivo do multipart, gravado num diretório temporário da requisição
+type ArquivoRecebido struct {
+	Nome    string // nome original
+	Caminho string // arquivo temporário
+}
+
+// ArquivoLote é a linha do relatório do upload em lote
+type ArquivoLote struct {
+	Arquivo              string               `json:"arquivo"`           // nome original (no ZIP, o caminho da entrada)
+	Zip                  string               `json:"zip,omitempty"`     // ZIP de onde a entrada saiu
+	IdFile               int                  `json:"id_file,omitempty"` // registro em "uploads"
+	Sha256               string               `json:"sha256,omitempty"`
+	Status               string               `json:"status"`
+	Motivo               string               `json:"motivo,omitempty"`                 // motivo de ter sido ignorado ou erro da falha
+	DtImportacaoAnterior *time.Time           `json:"dt_importacao_anterior,omitempty"` // o mesmo arquivo já foi extraído no contexto
+	Relatorio            *RelatorioImportacao `json:"relatorio,omitempty"`              // documentos extraídos
+}
+
+/*
+RegistraLote grava no armazenamento e registra em "uploads" os arquivos recebidos. ZIPs
+comuns são descompactados (com as proteções de files.DescompactaZip) ao lado do arquivo
+temporário e cada entrada vira um registro; ZIPs reconhecidos por um importador (eproc,
+Projudi) passam pelos mesmos limites, mas são registrados inteiros. O formato informado orienta esse reconhecimento ("auto":
+detecção). Os arquivos temporários ficam para o chamador apagar.
+*/
+func (obj *UploadServiceType) RegistraLote(ctx context.Context, idCtxt string, recebidos []ArquivoRecebido, formato string) []ArquivoLote {
+	lote := make([]ArquivoLote, 0, len(recebidos))
+	for _, r := range recebidos {
+		ext := strings.ToLower(filepath.Ext(r.Nome))
+
+		switch {
+		case ext == ".zip" && !obj.ehAutosZip(r.Caminho, r.Nome, formato):
+			lote = append(lote, obj.registraZip(ctx, idCtxt, r)...)
+
+		case ext == ".zip":
+			lote = append(lote, obj.registraAutosZip(ctx, idCtxt, r)...)
+
+		case aceitaNoLote(ext):
+			lote = append(lote, obj.registraArquivo(ctx, idCtxt, r.Nome, r.Caminho, ""))
+
+		default:
+			lote = append(lote, ArquivoLote{Arquivo: r.Nome, Status: LOTE_IGNORADO,
+				Motivo: fmt.Sprintf("extensão %q não aceita (use .pdf, .txt ou .zip)", ext)})
+		}
+	}
+	return lote
+}
+
+// ehAutosZip indica se o ZIP são autos do eproc ou do Projudi (um único upload)
+func (obj *UploadServiceType) ehAutosZip(caminho string, nome string, formato string) bool {
+	imp, err := importadores.Seleciona(formato, &importadores.Arquivo{Caminho: caminho, NomeOriginal: nome, LimitesZip: limitesZip()})
+	return err == nil && imp != nil
+}
+
+func (obj *UploadServiceType) registraZip(ctx context.Context, idCtxt string, r ArquivoRecebido) []ArquivoLote {
+	entradas, err := files.DescompactaZip(r.Caminho, r.Caminho+".d", limitesZip(), extensoesLote)
+	if err != nil {
+		logger.Log.Errorf("ZIP recusado - %s: %v", r.Nome, err)
+		return []ArquivoLote{{Arquivo: r.Nome, Status: LOTE_FALHA, Motivo: err.Error()}}
+	}
+	lote := make([]ArquivoLote, 0, len(entradas))
+	for _, e := range entradas {
+		if e.Caminho == "" {
+			lote = append(lote, ArquivoLote{Arquivo: e.Nome, Zip: r.Nome, Status: LOTE_IGNORADO, Motivo: e.Motivo})
+			continue
+		}
+		lote = append(lote, obj.registraArquivo(ctx, idCtxt, e.Nome, e.Caminho, r.Nome))
+	}
+	logger.Log.Infof("ZIP %s: %d entradas", r.Nome, len(entradas))
+	return lote
+}
+
+/*
+registraAutosZip confere os autos em ZIP com os limites de limitesZip antes do registro: o
+ZIP recusado não chega a uploads, e os PDFs que o importador deixará de fora voltam como
+ignorados, com o motivo. A cópia descompactada é descartada; o importador descompacta o
+ZIP de novo na extração.
+*/
+func (obj *UploadServiceType) registraAutosZip(ctx context.Context, idCtxt string, r ArquivoRecebido) []ArquivoLote {
+	destino := r.Caminho + ".d"
+	defer os.RemoveAll(destino)
+	entradas, err := files.DescompactaZip(r.Caminho, destino, limitesZip(), []string{".pdf"})
+	if err != nil {
+		logger.Log.Errorf("ZIP recusado - %s: %v", r.Nome, err)
+		return []ArquivoLote{{Arquivo: r.Nome, Status: LOTE_FALHA, Motivo: err.Error()}}
+	}
+	var lote []ArquivoLote
+	pdfs := 0
+	for _, e := range entradas {
+		switch {
+		case e.Caminho != "":
+			pdfs++
+		case strings.EqualFold(path.Ext(e.Nome), ".pdf"):
+			lote = append(lote, ArquivoLote{Arquivo: e.Nome, Zip: r.Nome, Status: LOTE_IGNORADO, Motivo: e.Motivo})
+		}
+	}
+	if pdfs == 0 {
+		return append(lote, ArquivoLote{Arquivo: r.Nome, Status: LOTE_FALHA, Motivo: "nenhum PDF do ZIP dentro dos limites de descompactação"})
+	}
+	return append(lote, obj.registraArquivo(ctx, idCtxt, r.Nome, r.Caminho, ""))
+}
+
+func (obj *UploadServiceType) registraArquivo(ctx context.Context, idCtxt string, nome string, caminho string, zip string) ArquivoLote {
+	item := ArquivoLote{Arquivo: nome, Zip: zip}
+	reg, err := obj.RegistraUpload(ctx, idCtxt, caminho, path.Base(nome))
+	if err != nil {
+		item.Status, item.Motivo = LOTE_FALHA, fmt.Sprintf("erro ao registrar em uploads: %v", err)
+		return item
+	}
+	item.IdFile, item.Sha256, item.DtImportacaoAnterior = reg.IdFile, reg.Sha256, reg.DtImportacaoAnterior
+	if reg.Pendente {
+		item.Status, item.Motivo = LOTE_IGNORADO, fmt.Sprintf("arquivo idêntico ao id_file=%d, que aguarda a extração", reg.IdFile)
+		return item
+	}
+	item.Status = LOTE_REGISTRADO
+	return item
+}
+
+// ParamsLote devolve os parâmetros de extração dos arquivos registrados no lote (job assíncrono)
+func ParamsLote(idCtxt string, lote []ArquivoLote, perfil string, formato string) []BodyParamsPDF {
+	var params []BodyParamsPDF
+	for _, item := range lote {
+		if item.Status == LOTE_REGISTRADO {
+			params = append(params, paramsArquivoLote(idCtxt, item, perfil, formato))
+		}
+	}
+	return params
+}
+
+/*
+paramsArquivoLote monta os parâmetros de extração do arquivo. As entradas de um ZIP comum
+são documentos avulsos: o formato informado só vale para os arquivos enviados diretamente.
+*/
+func paramsArquivoLote(idCtxt string, item ArquivoLote, perfil string, formato string) BodyParamsPDF {
+	params := BodyParamsPDF{IdContexto: idCtxt, IdFile: item.IdFile, Perfil: perfil, Formato: formato}
+	if item.Zip != "" {
+		params.Formato = ""
+	}
+	return params
+}
+
+// ExtraiLote executa a extração dos arquivos registrados, atualizando o relatório
+func (obj *UploadServiceType) ExtraiLote(ctx context.Context, idCtxt string, lote []ArquivoLote, perfil string, formato string) []ArquivoLote {
+	total := 0
+	for _, item := range lote {
+		if item.Status == LOTE_REGISTRADO {
+			total++
+		}
+	}
+	feitos := 0
+	for i := range lote {
+		item := &lote[i]
+		if item.Status != LOTE_REGISTRADO {
+			continue
+		}
+		if ctx.Err() != nil {
+			item.Motivo = "extração interrompida; o arquivo continua em uploads"
+			continue
+		}
+		InformaProgressoJob(ctx, feitos, total, fmt.Sprintf("Extraindo %s", item.Arquivo))
+		feitos++
+
+		_, rel, err := obj.processaArquivo(ctx, paramsArquivoLote(idCtxt, *item, perfil, formato))
+		if err != nil {
+			item.Status, item.Motivo = LOTE_FALHA, err.Error()
+			continue
+		}
+		item.Status, item.Relatorio = LOTE_EXTRAIDO, rel
+	}
+	return lote
+}
+
+// limitesZip monta os limites da descompactação a partir de ZIP_MAX_ENTRADAS e ZIP_MAX_DESCOMPACTADO_MB
+func limitesZip() files.LimitesZip {
+	limites := files.LimitesZip{
+		MaxEntradas:     500,
+		MaxBytesEntrada: maxBytesArquivoZip,
+		MaxBytesTotal:   1024 << 20,
+		MaxTaxa:         maxTaxaCompressaoZip,
+	}
+	if cfg := config.GlobalConfig; cfg != nil {
+		limites.MaxEntradas = cfg.ZipMaxEntradas
+		limites.MaxBytesTotal = int64(cfg.ZipMaxDescompactadoMB) << 20
+	}
+	return limites
+}
+
+func aceitaNoLote(ext string) bool {
+	for _, e := range extensoesLote {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
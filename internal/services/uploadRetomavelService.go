@@ -0,0 +1,455 @@
+/*
+---------------------------------------------------------------------------------------
+File: uploadRetomavelService.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Upload retomável dos autos grandes, em blocos. O cliente inicia o upload
+informando o tamanho e o hash SHA-256 do arquivo, envia os blocos em sequência, cada
+um com o deslocamento (offset) em que começa, e conclui. Se a conexão cair, consulta
+os bytes já recebidos e continua dali. O andamento fica no próprio registro de
+"uploads" (status "R", bytes recebidos e validade). Cada bloco é um objeto do
+armazenamento (<nm_file_new>/<início>-<fim>-<gravação>), de modo que blocos recebidos por réplicas
+diferentes se somam; na conclusão, os blocos são reunidos, o hash é conferido e o
+arquivo passa à chave endereçada pelo conteúdo, com o registro em "S".
+
+Entre réplicas, quem guarda o offset é o banco: o bloco só conta se o UPDATE condicional
+(bytes_recebidos igual ao offset do bloco) o confirmar. Cada gravação tem chave própria,
+de modo que o bloco de uma réplica que perdeu a disputa não sobrescreve o confirmado; a
+perdedora apaga o seu. Se uma réplica cair entre a gravação e o UPDATE, o bloco órfão
+fica no armazenamento: a conclusão tenta as sequências de blocos que cobrem o arquivo
+até uma conferir com o hash.
+---------------------------------------------------------------------------------------
+*/
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/models"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/utils/logger"
+)
+
+var (
+	ErrUploadNaoEncontrado = errors.New("upload não encontrado")
+	ErrUploadExpirado      = errors.New("upload expirado")
+	ErrUploadConcluido     = errors.New("upload já concluído")
+	ErrUploadOffset        = errors.New("offset diferente dos bytes já recebidos")
+	ErrUploadTamanho       = errors.New("bloco maior que o permitido")
+	ErrUploadIncompleto    = errors.New("upload incompleto")
+	ErrUploadHash          = errors.New("hash SHA-256 do arquivo não confere")
+	ErrUploadInvalido      = errors.New("parâmetros do upload inválidos")
+)
+
+// extensões aceitas no upload retomável (as mesmas da extração)
+var extensoesRetomavel = []string{".pdf", ".txt", ".zip"}
+
+// prefixo das chaves provisórias dos uploads em andamento
+const prefixoRetomavel = "retomavel/"
+
+// travas por upload, só nesta réplica: evitam gravar à toa blocos que o banco recusaria
+var travasRetomavel sync.Map
+
+func travaRetomavel(idFile int) *sync.Mutex {
+	m, _ := travasRetomavel.LoadOrStore(idFile, &sync.Mutex{})
+	return m.(*sync.Mutex)
+}
+
+// IniciaRetomavel registra o upload; os blocos ficam sob a chave provisória nm_file_new
+func (obj *UploadServiceType) IniciaRetomavel(ctx context.Context, idCtxt string, nmFileOri string, bytesTotal int64, hash string) (*models.UploadParcialRow, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	ext := strings.ToLower(filepath.Ext(nmFileOri))
+	maxBytes := int64(retomavelCfg().UploadRetomavelMaxMB) << 20
+
+	switch {
+	case idCtxt == "" || nmFileOri == "":
+		return nil, fmt.Errorf("%w: idContexto e filename_ori obrigatórios", ErrUploadInvalido)
+	case !aceitaExtensaoRetomavel(ext):
+		return nil, fmt.Errorf("%w: extensão %q não aceita (use %s)", ErrUploadInvalido, ext, strings.Join(extensoesRetomavel, ", "))
+	case bytesTotal <= 0 || bytesTotal > maxBytes:
+		return nil, fmt.Errorf("%w: tamanho deve estar entre 1 e %d bytes", ErrUploadInvalido, maxBytes)
+	case !hashValido(hash):
+		return nil, fmt.Errorf("%w: sha256 deve ter 64 dígitos hexadecimais", ErrUploadInvalido)
+	}
+
+	obj.LimpaRetomaveisExpirados(ctx)
+
+	nmFileNew := fmt.Sprintf("%s%d%s", prefixoRetomavel, time.Now().UnixNano(), ext)
+	id, err := obj.Model.InsertRowParcial(idCtxt, nmFileNew, nmFileOri, bytesTotal, hash, validadeRetomavel())
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.Infof("Upload retomável iniciado - id_file=%d - %s (%d bytes)", id, nmFileOri, bytesTotal)
+	return obj.Model.SelectRowParcial(int(id))
+}
+
+// SituacaoRetomavel devolve o andamento do upload (bytes recebidos: offset do próximo bloco)
+func (obj *UploadServiceType) SituacaoRetomavel(idFile int) (*models.UploadParcialRow, error) {
+	row, err := obj.Model.SelectRowParcial(idFile)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, ErrUploadNaoEncontrado
+	}
+	return row, nil
+}
+
+/*
+AnexaBloco grava o bloco a partir do offset, que deve ser igual aos bytes já recebidos.
+Se a leitura for interrompida (conexão perdida), os bytes lidos até ali são mantidos e
+o cliente retoma do novo offset. Devolve o andamento atualizado, também nos erros de
+offset, para o cliente se reposicionar. O offset é conferido de novo no UPDATE: outra
+réplica pode ter gravado o mesmo trecho enquanto este bloco era lido.
+*/
+func (obj *UploadServiceType) AnexaBloco(ctx context.Context, idFile int, offset int64, bloco io.Reader) (*models.UploadParcialRow, error) {
+	trava := travaRetomavel(idFile)
+	trava.Lock()
+	defer trava.Unlock()
+
+	row, err := obj.retomavelAberto(ctx, idFile)
+	if err != nil {
+		return row, err
+	}
+	if offset != row.BytesRecebidos {
+		return row, ErrUploadOffset
+	}
+	limite := min(row.BytesTotal-row.BytesRecebidos, int64(retomavelCfg().UploadRetomavelBlocoMB)<<20)
+
+	// o bloco passa por um arquivo temporário: o tamanho só é conhecido ao final da leitura
+	tmp, err := os.CreateTemp("", "bloco-*")
+	if err != nil {
+		return row, fmt.Errorf("erro ao criar o arquivo temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, errCopia := io.Copy(tmp, io.LimitReader(bloco, limite+1))
+	if n > limite {
+		return row, fmt.Errorf("%w: restam %d bytes e o bloco pode ter até %d MB", ErrUploadTamanho,
+			row.BytesTotal-row.BytesRecebidos, retomavelCfg().UploadRetomavelBlocoMB)
+	}
+	if errCopia != nil {
+		logger.Log.Warningf("Bloco interrompido - id_file=%d - offset=%d - %d bytes lidos: %v", idFile, offset, n, errCopia)
+	}
+
+	if n > 0 {
+		// grava mesmo com a requisição cancelada: os bytes lidos valem para a retomada
+		ctxGrava := context.WithoutCancel(ctx)
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return row, err
+		}
+		chave := chaveBloco(row.NmFileNew, offset, offset+n, idGravacao())
+		if err := armazenamento.Global().Grava(ctxGrava, chave, tmp, n); err != nil {
+			return row, fmt.Errorf("erro ao gravar o bloco: %w", err)
+		}
+		ok, err := obj.Model.UpdateRecebidos(idFile, offset, offset+n, validadeRetomavel())
+		if err != nil {
+			return row, err
+		}
+		if !ok {
+			logger.Log.Warningf("Bloco recusado - id_file=%d - offset=%d já gravado por outra requisição", idFile, offset)
+			if err := armazenamento.Global().Apaga(ctxGrava, chave); err != nil {
+				logger.Log.Warningf("Bloco recusado não apagado - %s: %v", chave, err)
+			}
+			atual, _ := obj.Model.SelectRowParcial(idFile)
+			return atual, ErrUploadOffset
+		}
+	}
+
+	row, err = obj.Model.SelectRowParcial(idFile)
+	if err != nil {
+		return nil, err
+	}
+	if errCopia != nil {
+		return row, fmt.Errorf("bloco interrompido após %d bytes: %w", n, errCopia)
+	}
+	return row, nil
+}
+
+/*
+ConcluiRetomavel reúne os blocos, confere o tamanho e o hash SHA-256 e grava o arquivo
+na chave endereçada pelo conteúdo, disponível para a extração. Arquivo com hash
+divergente é descartado: os blocos já gravados não têm como ser corrigidos.
+*/
+func (obj *UploadServiceType) ConcluiRetomavel(ctx context.Context, idFile int) (*models.UploadParcialRow, error) {
+	trava := travaRetomavel(idFile)
+	trava.Lock()
+	defer trava.Unlock()
+
+	row, err := obj.retomavelAberto(ctx, idFile)
+	if err != nil {
+		return row, err
+	}
+	if row.BytesRecebidos != row.BytesTotal {
+		return row, fmt.Errorf("%w: %d de %d bytes recebidos", ErrUploadIncompleto, row.BytesRecebidos, row.BytesTotal)
+	}
+
+	tmp, err := os.CreateTemp("", "retomavel-*")
+	if err != nil {
+		return row, fmt.Errorf("erro ao criar o arquivo temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := obj.reuneBlocos(ctx, row, tmp); err != nil {
+		if errors.Is(err, ErrUploadHash) {
+			logger.Log.Errorf("Upload descartado - id_file=%d: %v", idFile, err)
+			obj.descartaRetomavel(ctx, row)
+			return nil, err
+		}
+		return row, err
+	}
+
+	chave, err := obj.ArmazenaArquivo(ctx, tmp.Name(), row.NmFileOri)
+	if err != nil {
+		return row, err
+	}
+	ok, err := obj.Model.ConcluiRow(idFile, chave)
+	if err != nil {
+		return row, err
+	}
+	if !ok {
+		return row, ErrUploadConcluido
+	}
+	obj.apagaBlocos(ctx, row.NmFileNew)
+	travasRetomavel.Delete(idFile)
+	logger.Log.Infof("Upload retomável concluído - id_file=%d - %s", idFile, row.NmFileOri)
+	return obj.Model.SelectRowParcial(idFile)
+}
+
+// máximo de sequências de blocos tentadas na conclusão (só há mais de uma com blocos órfãos)
+const MAX_CADEIAS_RETOMAVEL = 8
+
+/*
+reuneBlocos grava no destino a sequência de blocos que cobre o arquivo e confere com o
+hash SHA-256 informado no início; ErrUploadHash se nenhuma conferir.
+*/
+func (obj *UploadServiceType) reuneBlocos(ctx context.Context, row *models.UploadParcialRow, destino *os.File) error {
+	objetos, err := armazenamento.Global().Lista(ctx, row.NmFileNew+"/")
+	if err != nil {
+		return err
+	}
+	cadeias := cadeiasBlocos(row.NmFileNew, objetos, row.BytesTotal, MAX_CADEIAS_RETOMAVEL)
+	if len(cadeias) == 0 {
+		return fmt.Errorf("%w: os blocos gravados não cobrem os %d bytes do arquivo", ErrUploadIncompleto, row.BytesTotal)
+	}
+
+	var hash string
+	for _, cadeia := range cadeias {
+		if err := destino.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := destino.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		h := sha256.New()
+		if err := copiaBlocos(ctx, cadeia, io.MultiWriter(destino, h)); err != nil {
+			return err
+		}
+		if hash = hex.EncodeToString(h.Sum(nil)); hash == row.Sha256 {
+			return nil
+		}
+	}
+	if len(cadeias) > 1 {
+		return fmt.Errorf("%w: nenhuma das %d sequências de blocos confere com %s", ErrUploadHash, len(cadeias), row.Sha256)
+	}
+	return fmt.Errorf("%w: recebido %s, esperado %s", ErrUploadHash, hash, row.Sha256)
+}
+
+// copiaBlocos copia os blocos em ordem, conferindo o tamanho de cada um
+func copiaBlocos(ctx context.Context, cadeia []blocoRetomavel, destino io.Writer) error {
+	store := armazenamento.Global()
+	for _, b := range cadeia {
+		r, err := store.Le(ctx, b.chave)
+		if err != nil {
+			return fmt.Errorf("erro ao ler o bloco %s: %w", b.chave, err)
+		}
+		n, err := io.Copy(destino, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("erro ao reunir os blocos: %w", err)
+		}
+		if n != b.fim-b.inicio {
+			return fmt.Errorf("%w: bloco %s com %d bytes", ErrUploadIncompleto, path.Base(b.chave), n)
+		}
+	}
+	return nil
+}
+
+type blocoRetomavel struct {
+	chave       string
+	inicio, fim int64
+}
+
+/*
+cadeiasBlocos devolve até limite sequências contíguas de blocos de 0 a total. Blocos
+órfãos (réplica que caiu antes do UPDATE) podem começar no mesmo offset de um
+confirmado: a busca volta atrás quando o bloco escolhido não tem continuação.
+*/
+func cadeiasBlocos(nmFileNew string, objetos []armazenamento.Objeto, total int64, limite int) [][]blocoRetomavel {
+	porInicio := map[int64][]blocoRetomavel{}
+	for _, o := range objetos {
+		b, ok := parseChaveBloco(nmFileNew, o.Chave)
+		if ok && b.fim <= total {
+			porInicio[b.inicio] = append(porInicio[b.inicio], b)
+		}
+	}
+	for _, bs := range porInicio {
+		// os maiores primeiro: menos blocos a ler
+		sort.Slice(bs, func(i, j int) bool { return bs[i].fim > bs[j].fim })
+	}
+
+	var cadeias [][]blocoRetomavel
+	var cadeia []blocoRetomavel
+	semSaida := map[int64]bool{}
+	var busca func(inicio int64) bool
+	busca = func(inicio int64) bool {
+		if inicio == total {
+			cadeias = append(cadeias, append([]blocoRetomavel{}, cadeia...))
+			return true
+		}
+		if semSaida[inicio] {
+			return false
+		}
+		achou := false
+		for _, b := range porInicio[inicio] {
+			if len(cadeias) == limite {
+				break
+			}
+			cadeia = append(cadeia, b)
+			if busca(b.fim) {
+				achou = true
+			}
+			cadeia = cadeia[:len(cadeia)-1]
+		}
+		if !achou && len(cadeias) < limite {
+			semSaida[inicio] = true
+		}
+		return achou
+	}
+	busca(0)
+	return cadeias
+}
+
+// LimpaRetomaveisExpirados apaga os uploads não concluídos dentro da validade
+func (obj *UploadServiceType) LimpaRetomaveisExpirados(ctx context.Context) {
+	rows, err := obj.Model.SelectRowsExpirados(time.Now())
+	if err != nil {
+		logger.Log.Warningf("Erro ao buscar os uploads retomáveis expirados: %v", err)
+		return
+	}
+	for i := range rows {
+		logger.Log.Infof("Upload retomável expirado - id_file=%d - %s", rows[i].IdFile, rows[i].NmFileOri)
+		obj.descartaRetomavel(ctx, &rows[i])
+	}
+}
+
+// retomavelAberto devolve o upload que ainda pode receber blocos
+func (obj *UploadServiceType) retomavelAberto(ctx context.Context, idFile int) (*models.UploadParcialRow, error) {
+	row, err := obj.SituacaoRetomavel(idFile)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != models.UPLOAD_STATUS_RECEBENDO {
+		return row, ErrUploadConcluido
+	}
+	if row.DtExpira != nil && row.DtExpira.Before(time.Now()) {
+		obj.descartaRetomavel(ctx, row)
+		return nil, ErrUploadExpirado
+	}
+	return row, nil
+}
+
+func (obj *UploadServiceType) descartaRetomavel(ctx context.Context, row *models.UploadParcialRow) {
+	obj.apagaBlocos(ctx, row.NmFileNew)
+	if err := obj.DeleteRegistro(row.IdFile); err != nil {
+		logger.Log.Warningf("Registro do upload não apagado - id_file=%d: %v", row.IdFile, err)
+	}
+	travasRetomavel.Delete(row.IdFile)
+}
+
+// apagaBlocos apaga os blocos do upload, confirmados ou não
+func (obj *UploadServiceType) apagaBlocos(ctx context.Context, nmFileNew string) {
+	store := armazenamento.Global()
+	blocos, err := store.Lista(ctx, nmFileNew+"/")
+	if err != nil {
+		logger.Log.Warningf("Erro ao listar os blocos de %s: %v", nmFileNew, err)
+		return
+	}
+	for _, b := range blocos {
+		if err := store.Apaga(ctx, b.Chave); err != nil {
+			logger.Log.Warningf("Bloco não apagado - %s: %v", b.Chave, err)
+		}
+	}
+}
+
+// chaveBloco: início e fim (exclusivo) do trecho, com 20 dígitos cada, e a gravação
+func chaveBloco(nmFileNew string, inicio int64, fim int64, gravacao string) string {
+	return fmt.Sprintf("%s/%020d-%020d-%s", nmFileNew, inicio, fim, gravacao)
+}
+
+// idGravacao distingue as gravações do mesmo trecho feitas ao mesmo tempo
+func idGravacao() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// parseChaveBloco desfaz chaveBloco; ignora outros objetos sob o prefixo
+func parseChaveBloco(nmFileNew string, chave string) (blocoRetomavel, bool) {
+	nome, ok := strings.CutPrefix(chave, nmFileNew+"/")
+	if !ok || len(nome) < 43 || nome[20] != '-' || nome[41] != '-' {
+		return blocoRetomavel{}, false
+	}
+	inicio, err1 := strconv.ParseInt(nome[:20], 10, 64)
+	fim, err2 := strconv.ParseInt(nome[21:41], 10, 64)
+	if err1 != nil || err2 != nil || fim <= inicio {
+		return blocoRetomavel{}, false
+	}
+	return blocoRetomavel{chave: chave, inicio: inicio, fim: fim}, true
+}
+
+func hashValido(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+func aceitaExtensaoRetomavel(ext string) bool {
+	for _, e := range extensoesRetomavel {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func validadeRetomavel() time.Time {
+	return time.Now().Add(time.Duration(retomavelCfg().UploadRetomavelExpiraHoras) * time.Hour)
+}
+
+// retomavelCfg devolve a configuração, com os padrões quando não carregada
+func retomavelCfg() *config.Config {
+	if cfg := config.GlobalConfig; cfg != nil {
+		return cfg
+	}
+	return &config.Config{UploadRetomavelMaxMB: 2048, UploadRetomavelBlocoMB: 16, UploadRetomavelExpiraHoras: 24}
+}
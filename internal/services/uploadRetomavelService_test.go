@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/models"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/utils/logger"
+)
+
+// ------------------------- Tabela "uploads" -------------------------
+
+// tabelaUploads guarda em memória as colunas usadas pelo upload retomável
+type tabelaUploads struct {
+	mu    sync.Mutex
+	prox  int64
+	linha map[int64][]driver.Value // na ordem de uploadsParcialColunas
+}
+
+const (
+	colNmFileNew = 2
+	colStatus    = 6
+	colRecebidos = 8
+	colDtExpira  = 10
+)
+
+var (
+	tabelasUploads     sync.Map // dsn → *tabelaUploads
+	onceDriverUploads  sync.Once
+	onceArmazenaUpload sync.Once
+)
+
+// driverUploads atende às consultas de models/uploadModel.go sobre o upload retomável
+type driverUploads struct{}
+type conexaoUploads struct{ t *tabelaUploads }
+type stmtUploads struct {
+	t     *tabelaUploads
+	query string
+}
+
+func (driverUploads) Open(dsn string) (driver.Conn, error) {
+	t, _ := tabelasUploads.LoadOrStore(dsn, &tabelaUploads{linha: map[int64][]driver.Value{}})
+	return conexaoUploads{t: t.(*tabelaUploads)}, nil
+}
+func (c conexaoUploads) Prepare(q string) (driver.Stmt, error) {
+	return stmtUploads{t: c.t, query: q}, nil
+}
+func (conexaoUploads) Close() error              { return nil }
+func (conexaoUploads) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+func (stmtUploads) Close() error                 { return nil }
+func (stmtUploads) NumInput() int                { return -1 }
+
+func (s stmtUploads) Exec(args []driver.Value) (driver.Result, error) {
+	t := s.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case strings.Contains(s.query, "SET bytes_recebidos"):
+		l := t.linha[args[2].(int64)]
+		if l == nil || l[colStatus] != args[3] || l[colRecebidos] != args[4] {
+			return driver.RowsAffected(0), nil
+		}
+		l[colRecebidos], l[colDtExpira] = args[0], args[1]
+	case strings.Contains(s.query, "SET status"):
+		l := t.linha[args[2].(int64)]
+		if l == nil || l[colStatus] != args[3] {
+			return driver.RowsAffected(0), nil
+		}
+		l[colStatus], l[colNmFileNew], l[colDtExpira] = args[0], args[1], nil
+	case strings.HasPrefix(strings.TrimSpace(s.query), "DELETE FROM uploads"):
+		if _, ok := t.linha[args[0].(int64)]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		delete(t.linha, args[0].(int64))
+	default:
+		return nil, errors.New("consulta não prevista no teste: " + s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s stmtUploads) Query(args []driver.Value) (driver.Rows, error) {
+	t := s.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case strings.Contains(s.query, "INSERT INTO uploads"):
+		t.prox++
+		t.linha[t.prox] = []driver.Value{t.prox, args[0], args[1], args[2], "N", args[3], args[4], args[5], int64(0), args[6], args[7]}
+		return &linhasUploads{colunas: []string{"id_file"}, linhas: [][]driver.Value{{t.prox}}}, nil
+	case strings.Contains(s.query, "WHERE id_file = $1"):
+		l := &linhasUploads{colunas: colunasUploads}
+		if linha, ok := t.linha[args[0].(int64)]; ok {
+			l.linhas = append(l.linhas, append([]driver.Value{}, linha...))
+		}
+		return l, nil
+	case strings.Contains(s.query, "dt_expira < $2"):
+		l := &linhasUploads{colunas: colunasUploads}
+		for _, linha := range t.linha {
+			if linha[colStatus] == args[0] && linha[colDtExpira] != nil && linha[colDtExpira].(time.Time).Before(args[1].(time.Time)) {
+				l.linhas = append(l.linhas, append([]driver.Value{}, linha...))
+			}
+		}
+		return l, nil
+	}
+	return nil, errors.New("consulta não prevista no teste: " + s.query)
+}
+
+var colunasUploads = []string{"id_file", "id_ctxt", "nm_file_new", "nm_file_ori", "sn_autos", "dt_inc", "status",
+	"bytes_total", "bytes_recebidos", "sha256", "dt_expira"}
+
+type linhasUploads struct {
+	colunas []string
+	linhas  [][]driver.Value
+}
+
+func (l *linhasUploads) Columns() []string { return l.colunas }
+func (l *linhasUploads) Close() error      { return nil }
+func (l *linhasUploads) Next(dest []driver.Value) error {
+	if len(l.linhas) == 0 {
+		return io.EOF
+	}
+	copy(dest, l.linhas[0])
+	l.linhas = l.linhas[1:]
+	return nil
+}
+
+// ------------------------- Ambiente do teste -------------------------
+
+/*
+novoUploadRetomavel devolve um serviço sobre a tabela "uploads" do teste. Chamado de novo
+com o mesmo teste, simula outra réplica ou o servidor reiniciado: mesma tabela e mesmo
+armazenamento, sem as travas em memória.
+*/
+func novoUploadRetomavel(t *testing.T) *UploadServiceType {
+	t.Helper()
+	logger.InitLoggerGlobal("", false)
+
+	anterior := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = anterior })
+	config.GlobalConfig = &config.Config{UploadRetomavelMaxMB: 1, UploadRetomavelBlocoMB: 1, UploadRetomavelExpiraHoras: 1}
+
+	onceArmazenaUpload.Do(func() {
+		dir, err := os.MkdirTemp("", "retomavel-teste-*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := armazenamento.InitArmazenamento(&config.Config{BlobBackend: "local", BlobLocalDir: dir}); err != nil {
+			t.Fatalf("armazenamento local: %v", err)
+		}
+	})
+	onceDriverUploads.Do(func() { sql.Register("uploads_teste", driverUploads{}) })
+	db, err := sql.Open("uploads_teste", t.Name())
+	if err != nil {
+		t.Fatalf("driver de uploads: %v", err)
+	}
+	travasRetomavel.Clear()
+	return &UploadServiceType{Model: models.NewUploadModel(db)}
+}
+
+func hashTeste(dados []byte) string {
+	h := sha256.Sum256(dados)
+	return hex.EncodeToString(h[:])
+}
+
+// leitorInterrompido entrega os dados e falha em seguida, como uma conexão perdida
+type leitorInterrompido struct{ r io.Reader }
+
+func (l leitorInterrompido) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// confereArquivo compara o arquivo concluído com o enviado
+func confereArquivo(t *testing.T, chave string, dados []byte) {
+	t.Helper()
+	r, err := armazenamento.Global().Le(context.Background(), chave)
+	if err != nil {
+		t.Fatalf("arquivo concluído: %v", err)
+	}
+	defer r.Close()
+	if gravado, _ := io.ReadAll(r); !bytes.Equal(gravado, dados) {
+		t.Errorf("arquivo reunido com %d bytes diferente do enviado (%d bytes)", len(gravado), len(dados))
+	}
+}
+
+// ------------------------- Testes -------------------------
+
+func TestAnexaBlocoOffsetDivergente(t *testing.T) {
+	svc := novoUploadRetomavel(t)
+	ctx := context.Background()
+	dados := []byte("0123456789")
+
+	row, err := svc.IniciaRetomavel(ctx, "1", "peticao.txt", int64(len(dados)), hashTeste(dados))
+	if err != nil {
+		t.Fatalf("IniciaRetomavel: %v", err)
+	}
+	if _, err := svc.AnexaBloco(ctx, row.IdFile, 0, bytes.NewReader(dados[:4])); err != nil {
+		t.Fatalf("primeiro bloco: %v", err)
+	}
+
+	for _, offset := range []int64{0, 2, 6} {
+		atual, err := svc.AnexaBloco(ctx, row.IdFile, offset, bytes.NewReader(dados[offset:]))
+		if !errors.Is(err, ErrUploadOffset) {
+			t.Fatalf("offset %d: erro = %v, esperado ErrUploadOffset", offset, err)
+		}
+		if atual == nil || atual.BytesRecebidos != 4 {
+			t.Fatalf("offset %d: andamento devolvido %+v, esperado 4 bytes recebidos", offset, atual)
+		}
+	}
+
+	// Duas réplicas leem o offset 4: a outra confirma primeiro e esta, já com o bloco
+	// gravado, tem o UPDATE condicional recusado e cai antes de apagá-lo
+	outra := novoUploadRetomavel(t)
+	if _, err := outra.AnexaBloco(ctx, row.IdFile, 4, bytes.NewReader(dados[4:7])); err != nil {
+		t.Fatalf("bloco da outra réplica: %v", err)
+	}
+	perdedor := []byte("zzzzzz")
+	if err := armazenamento.Global().Grava(ctx, chaveBloco(row.NmFileNew, 4, 10, "perdedor"), bytes.NewReader(perdedor), int64(len(perdedor))); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := svc.Model.UpdateRecebidos(row.IdFile, 4, 10, time.Now().Add(time.Hour)); ok || err != nil {
+		t.Fatalf("UpdateRecebidos com offset vencido = (%v, %v), esperado recusado", ok, err)
+	}
+
+	if _, err := svc.AnexaBloco(ctx, row.IdFile, 7, bytes.NewReader(dados[7:])); err != nil {
+		t.Fatalf("último bloco: %v", err)
+	}
+	fim, err := svc.ConcluiRetomavel(ctx, row.IdFile)
+	if err != nil || fim.Status != models.UPLOAD_STATUS_DISPONIVEL {
+		t.Fatalf("ConcluiRetomavel = (%+v, %v)", fim, err)
+	}
+	confereArquivo(t, fim.NmFileNew, dados)
+}
+
+func TestRetomavelAposReinicio(t *testing.T) {
+	svc := novoUploadRetomavel(t)
+	ctx := context.Background()
+	dados := bytes.Repeat([]byte("Vistos etc. "), 100)
+	total := int64(len(dados))
+
+	row, err := svc.IniciaRetomavel(ctx, "1", "sentenca.txt", total, hashTeste(dados))
+	if err != nil {
+		t.Fatalf("IniciaRetomavel: %v", err)
+	}
+
+	// Conexão perdida no meio do bloco: os bytes lidos ficam valendo
+	parcial, err := svc.AnexaBloco(ctx, row.IdFile, 0, leitorInterrompido{bytes.NewReader(dados[:500])})
+	if err == nil || parcial == nil || parcial.BytesRecebidos != 500 {
+		t.Fatalf("bloco interrompido = (%+v, %v), esperados 500 bytes e erro", parcial, err)
+	}
+
+	// Bloco de uma réplica que caiu entre a gravação e o UPDATE: mesmo trecho do próximo
+	orfao := []byte(strings.Repeat("x", 400))
+	if err := armazenamento.Global().Grava(ctx, chaveBloco(row.NmFileNew, 500, 900, "orfao"), bytes.NewReader(orfao), int64(len(orfao))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Servidor reiniciado: o andamento vem do banco
+	reiniciado := novoUploadRetomavel(t)
+	situacao, err := reiniciado.SituacaoRetomavel(row.IdFile)
+	if err != nil || situacao.BytesRecebidos != 500 {
+		t.Fatalf("SituacaoRetomavel = (%+v, %v), esperados 500 bytes", situacao, err)
+	}
+	for _, fim := range []int64{900, total} {
+		if _, err := reiniciado.AnexaBloco(ctx, row.IdFile, situacao.BytesRecebidos, bytes.NewReader(dados[situacao.BytesRecebidos:fim])); err != nil {
+			t.Fatalf("bloco até %d: %v", fim, err)
+		}
+		if situacao, err = reiniciado.SituacaoRetomavel(row.IdFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fim, err := reiniciado.ConcluiRetomavel(ctx, row.IdFile)
+	if err != nil {
+		t.Fatalf("ConcluiRetomavel: %v", err)
+	}
+	confereArquivo(t, fim.NmFileNew, dados)
+	if blocos, _ := armazenamento.Global().Lista(ctx, row.NmFileNew+"/"); len(blocos) != 0 {
+		t.Errorf("%d blocos não apagados após a conclusão", len(blocos))
+	}
+}
+
+func TestConcluiRetomavelHashDivergente(t *testing.T) {
+	svc := novoUploadRetomavel(t)
+	ctx := context.Background()
+	dados := []byte("conteúdo enviado")
+
+	row, err := svc.IniciaRetomavel(ctx, "1", "laudo.txt", int64(len(dados)), hashTeste([]byte("conteúdo esperado")))
+	if err != nil {
+		t.Fatalf("IniciaRetomavel: %v", err)
+	}
+	if _, err := svc.AnexaBloco(ctx, row.IdFile, 0, bytes.NewReader(dados)); err != nil {
+		t.Fatalf("AnexaBloco: %v", err)
+	}
+
+	if _, err := svc.ConcluiRetomavel(ctx, row.IdFile); !errors.Is(err, ErrUploadHash) {
+		t.Fatalf("ConcluiRetomavel: erro = %v, esperado ErrUploadHash", err)
+	}
+	if _, err := svc.SituacaoRetomavel(row.IdFile); !errors.Is(err, ErrUploadNaoEncontrado) {
+		t.Errorf("registro após o hash divergente: erro = %v, esperado ErrUploadNaoEncontrado", err)
+	}
+	if blocos, _ := armazenamento.Global().Lista(ctx, row.NmFileNew+"/"); len(blocos) != 0 {
+		t.Errorf("%d blocos não apagados após o descarte", len(blocos))
+	}
+}
@@ -9,16 +9,20 @@ Data: 03-05-2025
 package services
 
 import (
-	"bufio"
 	"context"
 
 	"fmt"
 
+	"ocrserver/internal/config"
 	"ocrserver/internal/consts"
 	"ocrserver/internal/models"
 	"ocrserver/internal/opensearch"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/services/importadores"
+	"ocrserver/internal/services/ocr"
+	"ocrserver/internal/services/pdftexto"
+	"ocrserver/internal/services/tribunais"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -30,7 +34,9 @@ import (
 )
 
 type UploadServiceType struct {
-	Model *models.UploadModelType
+	Model    *models.UploadModelType
+	extrator pdftexto.Extrator
+	ocr      ocr.Motor // nil: OCR desativado
 }
 
 var UploadServiceGlobal *UploadServiceType
@@ -41,7 +47,9 @@ func InitUploadService(model *models.UploadModelType) {
 	onceInitUploadService.Do(func() {
 
 		UploadServiceGlobal = &UploadServiceType{
-			Model: model,
+			Model:    model,
+			extrator: novoExtratorPDF(),
+			ocr:      novoMotorOCR(),
 		}
 
 		logger.Log.Info("Global AutosService configurado com sucesso.")
@@ -52,10 +60,46 @@ func NewUploadService(model *models.UploadModelType,
 ) *UploadServiceType {
 	return &UploadServiceType{
 
-		Model: model,
+		Model:    model,
+		extrator: novoExtratorPDF(),
+		ocr:      novoMotorOCR(),
 	}
 }
 
+// novoExtratorPDF cria o extrator de texto configurado em PDF_EXTRATOR (padrão: nativo)
+func novoExtratorPDF() pdftexto.Extrator {
+	nome := pdftexto.EXTRATOR_NATIVO
+	if config.GlobalConfig != nil {
+		nome = config.GlobalConfig.PdfExtrator
+	}
+	extrator, err := pdftexto.NewExtrator(nome)
+	if err != nil {
+		logger.Log.Errorf("Extrator de PDF inválido, usando o nativo: %v", err)
+		return &pdftexto.ExtratorNativo{}
+	}
+	logger.Log.Infof("Extrator de PDF: %s", extrator.Nome())
+	return extrator
+}
+
+// novoMotorOCR cria o motor de OCR configurado em OCR_MOTOR; sem motor disponível, o OCR fica desativado
+func novoMotorOCR() ocr.Motor {
+	nome, idioma := ocr.MOTOR_TESSERACT, "por"
+	if config.GlobalConfig != nil {
+		nome, idioma = config.GlobalConfig.OcrMotor, config.GlobalConfig.OcrIdioma
+	}
+	motor, err := ocr.NewMotor(nome, idioma)
+	if err != nil {
+		logger.Log.Warningf("OCR desativado: %v", err)
+		return nil
+	}
+	if motor == nil {
+		logger.Log.Info("OCR desativado (OCR_MOTOR=nenhum)")
+		return nil
+	}
+	logger.Log.Infof("Motor de OCR: %s (%s)", motor.Nome(), idioma)
+	return motor
+}
+
 type DocumentoIndice struct {
 	Id        string
 	Data      string
@@ -65,8 +109,11 @@ type DocumentoIndice struct {
 }
 
 type NaturezaDoc struct {
-	Key         int    `json:"key"`
-	Description string `json:"description"`
+	Key         int     `json:"key"`
+	Description string  `json:"description"`
+	Fonte       string  `json:"fonte,omitempty"`     // regra, palavras-chave ou modelo
+	Confianca   float64 `json:"confianca,omitempty"` // confiança do classificador local
+	Motivo      string  `json:"motivo,omitempty"`
 }
 
 var naturezasValidasImportarPJE = []int{
@@ -92,189 +139,429 @@ var naturezasValidasImportarPJE = []int{
 type BodyParamsPDF struct {
 	IdContexto string
 	IdFile     int
+	Perfil     string // perfil do tribunal (ex.: "tjce"); vazio usa PERFIL_TRIBUNAL ("auto": detecção)
+	Formato    string // sistema de origem dos autos: auto (padrão), pje, esaj, eproc ou projudi
 }
 
 const maxTextSize = 60 * 1024 * 3 // 180 KB em bytes
 
+const (
+	minTextoPagina     = 80  // caracteres úteis (sem o rodapé do PJe) abaixo dos quais a página é candidata ao OCR
+	minCoberturaImagem = 0.5 // fração mínima da página ocupada pela imagem para ser considerada digitalizada
+)
+
 /*
 Função genérica destinada a processar a extração dos documentos contidos nos autos de cada
 processo, e pode extrarir diretamente do arquivo PDF gerado pelo PJe, ou incorporá arquivos
-txt já extraídos externamente. Não estamos utilizadon mais OCR, apesar das rotinas ainda
-estarem disponíveis. Utilizaremos o utilitário linux "pdftotext" para converte o PDF p/TXT.
-A rotina trabalha tanto com o PDF completo dos autos quanto de pelas individuais.
+txt já extraídos externamente. O texto do PDF é extraído página a página pelo extrator
+configurado em PDF_EXTRATOR (pacote pdftexto: nativo em Go ou "pdftotext"). As páginas
+digitalizadas (só imagem, sem camada de texto) passam pelo OCR configurado em OCR_MOTOR.
+A rotina trabalha tanto com o PDF completo dos autos quanto de pelas individuais. Autos de
+outros sistemas (pasta digital do e-SAJ, ZIP do eproc ou do Projudi) são divididos pelos
+importadores do pacote importadores, conforme o "Formato" informado ou detectado.
+Cada arquivo extraído tem o seu relatório: documentos novos, inalterados ou alterados
+desde a última importação no contexto.
 */
-func (obj *UploadServiceType) ProcessaPDF(ctx context.Context, bodyParams []BodyParamsPDF) (extractedFiles []string, extractedErros []int) {
+func (obj *UploadServiceType) ProcessaPDF(ctx context.Context, bodyParams []BodyParamsPDF) (extractedFiles []string, extractedErros []int, relatorios []RelatorioImportacao) {
 	if obj == nil {
 		logger.Log.Error("Tentativa de uso de serviço não iniciado.")
 		return
 	}
 
-	for _, doc := range bodyParams {
-		autuar := true
-		idCtxt := doc.IdContexto
-		idFile := doc.IdFile
+	for i, doc := range bodyParams {
+		// Cancelamento (job): interrompe antes do próximo arquivo
+		if ctx.Err() != nil {
+			logger.Log.Warningf("Extração interrompida: %v", ctx.Err())
+			return extractedFiles, extractedErros, relatorios
+		}
+		InformaProgressoJob(ctx, i, len(bodyParams), fmt.Sprintf("Extraindo arquivo %d de %d", i+1, len(bodyParams)))
 
-		row, err := obj.Model.SelectRowById(idFile)
+		nmFile, rel, err := obj.processaArquivo(ctx, doc)
 		if err != nil {
-			logger.Log.Errorf("Arquivo não encontrado em temp_uploads - id_file=%d - contexto=%s", idFile, idCtxt)
-			extractedErros = append(extractedErros, idFile)
+			extractedErros = append(extractedErros, doc.IdFile)
 			continue
 		}
+		extractedFiles = append(extractedFiles, nmFile)
+		relatorios = append(relatorios, *rel)
+	}
 
-		filePath := filepath.Join("uploads", row.NmFileNew)
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			logger.Log.Errorf("Arquivo não encontrado - fileName=%s - contexto=%s", row.NmFileNew, idCtxt)
-			extractedErros = append(extractedErros, idFile)
-			continue
-		}
+	return extractedFiles, extractedErros, relatorios
+}
 
-		var resultText string
-		ext := strings.ToLower(filepath.Ext(row.NmFileNew))
+/*
+processaArquivo extrai os documentos de um arquivo de "uploads" e, concluída a extração,
+registra a importação e apaga o registro e o arquivo. Devolve o nome do arquivo
+processado e o relatório da importação, ou o motivo da falha.
+*/
+func (obj *UploadServiceType) processaArquivo(ctx context.Context, doc BodyParamsPDF) (string, *RelatorioImportacao, error) {
+	autuar := true
+	idCtxt := doc.IdContexto
+	idFile := doc.IdFile
+
+	row, err := obj.Model.SelectRowById(idFile)
+	if err != nil || row == nil {
+		logger.Log.Errorf("Arquivo não encontrado em temp_uploads - id_file=%d - contexto=%s", idFile, idCtxt)
+		return "", nil, fmt.Errorf("arquivo não encontrado em uploads (id_file=%d)", idFile)
+	}
+	if row.Status == models.UPLOAD_STATUS_RECEBENDO {
+		logger.Log.Errorf("Upload retomável não concluído - id_file=%d - contexto=%s", idFile, idCtxt)
+		return "", nil, fmt.Errorf("upload do arquivo ainda não concluído (id_file=%d)", idFile)
+	}
 
-		//******   TEXTO **************************
-		if ext == ".txt" {
-			bytesContent, err := os.ReadFile(filePath)
-			if err != nil {
-				logger.Log.Errorf("Erro ao ler arquivo txt - fileName=%s - contexto=%s", row.NmFileNew, idCtxt)
-				extractedErros = append(extractedErros, idFile)
-				continue
-			}
-			resultText = string(bytesContent)
-			natuDoc, err := AutosTempServiceGlobal.VerificarNaturezaDocumento(ctx, idCtxt, resultText)
+	// Cópia local do arquivo armazenado (no backend local, o próprio arquivo)
+	filePath, limpa, err := armazenamento.CopiaLocal(ctx, armazenamento.Global(), row.NmFileNew)
+	if err != nil {
+		logger.Log.Errorf("Arquivo não encontrado - fileName=%s - contexto=%s: %v", row.NmFileNew, idCtxt, err)
+		return "", nil, fmt.Errorf("arquivo %s não encontrado no armazenamento: %w", row.NmFileNew, err)
+	}
+	defer limpa()
+
+	rel := obj.iniciaRelatorio(idCtxt, row, filePath)
+	// arquivo de origem dos documentos, para a consulta da página original
+	origem := consts.ProcedenciaAutos{Arquivo: row.NmFileOri, ChaveArquivo: row.NmFileNew}
+
+	var resultText string
+	var idDocTxt string
+	var idNatuTxt int
+	ext := strings.ToLower(filepath.Ext(row.NmFileNew))
+
+	//******   TEXTO **************************
+	if ext == ".txt" {
+		bytesContent, err := os.ReadFile(filePath)
+		if err != nil {
+			logger.Log.Errorf("Erro ao ler arquivo txt - fileName=%s - contexto=%s", row.NmFileNew, idCtxt)
+			return "", nil, fmt.Errorf("erro ao ler o arquivo txt: %w", err)
+		}
+		resultText = string(bytesContent)
+		idDocTxt = idTextoEnviado(resultText)
+		natuDoc, err := AutosTempServiceGlobal.VerificarNaturezaDocumento(ctx, idCtxt, idDocTxt, resultText)
+		if err != nil {
+			autuar = false
+		} else {
+			idNatuTxt = natuDoc.Key
+			logger.Log.Infof("natuDoc=%d - %s", natuDoc.Key, natuDoc.Description)
+		}
+		// if autuar {
+		// 	err = SalvaTextoExtraido(reg.IdContexto, 0, row.NmFileNew, resultText)
+		// 	if err != nil {
+		// 		logger.Log.Errorf("Erro ao salvar o texto extraído - fileName=%s - contexto=%d", row.NmFileNew, reg.IdContexto)
+		// 		extractedErros = append(extractedErros, reg.IdFile)
+		// 		continue
+		// 	}
+		// }
+
+	} else {
+		//****************************************************
+		//TRATAMENTO DOS AUTOS: PDF DO PJe, e-SAJ OU ZIP (eproc, Projudi)
+		//****************************************************
+		autuar = false
+		arq := &importadores.Arquivo{Caminho: filePath, NomeOriginal: row.NmFileOri, LimitesZip: limitesZip()}
+		if ext != ".zip" {
+			//Extraindo o texto do PDF, página a página
+			arq.Paginas, err = obj.extraiPaginasPDF(ctx, filePath)
 			if err != nil {
-				autuar = false
-			} else {
-				logger.Log.Infof("natuDoc=%d - %s", natuDoc.Key, natuDoc.Description)
+				logger.Log.Errorf("Erro na extração do texto - fileName=%s - contexto=%s: %v", row.NmFileNew, idCtxt, err)
+				return "", nil, fmt.Errorf("erro na extração do texto: %w", err)
 			}
-			// if autuar {
-			// 	err = SalvaTextoExtraido(reg.IdContexto, 0, row.NmFileNew, resultText)
-			// 	if err != nil {
-			// 		logger.Log.Errorf("Erro ao salvar o texto extraído - fileName=%s - contexto=%d", row.NmFileNew, reg.IdContexto)
-			// 		extractedErros = append(extractedErros, reg.IdFile)
-			// 		continue
-			// 	}
-			// }
+		}
 
+		//Formato dos autos: informado no upload ou detectado pelo conteúdo (nil: PJe)
+		importador, err := importadores.Seleciona(doc.Formato, arq)
+		if err != nil {
+			logger.Log.Errorf("Formato dos autos inválido - fileName=%s - contexto=%s: %v", row.NmFileNew, idCtxt, err)
+			return "", nil, err
+		}
+
+		if importador != nil {
+			err = obj.importaDocumentos(ctx, idCtxt, origem, importador, arq, rel)
 		} else {
-			//****************************************************
-			//TRATAMENTO DO ARQUIVO PDF
-			//****************************************************
-			autuar = false
-			//Usando OCR - desativado
-			//resultText, err = processPDFWithPipeline(filePath)
+			err = obj.importaAutosPJe(ctx, idCtxt, origem, filePath, doc.Perfil, arq.Paginas, rel)
+		}
+		if err != nil {
+			logger.Log.Errorf("Erro na extração dos documentos - fileName=%s - contexto=%s: %v", row.NmFileNew, idCtxt, err)
+			return "", nil, fmt.Errorf("erro na extração dos documentos: %w", err)
+		}
 
-			//Convertendo PDF para TXT com o aplicativo "pdftotext"
-			txtPath, err := obj.convertePDFParaTexto(filePath)
-			if err != nil {
-				logger.Log.Errorf("Erro na extração do texto - fileName=%s - contexto=%s", row.NmFileNew, idCtxt)
-				extractedErros = append(extractedErros, idFile)
-				continue
+	}
+
+	if autuar {
+		docImportado, err := obj.SalvaTextoExtraido(idCtxt, idNatuTxt, idDocTxt, resultText, nil, &origem)
+		if err != nil {
+			logger.Log.Errorf("Erro ao salvar o texto extraído - fileName=%s - contexto=%s", row.NmFileNew, idCtxt)
+			return "", nil, fmt.Errorf("erro ao salvar o texto extraído: %w", err)
+		}
+		rel.adiciona(docImportado)
+	}
+	obj.registraImportacao(idCtxt, rel)
+	logger.Log.Infof("Importação de %s - contexto=%s: %s", row.NmFileOri, idCtxt, resumoRelatorio(rel))
+
+	//DELETA o registro em "uploads"
+	if err := obj.DeleteRegistro(idFile); err != nil {
+		logger.Log.Errorf("Erro ao deletar o registro no banco - id_file=%d", idFile)
+		return "", nil, fmt.Errorf("erro ao apagar o registro em uploads: %w", err)
+	}
+	//LIBERA o arquivo armazenado (mantido por BLOB_RETENCAO_DIAS, se configurado)
+	obj.liberaArquivo(context.WithoutCancel(ctx), row.NmFileNew, true)
+
+	return row.NmFileNew, rel, nil
+}
+
+/*
+idTextoEnviado identifica o .txt enviado pelo conteúdo, gravado como id_pje: o mesmo texto
+enviado de novo (mesmo com outro nome ou outra chave no armazenamento) é reconhecido como
+inalterado.
+*/
+func idTextoEnviado(texto string) string {
+	return "txt-" + HashTexto(texto)[:16]
+}
+
+/*
+Extrai o texto do arquivo PDF baixado do PJe, com todos os documentos dos autos,
+devolvendo o texto de cada página com o respectivo número. Nada é gravado em disco.
+*/
+func (obj *UploadServiceType) extraiPaginasPDF(ctx context.Context, pdfPath string) ([]pdftexto.Pagina, error) {
+	if obj.extrator == nil {
+		obj.extrator = novoExtratorPDF()
+	}
+	ini := time.Now()
+	paginas, err := obj.extrator.Extrai(ctx, pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.Infof("Texto extraído (%s): %s - %d páginas em %s", obj.extrator.Nome(), pdfPath, len(paginas), time.Since(ini).Round(time.Millisecond))
+	return paginas, nil
+}
+
+// importaAutosPJe extrai os documentos do PDF completo dos autos do PJe (índice + rodapé "Num. X - Pág. Y")
+func (obj *UploadServiceType) importaAutosPJe(ctx context.Context, idCtxt string, origem consts.ProcedenciaAutos, filePath string, idPerfil string, paginas []pdftexto.Pagina, rel *RelatorioImportacao) error {
+	//Perfil do tribunal: informado no upload ou detectado pelo texto
+	perfil, err := obj.perfilTribunal(idPerfil, paginas)
+	if err != nil {
+		return fmt.Errorf("perfil de tribunal inválido: %w", err)
+	}
+
+	//OCR das páginas digitalizadas
+	paginas = obj.reconhecePaginasDigitalizadas(ctx, filePath, perfil, paginas)
+
+	//Fazendo a extração dos documentos contidos nas páginas
+	_, err = obj.extrairDocumentosProcessuais(idCtxt, origem, perfil, paginas, rel)
+	return err
+}
+
+/*
+importaDocumentos grava no autos_temp as peças devolvidas pelo importador do sistema de
+origem (e-SAJ, eproc, Projudi), com os mesmos filtros de tipo e tamanho do PJe. O perfil
+de tribunal do importador orienta o OCR, tanto do PDF quanto dos PDFs contidos no ZIP.
+*/
+func (obj *UploadServiceType) importaDocumentos(ctx context.Context, idCtxt string, origem consts.ProcedenciaAutos, importador importadores.Importador, arq *importadores.Arquivo, rel *RelatorioImportacao) error {
+	perfil, err := tribunais.Global().Perfil(importador.Perfil())
+	if err != nil || perfil == nil {
+		perfil, _ = tribunais.Global().Perfil(tribunais.PERFIL_GENERICO)
+	}
+	if len(arq.Paginas) > 0 {
+		arq.Paginas = obj.reconhecePaginasDigitalizadas(ctx, arq.Caminho, perfil, arq.Paginas)
+	}
+	arq.ExtraiPDF = func(ctx context.Context, pdfPath string) ([]pdftexto.Pagina, error) {
+		paginas, err := obj.extraiPaginasPDF(ctx, pdfPath)
+		if err != nil {
+			return nil, err
+		}
+		return obj.reconhecePaginasDigitalizadas(ctx, pdfPath, perfil, paginas), nil
+	}
+
+	docs, err := importador.Importa(ctx, arq)
+	if err != nil {
+		return err
+	}
+	logger.Log.Infof("\n\n ** Iniciando Extração de Peças **\n\n")
+	logger.Log.Infof("Arquivo original: %s ", origem.Arquivo)
+	logger.Log.Infof("Formato: %s ", importador.Nome())
+	logger.Log.Infof("Quantidade de peças: %d ", len(docs))
+	logger.Log.Infof("\n\n **** \n\n")
+
+	var totalSalvos, totalIgnorados int
+	for _, d := range docs {
+		pags := faixaPaginas(d.Paginas)
+		switch {
+		case !obj.isDocumentoTipoValido(perfil, d.Tipo):
+			totalIgnorados++
+			logger.Log.Infof("ID: %s (%s): %s — IGNORADO: tipo não importável", d.Id, d.Fonte, d.Tipo)
+
+		case !obj.isDocumentoSizeValido(perfil, d.Texto, maxTextSize):
+			totalIgnorados++
+			logger.Log.Infof("ID: %s (%s): %s - %d bytes — IGNORADO: tamanho excede o limite(%d bytes) ou sem conteúdo", d.Id, d.Fonte, d.Tipo, len(d.Texto), maxTextSize)
+
+		default:
+			proc := procedenciaDocumento(origem, d.Paginas)
+			if d.Fonte != origem.Arquivo {
+				proc.Fonte = d.Fonte
 			}
+			proc.DtIndice = strings.TrimSpace(d.Data + " " + d.Hora)
+			proc.Signatario, proc.DtAssinatura = d.Signatario, d.DtAssinatura
 
-			//Fazendo a extração dos documentos contidos no arquivo texto
-			_, err = obj.extrairDocumentosProcessuais(idCtxt, row.NmFileOri, txtPath)
+			docImportado, err := obj.SalvaTextoExtraido(idCtxt, d.IdNatu, d.Id, d.Texto, d.Paginas, proc)
 			if err != nil {
-				logger.Log.Errorf("Erro na extração do texto - fileName=%s - contexto=%s", row.NmFileNew, doc.IdContexto)
-				extractedErros = append(extractedErros, idFile)
-				continue
-			}
-			//DELETA o arquivo .TXT
-			if err := obj.deletarArquivo(txtPath); err != nil {
-				logger.Log.Errorf("Erro ao deletar o arquivo físico - %s", txtPath)
-				extractedErros = append(extractedErros, idFile)
+				logger.Log.Errorf("[CTX=%s] ERRO ao salvar %s (%s, tipo=%s): %v", idCtxt, d.Id, d.Fonte, d.Tipo, err)
 				continue
 			}
+			rel.adiciona(docImportado)
+			totalSalvos++
+			logger.Log.Infof("ID: %s - Tipo: %s - Data: %s %s - %d bytes - %s (%s) - %s", d.Id, d.Tipo, d.Data, d.Hora, len(d.Texto), d.Fonte, pags, docImportado.Situacao)
+		}
+	}
+	logger.Log.Infof("Finalizado: %s — peças=%d, salvos=%d, ignorados=%d", origem.Arquivo, len(docs), totalSalvos, totalIgnorados)
+	return nil
+}
+
+/*
+perfilTribunal devolve o perfil indicado no upload (ou em PERFIL_TRIBUNAL); com "auto",
+o perfil é detectado pelas URLs e rodapés das primeiras páginas.
+*/
+func (obj *UploadServiceType) perfilTribunal(id string, paginas []pdftexto.Pagina) (*tribunais.Perfil, error) {
+	if strings.TrimSpace(id) == "" && config.GlobalConfig != nil {
+		id = config.GlobalConfig.PerfilTribunal
+	}
+	perfis := tribunais.Global()
+	perfil, err := perfis.Perfil(id)
+	if err != nil {
+		return nil, err
+	}
+	if perfil != nil {
+		logger.Log.Infof("Perfil de tribunal: %s (informado)", perfil.Id)
+		return perfil, nil
+	}
 
+	textos := make([]string, len(paginas))
+	for i, p := range paginas {
+		textos[i] = p.Texto
+	}
+	perfil = perfis.Detecta(textos)
+	logger.Log.Infof("Perfil de tribunal: %s (detectado)", perfil.Id)
+	return perfil, nil
+}
+
+/*
+Aplica o OCR às páginas digitalizadas: páginas quase sem texto (descontado o rodapé do PJe)
+cuja imagem principal cobre a maior parte da página. O texto reconhecido é colocado antes
+das linhas da camada de texto, que mantêm o rodapé "Num. X - Pág. N" usado para identificar
+o documento. Falhas no OCR de uma página são registradas e a página segue sem o texto.
+*/
+func (obj *UploadServiceType) reconhecePaginasDigitalizadas(ctx context.Context, pdfPath string, perfil *tribunais.Perfil, paginas []pdftexto.Pagina) []pdftexto.Pagina {
+	if obj.ocr == nil {
+		return paginas
+	}
+	var candidatas []int
+	for i, p := range paginas {
+		if obj.textoUtilPagina(perfil, p) < minTextoPagina {
+			candidatas = append(candidatas, i)
 		}
+	}
+	if len(candidatas) == 0 {
+		return paginas
+	}
 
-		if autuar {
-			err = obj.SalvaTextoExtraido(idCtxt, 0, row.NmFileNew, resultText)
-			if err != nil {
-				logger.Log.Errorf("Erro ao salvar o texto extraído - fileName=%s - contexto=%s", row.NmFileNew, idCtxt)
-				extractedErros = append(extractedErros, idFile)
-				continue
-			}
+	leitor, err := pdftexto.AbreImagens(pdfPath)
+	if err != nil {
+		logger.Log.Warningf("OCR ignorado - não foi possível ler as imagens de %s: %v", pdfPath, err)
+		return paginas
+	}
+
+	ini := time.Now()
+	reconhecidas := 0
+	for _, i := range candidatas {
+		if ctx.Err() != nil {
+			logger.Log.Warningf("OCR interrompido: %v", ctx.Err())
+			break
 		}
-		//DELETA o registro em "uploads"
-		if err := obj.DeleteRegistro(doc.IdFile); err != nil {
-			logger.Log.Errorf("Erro ao deletar o registro no banco - id_file=%d", idFile)
-			extractedErros = append(extractedErros, idFile)
+		pagina := &paginas[i]
+		img, err := leitor.ImagemPrincipal(pagina.Numero)
+		if err != nil {
+			logger.Log.Warningf("OCR - página %d: %v", pagina.Numero, err)
 			continue
 		}
-		//DELETA o arquivo .PDF
-		if err := obj.deletarArquivo(filePath); err != nil {
-			logger.Log.Errorf("Erro ao deletar o arquivo físico - %s", filePath)
-			extractedErros = append(extractedErros, idFile)
+		if img == nil || img.Cobertura < minCoberturaImagem {
+			// página em branco ou com imagem pequena (logotipo, carimbo): nada a reconhecer
 			continue
 		}
-
-		extractedFiles = append(extractedFiles, row.NmFileNew)
+		texto, err := obj.ocr.Reconhece(ctx, img.Dados, img.Formato)
+		if err != nil {
+			logger.Log.Warningf("OCR - página %d (%dx%d %s): %v", pagina.Numero, img.Largura, img.Altura, img.Formato, err)
+			continue
+		}
+		pagina.Texto = obj.mesclaTextoOCR(perfil, texto, pagina.Texto)
+		pagina.Origem = pdftexto.ORIGEM_OCR
+		reconhecidas++
 	}
+	logger.Log.Infof("OCR (%s): %s - %d de %d páginas candidatas reconhecidas em %s",
+		obj.ocr.Nome(), pdfPath, reconhecidas, len(candidatas), time.Since(ini).Round(time.Millisecond))
+	return paginas
+}
 
-	return extractedFiles, extractedErros
+// textoUtilPagina conta os caracteres visíveis da página, desconsiderando o rodapé do PJe
+func (obj *UploadServiceType) textoUtilPagina(perfil *tribunais.Perfil, p pdftexto.Pagina) int {
+	total := 0
+	for _, linha := range p.Linhas() {
+		if perfil.EhLinhaRodape(linha) {
+			continue
+		}
+		for _, r := range linha {
+			if r > ' ' {
+				total++
+			}
+		}
+	}
+	return total
 }
 
 /*
-Converte o arquivo PDF baixado do PJe, com todos os documentos dos autos,
-para o formato txt, criando um novo arquivo com o mesmo nome, e extensão
-.txt
+mesclaTextoOCR junta o texto reconhecido às linhas da camada de texto da página. Marcadores
+"Num. X - Pág. N" lidos na imagem (cópias impressas de outros documentos do PJe) são
+descartados: o documento da página é sempre o do rodapé aplicado pelo PJe.
 */
-func (obj *UploadServiceType) convertePDFParaTexto(pdfPath string) (string, error) {
-	txtFile := strings.TrimSuffix(pdfPath, ".pdf") + ".txt"
-
-	cmd := exec.Command("pdftotext", "-layout", pdfPath, txtFile)
-	err := cmd.Run()
-	if err != nil {
-		logger.Log.Errorf("Erro executando pdftotext: %v\n", err)
-		return "", err
+func (obj *UploadServiceType) mesclaTextoOCR(perfil *tribunais.Perfil, textoOCR string, camada string) string {
+	linhas := make([]string, 0, 64)
+	for _, linha := range strings.Split(textoOCR, "\n") {
+		if perfil.IdDocumento(linha) != "" {
+			continue
+		}
+		linhas = append(linhas, strings.TrimRight(linha, " \t"))
 	}
-
-	logger.Log.Infof("Arquivo salvo como: %s\n", txtFile)
-	return txtFile, nil
+	texto := strings.TrimSpace(strings.Join(linhas, "\n"))
+	if strings.TrimSpace(camada) == "" {
+		return texto
+	}
+	return texto + "\n\n" + camada
 }
 
 func (obj *UploadServiceType) extrairDocumentosProcessuais(
 	IdContexto string,
-	NmFileOri string,
-	txtPath string,
+	origem consts.ProcedenciaAutos,
+	perfil *tribunais.Perfil,
+	paginas []pdftexto.Pagina,
+	rel *RelatorioImportacao,
 ) (string, error) {
 
 	// 1) Extrai o índice para mapear ID → {Documento, Tipo, Data, Hora}
-	indice, err := obj.extrairIndice(txtPath)
+	indice, err := obj.extrairIndice(perfil, paginas)
 	if err != nil {
 		return "", fmt.Errorf("erro ao extrair índice: %w", err)
 	}
 	//logger.Log.Infof("[CTX=%s] ", IdContexto)
 	logger.Log.Infof("\n\n ** Iniciando Extração de Peças **\n\n")
-	logger.Log.Infof("Arquivo original: %s ", NmFileOri)
-	logger.Log.Infof("Arquivo upload: '%s' ", txtPath)
+	logger.Log.Infof("Arquivo original: %s ", origem.Arquivo)
+	logger.Log.Infof("Perfil do tribunal: %s ", perfil.Nome)
+	logger.Log.Infof("Páginas: %d ", len(paginas))
 	logger.Log.Infof("Quantidade de peças: %d ", len(indice))
 
 	logger.Log.Infof("\n\n **** \n\n")
 
-	// 2) Abre o TXT para varrer páginas/linhas
-	file, err := os.Open(txtPath)
-	if err != nil {
-		logger.Log.Errorf("[CTX=%s] Erro ao abrir TXT: %v", IdContexto, err)
-		return "", err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	// Aumenta o buffer para evitar truncamentos em linhas longas
-	const maxTokenSize = 1024 * 1024
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, maxTokenSize)
-
 	var (
-		lastDocNumber    string
-		pageLinesBuffer  []string
-		docsPages        = make(map[string][]string) // acumula “pedaços de página” por documento
-		firstMarkerFound bool
-		lineNo           int
-		totalSalvos      int
-		totalIgnorados   int
-		totalFechados    int
+		lastDocNumber  string
+		docsPages      = make(map[string][]string)             // acumula as linhas das páginas por documento
+		docsPaginas    = make(map[string][]consts.PaginaAutos) // páginas (no PDF) de cada documento e origem do texto
+		totalSalvos    int
+		totalIgnorados int
+		totalFechados  int
 	)
 
 	// Helper: tenta salvar/descartar o documento anterior com logs detalhados
@@ -284,105 +571,136 @@ func (obj *UploadServiceType) extrairDocumentosProcessuais(
 		}
 		totalFechados++
 		docLines := docsPages[docNumber]
+		pags := faixaPaginas(docsPaginas[docNumber])
 
-		docText, err := obj.removeRodape(docLines)
+		docText, err := obj.removeRodape(perfil, docLines)
 		if err != nil {
 			logger.Log.Errorf("[CTX=%s] Erro limpando rodapé do Num=%s: %v", IdContexto, docNumber, err)
 			return
 		}
 
-		nmFile := obj.ultimosNDigitos(docNumber, 9)
+		nmFile := perfil.IdPje(docNumber)
 		docInfo, existe := indice[nmFile]
 		if !existe || docInfo == nil {
 			totalIgnorados++
-			logger.Log.Infof("IDPJE: %s — IGNORADO: inexistente no índice (chave=%s)", docNumber, nmFile)
+			logger.Log.Infof("IDPJE: %s (%s) — IGNORADO: inexistente no índice (chave=%s)", docNumber, pags, nmFile)
 			docsPages[docNumber] = nil
 			return
 		}
 
 		switch {
 
-		case !obj.isDocumentoTipoValido(docInfo.Tipo):
+		case !obj.isDocumentoTipoValido(perfil, docInfo.Tipo):
 			totalIgnorados++
 			logger.Log.Infof("IDPJE: %s:  %s) — IGNORADO: tipo não importável", docNumber, docInfo.Tipo)
 
-		case !obj.isDocumentoSizeValido(docText, maxTextSize):
+		case !obj.isDocumentoSizeValido(perfil, docText, maxTextSize):
 			totalIgnorados++
 			logger.Log.Infof("IDPJE: %s:  %s - %d) — IGNORADO: tamanho excete limite(%d bytes)", docNumber, docInfo.Tipo, len([]byte(docText)), maxTextSize)
 
 		default:
-			idNatu := consts.GetCodigoNatureza(docInfo.Tipo)
-			if err := obj.SalvaTextoExtraido(IdContexto, idNatu, nmFile, docText); err != nil {
+			idNatu := perfil.CodigoNatureza(docInfo.Tipo)
+			// Procedência: páginas no PDF, data/hora do índice e assinatura eletrônica
+			proc := procedenciaDocumento(origem, docsPaginas[docNumber])
+			proc.IdDocumento = docNumber
+			proc.DtIndice = strings.TrimSpace(docInfo.Data + " " + docInfo.Hora)
+			proc.Signatario, proc.DtAssinatura = perfil.Assinatura(docText)
+
+			docImportado, err := obj.SalvaTextoExtraido(IdContexto, idNatu, nmFile, docText, docsPaginas[docNumber], proc)
+			if err != nil {
 				logger.Log.Errorf("[CTX=%s] ERRO ao salvar Num=%s (nmFile=%s, tipo=%s): %v",
 					IdContexto, docNumber, nmFile, docInfo.Tipo, err)
 			} else {
+				rel.adiciona(docImportado)
 				totalSalvos++
-				logger.Log.Infof("IDPJE: %s - Tipo: %s - %d bytes)",
-					docNumber, docInfo.Tipo, len([]byte(docText)))
+				logger.Log.Infof("IDPJE: %s - Tipo: %s - %d bytes - %s) - %s",
+					docNumber, docInfo.Tipo, len([]byte(docText)), pags, docImportado.Situacao)
 			}
 		}
 
 		// limpa o acumulador do doc anterior para liberar memória
 		docsPages[docNumber] = nil
+		docsPaginas[docNumber] = nil
 	}
 
-	// 3) Varre o arquivo linha a linha
-	for scanner.Scan() {
-		lineNo++
-		linhaOriginal := scanner.Text()
-		//linha := obj.normalizaURLRodape(linhaOriginal) // já remove \f e normaliza espaços
-		linha := obj.normalizaURLRodape(linhaOriginal) // já remove \f e normaliza espaços
-
-		// Sempre acumula a linha atual como parte do “bloco” corrente
-		pageLinesBuffer = append(pageLinesBuffer, linha)
-
-		// Tenta detectar o marcador de página/ID: "Num. <digits> - Pág."
-		numeroDocumento := obj.getDocumentoID(linha)
-		if numeroDocumento != "" {
-			//logger.Log.Debugf("[CTX=%d][L%d] Encontrado marcador: Num=%s", IdContexto, lineNo, numeroDocumento)
-
-			if !firstMarkerFound {
-				firstMarkerFound = true
-				lastDocNumber = numeroDocumento
-				//logger.Log.Debugf("[CTX=%d] Primeiro marcador definido: lastDoc=%s", IdContexto, lastDocNumber)
-			} else if numeroDocumento != lastDocNumber {
-				// Fechamos o documento anterior e iniciamos um novo
-				//logger.Log.Debugf("[CTX=%d] Troca de doc: %s → %s", IdContexto, lastDocNumber, numeroDocumento)
-				saveOrSkip(lastDocNumber)
-				lastDocNumber = numeroDocumento
+	// 2) Varre as páginas. O documento de cada página é identificado pelo rodapé do PJe
+	// ("Num. <id> - Pág. <n>"); a página em si vem numerada pelo extrator.
+	for _, pagina := range paginas {
+		linhasPagina := pagina.Linhas()
+		linhas := make([]string, 0, len(linhasPagina))
+		numeroDocumento := ""
+		for _, linhaOriginal := range linhasPagina {
+			linha := obj.normalizaURLRodape(perfil, linhaOriginal) // já remove \f e normaliza espaços
+			linhas = append(linhas, linha)
+			if numeroDocumento == "" {
+				numeroDocumento = perfil.IdDocumento(linha)
+			}
+		}
+
+		if numeroDocumento == "" {
+			// Página sem rodapé (capa/índice dos autos ou página sem texto): se já houver
+			// documento aberto, a página é agregada a ele
+			if lastDocNumber != "" {
+				docsPages[lastDocNumber] = append(docsPages[lastDocNumber], linhas...)
+				docsPaginas[lastDocNumber] = append(docsPaginas[lastDocNumber], consts.PaginaAutos{Numero: pagina.Numero, Origem: pagina.Origem})
 			}
+			continue
+		}
 
-			// Move o bloco acumulado para o doc atual e zera o buffer
-			docsPages[lastDocNumber] = append(docsPages[lastDocNumber], pageLinesBuffer...)
-			// logger.Log.Debugf("[CTX=%d] Acumulado em Num=%s (chunk linhas=%d, total=%d)",
-			// 	IdContexto, lastDocNumber, len(pageLinesBuffer), len(docsPages[lastDocNumber]))
-			pageLinesBuffer = nil
+		if numeroDocumento != lastDocNumber {
+			// Fechamos o documento anterior e iniciamos um novo
+			saveOrSkip(lastDocNumber)
+			lastDocNumber = numeroDocumento
 		}
+		docsPages[lastDocNumber] = append(docsPages[lastDocNumber], linhas...)
+		docsPaginas[lastDocNumber] = append(docsPaginas[lastDocNumber], consts.PaginaAutos{Numero: pagina.Numero, Origem: pagina.Origem})
 	}
 
-	// 4) Fecha o último documento (se houver)
-	if firstMarkerFound {
-		// Acrescenta o que sobrou do buffer ao último doc
-		if len(pageLinesBuffer) > 0 {
-			docsPages[lastDocNumber] = append(docsPages[lastDocNumber], pageLinesBuffer...)
-			logger.Log.Debugf("EOF: anexado restante ao Num=%s (restante linhas=%d, total=%d)",
-				lastDocNumber, len(pageLinesBuffer), len(docsPages[lastDocNumber]))
-		}
+	// 3) Fecha o último documento (se houver)
+	if lastDocNumber != "" {
 		saveOrSkip(lastDocNumber)
 	} else {
-		logger.Log.Warningf("[CTX=%s] Nenhum marcador 'Num. <id> - Pág.' encontrado no arquivo — nada a salvar.", IdContexto)
-	}
-
-	if err := scanner.Err(); err != nil {
-		logger.Log.Errorf("[CTX=%s] Erro na leitura do arquivo: %v", IdContexto, err)
+		logger.Log.Warningf("[CTX=%s] Nenhum marcador de documento (perfil %s) encontrado no arquivo — nada a salvar.", IdContexto, perfil.Id)
 	}
 
 	logger.Log.Infof("Finalizado: %s  — fechados=%d, salvos=%d, ignorados=%d",
-		txtPath, totalFechados, totalSalvos, totalIgnorados)
+		origem.Arquivo, totalFechados, totalSalvos, totalIgnorados)
 
 	return "", nil
 }
 
+// procedenciaDocumento parte da procedência do arquivo enviado, com as páginas do documento no PDF
+func procedenciaDocumento(origem consts.ProcedenciaAutos, pags []consts.PaginaAutos) *consts.ProcedenciaAutos {
+	proc := origem
+	if len(pags) > 0 {
+		proc.PagIni, proc.PagFim = pags[0].Numero, pags[len(pags)-1].Numero
+	}
+	return &proc
+}
+
+// faixaPaginas descreve as páginas do documento no PDF (ex.: "págs. 12-15, 2 por OCR")
+func faixaPaginas(pags []consts.PaginaAutos) string {
+	var faixa string
+	switch len(pags) {
+	case 0:
+		return "sem páginas"
+	case 1:
+		faixa = fmt.Sprintf("pág. %d", pags[0].Numero)
+	default:
+		faixa = fmt.Sprintf("págs. %d-%d", pags[0].Numero, pags[len(pags)-1].Numero)
+	}
+	ocr := 0
+	for _, p := range pags {
+		if p.Origem == pdftexto.ORIGEM_OCR {
+			ocr++
+		}
+	}
+	if ocr > 0 {
+		faixa += fmt.Sprintf(", %d por OCR", ocr)
+	}
+	return faixa
+}
+
 func (obj *UploadServiceType) deletarArquivo(filePath string) error {
 	if files.FileExist(filePath) {
 		err := files.DeletarFile(filePath)
@@ -394,27 +712,104 @@ func (obj *UploadServiceType) deletarArquivo(filePath string) error {
 	return nil
 }
 
-func (obj *UploadServiceType) SalvaTextoExtraido(idCtxt string, idNatu int, idPje string, texto string) error {
+// ArmazenaArquivo grava o arquivo local no armazenamento, na chave endereçada pelo conteúdo
+func (obj *UploadServiceType) ArmazenaArquivo(ctx context.Context, caminho string, nmFileOri string) (string, error) {
+	chave, _, err := armazenamento.GravaArquivo(ctx, armazenamento.Global(), caminho, filepath.Ext(nmFileOri))
+	if err != nil {
+		logger.Log.Errorf("Erro ao armazenar o arquivo %s: %v", nmFileOri, err)
+		return "", err
+	}
+	return chave, nil
+}
+
+// ApagaUpload apaga o registro em "uploads" e o arquivo armazenado, se nenhum outro registro o usa
+func (obj *UploadServiceType) ApagaUpload(ctx context.Context, row *models.UploadRow) error {
+	if row == nil {
+		return fmt.Errorf("registro não encontrado em uploads")
+	}
+	if err := obj.DeleteRegistro(row.IdFile); err != nil {
+		return err
+	}
+	if row.Status == models.UPLOAD_STATUS_RECEBENDO {
+		obj.apagaBlocos(ctx, row.NmFileNew)
+		return nil
+	}
+	obj.liberaArquivo(ctx, row.NmFileNew, false)
+	return nil
+}
+
+/*
+liberaArquivo apaga o arquivo armazenado que deixou de ser usado. O mesmo conteúdo pode
+estar em outro registro (chave endereçada pelo conteúdo): nesse caso, fica. Após a
+extração (retencao), com BLOB_MANTER_ORIGINAIS, o arquivo é mantido para a consulta das
+páginas citadas; com BLOB_RETENCAO_DIAS > 0, é mantido e apagado depois pelo
+ArmazenamentoCleaner.
+*/
+func (obj *UploadServiceType) liberaArquivo(ctx context.Context, chave string, retencao bool) {
+	cfg := config.GlobalConfig
+	if retencao && armazenamento.EhChaveConteudo(chave) && cfg != nil && (cfg.BlobManterOriginais || cfg.BlobRetencaoDias > 0) {
+		return
+	}
+	if emUso, err := obj.Model.ExisteArquivo(chave); err != nil || emUso {
+		return
+	}
+	if err := armazenamento.Global().Apaga(ctx, chave); err != nil {
+		logger.Log.Errorf("Erro ao apagar o arquivo armazenado - %s: %v", chave, err)
+	}
+}
+
+/*
+SalvaTextoExtraido grava o documento extraído em "autos_temp", comparando o hash do texto
+com a versão do mesmo documento no contexto: ainda não autuada ("autos_temp"), a versão
+alterada a substitui; já autuada ("autos"), a versão alterada vai para "autos_temp" e
+substitui a anterior na autuação. O documento inalterado não é gravado de novo. A
+procedência (páginas, índice e assinatura) acompanha o texto até a autuação.
+*/
+func (obj *UploadServiceType) SalvaTextoExtraido(idCtxt string, idNatu int, idPje string, texto string, paginas []consts.PaginaAutos, procedencia *consts.ProcedenciaAutos) (DocumentoImportado, error) {
+	doc := DocumentoImportado{IdPje: idPje, IdNatu: idNatu, Sha256: HashTexto(texto)}
 
 	autos_temp := opensearch.NewAutos_tempIndex()
 
-	exist, err := autos_temp.IsExisteByIdPje(idPje)
+	temp, err := autos_temp.ConsultaByIdPje(idCtxt, idPje)
 	if err != nil {
 		logger.Log.Errorf("Erro ao verificar existência: %v", err)
-		return err
+		return doc, err
 	}
-	if exist {
-		logger.Log.Infof("Documento IDPJE: %s já existe", idPje)
-		return nil
+	if temp != nil {
+		if HashTexto(temp.Doc) == doc.Sha256 {
+			doc.Situacao = DOC_INALTERADO
+			return doc, nil
+		}
+		if err := autos_temp.AtualizaTexto(temp.Id, idNatu, texto, paginas, procedencia); err != nil {
+			logger.Log.Errorf("Erro ao atualizar o documento IDPJE: %s: %v", idPje, err)
+			return doc, err
+		}
+		doc.Situacao = DOC_ALTERADO
+		return doc, nil
+	}
+
+	autuado, err := AutosServiceGlobal.SelectByIdPje(idCtxt, idPje)
+	if err != nil {
+		logger.Log.Errorf("Erro ao verificar existência em 'autos': %v", err)
+		return doc, err
+	}
+	doc.Situacao = DOC_NOVO
+	if autuado != nil {
+		doc.Autuado = true
+		if HashTexto(autuado.Doc) == doc.Sha256 {
+			doc.Situacao = DOC_INALTERADO
+			return doc, nil
+		}
+		doc.Situacao = DOC_ALTERADO
 	}
 
-	_, err = autos_temp.Indexa(idCtxt, idNatu, idPje, texto, "")
+	_, err = autos_temp.Indexa(idCtxt, idNatu, idPje, texto, paginas, procedencia, "")
 	if err != nil {
 		logger.Log.Errorf("Erro ao inserir linha: %v", err)
-		return err
+		return doc, err
 	}
 	//logger.Log.Infof("Doc %s - idNatu=%d", idPje, idNatu)
-	return nil
+	return doc, nil
 
 }
 
@@ -444,29 +839,16 @@ func (obj *UploadServiceType) DeleteRegistro(idFile int) error {
 	return err
 }
 
-// extrairIndice extrai o índice do arquivo texto, devolvendo um mapa id → DocumentoIndice
-func (obj *UploadServiceType) extrairIndice(txtPath string) (map[string]*DocumentoIndice, error) {
-	file, err := os.Open(txtPath)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao abrir arquivo: %w", err)
-	}
-	defer file.Close()
+// extrairIndice extrai o índice das páginas do PDF, devolvendo um mapa id → DocumentoIndice.
+// A linha do índice segue o perfil do tribunal (id, data e as colunas documento/tipo).
+func (obj *UploadServiceType) extrairIndice(perfil *tribunais.Perfil, paginas []pdftexto.Pagina) (map[string]*DocumentoIndice, error) {
 
-	scanner := bufio.NewScanner(file)
-	// Aumenta limite de token para linhas atípicas (opcional, mas seguro)
-	const maxTokenSize = 1024 * 1024
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, maxTokenSize)
-
-	// Aceita controles/brancos no início da linha antes do ID
-	reLinhaIndice := regexp.MustCompile(`^[\f\t\r ]*(\d+)\s+(\d{2}/\d{2}/\d{4})\s+(.*)$`)
 	reHora := regexp.MustCompile(`\b(\d{2}:\d{2})\b`)
 
 	indice := make(map[string]*DocumentoIndice)
 	var linhaAnteriorIndice *DocumentoIndice
 
-	for scanner.Scan() {
-		linha := scanner.Text()
+	for _, linha := range strings.Split(pdftexto.TextoCompleto(paginas), "\n") {
 		// Sanitiza: remove form-feed e outros controles não impressos, preservando \n (já removido pelo Scanner)
 		linha = strings.Map(func(r rune) rune {
 			// Remove form-feed e demais controles (exceto TAB, que pode existir entre colunas)
@@ -477,11 +859,7 @@ func (obj *UploadServiceType) extrairIndice(txtPath string) (map[string]*Documen
 		}, linha)
 		linha = strings.TrimRight(linha, " \r")
 
-		if reLinhaIndice.MatchString(linha) {
-			matches := reLinhaIndice.FindStringSubmatch(linha)
-			id := matches[1]
-			data := matches[2]
-			resto := matches[3]
+		if id, data, resto, ok := perfil.LinhaIndice(linha); ok {
 
 			// Divide por 2+ espaços (colunas); o último item tende a ser o "Tipo"
 			partes := regexp.MustCompile(`\s{2,}`).Split(resto, -1)
@@ -515,9 +893,6 @@ func (obj *UploadServiceType) extrairIndice(txtPath string) (map[string]*Documen
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
 	return indice, nil
 }
 
@@ -530,7 +905,8 @@ inserido automaticamente pelo PJe.
 // preservando a data da assinatura eletrônica quando existente.
 // normalizaURLRodape faz a limpeza e normalização da linha de rodapé do PJe.
 // Se a linha contiver "Assinado eletronicamente por", preserva a formatação da assinatura.
-func (obj *UploadServiceType) normalizaURLRodape(linha string) string {
+// As correções da URL próprias do tribunal (ex.: "pje1grau") vêm do perfil.
+func (obj *UploadServiceType) normalizaURLRodape(perfil *tribunais.Perfil, linha string) string {
 	// Remove caracteres de controle (form-feed etc.)
 	linha = strings.Map(func(r rune) rune {
 		if r == '\f' || (r < 32 && r != '\t') {
@@ -555,11 +931,7 @@ func (obj *UploadServiceType) normalizaURLRodape(linha string) string {
 	rePontos := regexp.MustCompile(`(\w)\s+(\.)\s*(\w)`)
 	linha = rePontos.ReplaceAllString(linha, `$1.$3`)
 
-	rePje1 := regexp.MustCompile(`pje\s+1`)
-	linha = rePje1.ReplaceAllString(linha, `pje1`)
-
-	rePje1Grau := regexp.MustCompile(`pje1\s+grau`)
-	linha = rePje1Grau.ReplaceAllString(linha, `pje1grau`)
+	linha = perfil.NormalizaLinha(linha)
 
 	reEspacosEspeciais := regexp.MustCompile(`\s*([:/?=])\s*`)
 	linha = reEspacosEspeciais.ReplaceAllString(linha, `$1`)
@@ -573,21 +945,11 @@ func (obj *UploadServiceType) normalizaURLRodape(linha string) string {
 	return strings.TrimSpace(linha)
 }
 
-/*
-Função utilitária que complementa a extração do ID do documento.
-*/
-func (obj *UploadServiceType) ultimosNDigitos(s string, n int) string {
-	if len(s) > n {
-		return s[len(s)-n:]
-	}
-	return s
-}
-
 // Função que verifica se o tipo de documento deve importado e salvo
-func (obj *UploadServiceType) isDocumentoTipoValido(tipo string) bool {
+func (obj *UploadServiceType) isDocumentoTipoValido(perfil *tribunais.Perfil, tipo string) bool {
 
 	//logger.Log.Infof("Tipo: %s", tipo)
-	natu := consts.GetCodigoNatureza(tipo)
+	natu := perfil.CodigoNatureza(tipo)
 
 	for _, v := range naturezasValidasImportarPJE {
 		if v == natu {
@@ -599,7 +961,7 @@ func (obj *UploadServiceType) isDocumentoTipoValido(tipo string) bool {
 
 }
 
-func (obj *UploadServiceType) isDocumentoSizeValido(texto string, limiteBytes int) bool {
+func (obj *UploadServiceType) isDocumentoSizeValido(perfil *tribunais.Perfil, texto string, limiteBytes int) bool {
 	// Calcula tamanho total do texto
 	tamanho := len([]byte(texto))
 	if tamanho > limiteBytes {
@@ -607,8 +969,8 @@ func (obj *UploadServiceType) isDocumentoSizeValido(texto string, limiteBytes in
 		return false
 	}
 
-	// Regex para detectar linhas do tipo "Num. 12345 - Pág. 1"
-	rePagina := regexp.MustCompile(`(?i)^num\.\s*\d+\s*-\s*p[áa]g\.\s*\d+`)
+	// Linhas de numeração de página do tribunal (ex.: "Num. 12345 - Pág. 1")
+	rePagina := perfil.LinhaPagina()
 
 	// Filtra linhas relevantes
 	linhas := strings.Split(texto, "\n")
@@ -619,7 +981,7 @@ func (obj *UploadServiceType) isDocumentoSizeValido(texto string, limiteBytes in
 		if linhaNorm == "" {
 			continue
 		}
-		if rePagina.MatchString(linhaNorm) {
+		if rePagina.MatchString(strings.TrimSpace(linha)) {
 			continue
 		}
 		restantes = append(restantes, linhaNorm)
@@ -635,30 +997,16 @@ func (obj *UploadServiceType) isDocumentoSizeValido(texto string, limiteBytes in
 	return true
 }
 
-/*
-Função utilitária que extrai o ID do documento para ser utilizado como o nome para fins
-de registro na tabela "docsocr"
-*/
-
-func (obj *UploadServiceType) getDocumentoID(texto string) string {
-	//re := regexp.MustCompile(`Num\.\s*(\d+)\s*[-–—]\s*Pág\.`)
-	re := regexp.MustCompile(`Num\.?\s*(\d{6,12})\s*[-–—]\s*Pág\.?`)
-
-	if m := re.FindStringSubmatch(texto); len(m) == 2 {
-		return m[1]
-	}
-	return ""
-}
-
 /*
 Rotina que extrai o rodapé das páginas dos documentos criados pelo PJe,
 removendo as linhas técnicas (usuário, número, URL),
 mas preservando a linha de assinatura eletrônica e a numeração de página.
+Os padrões do rodapé, da assinatura e da numeração vêm do perfil do tribunal.
 Insere:
   - Linha pontilhada antes da assinatura eletrônica;
   - Linha pontilhada após a linha de numeração "Num. ... - Pág. ...".
 */
-func (obj *UploadServiceType) removeRodape(lines []string) (string, error) {
+func (obj *UploadServiceType) removeRodape(perfil *tribunais.Perfil, lines []string) (string, error) {
 	// Junta todas as linhas em um texto único
 	textoCompleto := strings.Join(lines, "\n")
 
@@ -669,20 +1017,18 @@ func (obj *UploadServiceType) removeRodape(lines []string) (string, error) {
 	// "https://pje.tjce.jus.br..."
 	// Mantém "Assinado eletronicamente por ..."
 	// ============================================================
-	padrao := `(?m)Este documento foi gerado pelo usuário\s+[\d*.\-]+ em \d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2}\nNúmero do documento:\s*\d+\nhttps?://[^\n]+\n?`
-	reRodape := regexp.MustCompile(padrao)
-	textoSemRodape := reRodape.ReplaceAllString(textoCompleto, "")
+	textoSemRodape := perfil.RemoveRodape(textoCompleto)
 
 	// ============================================================
 	// 🔹 Linha pontilhada antes da assinatura eletrônica
 	// ============================================================
-	reAssinatura := regexp.MustCompile(`(?m)^(Assinado eletronicamente por:[^\n]+)$`)
+	reAssinatura := perfil.LinhaAssinatura()
 	textoSemRodape = reAssinatura.ReplaceAllString(textoSemRodape, "----------------------------------------\n$1")
 
 	// ============================================================
 	// 🔹 Linha pontilhada após a numeração de página ("Num. ... - Pág. ...")
 	// ============================================================
-	reNumPag := regexp.MustCompile(`(?m)^(Num\.\s*\d+\s*-\s*Pág\.\s*\d+)$`)
+	reNumPag := perfil.LinhaPagina()
 	textoSemRodape = reNumPag.ReplaceAllString(textoSemRodape, "$1\n----------------------------------------")
 
 	// ============================================================
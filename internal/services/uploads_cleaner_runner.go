@@ -0,0 +1,122 @@
+package services
+
+/*
+File: uploads_cleaner_runner.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Faz a limpeza do armazenamento dos uploads, a cada hora: descarta os uploads
+retomáveis expirados e apaga os arquivos endereçados pelo conteúdo (sha256/...) que
+nenhum registro de "uploads" usa há mais de BLOB_RETENCAO_DIAS (no mínimo 24 horas,
+para não apagar o arquivo gravado enquanto o registro ainda está sendo inserido). Com
+BLOB_MANTER_ORIGINAIS, os arquivos já extraídos ("uploads_importados") são mantidos:
+deles se abre a página original citada nos autos.
+
+*/
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"ocrserver/internal/config"
+	"ocrserver/internal/services/armazenamento"
+	"ocrserver/internal/utils/logger"
+)
+
+type UploadsCleaner struct {
+	svc             *UploadServiceType
+	interval        time.Duration
+	olderThan       time.Duration
+	manterOriginais bool
+
+	running atomic.Bool // impede sobreposição
+}
+
+func NewUploadsCleaner(svc *UploadServiceType, cfg *config.Config) *UploadsCleaner {
+	olderThan := 24 * time.Hour
+	if cfg != nil && cfg.BlobRetencaoDias > 0 {
+		olderThan = time.Duration(cfg.BlobRetencaoDias) * 24 * time.Hour
+	}
+	return &UploadsCleaner{
+		svc:             svc,
+		interval:        time.Hour,
+		olderThan:       olderThan,
+		manterOriginais: cfg != nil && cfg.BlobManterOriginais,
+	}
+}
+
+// Start roda em goroutine. Para parar, cancele o ctx.
+func (c *UploadsCleaner) Start(ctx context.Context) {
+	if c == nil || c.svc == nil {
+		logger.Log.Error("UploadsCleaner: svc nil (não iniciado)")
+		return
+	}
+
+	go func() {
+		c.runOnce(ctx)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Log.Info("UploadsCleaner: finalizando (ctx cancelado).")
+				return
+			case <-ticker.C:
+				c.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (c *UploadsCleaner) runOnce(ctx context.Context) {
+	if !c.running.CompareAndSwap(false, true) {
+		logger.Log.Warning("UploadsCleaner: execução anterior ainda em andamento; pulando este ciclo.")
+		return
+	}
+	defer c.running.Store(false)
+
+	start := time.Now()
+	runCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	c.svc.LimpaRetomaveisExpirados(runCtx)
+
+	store := armazenamento.Global()
+	objetos, err := store.Lista(runCtx, armazenamento.PREFIXO_CONTEUDO)
+	if err != nil {
+		logger.Log.Warningf("UploadsCleaner: erro ao listar o armazenamento: %v", err)
+		return
+	}
+	cutoff := start.Add(-c.olderThan)
+	removidos := 0
+	for _, o := range objetos {
+		if runCtx.Err() != nil {
+			break
+		}
+		if o.DtModificacao.After(cutoff) {
+			continue
+		}
+		emUso, err := c.svc.Model.ExisteArquivo(o.Chave)
+		if err != nil || emUso {
+			continue
+		}
+		if c.manterOriginais {
+			if importado, err := c.svc.Model.ExisteImportado(armazenamento.HashDaChave(o.Chave)); err != nil || importado {
+				continue
+			}
+		}
+		if err := store.Apaga(runCtx, o.Chave); err != nil {
+			logger.Log.Warningf("UploadsCleaner: %s não apagado: %v", o.Chave, err)
+			continue
+		}
+		removidos++
+	}
+
+	logger.Log.Infof(
+		"Finalizado cleanup do armazenamento dos uploads: removidos=%d, duração=%s",
+		removidos,
+		time.Since(start).Truncate(time.Millisecond),
+	)
+}
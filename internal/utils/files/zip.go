@@ -0,0 +1,183 @@
+/*
+---------------------------------------------------------------------------------------
+File: zip.go
+Autor: Aldenor
+Data: 18-10-2026
+Finalidade: Descompactação segura dos ZIPs recebidos no upload em lote. Entradas com
+caminho absoluto ou com ".." (zip-slip) são recusadas, e o arquivo gravado recebe um
+nome gerado, nunca o caminho da entrada. Contra ZIPs-bomba, são limitados a quantidade
+de entradas, o tamanho de cada arquivo, a soma descompactada e a taxa de compressão;
+os limites valem para os bytes efetivamente lidos, não para o tamanho declarado no ZIP.
+---------------------------------------------------------------------------------------
+*/
+package files
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrLimiteZip = errors.New("limite de descompactação excedido")
+
+// LimitesZip são os limites aplicados na descompactação
+type LimitesZip struct {
+	MaxEntradas     int
+	MaxBytesEntrada int64
+	MaxBytesTotal   int64
+	MaxTaxa         int64 // razão máxima entre o tamanho descompactado e o compactado
+}
+
+// EntradaZip é o resultado de cada arquivo do ZIP
+type EntradaZip struct {
+	Nome    string // caminho da entrada no ZIP
+	Caminho string // arquivo gravado; vazio se a entrada foi ignorada
+	Motivo  string // motivo de a entrada ter sido ignorada
+}
+
+/*
+DescompactaZip grava em destino as entradas com as extensões aceitas (ex.: ".pdf", ".txt").
+As demais entradas voltam com o motivo de terem sido ignoradas. O erro indica um ZIP
+ilegível ou com entradas demais; nesse caso nada é gravado.
+*/
+func DescompactaZip(zipPath string, destino string, limites LimitesZip, extensoes []string) ([]EntradaZip, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o ZIP: %w", err)
+	}
+	defer zr.Close()
+
+	if limites.MaxEntradas > 0 && len(zr.File) > limites.MaxEntradas {
+		return nil, fmt.Errorf("%w: %d entradas (máximo %d)", ErrLimiteZip, len(zr.File), limites.MaxEntradas)
+	}
+	if err := os.MkdirAll(destino, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("erro ao criar o diretório %s: %w", destino, err)
+	}
+
+	var (
+		entradas []EntradaZip
+		total    int64
+		esgotado bool
+	)
+	for i, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		e := EntradaZip{Nome: f.Name}
+		nome := path.Base(f.Name)
+		ext := strings.ToLower(path.Ext(nome))
+
+		switch {
+		case !caminhoSeguro(f.Name):
+			e.Motivo = "caminho inválido no ZIP (absoluto ou com \"..\")"
+		case strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(nome, "."):
+			e.Motivo = "arquivo oculto ou de sistema"
+		case ext == ".zip":
+			e.Motivo = "ZIP dentro do ZIP não é descompactado"
+		case !aceitaExtensao(ext, extensoes):
+			e.Motivo = fmt.Sprintf("extensão %q não aceita (use %s)", ext, strings.Join(extensoes, ", "))
+		case esgotado:
+			e.Motivo = fmt.Sprintf("limite de %d MB descompactados do ZIP atingido", limites.MaxBytesTotal>>20)
+		case f.UncompressedSize64 == 0:
+			e.Motivo = "arquivo vazio"
+		default:
+			restante := limites.MaxBytesTotal - total
+			if limites.MaxBytesTotal <= 0 {
+				restante = -1
+			}
+			arquivo := filepath.Join(destino, fmt.Sprintf("%d_%d%s", time.Now().UnixNano(), i, ext))
+			n, err := gravaEntrada(f, arquivo, limites, restante)
+			if err != nil {
+				e.Motivo = err.Error()
+				// estourou a soma do ZIP (e não só o limite do arquivo): as entradas seguintes são ignoradas
+				if errors.Is(err, ErrLimiteZip) && restante >= 0 && n > restante {
+					esgotado = true
+				}
+			} else {
+				total += n
+				e.Caminho = arquivo
+				// soma do ZIP atingida exatamente: não resta espaço para as entradas seguintes
+				if limites.MaxBytesTotal > 0 && total >= limites.MaxBytesTotal {
+					esgotado = true
+				}
+			}
+		}
+		entradas = append(entradas, e)
+	}
+	return entradas, nil
+}
+
+// Limite de bytes de gravaEntrada ausente (0 é um limite: nenhum byte)
+const semLimiteZip int64 = -1
+
+// gravaEntrada descompacta a entrada; restante < 0 indica sem limite total
+func gravaEntrada(f *zip.File, arquivo string, limites LimitesZip, restante int64) (int64, error) {
+	limite := semLimiteZip
+	if limites.MaxBytesEntrada > 0 {
+		limite = limites.MaxBytesEntrada
+	}
+	if restante >= 0 && (limite == semLimiteZip || restante < limite) {
+		limite = restante
+	}
+	if limites.MaxTaxa > 0 && f.CompressedSize64 > 0 {
+		if porTaxa := int64(f.CompressedSize64) * limites.MaxTaxa; limite == semLimiteZip || porTaxa < limite {
+			limite = porTaxa
+		}
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("entrada ilegível: %w", err)
+	}
+	defer rc.Close()
+
+	out, err := os.Create(arquivo)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao gravar: %w", err)
+	}
+	var r io.Reader = rc
+	if limite != semLimiteZip {
+		r = io.LimitReader(rc, limite+1)
+	}
+	n, err := io.Copy(out, r)
+	if errC := out.Close(); err == nil {
+		err = errC
+	}
+	if err == nil && limite != semLimiteZip && n > limite {
+		err = fmt.Errorf("%w: arquivo com mais de %d bytes descompactados", ErrLimiteZip, limite)
+	}
+	if err != nil {
+		os.Remove(arquivo)
+		return n, err
+	}
+	return n, nil
+}
+
+// caminhoSeguro recusa caminhos absolutos e que saiam do diretório de destino (zip-slip)
+func caminhoSeguro(nome string) bool {
+	nome = strings.ReplaceAll(nome, "\\", "/")
+	if nome == "" || strings.HasPrefix(nome, "/") || filepath.VolumeName(nome) != "" || (len(nome) > 1 && nome[1] == ':') {
+		return false
+	}
+	for _, parte := range strings.Split(nome, "/") {
+		if parte == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func aceitaExtensao(ext string, extensoes []string) bool {
+	for _, e := range extensoes {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package files
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entradaTeste struct {
+	nome     string
+	conteudo string
+	deflate  bool
+}
+
+// criaZip grava no diretório temporário do teste um ZIP com as entradas informadas
+func criaZip(t *testing.T, entradas []entradaTeste) string {
+	t.Helper()
+	caminho := filepath.Join(t.TempDir(), "teste.zip")
+	f, err := os.Create(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range entradas {
+		metodo := zip.Store
+		if e.deflate {
+			metodo = zip.Deflate
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.nome, Method: metodo})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.conteudo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return caminho
+}
+
+func TestDescompactaZip(t *testing.T) {
+	extensoes := []string{".pdf", ".txt"}
+
+	casos := []struct {
+		nome     string
+		entradas []entradaTeste
+		limites  LimitesZip
+		erro     error
+		motivos  []string // por entrada gravada ou ignorada: "" se gravada, senão trecho do motivo
+	}{
+		{
+			nome: "entradas aceitas e ignoradas",
+			entradas: []entradaTeste{
+				{nome: "docs/", conteudo: ""},
+				{nome: "docs/inicial.pdf", conteudo: "%PDF inicial"},
+				{nome: "CONTESTACAO.TXT", conteudo: "contestação"},
+				{nome: "planilha.xlsx", conteudo: "x"},
+				{nome: "__MACOSX/._inicial.pdf", conteudo: "x"},
+				{nome: ".oculto.pdf", conteudo: "x"},
+				{nome: "outro.zip", conteudo: "PK"},
+				{nome: "vazio.txt", conteudo: ""},
+			},
+			motivos: []string{"", "", "extensão", "oculto", "oculto", "ZIP dentro do ZIP", "vazio"},
+		},
+		{
+			nome: "zip-slip",
+			entradas: []entradaTeste{
+				{nome: "../fora.pdf", conteudo: "x"},
+				{nome: "a/../../fora.txt", conteudo: "x"},
+				{nome: "/etc/abs.txt", conteudo: "x"},
+				{nome: "C:/abs.txt", conteudo: "x"},
+			},
+			motivos: []string{"caminho inválido", "caminho inválido", "caminho inválido", "caminho inválido"},
+		},
+		{
+			nome: "entradas demais",
+			entradas: []entradaTeste{
+				{nome: "a.txt", conteudo: "a"},
+				{nome: "b.txt", conteudo: "b"},
+				{nome: "c.txt", conteudo: "c"},
+			},
+			limites: LimitesZip{MaxEntradas: 2},
+			erro:    ErrLimiteZip,
+		},
+		{
+			nome: "limite por arquivo",
+			entradas: []entradaTeste{
+				{nome: "grande.txt", conteudo: strings.Repeat("x", 11)},
+				{nome: "pequeno.txt", conteudo: strings.Repeat("x", 10)},
+			},
+			limites: LimitesZip{MaxBytesEntrada: 10},
+			motivos: []string{"mais de 10 bytes", ""},
+		},
+		{
+			nome: "soma do ZIP atingida exatamente",
+			entradas: []entradaTeste{
+				{nome: "a.txt", conteudo: strings.Repeat("a", 6)},
+				{nome: "b.txt", conteudo: strings.Repeat("b", 4)},
+				{nome: "c.txt", conteudo: "c"},
+			},
+			limites: LimitesZip{MaxBytesTotal: 10},
+			motivos: []string{"", "", "limite de"},
+		},
+		{
+			nome: "soma do ZIP excedida",
+			entradas: []entradaTeste{
+				{nome: "a.txt", conteudo: strings.Repeat("a", 6)},
+				{nome: "b.txt", conteudo: strings.Repeat("b", 6)},
+				{nome: "c.txt", conteudo: "c"},
+			},
+			limites: LimitesZip{MaxBytesTotal: 10},
+			motivos: []string{"", "mais de 4 bytes", "limite de"},
+		},
+		{
+			nome: "taxa de compressão",
+			entradas: []entradaTeste{
+				{nome: "bomba.txt", conteudo: strings.Repeat("0", 1<<20), deflate: true},
+				{nome: "normal.txt", conteudo: "texto comum"},
+			},
+			limites: LimitesZip{MaxTaxa: 100},
+			motivos: []string{"limite de descompactação", ""},
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			zipPath := criaZip(t, c.entradas)
+			destino := filepath.Join(t.TempDir(), "saida")
+
+			entradas, err := DescompactaZip(zipPath, destino, c.limites, extensoes)
+			if c.erro != nil {
+				if !errors.Is(err, c.erro) {
+					t.Fatalf("erro = %v, esperado %v", err, c.erro)
+				}
+				if _, errStat := os.Stat(destino); !os.IsNotExist(errStat) {
+					t.Errorf("diretório de destino criado apesar do erro")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if len(entradas) != len(c.motivos) {
+				t.Fatalf("esperadas %d entradas, obtidas %d: %+v", len(c.motivos), len(entradas), entradas)
+			}
+
+			for i, e := range entradas {
+				motivo := c.motivos[i]
+				if motivo == "" {
+					if e.Caminho == "" {
+						t.Errorf("%s: não gravada (%s)", e.Nome, e.Motivo)
+						continue
+					}
+					if filepath.Dir(e.Caminho) != destino {
+						t.Errorf("%s: gravada fora do destino: %s", e.Nome, e.Caminho)
+					}
+					if _, err := os.Stat(e.Caminho); err != nil {
+						t.Errorf("%s: arquivo gravado ausente: %v", e.Nome, err)
+					}
+					continue
+				}
+				if e.Caminho != "" || !strings.Contains(e.Motivo, motivo) {
+					t.Errorf("%s: caminho=%q motivo=%q, esperado ignorada com %q", e.Nome, e.Caminho, e.Motivo, motivo)
+				}
+			}
+		})
+	}
+}